@@ -0,0 +1,185 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// edgex-archive moves events between core-data instances that share no network, by way of archive
+// files written and read by core-data's /api/v1/event/archive endpoint.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/archive"
+)
+
+var usageStr = `Usage: %s <command> [options]
+Commands:
+    export -o <file> [-url <core-data>] [-start <ms>] [-end <ms>] [-device <name>,...] [-format ndjson|cbor]
+                                Write the events and readings of core-data to an archive
+    import [-url <core-data>] <file>
+                                Add the events and readings of an archive that core-data does not hold yet
+    verify <file>               Check the checksum of an archive without importing it
+    -h                          Show this message`
+
+const defaultURL = "http://localhost:48080"
+
+func usage() {
+	fmt.Printf(usageStr+"\n", os.Args[0])
+	os.Exit(0)
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = exportArchive(os.Args[2:])
+	case "import":
+		err = importArchive(os.Args[2:])
+	case "verify":
+		err = verifyArchive(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func exportArchive(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	base := fs.String("url", defaultURL, "base URL of core-data")
+	start := fs.Int64("start", 0, "earliest creation time, in milliseconds")
+	end := fs.Int64("end", 0, "latest creation time, in milliseconds (0 for now)")
+	devices := fs.String("device", "", "comma separated devices (all when empty)")
+	format := fs.String("format", archive.FormatNDJSON, "encoding of the events, ndjson or cbor")
+	output := fs.String("o", "", "archive file to write")
+	fs.Parse(args)
+
+	if *output == "" {
+		return fmt.Errorf("an output file is required")
+	}
+
+	query := url.Values{}
+	query.Set("start", strconv.FormatInt(*start, 10))
+	query.Set("end", strconv.FormatInt(*end, 10))
+	query.Set("format", *format)
+	for _, d := range strings.Split(*devices, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			query.Add("device", d)
+		}
+	}
+
+	resp, err := http.Get(archiveURL(*base) + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return err
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	// Check the file before it leaves the site, as a transfer cut short loses the trailer
+	return verifyArchive([]string{*output})
+}
+
+func importArchive(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	base := fs.String("url", defaultURL, "base URL of core-data")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("one archive file is required")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	resp, err := http.Post(archiveURL(*base), "application/gzip", f)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return err
+	}
+
+	result, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(result))
+	return nil
+}
+
+func verifyArchive(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("one archive file is required")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The file is the operator's own, so it is read whatever its size
+	_, trailer, err := archive.Read(f, 0)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d events and %d readings (%s), sha256 %s\n", args[0], trailer.Count, trailer.Readings, trailer.Format, trailer.SHA256)
+	return nil
+}
+
+func archiveURL(base string) string {
+	return strings.TrimRight(base, "/") + clients.ApiEventRoute + "/archive"
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("core-data returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
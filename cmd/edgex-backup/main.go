@@ -0,0 +1,170 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// edgex-backup takes snapshots of the databases of the EdgeX services and restores them, reading
+// the databases directly so that it works with the services stopped. A snapshot restores into
+// any supported database, which also moves a site from one database to another.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/backup"
+)
+
+var usageStr = `Usage: %s <command> [options]
+Commands:
+    backup -config <file> -o <file>
+                                Write a snapshot of the databases
+    restore -config <file> <file>
+                                Add the objects of a snapshot the databases do not hold yet
+    verify <file>               Check the checksum of a snapshot without restoring it
+    -h                          Show this message
+
+The configuration file gives the database of each service in a [Databases.<service key>] table,
+as in the configuration of sys-mgmt-agent.`
+
+type configuration struct {
+	Databases map[string]config.DatabaseInfo
+}
+
+func usage() {
+	fmt.Printf(usageStr+"\n", os.Args[0])
+	os.Exit(0)
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "backup":
+		err = backupDatabases(os.Args[2:])
+	case "restore":
+		err = restoreDatabases(os.Args[2:])
+	case "verify":
+		err = verifySnapshot(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func backupDatabases(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	configFile := fs.String("config", "", "configuration file giving the databases")
+	output := fs.String("o", "", "snapshot file to write")
+	fs.Parse(args)
+
+	if *output == "" {
+		return fmt.Errorf("an output file is required")
+	}
+	clients, err := connect(*configFile)
+	if err != nil {
+		return err
+	}
+	defer clients.Close()
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err = backup.Backup(f, clients); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return verifySnapshot([]string{*output})
+}
+
+func restoreDatabases(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	configFile := fs.String("config", "", "configuration file giving the databases")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("one snapshot file is required")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	clients, err := connect(*configFile)
+	if err != nil {
+		return err
+	}
+	defer clients.Close()
+
+	summary, err := backup.Restore(f, clients)
+	if err != nil {
+		return err
+	}
+	result, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(result))
+	return nil
+}
+
+func verifySnapshot(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("one snapshot file is required")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header, err := backup.Verify(f)
+	if err != nil {
+		return err
+	}
+	created := time.Unix(0, header.Created*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+	fmt.Printf("%s: version %d taken %s, collections %v\n", args[0], header.Version, created, header.Collections)
+	return nil
+}
+
+func connect(configFile string) (backup.Clients, error) {
+	if configFile == "" {
+		return backup.Clients{}, fmt.Errorf("a configuration file is required")
+	}
+	var c configuration
+	if _, err := toml.DecodeFile(configFile, &c); err != nil {
+		return backup.Clients{}, err
+	}
+	if len(c.Databases) == 0 {
+		return backup.Clients{}, fmt.Errorf("%s gives no databases", configFile)
+	}
+	return backup.Connect(c.Databases)
+}
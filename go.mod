@@ -27,6 +27,8 @@ require (
 	github.com/stretchr/testify v1.3.0
 	github.com/ugorji/go v1.1.4
 	github.com/vektra/mockery v0.0.0-20181123154057-e78b021dcbb5 // indirect
+	go.etcd.io/bbolt v1.3.5
+	golang.org/x/net v0.0.0-20181201002055-351d144fa1fc
 	gopkg.in/eapache/queue.v1 v1.1.0
 	gopkg.in/yaml.v2 v2.2.2
 )
@@ -24,3 +24,9 @@ const (
 const (
 	WritableKey = "/Writable"
 )
+
+// Content types of event payloads beyond the JSON and CBOR ones of the contracts
+const (
+	ContentTypeMsgPack  = "application/x-msgpack"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
@@ -0,0 +1,92 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const acceptanceQuarantine = "quarantine"
+
+// Check the origins of an event and of its readings against the acceptance window.
+// An event out of range is rejected, or stored in quarantine when the action says so.
+// Events replayed from quarantine were reviewed and are accepted whatever their origin.
+func acceptEvent(e models.Event, ctx context.Context) error {
+	if replaying(ctx) {
+		return nil
+	}
+
+	reason := outOfRange(e, db.MakeTimestamp())
+	if reason == "" {
+		return nil
+	}
+
+	if !strings.EqualFold(Configuration.Writable.Acceptance.Action, acceptanceQuarantine) {
+		return errors.NewErrOriginOutOfRange(reason)
+	}
+	return quarantineEvent(e, reason)
+}
+
+// The reason the event or one of its readings is outside the acceptance window, if any
+func outOfRange(e models.Event, now int64) string {
+	info := Configuration.Writable.Acceptance
+	maxFuture := acceptanceBound("future", info.MaxFuture)
+	maxAge := acceptanceBound("age", info.MaxAge)
+	if maxFuture < 0 && maxAge < 0 {
+		return ""
+	}
+
+	check := func(origin int64) string {
+		switch {
+		case origin == 0:
+			return ""
+		case maxFuture >= 0 && origin > now+maxFuture:
+			return fmt.Sprintf("origin %d is more than %s ahead", origin, info.MaxFuture)
+		case maxAge >= 0 && origin < now-maxAge:
+			return fmt.Sprintf("origin %d is more than %s old", origin, info.MaxAge)
+		}
+		return ""
+	}
+
+	if reason := check(e.Origin); reason != "" {
+		return reason
+	}
+	for _, r := range e.Readings {
+		if reason := check(r.Origin); reason != "" {
+			return fmt.Sprintf("reading %s: %s", r.Name, reason)
+		}
+	}
+	return ""
+}
+
+// A bound of the acceptance window in milliseconds, -1 when it is not enforced
+func acceptanceBound(name string, value string) int64 {
+	if value == "" {
+		return -1
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		LoggingClient.Error(fmt.Sprintf("invalid acceptance %s '%s', not enforced", name, value))
+		return -1
+	}
+	return int64(d / time.Millisecond)
+}
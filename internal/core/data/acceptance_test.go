@@ -0,0 +1,142 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestOutOfRange(t *testing.T) {
+	reset()
+	now := int64(10 * 3600 * 1000)
+	hour := int64(3600 * 1000)
+
+	tests := []struct {
+		name      string
+		maxFuture string
+		maxAge    string
+		origin    int64
+		reading   int64
+		rejected  bool
+	}{
+		{"not enforced", "", "", now + 5*hour, 0, false},
+		{"no origin", "1h", "1h", 0, 0, false},
+		{"within", "1h", "2h", now - hour, now + hour/2, false},
+		{"future", "1h", "", now + 2*hour, 0, true},
+		{"too old", "", "2h", now - 3*hour, 0, true},
+		{"old reading", "", "2h", now, now - 3*hour, true},
+		{"invalid bound", "soon", "", now + 2*hour, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Configuration.Writable.Acceptance = AcceptanceInfo{MaxFuture: tt.maxFuture, MaxAge: tt.maxAge}
+			e := models.Event{Event: contract.Event{Device: testDeviceName, Origin: tt.origin,
+				Readings: []contract.Reading{{Name: "Temperature", Origin: tt.reading}}}}
+
+			reason := outOfRange(e, now)
+			if (reason != "") != tt.rejected {
+				t.Errorf("Expected rejected %v, got '%s'", tt.rejected, reason)
+			}
+		})
+	}
+}
+
+func TestAddEventOriginRejected(t *testing.T) {
+	reset()
+	Configuration.Writable.Acceptance = AcceptanceInfo{MaxAge: "1h", Action: "Reject"}
+	dbClient = &dbMock.DBClient{}
+
+	_, err := addNewEvent(models.Event{Event: testEvent}, context.Background())
+	if _, ok := err.(*errors.ErrOriginOutOfRange); !ok {
+		t.Fatalf("Expected the event to be rejected, got %v", err)
+	}
+	if eventErrorStatus(err) != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, eventErrorStatus(err))
+	}
+
+	// Posted, the event is not reported as stored
+	body, _ := json.Marshal(testEvent)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "old") {
+		t.Errorf("Expected the event to be rejected, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAddEventOriginQuarantined(t *testing.T) {
+	reset()
+	Configuration.Writable.Acceptance = AcceptanceInfo{MaxAge: "1h", Action: "Quarantine"}
+	myMock := &dbMock.DBClient{}
+	myMock.On("AddQuarantinedEvent", mock.MatchedBy(func(q db.QuarantinedEvent) bool {
+		return q.Event.Device == testDeviceName && strings.Contains(q.Reason, "old")
+	})).Return("q1", nil)
+	dbClient = myMock
+
+	_, err := addNewEvent(models.Event{Event: testEvent}, context.Background())
+	if _, ok := err.(*errors.ErrEventQuarantined); !ok {
+		t.Fatalf("Expected the event to be quarantined, got %v", err)
+	}
+	if eventErrorStatus(err) != http.StatusAccepted {
+		t.Errorf("Expected status %d, got %d", http.StatusAccepted, eventErrorStatus(err))
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestEventByOriginTime(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+	myMock.On("EventsByOriginTime", int64(1000), int64(2000), 5).Return([]contract.Event{{ID: "1", Origin: 1500}}, nil)
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/event/origin/1000/2000/5", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var events []map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &events); err != nil || len(events) != 1 || events[0]["id"] != "1" {
+		t.Errorf("Expected the event in range, got %s %v", rr.Body.String(), err)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestReadingByOriginTime(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+	myMock.On("ReadingsByOriginTime", int64(1000), int64(2000), 5).Return([]contract.Reading{{Id: "1", Origin: 1500}}, nil)
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reading/origin/1000/2000/5", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/reading/origin/1000/2000/50", nil)
+	rr = httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+	myMock.AssertExpectations(t)
+}
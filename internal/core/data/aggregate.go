@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Read an aggregation from the query string
+// name and device may be repeated, start, end and interval are in milliseconds
+func decodeAggregateQuery(r *http.Request) (q db.AggregateQuery, err error) {
+	params := r.URL.Query()
+	q.Names = params["name"]
+	q.Devices = params["device"]
+
+	if q.Start, err = queryInt(params, "start"); err != nil {
+		return q, err
+	}
+	if q.End, err = queryInt(params, "end"); err != nil {
+		return q, err
+	}
+	if q.Interval, err = queryInt(params, "interval"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// Summarize numeric readings in time buckets
+// The window ends now unless an end is given, and may not span more buckets than the max result count
+func aggregateReadings(q db.AggregateQuery) ([]db.Bucket, error) {
+	if len(q.Names) == 0 {
+		return nil, errors.NewErrInvalidQuery("at least one value descriptor name is required")
+	}
+	if q.Interval <= 0 {
+		return nil, errors.NewErrInvalidQuery("the interval must be positive")
+	}
+	if q.End == 0 {
+		q.End = db.MakeTimestamp()
+	}
+	if q.Start >= q.End {
+		return nil, errors.NewErrInvalidQuery("the start must be before the end")
+	}
+
+	// Every series holds at most one bucket per interval of the window
+	count := (db.BucketStart(q.End, q.Interval)-db.BucketStart(q.Start, q.Interval))/q.Interval + 1
+	if err := checkMaxLimit(int(count)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range q.Names {
+		vd, err := getValueDescriptorByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !isNumericValueDescriptor(vd) {
+			return nil, errors.NewErrInvalidQuery(fmt.Sprintf("value descriptor %s of type %s is not numeric", name, vd.Type))
+		}
+	}
+
+	buckets, err := dbClient.AggregateReadings(q)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, err
+	}
+
+	return buckets, nil
+}
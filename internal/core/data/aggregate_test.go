@@ -0,0 +1,99 @@
+package data
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func newAggregateMockDB() *dbMock.DBClient {
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("ValueDescriptorByName", "temperature").Return(models.ValueDescriptor{Name: "temperature", Type: "F"}, nil)
+	myMock.On("ValueDescriptorByName", "status").Return(models.ValueDescriptor{Name: "status", Type: "S"}, nil)
+	myMock.On("ValueDescriptorByName", "missing").Return(models.ValueDescriptor{}, db.ErrNotFound)
+	myMock.On("AggregateReadings", mock.Anything).Return([]db.Bucket{{Name: "temperature", Count: 2}}, nil)
+
+	return myMock
+}
+
+func TestDecodeAggregateQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/reading/aggregate?name=temperature&name=humidity&device=d1&start=60000&end=120000&interval=60000", nil)
+
+	q, err := decodeAggregateQuery(req)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding aggregation: %v", err)
+	}
+	if len(q.Names) != 2 || q.Devices[0] != "d1" || q.Start != 60000 || q.End != 120000 || q.Interval != 60000 {
+		t.Errorf("Aggregation decoded incorrectly: %+v", q)
+	}
+}
+
+func TestAggregateReadings(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	myMock := newAggregateMockDB()
+	dbClient = myMock
+
+	buckets, err := aggregateReadings(db.AggregateQuery{Names: []string{"temperature"}, Start: 0, End: 599999, Interval: 60000})
+	if err != nil {
+		t.Fatalf("Unexpected error aggregating readings: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Errorf("Expected 1 bucket, got %d", len(buckets))
+	}
+
+	myMock.AssertCalled(t, "AggregateReadings", mock.Anything)
+}
+
+func TestAggregateReadingsInvalid(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	dbClient = newAggregateMockDB()
+
+	tests := []struct {
+		name  string
+		query db.AggregateQuery
+	}{
+		{"no name", db.AggregateQuery{Start: 0, End: 60000, Interval: 60000}},
+		{"no interval", db.AggregateQuery{Names: []string{"temperature"}, Start: 0, End: 60000}},
+		{"empty window", db.AggregateQuery{Names: []string{"temperature"}, Start: 60000, End: 60000, Interval: 1000}},
+		{"not numeric", db.AggregateQuery{Names: []string{"status"}, Start: 0, End: 60000, Interval: 60000}},
+	}
+	for _, tt := range tests {
+		_, err := aggregateReadings(tt.query)
+		if _, ok := err.(*errors.ErrInvalidQuery); !ok {
+			t.Errorf("Expected invalid query error for %s, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestAggregateReadingsTooManyBuckets(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	dbClient = newAggregateMockDB()
+
+	_, err := aggregateReadings(db.AggregateQuery{Names: []string{"temperature"}, Start: 0, End: 60000, Interval: 1000})
+	if _, ok := err.(*errors.ErrLimitExceeded); !ok {
+		t.Errorf("Expected limit exceeded error, got %v", err)
+	}
+}
+
+func TestAggregateReadingsValueDescriptorNotFound(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	dbClient = newAggregateMockDB()
+
+	_, err := aggregateReadings(db.AggregateQuery{Names: []string{"missing"}, Start: 0, End: 60000, Interval: 60000})
+	if _, ok := err.(*errors.ErrDbNotFound); !ok {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/archive"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Content type of the archives
+const contentTypeGzip = "application/gzip"
+
+// archiveQuery selects the events written to an archive
+type archiveQuery struct {
+	Devices []string
+	Start   int64
+	End     int64
+	Format  string
+}
+
+// archiveResult reports what an import did with the events and readings of an archive
+type archiveResult struct {
+	Imported         int `json:"imported"`
+	Skipped          int `json:"skipped"`
+	ImportedReadings int `json:"importedReadings"`
+	SkippedReadings  int `json:"skippedReadings"`
+}
+
+// Read the selection of an archive from the query string
+// device may be repeated, start and end are creation times in milliseconds
+func decodeArchiveQuery(r *http.Request) (q archiveQuery, err error) {
+	params := r.URL.Query()
+	q.Devices = params["device"]
+
+	if q.Start, err = queryInt(params, "start"); err != nil {
+		return q, err
+	}
+	if q.End, err = queryInt(params, "end"); err != nil {
+		return q, err
+	}
+
+	q.Format = params.Get("format")
+	if q.Format == "" {
+		q.Format = archive.FormatNDJSON
+	}
+	if q.Format != archive.FormatNDJSON && q.Format != archive.FormatCBOR {
+		return q, errors.NewErrInvalidQuery(fmt.Sprintf("unsupported archive format '%s'", q.Format))
+	}
+	return q, nil
+}
+
+// Write the selected events and their readings to an archive, oldest first for each device,
+// followed by the readings of the same devices and time range that belong to no event.
+// Events and readings are read a page at a time, so the archive is not bound by the max result count.
+func writeArchive(w io.Writer, q archiveQuery) (int, int, error) {
+	aw, err := archive.NewWriter(w, q.Format)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	devices := q.Devices
+	if len(devices) == 0 {
+		devices = []string{""}
+	}
+
+	// The readings of the events archived, to tell those added on their own
+	archived := make(map[string]bool)
+	for _, device := range devices {
+		filter := db.EventFilter{Device: device, Start: q.Start, End: q.End}
+		var cursor db.Cursor
+		for {
+			events, next, err := dbClient.EventsPage(filter, cursor, Configuration.Service.MaxResultCount)
+			if err != nil {
+				return aw.Count(), aw.ReadingCount(), err
+			}
+			for _, e := range events {
+				if err = aw.Write(e); err != nil {
+					return aw.Count(), aw.ReadingCount(), err
+				}
+				for _, r := range e.Readings {
+					archived[r.Id] = true
+				}
+			}
+			if next.IsZero() {
+				break
+			}
+			cursor = next
+		}
+	}
+
+	for _, device := range devices {
+		filter := db.ReadingFilter{Device: device, Start: q.Start, End: q.End}
+		var cursor db.Cursor
+		for {
+			readings, next, err := dbClient.ReadingsPage(filter, cursor, Configuration.Service.MaxResultCount)
+			if err != nil {
+				return aw.Count(), aw.ReadingCount(), err
+			}
+			for _, r := range readings {
+				if archived[r.Id] {
+					continue
+				}
+				if err = aw.WriteReading(r); err != nil {
+					return aw.Count(), aw.ReadingCount(), err
+				}
+			}
+			if next.IsZero() {
+				break
+			}
+			cursor = next
+		}
+	}
+
+	return aw.Count(), aw.ReadingCount(), aw.Close()
+}
+
+// Add the events and readings of an archive that are not in the database yet.
+// Events are matched by id or, lacking one, by the checksum of their content, so that importing
+// the same archive twice adds nothing the second time. Readings are matched by id.
+// Events and readings are stored as they were archived, without being checked against metadata
+// or published. The archive is checked as a whole before anything is added, its size once
+// decompressed being bound by Writable.Archive.MaxContentSize.
+func importArchive(r io.Reader) (archiveResult, error) {
+	var result archiveResult
+
+	content, _, err := archive.Read(r, Configuration.Writable.Archive.MaxContentSize)
+	if err != nil {
+		return result, errors.NewErrInvalidArchive(err)
+	}
+
+	seen := make(map[string]bool)
+	var batch []models.Event
+	for _, e := range content.Events {
+		me := models.Event{Event: e}
+		key := e.ID
+		if key == "" {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return result, err
+			}
+			me.Checksum = checksum(data)
+			key = me.Checksum
+		}
+
+		exists := seen[key]
+		if !exists {
+			exists, err = archivedEventExists(me)
+			if err != nil {
+				return result, err
+			}
+		}
+		seen[key] = true
+		if exists {
+			result.Skipped++
+			continue
+		}
+
+		batch = append(batch, me)
+		if len(batch) == Configuration.Service.MaxResultCount {
+			if err = addArchivedEvents(batch, &result); err != nil {
+				return result, err
+			}
+			batch = nil
+		}
+	}
+
+	if len(batch) > 0 {
+		if err = addArchivedEvents(batch, &result); err != nil {
+			return result, err
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, reading := range content.Readings {
+		exists := reading.Id != "" && seen[reading.Id]
+		if !exists && reading.Id != "" {
+			_, err = dbClient.ReadingById(reading.Id)
+			if err != nil && err != db.ErrNotFound {
+				return result, err
+			}
+			exists = err == nil
+		}
+		seen[reading.Id] = true
+		if exists {
+			result.SkippedReadings++
+			continue
+		}
+
+		if _, err = dbClient.AddReading(reading); err != nil {
+			LoggingClient.Error(fmt.Sprintf("error importing archived reading %s: %s", reading.Id, err.Error()))
+			return result, err
+		}
+		result.ImportedReadings++
+	}
+	return result, nil
+}
+
+// Both databases report an unknown checksum as not found, as they do an unknown id
+func archivedEventExists(e models.Event) (bool, error) {
+	var err error
+	if e.ID == "" {
+		_, err = dbClient.EventsByChecksum(e.Checksum)
+	} else {
+		_, err = dbClient.EventById(e.ID)
+	}
+	switch err {
+	case nil:
+		return true, nil
+	case db.ErrNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func addArchivedEvents(batch []models.Event, result *archiveResult) error {
+	if _, err := dbClient.AddEvents(batch); err != nil {
+		LoggingClient.Error(fmt.Sprintf("error importing %d archived events: %s", len(batch), err.Error()))
+		return err
+	}
+	result.Imported += len(batch)
+	return nil
+}
+
+// The file name suggested for an archive
+func archiveName(q archiveQuery) string {
+	return fmt.Sprintf("events-%d-%d.%s.gz", q.Start, q.End, q.Format)
+}
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/archive"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestArchiveExport(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 1
+	myMock := &dbMock.DBClient{}
+
+	// Each device is read a page at a time
+	next := db.Cursor{Created: 1, Id: "e1"}
+	myMock.On("EventsPage", db.EventFilter{Device: "d1", Start: 10, End: 20}, db.Cursor{}, 1).
+		Return([]contract.Event{{ID: "e1", Device: "d1"}}, next, nil)
+	myMock.On("EventsPage", db.EventFilter{Device: "d1", Start: 10, End: 20}, next, 1).
+		Return([]contract.Event{{ID: "e2", Device: "d1"}}, db.Cursor{}, nil)
+	myMock.On("EventsPage", db.EventFilter{Device: "d2", Start: 10, End: 20}, db.Cursor{}, 1).
+		Return([]contract.Event{{ID: "e3", Device: "d2", Readings: []contract.Reading{{Id: "r1"}}}}, db.Cursor{}, nil)
+
+	// The readings of the events archived are left out of the readings added on their own
+	myMock.On("ReadingsPage", db.ReadingFilter{Device: "d1", Start: 10, End: 20}, db.Cursor{}, 1).
+		Return([]contract.Reading{}, db.Cursor{}, nil)
+	myMock.On("ReadingsPage", db.ReadingFilter{Device: "d2", Start: 10, End: 20}, db.Cursor{}, 1).
+		Return([]contract.Reading{{Id: "r1"}, {Id: "r2", Device: "d2"}}, db.Cursor{}, nil)
+
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/event/archive?start=10&end=20&device=d1&device=d2&format=cbor", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Content-Disposition") != `attachment; filename="events-10-20.cbor.gz"` {
+		t.Errorf("Unexpected disposition %s", rr.Header().Get("Content-Disposition"))
+	}
+
+	content, trailer, err := archive.Read(rr.Body, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error reading the archive: %v", err)
+	}
+	if len(content.Events) != 3 || content.Events[2].ID != "e3" || trailer.Format != archive.FormatCBOR {
+		t.Errorf("Unexpected archive %v %+v", content.Events, trailer)
+	}
+	if len(content.Readings) != 1 || content.Readings[0].Id != "r2" {
+		t.Errorf("Unexpected archived readings %v", content.Readings)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestArchiveExportInvalidFormat(t *testing.T) {
+	reset()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/event/archive?format=xml", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestArchiveImport(t *testing.T) {
+	reset()
+	Configuration.Writable.ChecksumAlgo = ChecksumAlgoxxHash
+	Configuration.Service.MaxResultCount = 100
+	myMock := &dbMock.DBClient{}
+
+	var buf bytes.Buffer
+	w, _ := archive.NewWriter(&buf, archive.FormatNDJSON)
+	w.Write(contract.Event{ID: "known", Device: "d1"})
+	w.Write(contract.Event{ID: "new", Device: "d1"})
+	w.Write(contract.Event{ID: "new", Device: "d1"})
+	w.Write(contract.Event{Device: "d2", Origin: 5})
+	w.WriteReading(contract.Reading{Id: "known", Name: "temperature", Value: "20"})
+	w.WriteReading(contract.Reading{Id: "added", Name: "temperature", Value: "21"})
+	w.Close()
+
+	myMock.On("EventById", "known").Return(contract.Event{ID: "known"}, nil)
+	myMock.On("EventById", "new").Return(contract.Event{}, db.ErrNotFound).Once()
+	myMock.On("EventsByChecksum", mock.Anything).Return([]contract.Event{}, db.ErrNotFound).Once()
+	myMock.On("AddEvents", mock.MatchedBy(func(events []models.Event) bool {
+		return len(events) == 2 && events[0].ID == "new" && events[1].Checksum != ""
+	})).Return([]string{"new", "generated"}, nil)
+	myMock.On("ReadingById", "known").Return(contract.Reading{Id: "known"}, nil)
+	myMock.On("ReadingById", "added").Return(contract.Reading{}, db.ErrNotFound)
+	myMock.On("AddReading", mock.MatchedBy(func(r contract.Reading) bool { return r.Id == "added" })).Return("added", nil)
+
+	dbClient = myMock
+
+	result, err := importArchive(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != (archiveResult{Imported: 2, Skipped: 2, ImportedReadings: 1, SkippedReadings: 1}) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestArchiveImportInvalid(t *testing.T) {
+	reset()
+
+	_, err := importArchive(bytes.NewReader([]byte("not an archive")))
+	if _, ok := err.(*errors.ErrInvalidArchive); !ok {
+		t.Errorf("Expected an invalid archive error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event/archive", bytes.NewReader([]byte("not an archive")))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestArchiveImportTooLarge(t *testing.T) {
+	reset()
+
+	var buf bytes.Buffer
+	w, _ := archive.NewWriter(&buf, archive.FormatNDJSON)
+	w.Write(contract.Event{ID: "e1", Device: strings.Repeat("d", 1000)})
+	w.Close()
+
+	tests := []struct {
+		name    string
+		archive ArchiveInfo
+	}{
+		{"compressed", ArchiveInfo{MaxSize: int64(buf.Len() - 1)}},
+		{"decompressed", ArchiveInfo{MaxContentSize: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Configuration.Writable.Archive = tt.archive
+			dbClient = &dbMock.DBClient{}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/event/archive", bytes.NewReader(buf.Bytes()))
+			rr := httptest.NewRecorder()
+			testRoutes.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Caches of the lookups made while ingesting events, only holding what was found
+var vdCache = newTTLCache()     // Value descriptors by name
+var deviceCache = newTTLCache() // Device ids by the name or id events refer to them with
+var unitCache = newTTLCache()   // Units of the device resources of a device, by the same keys
+
+// ttlCache holds values for as long as the time to live given when reading them
+type ttlCache struct {
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+	hits    uint64
+	misses  uint64
+}
+
+type cacheEntry struct {
+	value interface{}
+	added time.Time
+}
+
+// cacheStats counts the lookups served by a cache since core-data started
+type cacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+func newTTLCache() *ttlCache {
+	return &ttlCache{entries: make(map[string]cacheEntry)}
+}
+
+// Return the value of key if it was added less than ttl ago
+func (c *ttlCache) get(key string, ttl time.Duration) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[key]
+	if ok && time.Since(e.added) >= ttl {
+		delete(c.entries, key)
+		ok = false
+	}
+
+	if ok {
+		c.hits++
+		return e.value, true
+	}
+	c.misses++
+	return nil, false
+}
+
+func (c *ttlCache) set(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = cacheEntry{value: value, added: time.Now()}
+}
+
+// Remove the entry stored under key and those holding the string value
+func (c *ttlCache) remove(key string, value string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, key)
+	for k, e := range c.entries {
+		if e.value == value {
+			delete(c.entries, k)
+		}
+	}
+}
+
+func (c *ttlCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+}
+
+func (c *ttlCache) stats() cacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return cacheStats{Hits: c.hits, Misses: c.misses, Entries: len(c.entries)}
+}
+
+// Parse a configured time to live, caching being disabled when it is empty or invalid
+func cacheTTL(ttl string) time.Duration {
+	if ttl == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("invalid cache TTL '%s', caching disabled", ttl))
+		return 0
+	}
+	return d
+}
+
+// Look up a value descriptor by name through the cache
+// Errors are those of the database client, so db.ErrNotFound when there is no such value descriptor
+func cachedValueDescriptorByName(name string) (contract.ValueDescriptor, error) {
+	ttl := cacheTTL(Configuration.Writable.Cache.ValueDescriptorTTL)
+	if ttl > 0 {
+		if v, ok := vdCache.get(name, ttl); ok {
+			return v.(contract.ValueDescriptor), nil
+		}
+	}
+
+	vd, err := dbClient.ValueDescriptorByName(name)
+	if err != nil {
+		return vd, err
+	}
+
+	if ttl > 0 {
+		vdCache.set(name, vd)
+	}
+	return vd, nil
+}
+
+// Check through the cache that metadata knows a device, by name or id
+func cachedCheckForDevice(device string, ctx context.Context) error {
+	ttl := cacheTTL(Configuration.Writable.Cache.DeviceTTL)
+	if ttl > 0 {
+		if _, ok := deviceCache.get(device, ttl); ok {
+			return nil
+		}
+	}
+
+	d, err := mdc.CheckForDevice(device, ctx)
+	if err != nil {
+		return err
+	}
+
+	if ttl > 0 {
+		deviceCache.set(device, d.Id)
+	}
+	return nil
+}
+
+// Look up through the cache the units the profile of a device gives its device resources,
+// keyed by device resource name. Metadata is queried even when MetaDataCheck is disabled.
+func cachedDeviceUnits(device string, ctx context.Context) (map[string]string, error) {
+	ttl := cacheTTL(Configuration.Writable.Cache.DeviceTTL)
+	if ttl > 0 {
+		if v, ok := unitCache.get(device, ttl); ok {
+			return v.(map[string]string), nil
+		}
+	}
+
+	d, err := mdc.CheckForDevice(device, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	units := make(map[string]string)
+	for _, r := range d.Profile.DeviceResources {
+		if u := r.Properties.Units.DefaultValue; u != "" {
+			units[r.Name] = u
+		}
+	}
+
+	if ttl > 0 {
+		unitCache.set(device, units)
+	}
+	return units, nil
+}
+
+// Value descriptors are rarely changed, so any change drops all of them
+func invalidateValueDescriptors() {
+	vdCache.clear()
+}
+
+// Drop a device however events refer to it, following a callback from metadata
+func invalidateDevice(id string) {
+	deviceCache.remove(id, id)
+	// Units are not keyed by id alone, and a changed profile may affect any device
+	unitCache.clear()
+}
+
+// Units are not kept by profile, so a changed profile drops all of them
+func invalidateProfile() {
+	unitCache.clear()
+}
+
+func cacheMetrics() map[string]cacheStats {
+	return map[string]cacheStats{
+		"ValueDescriptors": vdCache.stats(),
+		"Devices":          deviceCache.stats(),
+		"DeviceUnits":      unitCache.stats(),
+	}
+}
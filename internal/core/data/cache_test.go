@@ -0,0 +1,156 @@
+package data
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata/mocks"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestTTLCache(t *testing.T) {
+	c := newTTLCache()
+	c.set("a", 1)
+
+	if v, ok := c.get("a", time.Minute); !ok || v != 1 {
+		t.Errorf("Expected a hit on a fresh entry, got %v %v", v, ok)
+	}
+	if _, ok := c.get("a", 0); ok {
+		t.Errorf("Expected a miss on an expired entry")
+	}
+	if _, ok := c.get("a", time.Minute); ok {
+		t.Errorf("Expected the expired entry to be dropped")
+	}
+
+	stats := c.stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestTTLCacheRemove(t *testing.T) {
+	c := newTTLCache()
+	c.set("name", "id1")
+	c.set("id1", "id1")
+	c.set("other", "id2")
+
+	c.remove("id1", "id1")
+
+	if stats := c.stats(); stats.Entries != 1 {
+		t.Errorf("Only the entry of id2 should remain, %d entries left", stats.Entries)
+	}
+}
+
+func TestCachedValueDescriptorByName(t *testing.T) {
+	reset()
+	Configuration.Writable.Cache.ValueDescriptorTTL = "1m"
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("ValueDescriptorByName", "Temperature").Return(contract.ValueDescriptor{Name: "Temperature"}, nil).Times(2)
+	myMock.On("ValueDescriptorByName", "Unknown").Return(contract.ValueDescriptor{}, db.ErrNotFound).Times(2)
+
+	dbClient = myMock
+
+	for i := 0; i < 3; i++ {
+		if vd, err := cachedValueDescriptorByName("Temperature"); err != nil || vd.Name != "Temperature" {
+			t.Fatalf("Unexpected lookup result %v %v", vd, err)
+		}
+	}
+	// Missing value descriptors are looked up every time
+	for i := 0; i < 2; i++ {
+		if _, err := cachedValueDescriptorByName("Unknown"); err != db.ErrNotFound {
+			t.Fatalf("Expected not found, got %v", err)
+		}
+	}
+
+	invalidateValueDescriptors()
+	if _, err := cachedValueDescriptorByName("Temperature"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	myMock.AssertExpectations(t)
+}
+
+func TestCachedValueDescriptorByNameDisabled(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("ValueDescriptorByName", "Temperature").Return(contract.ValueDescriptor{Name: "Temperature"}, nil).Times(2)
+
+	dbClient = myMock
+
+	cachedValueDescriptorByName("Temperature")
+	cachedValueDescriptorByName("Temperature")
+
+	myMock.AssertExpectations(t)
+	if stats := vdCache.stats(); stats.Entries != 0 {
+		t.Errorf("Nothing should be cached when the TTL is empty")
+	}
+}
+
+func TestCallbackInvalidatesDevice(t *testing.T) {
+	reset()
+	Configuration.Writable.Cache.DeviceTTL = "1m"
+	client := &mocks.DeviceClient{}
+	client.On("CheckForDevice", testDeviceName, mock.Anything).Return(contract.Device{Id: "id1", Name: testDeviceName}, nil).Times(2)
+
+	saved := mdc
+	mdc = client
+	defer func() { mdc = saved }()
+
+	checkCachedDevice := func() {
+		if err := cachedCheckForDevice(testDeviceName, context.Background()); err != nil {
+			t.Fatalf("Unexpected error checking device: %v", err)
+		}
+	}
+	checkCachedDevice()
+	checkCachedDevice()
+
+	tests := []struct {
+		method   string
+		expected int
+	}{
+		// A new device leaves the cache alone
+		{http.MethodPost, 1},
+		{http.MethodPut, 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/v1/callback", strings.NewReader(`{"type":"DEVICE","id":"id1"}`))
+		rr := httptest.NewRecorder()
+		testRoutes.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s callback returned %d", tt.method, rr.Code)
+		}
+		if stats := deviceCache.stats(); stats.Entries != tt.expected {
+			t.Errorf("%s callback should leave %d cached devices, got %d", tt.method, tt.expected, stats.Entries)
+		}
+	}
+
+	checkCachedDevice()
+	client.AssertExpectations(t)
+}
+
+func TestCallbackInvalidatesProfileUnits(t *testing.T) {
+	reset()
+	unitCache.set(testDeviceName, map[string]string{"Temperature": "C"})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/callback", strings.NewReader(`{"type":"PROFILE","id":"profile1"}`))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("callback returned %d", rr.Code)
+	}
+	if stats := unitCache.stats(); stats.Entries != 0 {
+		t.Errorf("profile callback should drop the cached units, got %d", stats.Entries)
+	}
+}
@@ -33,4 +33,148 @@ type WritableInfo struct {
 	ValidateCheck              bool
 	LogLevel                   string
 	ChecksumAlgo               string
+	Retention                  RetentionInfo
+	Cache                      CacheInfo
+	Normalization              NormalizationInfo
+	Suppression                SuppressionInfo
+	Subscriptions              SubscriptionInfo
+	Derivation                 DerivationInfo
+	Deduplication              DeduplicationInfo
+	Acceptance                 AcceptanceInfo
+	Quarantine                 QuarantineInfo
+	Archive                    ArchiveInfo
+}
+
+// ArchiveInfo bounds the archives imported through /api/v1/event/archive, in bytes, 0 for no limit
+type ArchiveInfo struct {
+	// Size of the compressed archive posted
+	MaxSize int64
+	// Size of the archive once decompressed
+	MaxContentSize int64
+}
+
+// QuarantineInfo enables keeping the events whose device is not found or whose readings fail
+// validation, so that they can be replayed from /api/v1/quarantine once the device or the value
+// descriptors are fixed, instead of being lost.
+type QuarantineInfo struct {
+	Enabled bool
+}
+
+// AcceptanceInfo bounds the origins of the events received relative to the time they are received.
+// Events of devices that were offline may be backfilled as long as they are within MaxAge, while
+// origins beyond either bound are rejected or, with the Quarantine action, set aside for review.
+// An empty bound is not enforced and events without an origin are always accepted.
+type AcceptanceInfo struct {
+	// How far ahead of the time received an origin may be, e.g. '1m'
+	MaxFuture string
+	// How far behind the time received an origin may be, e.g. '72h'
+	MaxAge string
+	// 'Reject' or 'Quarantine' the events out of range
+	Action string
+}
+
+// DeduplicationInfo tells how long the events added are remembered, so that an event posted again,
+// as device services retry after a timeout, is not added twice. Events posted with an
+// Idempotency-Key header are recognized by their key whether Enabled is set or not.
+type DeduplicationInfo struct {
+	// Recognize events of the same device with the same origin and content
+	Enabled bool
+	// How long added events are remembered, e.g. '5m'
+	Window string
+}
+
+// DerivationInfo enables computing readings from the other readings of the events received.
+// Derived readings are added to the event before it is stored and exported, and must have a
+// value descriptor they are validated against like any reading.
+type DerivationInfo struct {
+	Enabled bool
+	// Derived readings keyed by the name of their value descriptor
+	ValueDescriptors map[string]DerivedReading
+}
+
+// DerivedReading tells how a reading is computed
+type DerivedReading struct {
+	// Arithmetic over the readings of the event by value descriptor name, e.g. 'voltage * current'.
+	// The reading is not derived for events lacking any of the readings used.
+	Expression string
+	// Devices the reading is derived for, all devices when empty
+	Devices []string
+	// Number of results averaged per device, for a rolling average. 0 or 1 keeps each result as is.
+	Window int
+}
+
+// SubscriptionInfo bounds the clients streaming events from /api/v1/event/subscribe
+type SubscriptionInfo struct {
+	// Streams open at once, 0 for no limit
+	MaxConnections int
+	// Events queued for each client before new ones are dropped
+	BufferSize int
+	// How long a client may leave its queue full before being disconnected, e.g. '30s'
+	SlowClientTimeout string
+	// Interval of the comments sent to idle Server-Sent Events clients, e.g. '15s'
+	KeepAlive string
+}
+
+// SuppressionInfo enables dropping the readings that repeat the last one accepted from the same
+// device for the same value descriptor. Value descriptors without a policy are never suppressed.
+type SuppressionInfo struct {
+	Enabled bool
+	// Policies keyed by value descriptor name
+	ValueDescriptors map[string]SuppressionPolicy
+}
+
+// SuppressionPolicy tells which readings of a value descriptor are redundant
+type SuppressionPolicy struct {
+	// Numeric readings that differ by no more than Deadband from the last accepted one are suppressed
+	Deadband float64
+	// Readings equal to the last accepted one are suppressed
+	ChangeOfValue bool
+	// Readings taken sooner than MinInterval after the last accepted one are suppressed, e.g. '10s'
+	MinInterval string
+	// Readings taken MaxInterval or more after the last accepted one are kept even when unchanged
+	MaxInterval string
+}
+
+// NormalizationInfo enables the conversion of readings on ingest into a canonical unit per value
+// descriptor. Readings are converted from the units their device profile gives the device resource
+// into the UomLabel of their value descriptor, unless Units names another for the value descriptor.
+type NormalizationInfo struct {
+	Enabled bool
+	// Canonical units keyed by value descriptor name
+	Units map[string]string
+}
+
+// CacheInfo sets how long the lookups made while ingesting events are kept, e.g. '30s'
+// An empty time to live disables the cache.
+type CacheInfo struct {
+	ValueDescriptorTTL string
+	DeviceTTL          string
+}
+
+// RetentionInfo configures the janitor removing old events and readings in the background.
+// Limits left at zero are not enforced. Device policies replace the global MaxAge, MaxEvents
+// and OnlyPushed for the events of their device, while value descriptor policies remove the
+// readings of their value descriptor sooner than the events holding them.
+type RetentionInfo struct {
+	Enabled bool
+	// Time between two passes of the janitor, e.g. '5m'
+	Interval string
+	// Maximum number of events or readings removed by a single delete
+	BatchSize int
+	// Approximate size of the stored events and readings above which the oldest are removed
+	MaxBytes   int64
+	MaxAge     int64
+	MaxEvents  int
+	OnlyPushed bool
+	// Policies keyed by device name
+	Devices map[string]RetentionPolicy
+	// Policies keyed by value descriptor name
+	ValueDescriptors map[string]RetentionPolicy
+}
+
+// RetentionPolicy limits the age in milliseconds and number of the events kept
+type RetentionPolicy struct {
+	MaxAge     int64
+	MaxEvents  int
+	OnlyPushed bool
 }
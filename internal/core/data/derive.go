@@ -0,0 +1,260 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/expression"
+)
+
+// The parsed expressions of the derived readings and the results their rolling averages are over
+var derivation = newDerivationState()
+
+type derivationState struct {
+	mutex       sync.Mutex
+	expressions map[string]derivedExpression // By value descriptor name
+	windows     map[suppressionKey][]float64
+}
+
+// An expression is parsed again when the configuration gives its value descriptor another source
+type derivedExpression struct {
+	source     string
+	expression *expression.Expression
+}
+
+// A result added to the window of a derived reading, undone when its event is not stored after all
+type derivationChange struct {
+	key      suppressionKey
+	window   []float64
+	previous []float64
+	existed  bool
+}
+
+func newDerivationState() *derivationState {
+	return &derivationState{
+		expressions: make(map[string]derivedExpression),
+		windows:     make(map[suppressionKey][]float64),
+	}
+}
+
+func (d *derivationState) parse(name string, source string) (*expression.Expression, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	x, ok := d.expressions[name]
+	if !ok || x.source != source {
+		parsed, err := expression.Parse(source)
+		if err != nil {
+			delete(d.expressions, name)
+			return nil, err
+		}
+		x = derivedExpression{source: source, expression: parsed}
+		d.expressions[name] = x
+	}
+	return x.expression, nil
+}
+
+// Drop the parsed expression of a value descriptor that was updated or deleted
+func (d *derivationState) forget(name string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.expressions, name)
+}
+
+// Add a result to the window of a device's derived reading, returning the average of the window
+func (d *derivationState) average(device string, name string, value float64, size int) (float64, derivationChange) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	key := suppressionKey{device: device, name: name}
+	previous, ok := d.windows[key]
+	kept := previous
+	if len(kept) >= size {
+		kept = kept[len(kept)-size+1:]
+	}
+	// Always a new slice, so that undo can tell whether the window changed since
+	window := make([]float64, 0, len(kept)+1)
+	window = append(append(window, kept...), value)
+	d.windows[key] = window
+
+	var sum float64
+	for _, v := range window {
+		sum += v
+	}
+	return sum / float64(len(window)), derivationChange{key: key, window: window, previous: previous, existed: ok}
+}
+
+// Restore the windows an event changed when it could not be stored, leaving alone those that
+// changed again since
+func (d *derivationState) undo(changes []derivationChange) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		window := d.windows[c.key]
+		if len(window) != len(c.window) || &window[0] != &c.window[0] {
+			continue
+		}
+		if c.existed {
+			d.windows[c.key] = c.previous
+		} else {
+			delete(d.windows, c.key)
+		}
+	}
+}
+
+func (d *derivationState) clear() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.expressions = make(map[string]derivedExpression)
+	d.windows = make(map[suppressionKey][]float64)
+}
+
+// Append the derived readings of an event when derivation is enabled.
+// Readings are derived from the readings the event came with, after normalization, and are
+// checked against their value descriptor. A reading the event already has is not derived again.
+// Value descriptors are kept in found as with validateReadings.
+// The results are added to their windows right away, so that the next events of a batch are
+// averaged with them. The changes returned are to be undone if the event is not stored.
+func deriveReadings(e models.Event, found map[string]contract.ValueDescriptor, ctx context.Context) (models.Event, []derivationChange, error) {
+	info := Configuration.Writable.Derivation
+	if !info.Enabled || len(info.ValueDescriptors) == 0 || len(e.Readings) == 0 {
+		return e, nil, nil
+	}
+
+	values := make(map[string]interface{}, len(e.Readings))
+	for _, r := range e.Readings {
+		values[r.Name] = readingValue(r.Value)
+	}
+	vars := func(name string) (interface{}, bool) {
+		v, ok := values[name]
+		return v, ok
+	}
+
+	// Derived in the order of their names, so that events always list them the same way
+	names := make([]string, 0, len(info.ValueDescriptors))
+	for name := range info.ValueDescriptors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var derived []contract.Reading
+	var changes []derivationChange
+	for _, name := range names {
+		d := info.ValueDescriptors[name]
+		if _, ok := values[name]; ok || (len(d.Devices) > 0 && !contains(d.Devices, e.Device)) {
+			continue
+		}
+
+		x, err := derivation.parse(name, d.Expression)
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("invalid expression for derived reading %s: %s", name, err.Error()))
+			derivation.undo(changes)
+			return e, nil, err
+		}
+		if !hasVariables(x, values) {
+			continue
+		}
+
+		v, err := x.Float(vars)
+		if err != nil {
+			derivation.undo(changes)
+			return e, nil, errors.NewErrDerivation(name, err)
+		}
+		if d.Window > 1 {
+			var change derivationChange
+			v, change = derivation.average(e.Device, name, v, d.Window)
+			changes = append(changes, change)
+		}
+
+		r := contract.Reading{Device: e.Device, Name: name, Value: formatConverted(v), Origin: e.Origin}
+		if err = validateDerivedReading(r, found); err != nil {
+			derivation.undo(changes)
+			return e, nil, err
+		}
+		derived = append(derived, r)
+	}
+
+	if len(derived) == 0 {
+		return e, changes, nil
+	}
+
+	LoggingClient.Debug(fmt.Sprintf("derived %d readings for device %s", len(derived), e.Device))
+	readings := make([]contract.Reading, 0, len(e.Readings)+len(derived))
+	readings = append(readings, e.Readings...)
+	e.Readings = append(readings, derived...)
+
+	// Binary payloads are published as received, so they must carry the derived readings too
+	if e.Bytes != nil {
+		var err error
+		e.Bytes, err = encodeEvent(e, clients.FromContext(clients.ContentType, ctx))
+		if err != nil {
+			derivation.undo(changes)
+			return e, nil, err
+		}
+	}
+	return e, changes, nil
+}
+
+// Readings are numbers in expressions when they parse as such, booleans likewise and strings otherwise
+func readingValue(value string) interface{} {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
+
+func hasVariables(x *expression.Expression, values map[string]interface{}) bool {
+	for _, name := range x.Variables() {
+		if _, ok := values[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Derived readings are always validated, whether ValidateCheck is set or not, as their value
+// descriptor tells what they hold
+func validateDerivedReading(r contract.Reading, found map[string]contract.ValueDescriptor) error {
+	vd, ok := found[r.Name]
+	if !ok {
+		var err error
+		vd, err = cachedValueDescriptorByName(r.Name)
+		if err != nil {
+			if err == db.ErrNotFound {
+				return errors.NewErrValueDescriptorNotFound(r.Name)
+			}
+			return err
+		}
+		found[r.Name] = vd
+	}
+	return isValidValueDescriptor(vd, r)
+}
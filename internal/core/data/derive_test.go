@@ -0,0 +1,181 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Enable derivation of the given readings, with value descriptors for a float, a bounded integer
+// and a missing one
+func setupDerivation(readings map[string]DerivedReading) {
+	reset()
+	Configuration.Writable.Derivation = DerivationInfo{Enabled: true, ValueDescriptors: readings}
+
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", "Scaled").Return(contract.ValueDescriptor{Name: "Scaled", Type: "F"}, nil)
+	myMock.On("ValueDescriptorByName", "Limited").Return(contract.ValueDescriptor{Name: "Limited", Type: "I", Max: "10"}, nil)
+	myMock.On("ValueDescriptorByName", "Unknown").Return(contract.ValueDescriptor{}, db.ErrNotFound)
+	dbClient = myMock
+}
+
+func TestDeriveReadings(t *testing.T) {
+	setupDerivation(map[string]DerivedReading{
+		"Scaled":  {Expression: "Temperature * 2 + Pressure"},
+		"Missing": {Expression: "Humidity * 2"},
+		"Other":   {Expression: "Temperature", Devices: []string{"other"}},
+	})
+
+	e := models.Event{Event: testEvent}
+	result, _, err := deriveReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Readings) != 3 {
+		t.Fatalf("Expected 3 readings, got %v", result.Readings)
+	}
+	r := result.Readings[2]
+	if r.Name != "Scaled" || r.Value != "91.01325" || r.Device != testDeviceName || r.Origin != testOrigin {
+		t.Errorf("Unexpected derived reading %+v", r)
+	}
+	if len(testEvent.Readings) != 2 {
+		t.Errorf("The readings of the event passed in should be left alone")
+	}
+}
+
+func TestDeriveReadingsWindow(t *testing.T) {
+	setupDerivation(map[string]DerivedReading{"Scaled": {Expression: "Temperature", Window: 2}})
+
+	expected := []string{"45", "50", "60"}
+	for i, value := range []string{"45", "55", "65"} {
+		e := models.Event{Event: contract.Event{Device: testDeviceName, Readings: []contract.Reading{
+			{Name: "Temperature", Value: value},
+		}}}
+		result, _, err := deriveReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Readings[1].Value != expected[i] {
+			t.Errorf("Expected average %s, got %s", expected[i], result.Readings[1].Value)
+		}
+	}
+}
+
+func TestDeriveReadingsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		reading    string
+		expression string
+		check      func(error) bool
+	}{
+		{"evaluation", "Scaled", "Temperature / 0", func(err error) bool {
+			_, ok := err.(*errors.ErrDerivation)
+			return ok
+		}},
+		{"out of range", "Limited", "Temperature", func(err error) bool {
+			_, ok := err.(*errors.ErrValueDescriptorInvalid)
+			return ok
+		}},
+		{"no value descriptor", "Unknown", "Temperature", func(err error) bool {
+			_, ok := err.(*errors.ErrValueDescriptorNotFound)
+			return ok
+		}},
+		{"syntax", "Scaled", "Temperature *", func(err error) bool {
+			return err != nil && eventErrorStatus(err) == 500
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupDerivation(map[string]DerivedReading{tt.reading: {Expression: tt.expression}})
+
+			e := models.Event{Event: testEvent}
+			_, _, err := deriveReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+			if !tt.check(err) {
+				t.Errorf("Unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestPostEventDerivationFailed(t *testing.T) {
+	setupDerivation(map[string]DerivedReading{"Scaled": {Expression: "Temperature / 0"}})
+
+	// The event is rejected rather than reported as stored
+	body, _ := json.Marshal(testEvent)
+	req := httptest.NewRequest(http.MethodPost, clients.ApiEventRoute, bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "Scaled") {
+		t.Errorf("Expected the derivation to be rejected, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDeriveReadingsDisabled(t *testing.T) {
+	reset()
+	Configuration.Writable.Derivation.ValueDescriptors = map[string]DerivedReading{"Scaled": {Expression: "Temperature"}}
+
+	e := models.Event{Event: testEvent}
+	result, _, err := deriveReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+	if err != nil || len(result.Readings) != 2 {
+		t.Errorf("Events should pass unchanged, got %v %v", result.Readings, err)
+	}
+}
+
+func TestAddNewEventNotStoredIsNotAveraged(t *testing.T) {
+	setupDerivation(map[string]DerivedReading{"Scaled": {Expression: "Temperature", Window: 2}})
+	Configuration.Writable.PersistData = true
+	dbClient.(*dbMock.DBClient).On("AddEvent", mock.Anything).Return("", goerrors.New("database unavailable"))
+
+	e := models.Event{Event: contract.Event{Device: testDeviceName, Readings: []contract.Reading{
+		{Name: "Temperature", Value: "45"},
+	}}}
+	if _, err := addNewEvent(e, context.Background()); err == nil {
+		t.Fatalf("Expected the error adding the event")
+	}
+
+	// The result of the event that failed is not in the window
+	e.Readings = []contract.Reading{{Name: "Temperature", Value: "55"}}
+	result, _, err := deriveReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Readings[1].Value != "55" {
+		t.Errorf("Expected average 55, got %s", result.Readings[1].Value)
+	}
+}
+
+func TestDeleteValueDescriptorForgetsExpression(t *testing.T) {
+	setupDerivation(map[string]DerivedReading{"Scaled": {Expression: "Temperature * 2"}})
+	myMock := dbClient.(*dbMock.DBClient)
+	myMock.On("ReadingsByValueDescriptor", "Scaled", 10).Return([]contract.Reading{}, nil)
+	myMock.On("DeleteValueDescriptorById", "vd1").Return(nil)
+
+	if _, _, err := deriveReadings(models.Event{Event: testEvent}, make(map[string]contract.ValueDescriptor), context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := derivation.expressions["Scaled"]; !ok {
+		t.Fatalf("Expected the expression of Scaled to be kept")
+	}
+
+	if err := deleteValueDescriptor(contract.ValueDescriptor{Id: "vd1", Name: "Scaled"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := derivation.expressions["Scaled"]; ok {
+		t.Errorf("Expected the expression of Scaled to be dropped")
+	}
+}
@@ -99,10 +99,7 @@ func checkMaxLimit(limit int) error {
 
 func checkDevice(device string, ctx context.Context) error {
 	if Configuration.Writable.MetaDataCheck {
-		_, err := mdc.CheckForDevice(device, ctx)
-		if err != nil {
-			return err
-		}
+		return cachedCheckForDevice(device, ctx)
 	}
 	return nil
 }
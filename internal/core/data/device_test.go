@@ -84,6 +84,11 @@ func reset() {
 	testEvent.Device = testDeviceName
 	testEvent.Origin = testOrigin
 	testEvent.Readings = buildReadings()
+	vdCache.clear()
+	deviceCache.clear()
+	unitCache.clear()
+	suppression.clear()
+	derivation.clear()
 }
 
 func newMockDeviceClient() *mocks.DeviceClient {
@@ -0,0 +1,137 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	// Header a client sets to the same value each time it posts an event again
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotencyKeyContextKey = "idempotency-key"
+
+	defaultDeduplicationWindow = 5 * time.Minute
+
+	// How long to wait for an event being added under the same key by another request
+	reservedKeyTimeout      = 2 * time.Second
+	reservedKeyPollInterval = 50 * time.Millisecond
+	// How long a key is reserved for, so that the keys of a request that died while adding its
+	// event are not held for the whole deduplication window
+	reservedKeyLifetime = 10 * time.Second
+)
+
+// The keys an event is recorded under once added, for the event posted again to be recognized:
+// the idempotency key it was posted with, if any, and its device, origin and content when
+// deduplication is enabled. Nothing is recorded when events are not persisted.
+func duplicateKeys(e models.Event, ctx context.Context) ([]string, error) {
+	if !Configuration.Writable.PersistData {
+		return nil, nil
+	}
+
+	var keys []string
+	if key, ok := ctx.Value(idempotencyKeyContextKey).(string); ok && key != "" {
+		keys = append(keys, "idempotency:"+key)
+	}
+
+	if Configuration.Writable.Deduplication.Enabled {
+		// Binary payloads come with the checksum of their bytes, JSON ones are summed once decoded
+		sum := e.Checksum
+		if sum == "" {
+			data, err := json.Marshal(e.Event)
+			if err != nil {
+				return nil, err
+			}
+			sum = checksum(data)
+		}
+		keys = append(keys, "content:"+e.Device+":"+strconv.FormatInt(e.Origin, 10)+":"+sum)
+	}
+	return keys, nil
+}
+
+// Reserve the keys of an event before adding it, so that the event posted again while it is being
+// added is not added twice. The id of the event added under one of the keys within the
+// deduplication window is returned instead, once that event is stored. The keys are to be
+// released if the event is not stored, and are kept for the window once it is recorded.
+func reserveDuplicateKeys(keys []string) (string, bool, error) {
+	expires := db.MakeTimestamp() + int64(reservedKeyLifetime/time.Millisecond)
+	for i, key := range keys {
+		id, err := reserveDuplicateKey(key, expires)
+		if err != nil || id != "" {
+			releaseDuplicateKeys(keys[:i])
+			return id, id != "", err
+		}
+	}
+	return "", false, nil
+}
+
+// Reserve a key, waiting for the event another request reserved it for to be stored or not
+func reserveDuplicateKey(key string, expires int64) (string, error) {
+	for waited := time.Duration(0); ; waited += reservedKeyPollInterval {
+		id, reserved, err := dbClient.ReserveEventKey(key, expires)
+		if err != nil || reserved || id != "" {
+			return id, err
+		}
+		if waited >= reservedKeyTimeout {
+			return "", errors.NewErrEventInProgress(key)
+		}
+		time.Sleep(reservedKeyPollInterval)
+	}
+}
+
+// Release the keys reserved for an event that was not stored
+func releaseDuplicateKeys(keys []string) {
+	for _, key := range keys {
+		if err := dbClient.DeleteEventKey(key); err != nil {
+			LoggingClient.Error(fmt.Sprintf("error releasing key %s: %s", key, err.Error()))
+		}
+	}
+}
+
+// Record the id of an added event under its reserved keys. The event being stored, failing to
+// record its keys is only logged.
+func recordDuplicateKeys(keys []string, id string) {
+	if id == "" || len(keys) == 0 {
+		return
+	}
+
+	expires := db.MakeTimestamp() + int64(deduplicationWindow()/time.Millisecond)
+	for _, key := range keys {
+		if err := dbClient.AddEventKey(key, id, expires); err != nil {
+			LoggingClient.Error(fmt.Sprintf("error recording key of event %s: %s", id, err.Error()))
+		}
+	}
+}
+
+func deduplicationWindow() time.Duration {
+	value := Configuration.Writable.Deduplication.Window
+	if value == "" {
+		return defaultDeduplicationWindow
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		LoggingClient.Error(fmt.Sprintf("invalid deduplication window '%s', using %s", value, defaultDeduplicationWindow))
+		return defaultDeduplicationWindow
+	}
+	return d
+}
@@ -0,0 +1,217 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	reset()
+	keyed := context.WithValue(context.Background(), idempotencyKeyContextKey, "k1")
+	e := models.Event{Event: contract.Event{Device: testDeviceName, Origin: 42, Readings: buildReadings()}}
+	data, _ := json.Marshal(e.Event)
+	binary := e
+	binary.Checksum = "abc"
+
+	tests := []struct {
+		name     string
+		persist  bool
+		enabled  bool
+		event    models.Event
+		ctx      context.Context
+		expected []string
+	}{
+		{"not persisted", false, true, e, keyed, nil},
+		{"disabled", true, false, e, context.Background(), nil},
+		{"idempotency key", true, false, e, keyed, []string{"idempotency:k1"}},
+		{"json", true, true, e, context.Background(), []string{"content:" + testDeviceName + ":42:" + checksum(data)}},
+		{"binary", true, true, binary, keyed, []string{"idempotency:k1", "content:" + testDeviceName + ":42:abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Configuration.Writable.PersistData = tt.persist
+			Configuration.Writable.Deduplication.Enabled = tt.enabled
+
+			keys, err := duplicateKeys(tt.event, tt.ctx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestAddEventPostedAgain(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	myMock := &dbMock.DBClient{}
+	myMock.On("ReserveEventKey", "idempotency:k1", mock.Anything).Return("original", false, nil)
+	dbClient = myMock
+
+	ctx := context.WithValue(context.Background(), idempotencyKeyContextKey, "k1")
+	id, err := addNewEvent(models.Event{Event: testEvent}, ctx)
+	if err != nil || id != "original" {
+		t.Errorf("Expected the original event, got %s %v", id, err)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestAddEventRecordsKeys(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.Deduplication = DeduplicationInfo{Enabled: true, Window: "1m"}
+	myMock := &dbMock.DBClient{}
+	myMock.On("AddEvent", mock.Anything).Return("id1", nil)
+
+	window := int64(time.Minute / time.Millisecond)
+	before := db.MakeTimestamp() + window
+	inWindow := mock.MatchedBy(func(expires int64) bool {
+		return expires >= before && expires <= db.MakeTimestamp()+window
+	})
+	lifetime := int64(reservedKeyLifetime / time.Millisecond)
+	reservedBefore := db.MakeTimestamp() + lifetime
+	reserved := mock.MatchedBy(func(expires int64) bool {
+		return expires >= reservedBefore && expires <= db.MakeTimestamp()+lifetime
+	})
+	myMock.On("ReserveEventKey", mock.Anything, reserved).Return("", true, nil).Twice()
+	myMock.On("AddEventKey", "idempotency:k1", "id1", inWindow).Return(nil)
+	myMock.On("AddEventKey", mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "content:"+testDeviceName+":")
+	}), "id1", inWindow).Return(nil)
+	dbClient = myMock
+
+	ctx := context.WithValue(context.Background(), idempotencyKeyContextKey, "k1")
+	id, err := addNewEvent(models.Event{Event: testEvent}, ctx)
+	<-chEvents
+	<-chEvents
+	if err != nil || id != "id1" {
+		t.Errorf("Expected the event to be added, got %s %v", id, err)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestAddEventsBatchDuplicates(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.Deduplication.Enabled = true
+	Configuration.Service.MaxResultCount = 10
+
+	first := models.Event{Event: contract.Event{Device: testDeviceName, Origin: 1, Readings: buildReadings()}}
+	known := models.Event{Event: contract.Event{Device: testDeviceName, Origin: 2, Readings: buildReadings()}}
+	knownKeys, _ := duplicateKeys(known, context.Background())
+
+	myMock := &dbMock.DBClient{}
+	myMock.On("ReserveEventKey", knownKeys[0], mock.Anything).Return("old", false, nil)
+	myMock.On("ReserveEventKey", mock.Anything, mock.Anything).Return("", true, nil)
+	myMock.On("AddEvents", mock.MatchedBy(func(events []models.Event) bool {
+		return len(events) == 1
+	})).Return([]string{"id1"}, nil)
+	myMock.On("AddEventKey", mock.Anything, "id1", mock.Anything).Return(nil).Once()
+	dbClient = myMock
+
+	// The idempotency key of the request does not apply to the events of the batch
+	ctx := context.WithValue(context.Background(), idempotencyKeyContextKey, "k1")
+	results, err := addNewEvents([]models.Event{first, first, known}, ctx)
+	<-chEvents
+	<-chEvents
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []batchResult{
+		{ID: "id1", Status: http.StatusOK},
+		{ID: "id1", Status: http.StatusOK, Duplicate: true},
+		{ID: "old", Status: http.StatusOK, Duplicate: true},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, results)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestEventHandlerIdempotencyKey(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	myMock := &dbMock.DBClient{}
+	myMock.On("ReserveEventKey", "idempotency:k1", mock.Anything).Return("original", false, nil)
+	dbClient = myMock
+
+	body, _ := json.Marshal(testEvent)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event", bytes.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, "k1")
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || rr.Body.String() != "original" {
+		t.Errorf("Expected the original event, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAddEventNotStoredReleasesKeys(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	myMock := &dbMock.DBClient{}
+	myMock.On("ReserveEventKey", "idempotency:k1", mock.Anything).Return("", true, nil)
+	myMock.On("AddEvent", mock.Anything).Return("", goerrors.New("database unavailable"))
+	myMock.On("DeleteEventKey", "idempotency:k1").Return(nil)
+	dbClient = myMock
+
+	ctx := context.WithValue(context.Background(), idempotencyKeyContextKey, "k1")
+	if _, err := addNewEvent(models.Event{Event: testEvent}, ctx); err == nil {
+		t.Fatalf("Expected the error adding the event")
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestAddEventWaitsForEventInProgress(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	myMock := &dbMock.DBClient{}
+	// Another request holds the key until its event is stored
+	myMock.On("ReserveEventKey", "idempotency:k1", mock.Anything).Return("", false, nil).Twice()
+	myMock.On("ReserveEventKey", "idempotency:k1", mock.Anything).Return("original", false, nil).Once()
+	dbClient = myMock
+
+	ctx := context.WithValue(context.Background(), idempotencyKeyContextKey, "k1")
+	id, err := addNewEvent(models.Event{Event: testEvent}, ctx)
+	if err != nil || id != "original" {
+		t.Errorf("Expected the original event, got %s %v", id, err)
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestEventHandlerEventInProgress(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	myMock := &dbMock.DBClient{}
+	// The key stays reserved by another request until the wait times out
+	myMock.On("ReserveEventKey", "idempotency:k1", mock.Anything).Return("", false, nil)
+	dbClient = myMock
+
+	body, _ := json.Marshal(testEvent)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event", bytes.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, "k1")
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected %d for the event in progress, got %d %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+}
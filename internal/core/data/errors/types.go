@@ -44,6 +44,69 @@ func NewErrValueDescriptorInvalid(name string, err error) error {
 	return &ErrValueDescriptorInvalid{name: name, err: err}
 }
 
+type ErrUnitConversion struct {
+	name string
+	err  error
+}
+
+func (e ErrUnitConversion) Error() string {
+	return fmt.Sprintf("cannot convert reading '%s': %v", e.name, e.err)
+}
+
+func NewErrUnitConversion(name string, err error) error {
+	return &ErrUnitConversion{name: name, err: err}
+}
+
+type ErrDerivation struct {
+	name string
+	err  error
+}
+
+func (e ErrDerivation) Error() string {
+	return fmt.Sprintf("cannot derive reading '%s': %v", e.name, e.err)
+}
+
+func NewErrDerivation(name string, err error) error {
+	return &ErrDerivation{name: name, err: err}
+}
+
+type ErrOriginOutOfRange struct {
+	reason string
+}
+
+func (e ErrOriginOutOfRange) Error() string {
+	return fmt.Sprintf("event not accepted: %s", e.reason)
+}
+
+func NewErrOriginOutOfRange(reason string) error {
+	return &ErrOriginOutOfRange{reason: reason}
+}
+
+type ErrEventQuarantined struct {
+	id     string
+	reason string
+}
+
+func (e ErrEventQuarantined) Error() string {
+	return fmt.Sprintf("event quarantined as %s: %s", e.id, e.reason)
+}
+
+func NewErrEventQuarantined(id string, reason string) error {
+	return &ErrEventQuarantined{id: id, reason: reason}
+}
+
+type ErrInvalidArchive struct {
+	err error
+}
+
+func (e ErrInvalidArchive) Error() string {
+	return fmt.Sprintf("invalid archive: %v", e.err)
+}
+
+func NewErrInvalidArchive(err error) error {
+	return &ErrInvalidArchive{err: err}
+}
+
 type ErrValueDescriptorNotFound struct {
 	id string
 }
@@ -150,3 +213,39 @@ func (e ErrInvalidId) Error() string {
 func NewErrInvalidId(id string) error {
 	return &ErrInvalidId{id: id}
 }
+
+type ErrInvalidQuery struct {
+	reason string
+}
+
+func (e ErrInvalidQuery) Error() string {
+	return fmt.Sprintf("invalid query: %s", e.reason)
+}
+
+func NewErrInvalidQuery(reason string) error {
+	return &ErrInvalidQuery{reason: reason}
+}
+
+type ErrEventInProgress struct {
+	key string
+}
+
+func (e ErrEventInProgress) Error() string {
+	return fmt.Sprintf("an event with key '%s' is still being added", e.key)
+}
+
+func NewErrEventInProgress(key string) error {
+	return &ErrEventInProgress{key: key}
+}
+
+type ErrEventSuppressed struct {
+	device string
+}
+
+func (e ErrEventSuppressed) Error() string {
+	return fmt.Sprintf("event of device %s suppressed: all its readings were redundant", e.device)
+}
+
+func NewErrEventSuppressed(device string) error {
+	return &ErrEventSuppressed{device: device}
+}
@@ -18,8 +18,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
 
@@ -88,38 +90,77 @@ func getEvents(limit int) ([]contract.Event, error) {
 }
 
 func addNewEvent(e models.Event, ctx context.Context) (string, error) {
-	err := checkDevice(e.Device, ctx)
+	keys, err := duplicateKeys(e, ctx)
 	if err != nil {
 		return "", err
 	}
+	original, posted, err := reserveDuplicateKeys(keys)
+	if err != nil {
+		return "", err
+	}
+	if posted {
+		LoggingClient.Info(fmt.Sprintf("event %s of device %s was posted again", original, e.Device))
+		return original, nil
+	}
 
-	if Configuration.Writable.ValidateCheck {
-		LoggingClient.Debug("Validation enabled, parsing events")
-		for reading := range e.Readings {
-			// Check value descriptor
-			name := e.Readings[reading].Name
-			vd, err := dbClient.ValueDescriptorByName(name)
-			if err != nil {
-				if err == db.ErrNotFound {
-					return "", errors.NewErrValueDescriptorNotFound(name)
-				} else {
-					return "", err
-				}
-			}
-			err = isValidValueDescriptor(vd, e.Readings[reading])
-			if err != nil {
-				return "", err
-			}
+	// The keys are held for this event until it is stored, and released otherwise
+	stored := false
+	defer func() {
+		if !stored {
+			releaseDuplicateKeys(keys)
 		}
+	}()
+
+	err = acceptEvent(e, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkDevice(e.Device, ctx)
+	if err != nil {
+		return "", quarantineRejected(e, err, ctx)
+	}
+
+	found := make(map[string]contract.ValueDescriptor)
+	err = validateReadings(e, found)
+	if err != nil {
+		return "", quarantineRejected(e, err, ctx)
+	}
+
+	e, err = normalizeReadings(e, found, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	e, derived, err := deriveReadings(e, found, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	n := len(e.Readings)
+	e, accepted, err := suppressReadings(e, ctx)
+	if err != nil {
+		derivation.undo(derived)
+		return "", err
+	}
+	if n > 0 && len(e.Readings) == 0 {
+		// Nothing is left to store or export, though the device did report
+		chEvents <- DeviceLastReported{e.Device}
+		chEvents <- DeviceServiceLastReported{e.Device}
+		return "", errors.NewErrEventSuppressed(e.Device)
 	}
 
 	// Add the event and readings to the database
 	if Configuration.Writable.PersistData {
 		id, err := dbClient.AddEvent(e)
 		if err != nil {
+			suppression.undo(accepted)
+			derivation.undo(derived)
 			return "", err
 		}
 		e.ID = id
+		recordDuplicateKeys(keys, id)
+		stored = true
 	}
 
 	putEventOnQueue(e, ctx)                         // Push the aux struct to export service (It has the actual readings)
@@ -129,6 +170,223 @@ func addNewEvent(e models.Event, ctx context.Context) (string, error) {
 	return e.ID, nil
 }
 
+// batchResult reports the outcome of adding one event of a batch
+type batchResult struct {
+	ID         string `json:"id,omitempty"`
+	Status     int    `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Suppressed bool   `json:"suppressed,omitempty"` // All the readings of the event were suppressed
+	Duplicate  bool   `json:"duplicate,omitempty"`  // The event was added before, ID being its id
+}
+
+// Add a batch of events, returning one result per event in the same order
+// Events failing the device or value descriptor checks are reported without holding up the
+// others, which are then added to the database at once and published one by one.
+// Events added before, or earlier in the batch, are reported as duplicates.
+func addNewEvents(events []models.Event, ctx context.Context) ([]batchResult, error) {
+	if err := checkMaxLimit(len(events)); err != nil {
+		return nil, err
+	}
+
+	results := make([]batchResult, len(events))
+	devices := make(map[string]error)
+	found := make(map[string]contract.ValueDescriptor)
+
+	// Idempotency keys identify a request, not the events of a batch
+	ctx = context.WithValue(ctx, idempotencyKeyContextKey, "")
+	keys := make([][]string, len(events))
+	firsts := make(map[string]int)
+	repeated := make(map[int]int)
+
+	var valid, suppressed []int
+	accepted := make([][]suppressionChange, len(events))
+	derived := make([][]derivationChange, len(events))
+	for i, e := range events {
+		var err error
+		keys[i], err = duplicateKeys(e, ctx)
+		if err == nil {
+			if j, ok := firstOfKeys(firsts, keys[i]); ok {
+				repeated[i] = j
+				continue
+			}
+			for _, key := range keys[i] {
+				firsts[key] = i
+			}
+
+			var original string
+			var posted bool
+			original, posted, err = reserveDuplicateKeys(keys[i])
+			if err != nil || posted {
+				// Nothing is reserved for the event
+				keys[i] = nil
+			}
+			if posted {
+				results[i] = batchResult{ID: original, Status: http.StatusOK, Duplicate: true}
+				continue
+			}
+		}
+
+		if err == nil {
+			err = acceptEvent(e, ctx)
+		}
+		if err == nil {
+			var checked bool
+			err, checked = devices[e.Device]
+			if !checked {
+				err = checkDevice(e.Device, ctx)
+				devices[e.Device] = err
+			}
+		}
+		if err == nil {
+			err = validateReadings(e, found)
+		}
+		if err != nil {
+			err = quarantineRejected(e, err, ctx)
+		}
+		if err == nil {
+			events[i], err = normalizeReadings(e, found, ctx)
+		}
+		if err == nil {
+			events[i], derived[i], err = deriveReadings(events[i], found, ctx)
+		}
+		if err == nil {
+			events[i], accepted[i], err = suppressReadings(events[i], ctx)
+			if err != nil {
+				derivation.undo(derived[i])
+			}
+		}
+		if err != nil {
+			releaseDuplicateKeys(keys[i])
+			results[i] = batchResult{Status: eventErrorStatus(err), Error: err.Error()}
+			continue
+		}
+		if len(e.Readings) > 0 && len(events[i].Readings) == 0 {
+			releaseDuplicateKeys(keys[i])
+			results[i] = batchResult{Status: http.StatusOK, Suppressed: true}
+			suppressed = append(suppressed, i)
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if Configuration.Writable.PersistData && len(valid) > 0 {
+		batch := make([]models.Event, len(valid))
+		for j, i := range valid {
+			batch[j] = events[i]
+		}
+
+		ids, err := dbClient.AddEvents(batch)
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("error adding a batch of %d events: %s", len(batch), err.Error()))
+			for j := len(valid) - 1; j >= 0; j-- {
+				i := valid[j]
+				suppression.undo(accepted[i])
+				derivation.undo(derived[i])
+				releaseDuplicateKeys(keys[i])
+				results[i] = batchResult{Status: http.StatusInternalServerError, Error: err.Error()}
+			}
+			// None of them is published, the other events being reported as they are
+			valid = nil
+		}
+		for j, i := range valid {
+			events[i].ID = ids[j]
+			recordDuplicateKeys(keys[i], ids[j])
+		}
+	}
+
+	// Devices are reported once per batch rather than once per event
+	reported := make(map[string]bool)
+	report := func(device string) {
+		if !reported[device] {
+			reported[device] = true
+			chEvents <- DeviceLastReported{device}
+			chEvents <- DeviceServiceLastReported{device}
+		}
+	}
+
+	for _, i := range valid {
+		e := events[i]
+		putEventOnQueue(e, batchEventContext(e, ctx))
+		results[i] = batchResult{ID: e.ID, Status: http.StatusOK}
+		report(e.Device)
+	}
+	for _, i := range suppressed {
+		report(events[i].Device)
+	}
+	for i, j := range repeated {
+		results[i] = results[j]
+		results[i].Duplicate = results[j].Error == ""
+	}
+
+	return results, nil
+}
+
+// The index of the event of a batch first recorded under one of the keys
+func firstOfKeys(firsts map[string]int, keys []string) (int, bool) {
+	for _, key := range keys {
+		if i, ok := firsts[key]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// The context an event of a batch is published with, carrying its own checksum if it has one
+func batchEventContext(e models.Event, ctx context.Context) context.Context {
+	if e.Checksum == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, checksumContextKey, e.Checksum)
+}
+
+// The HTTP status reporting an event that could not be added
+func eventErrorStatus(err error) int {
+	switch err := err.(type) {
+	case *types.ErrServiceClient:
+		return err.StatusCode
+	case *errors.ErrUnitConversion:
+		return http.StatusBadRequest
+	case *errors.ErrValueDescriptorNotFound, *errors.ErrValueDescriptorInvalid, *errors.ErrDerivation,
+		*errors.ErrOriginOutOfRange, *errors.ErrEventInProgress:
+		return http.StatusConflict
+	case *errors.ErrEventQuarantined, *errors.ErrEventSuppressed:
+		return http.StatusAccepted
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Check the readings of an event against their value descriptors when validation is enabled
+// Value descriptors are kept in found so that events of a batch share the lookups
+func validateReadings(e models.Event, found map[string]contract.ValueDescriptor) error {
+	if !Configuration.Writable.ValidateCheck {
+		return nil
+	}
+
+	LoggingClient.Debug("Validation enabled, parsing events")
+	for _, reading := range e.Readings {
+		// Check value descriptor
+		vd, ok := found[reading.Name]
+		if !ok {
+			var err error
+			vd, err = cachedValueDescriptorByName(reading.Name)
+			if err != nil {
+				if err == db.ErrNotFound {
+					return errors.NewErrValueDescriptorNotFound(reading.Name)
+				}
+				return err
+			}
+			found[reading.Name] = vd
+		}
+
+		err := isValidValueDescriptor(vd, reading)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func updateEvent(from models.Event, ctx context.Context) error {
 	to, err := dbClient.EventById(from.ID)
 	if err != nil {
@@ -199,6 +457,18 @@ func getEventById(id string) (contract.Event, error) {
 	return e, nil
 }
 
+// The event along with the values of its readings before their conversion into another unit
+func getEventWithOriginalsById(id string) (models.Event, error) {
+	e, err := dbClient.EventWithOriginalsById(id)
+	if err != nil {
+		if err == db.ErrNotFound {
+			err = errors.NewErrEventNotFound(id)
+		}
+		return models.Event{}, err
+	}
+	return e, nil
+}
+
 // updateEventPushDateByChecksum updates the pushed dated for all events with a matching checksum which have not already been marked pushed
 func updateEventPushDateByChecksum(checksum string, ctx context.Context) error {
 	evts, err := dbClient.EventsByChecksum(checksum)
@@ -236,6 +506,8 @@ func updateEventPushDate(id string, ctx context.Context) error {
 
 // Put event on the message queue to be processed by the rules engine
 func putEventOnQueue(evt models.Event, ctx context.Context) {
+	subscriptions.publish(evt.Event)
+
 	LoggingClient.Info("Putting event on message queue")
 
 	evt.CorrelationId = correlation.FromContext(ctx)
@@ -251,6 +523,7 @@ func putEventOnQueue(evt models.Event, ctx context.Context) {
 
 	msgEnvelope := msgTypes.NewMessageEnvelope(evt.Bytes, ctx)
 	err := msgClient.Publish(msgEnvelope, Configuration.MessageQueue.Topic)
+	busConnection.Record(err)
 	if err != nil {
 		LoggingClient.Error(fmt.Sprintf("Unable to send message for event: %s", evt.String()))
 	} else {
@@ -278,6 +551,26 @@ func getEventsByCreationTime(limit int, start int64, end int64) ([]contract.Even
 	return eventList, nil
 }
 
+func getEventsByOriginTime(start int64, end int64, limit int) ([]contract.Event, error) {
+	eventList, err := dbClient.EventsByOriginTime(start, end, limit)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, err
+	}
+
+	return eventList, nil
+}
+
+func getEventsPage(filter db.EventFilter, cursor db.Cursor, limit int) ([]contract.Event, db.Cursor, error) {
+	eventList, next, err := dbClient.EventsPage(filter, cursor, limit)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, db.Cursor{}, err
+	}
+
+	return eventList, next, nil
+}
+
 func deleteEvents(deviceId string) (int, error) {
 	// Get the events by the device name
 	events, err := dbClient.EventsForDevice(deviceId)
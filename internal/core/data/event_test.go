@@ -639,3 +639,108 @@ func testEventWithoutReadings(event models.Event, t *testing.T) {
 		t.Error("origin mismatch. expected " + strconv.FormatInt(testEvent.Origin, 10) + " received " + strconv.FormatInt(event.Origin, 10))
 	}
 }
+
+func TestAddEventsBatch(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.ValidateCheck = true
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+
+	// Each value descriptor is looked up once for the whole batch
+	myMock.On("ValueDescriptorByName", "Temperature").Return(models.ValueDescriptor{Name: "Temperature", Type: "S"}, nil).Once()
+	myMock.On("ValueDescriptorByName", "Pressure").Return(models.ValueDescriptor{Name: "Pressure", Type: "S"}, nil).Once()
+	myMock.On("ValueDescriptorByName", "Unknown").Return(models.ValueDescriptor{}, db.ErrNotFound)
+	myMock.On("AddEvents", mock.MatchedBy(func(events []correlation.Event) bool {
+		return len(events) == 2
+	})).Return([]string{"id1", "id3"}, nil)
+
+	dbClient = myMock
+
+	unknown := buildReadings()
+	unknown[1].Name = "Unknown"
+	events := []correlation.Event{
+		{Event: models.Event{Device: testDeviceName, Readings: buildReadings()}},
+		{Event: models.Event{Device: testDeviceName, Readings: unknown}},
+		{Event: models.Event{Device: testDeviceName, Readings: buildReadings()}},
+	}
+
+	bitEvents := make([]bool, 2)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go handleDomainEvents(bitEvents, &wg, t)
+
+	results, err := addNewEvents(events, context.Background())
+	Configuration.Writable.PersistData = false
+	Configuration.Writable.ValidateCheck = false
+	if err != nil {
+		t.Fatalf("Unexpected error adding batch: %v", err)
+	}
+
+	expected := []batchResult{
+		{ID: "id1", Status: 200},
+		{Status: 409, Error: errors.NewErrValueDescriptorNotFound("Unknown").Error()},
+		{ID: "id3", Status: 200},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("Result %d: expected %+v, got %+v", i, expected[i], results[i])
+		}
+	}
+
+	wg.Wait()
+	for i, val := range bitEvents {
+		if !val {
+			t.Errorf("event not received in timely fashion, index %v, TestAddEventsBatch", i)
+		}
+	}
+
+	myMock.AssertExpectations(t)
+}
+
+func TestAddEventsBatchDatabaseError(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("AddEvents", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	myMock.On("ReserveEventKey", mock.Anything, mock.Anything).Return("", true, nil)
+	myMock.On("DeleteEventKey", mock.Anything).Return(nil)
+
+	dbClient = myMock
+
+	// The second event repeats the first within the batch
+	Configuration.Writable.Deduplication.Enabled = true
+	events := []correlation.Event{
+		{Event: models.Event{Device: testDeviceName, Origin: 1, Readings: buildReadings()}},
+		{Event: models.Event{Device: testDeviceName, Origin: 1, Readings: buildReadings()}},
+		{Event: models.Event{Device: testDeviceName, Origin: 2, Readings: buildReadings()}},
+	}
+	results, err := addNewEvents(events, context.Background())
+	Configuration.Writable.PersistData = false
+	if err != nil {
+		t.Fatalf("Unexpected error adding batch: %v", err)
+	}
+
+	for i, r := range results {
+		if r.Status != 500 || r.ID != "" || r.Error == "" || r.Duplicate {
+			t.Errorf("Result %d should report the database error: %+v", i, r)
+		}
+	}
+}
+
+func TestAddEventsBatchOverLimit(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 1
+	dbClient = &dbMock.DBClient{}
+
+	events := make([]correlation.Event, 2)
+	_, err := addNewEvents(events, context.Background())
+	if _, ok := err.(*errors.ErrLimitExceeded); !ok {
+		t.Errorf("Expected limit exceeded error, got %v", err)
+	}
+}
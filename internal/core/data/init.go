@@ -36,8 +36,10 @@ import (
 	"github.com/edgexfoundry/edgex-go/internal/core/data/interfaces"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/bolt"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/redis"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/startup"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
@@ -54,6 +56,8 @@ var chErrors chan error        // A channel for "config wait error" sourced from
 var chUpdates chan interface{} // A channel for "config updates" sourced from Registry
 
 var msgClient messaging.MessageClient
+var dbMonitor *db.HealthMonitor
+var busConnection readiness.Connection
 var mdc metadata.DeviceClient
 var msc metadata.DeviceServiceClient
 
@@ -103,6 +107,9 @@ func Init(useRegistry bool) bool {
 	if Configuration == nil || dbClient == nil {
 		return false
 	}
+	dbMonitor = db.NewHealthMonitor(dbClient, LoggingClient)
+	dbMonitor.Start()
+
 	chEvents = make(chan interface{}, 100)
 	initEventHandlers()
 
@@ -112,12 +119,25 @@ func Init(useRegistry bool) bool {
 		go listenForConfigChanges()
 	}
 
+	chRetention = make(chan struct{})
+	go runRetention(chRetention)
+	if m, ok := dbClient.(maintainer); ok {
+		go runMaintenance(m, chRetention)
+	}
+
 	go telemetry.StartCpuUsageAverage()
 
 	return true
 }
 
 func Destruct() {
+	subscriptions.close()
+
+	if chRetention != nil {
+		close(chRetention)
+	}
+
+	dbMonitor.Stop()
 	if dbClient != nil {
 		dbClient.CloseSession()
 		dbClient = nil
@@ -145,6 +165,14 @@ func connectToDatabase() error {
 		return fmt.Errorf("couldn't create database client: %v", err.Error())
 	}
 
+	primary := Configuration.Databases["Primary"]
+	err = db.MigrateSchema(dbClient, primary.SchemaVersion, primary.MigrationDryRun, LoggingClient)
+	if err != nil {
+		dbClient.CloseSession()
+		dbClient = nil
+		return fmt.Errorf("couldn't migrate database: %v", err.Error())
+	}
+
 	return nil
 }
 
@@ -163,10 +191,25 @@ func newDBClient(dbType string) (interfaces.DBClient, error) {
 		return mongo.NewClient(dbConfig)
 	case db.RedisDB:
 		dbConfig := db.Configuration{
-			Host: Configuration.Databases["Primary"].Host,
-			Port: Configuration.Databases["Primary"].Port,
+			Host:      Configuration.Databases["Primary"].Host,
+			Port:      Configuration.Databases["Primary"].Port,
+			MaxMemory: Configuration.Databases["Primary"].MaxMemory,
+		}
+		if ttl := Configuration.Databases["Primary"].TTL; ttl != "" {
+			d, err := time.ParseDuration(ttl)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid TTL '%s'", ttl)
+			}
+			dbConfig.TTL = int64(d / time.Millisecond)
 		}
 		return redis.NewClient(dbConfig) // TODO: Verify this also connects to Redis
+	case db.BoltDB:
+		dbConfig := db.Configuration{
+			Host:         Configuration.Databases["Primary"].Host,
+			Timeout:      Configuration.Databases["Primary"].Timeout,
+			DatabaseName: Configuration.Databases["Primary"].Name,
+		}
+		return bolt.NewClient(dbConfig)
 	default:
 		return nil, db.ErrUnsupportedDatabase
 	}
@@ -310,6 +353,7 @@ func initializeClients(useRegistry bool) {
 	if err != nil {
 		LoggingClient.Error(fmt.Sprintf("failed to create messaging client: %s", err.Error()))
 	}
+	busConnection.Record(err)
 }
 
 func setLoggingTarget() string {
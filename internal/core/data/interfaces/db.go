@@ -15,6 +15,7 @@ package interfaces
 
 import (
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -36,6 +37,11 @@ type DBClient interface {
 	// NoValueDescriptor - no existing value descriptor for a reading in the event
 	AddEvent(e models.Event) (string, error)
 
+	// Add many events and their readings at once
+	// The ids of the added events are returned in the same order
+	// UnexpectedError - failed to add to database, in which case none of the events may have been added
+	AddEvents(events []models.Event) ([]string, error)
+
 	// Update an event - do NOT update readings
 	// UnexpectedError - problem updating in database
 	// NotFound - no event with the ID was found
@@ -44,6 +50,10 @@ type DBClient interface {
 	// Get an event by id
 	EventById(id string) (contract.Event, error)
 
+	// Get an event by id along with the values its readings were received with before being
+	// converted into another unit
+	EventWithOriginalsById(id string) (models.Event, error)
+
 	// Get all events with a matching checksum
 	EventsByChecksum(checksum string) ([]contract.Event, error)
 
@@ -77,6 +87,16 @@ type DBClient interface {
 	// Limit the number of results by limit
 	EventsByCreationTime(startTime, endTime int64, limit int) ([]contract.Event, error)
 
+	// Return a list of events whose origin time is between startTime and endTime, oldest first
+	// Limit the number of results by limit
+	EventsByOriginTime(startTime, endTime int64, limit int) ([]contract.Event, error)
+
+	// Return a page of events matching the filter, sorted by creation time (oldest first)
+	// The page starts after the given cursor and holds at most limit events
+	// The returned cursor is empty once there are no more events to read
+	// InvalidCursor - the cursor was not produced by this database
+	EventsPage(filter db.EventFilter, cursor db.Cursor, limit int) ([]contract.Event, db.Cursor, error)
+
 	// Return a list of readings for a device filtered by the value descriptor and limited by the limit
 	// The readings are linked to the device through an event
 	ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]contract.Reading, error)
@@ -97,6 +117,51 @@ type DBClient interface {
 	// Delete all readings and events
 	ScrubAllEvents() error
 
+	// Delete the oldest events matching the filter along with their readings
+	// Delete at most limit events and return how many were deleted
+	DeleteEvents(filter db.PurgeFilter, limit int) (int, error)
+
+	// Return the approximate number of bytes used to store events and readings
+	DataSize() (int64, error)
+
+	// Get the id of the event recorded under a key by AddEventKey
+	// NotFound - no event was recorded under the key, the key expired or is only reserved
+	EventIdByKey(key string) (string, error)
+
+	// Reserve a key with no event yet until the expiry time in milliseconds, unless the key is
+	// already recorded and not expired. Checking and reserving the key is a single operation.
+	// Return whether the key was reserved, or else the id of the event recorded under it, which is
+	// empty while the key is only reserved.
+	ReserveEventKey(key string, expires int64) (string, bool, error)
+
+	// Record the id of an event under a key, such as an idempotency key, until the expiry time
+	// in milliseconds. The key is removed once expired.
+	AddEventKey(key string, id string, expires int64) error
+
+	// Remove a key, such as one reserved for an event that could not be added
+	DeleteEventKey(key string) error
+
+	// ********************* QUARANTINE FUNCTIONS *************************
+	// Hold back an event, returning the id of the quarantined event
+	AddQuarantinedEvent(q db.QuarantinedEvent) (string, error)
+
+	// Return the quarantined events, oldest first, limited by limit unless it is 0
+	QuarantinedEvents(limit int) ([]db.QuarantinedEvent, error)
+
+	// Return the quarantined events of a device, oldest first, limited by limit unless it is 0
+	QuarantinedEventsByDevice(device string, limit int) ([]db.QuarantinedEvent, error)
+
+	// Return a quarantined event by its id
+	// NotFound - no quarantined event with the id
+	QuarantinedEventById(id string) (db.QuarantinedEvent, error)
+
+	// Remove a quarantined event by its id
+	// NotFound - no quarantined event with the id
+	DeleteQuarantinedEventById(id string) error
+
+	// Remove the events quarantined before a time, returning how many were removed
+	DeleteQuarantinedEvents(before int64) (int, error)
+
 	// ********************* READING FUNCTIONS *************************
 	// Return a list of readings sorted by reading id
 	Readings() ([]contract.Reading, error)
@@ -121,6 +186,10 @@ type DBClient interface {
 	// 404 - can't find the reading with the given id
 	DeleteReadingById(id string) error
 
+	// Delete the oldest readings matching the filter, removing them from their events
+	// Delete at most limit readings and return how many were deleted
+	DeleteReadings(filter db.PurgeFilter, limit int) (int, error)
+
 	// Return a list of readings for the given device (id or name)
 	// 404 - meta data checking enabled and can't find the device
 	// Sort the list of readings on creation date
@@ -146,6 +215,24 @@ type DBClient interface {
 	// Return a list of readings whos created time is between the start and end times
 	ReadingsByCreationTime(start, end int64, limit int) ([]contract.Reading, error)
 
+	// Return a list of readings whose origin time is between start and end, oldest first
+	ReadingsByOriginTime(start, end int64, limit int) ([]contract.Reading, error)
+
+	// Return a page of readings matching the filter, sorted by creation time (oldest first)
+	// The page starts after the given cursor and holds at most limit readings
+	// The returned cursor is empty once there are no more readings to read
+	// InvalidCursor - the cursor was not produced by this database
+	ReadingsPage(filter db.ReadingFilter, cursor db.Cursor, limit int) ([]contract.Reading, db.Cursor, error)
+
+	// Return the readings matching every predicate of the query, sorted by the query sort order
+	// Return at most query.Limit readings
+	ReadingsByQuery(query db.ReadingQuery) ([]contract.Reading, error)
+
+	// Summarize the numeric readings matching the query in time buckets
+	// Return one bucket per device, value descriptor and interval holding readings,
+	// sorted by device, value descriptor name and bucket start
+	AggregateReadings(query db.AggregateQuery) ([]db.Bucket, error)
+
 	// ************************** VALUE DESCRIPTOR FUNCTIONS ***************************
 	// Add a value descriptor
 	// 409 - Formatting is bad or it is not unique
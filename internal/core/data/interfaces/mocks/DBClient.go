@@ -5,6 +5,7 @@ package mocks
 import contracts "github.com/edgexfoundry/go-mod-core-contracts/models"
 
 import mock "github.com/stretchr/testify/mock"
+import db "github.com/edgexfoundry/edgex-go/internal/pkg/db"
 import "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 
 // DBClient is an autogenerated mock type for the DBClient type
@@ -33,6 +34,64 @@ func (_m *DBClient) AddEvent(e models.Event) (string, error) {
 	return r0, r1
 }
 
+// AddEventKey provides a mock function with given fields: key, id, expires
+func (_m *DBClient) AddEventKey(key string, id string, expires int64) error {
+	ret := _m.Called(key, id, expires)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) error); ok {
+		r0 = rf(key, id, expires)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddEvents provides a mock function with given fields: events
+func (_m *DBClient) AddEvents(events []models.Event) ([]string, error) {
+	ret := _m.Called(events)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func([]models.Event) []string); ok {
+		r0 = rf(events)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]models.Event) error); ok {
+		r1 = rf(events)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddQuarantinedEvent provides a mock function with given fields: q
+func (_m *DBClient) AddQuarantinedEvent(q db.QuarantinedEvent) (string, error) {
+	ret := _m.Called(q)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(db.QuarantinedEvent) string); ok {
+		r0 = rf(q)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.QuarantinedEvent) error); ok {
+		r1 = rf(q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddReading provides a mock function with given fields: r
 func (_m *DBClient) AddReading(r contracts.Reading) (string, error) {
 	ret := _m.Called(r)
@@ -75,11 +134,55 @@ func (_m *DBClient) AddValueDescriptor(v contracts.ValueDescriptor) (string, err
 	return r0, r1
 }
 
+// AggregateReadings provides a mock function with given fields: query
+func (_m *DBClient) AggregateReadings(query db.AggregateQuery) ([]db.Bucket, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.Bucket
+	if rf, ok := ret.Get(0).(func(db.AggregateQuery) []db.Bucket); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Bucket)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.AggregateQuery) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CloseSession provides a mock function with given fields:
 func (_m *DBClient) CloseSession() {
 	_m.Called()
 }
 
+// DataSize provides a mock function with given fields:
+func (_m *DBClient) DataSize() (int64, error) {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteEventById provides a mock function with given fields: id
 func (_m *DBClient) DeleteEventById(id string) error {
 	ret := _m.Called(id)
@@ -94,6 +197,76 @@ func (_m *DBClient) DeleteEventById(id string) error {
 	return r0
 }
 
+// DeleteEventKey provides a mock function with given fields: key
+func (_m *DBClient) DeleteEventKey(key string) error {
+	ret := _m.Called(key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEvents provides a mock function with given fields: filter, limit
+func (_m *DBClient) DeleteEvents(filter db.PurgeFilter, limit int) (int, error) {
+	ret := _m.Called(filter, limit)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(db.PurgeFilter, int) int); ok {
+		r0 = rf(filter, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.PurgeFilter, int) error); ok {
+		r1 = rf(filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteQuarantinedEventById provides a mock function with given fields: id
+func (_m *DBClient) DeleteQuarantinedEventById(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteQuarantinedEvents provides a mock function with given fields: before
+func (_m *DBClient) DeleteQuarantinedEvents(before int64) (int, error) {
+	ret := _m.Called(before)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int64) int); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteReadingById provides a mock function with given fields: id
 func (_m *DBClient) DeleteReadingById(id string) error {
 	ret := _m.Called(id)
@@ -108,6 +281,27 @@ func (_m *DBClient) DeleteReadingById(id string) error {
 	return r0
 }
 
+// DeleteReadings provides a mock function with given fields: filter, limit
+func (_m *DBClient) DeleteReadings(filter db.PurgeFilter, limit int) (int, error) {
+	ret := _m.Called(filter, limit)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(db.PurgeFilter, int) int); ok {
+		r0 = rf(filter, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.PurgeFilter, int) error); ok {
+		r1 = rf(filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteValueDescriptorById provides a mock function with given fields: id
 func (_m *DBClient) DeleteValueDescriptorById(id string) error {
 	ret := _m.Called(id)
@@ -185,6 +379,48 @@ func (_m *DBClient) EventCountByDeviceId(id string) (int, error) {
 	return r0, r1
 }
 
+// EventIdByKey provides a mock function with given fields: key
+func (_m *DBClient) EventIdByKey(key string) (string, error) {
+	ret := _m.Called(key)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EventWithOriginalsById provides a mock function with given fields: id
+func (_m *DBClient) EventWithOriginalsById(id string) (models.Event, error) {
+	ret := _m.Called(id)
+
+	var r0 models.Event
+	if rf, ok := ret.Get(0).(func(string) models.Event); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(models.Event)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Events provides a mock function with given fields:
 func (_m *DBClient) Events() ([]contracts.Event, error) {
 	ret := _m.Called()
@@ -254,6 +490,29 @@ func (_m *DBClient) EventsByCreationTime(startTime int64, endTime int64, limit i
 	return r0, r1
 }
 
+// EventsByOriginTime provides a mock function with given fields: startTime, endTime, limit
+func (_m *DBClient) EventsByOriginTime(startTime int64, endTime int64, limit int) ([]contracts.Event, error) {
+	ret := _m.Called(startTime, endTime, limit)
+
+	var r0 []contracts.Event
+	if rf, ok := ret.Get(0).(func(int64, int64, int) []contracts.Event); ok {
+		r0 = rf(startTime, endTime, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]contracts.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64, int) error); ok {
+		r1 = rf(startTime, endTime, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EventsForDevice provides a mock function with given fields: id
 func (_m *DBClient) EventsForDevice(id string) ([]contracts.Event, error) {
 	ret := _m.Called(id)
@@ -323,6 +582,36 @@ func (_m *DBClient) EventsOlderThanAge(age int64) ([]contracts.Event, error) {
 	return r0, r1
 }
 
+// EventsPage provides a mock function with given fields: filter, cursor, limit
+func (_m *DBClient) EventsPage(filter db.EventFilter, cursor db.Cursor, limit int) ([]contracts.Event, db.Cursor, error) {
+	ret := _m.Called(filter, cursor, limit)
+
+	var r0 []contracts.Event
+	if rf, ok := ret.Get(0).(func(db.EventFilter, db.Cursor, int) []contracts.Event); ok {
+		r0 = rf(filter, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]contracts.Event)
+		}
+	}
+
+	var r1 db.Cursor
+	if rf, ok := ret.Get(1).(func(db.EventFilter, db.Cursor, int) db.Cursor); ok {
+		r1 = rf(filter, cursor, limit)
+	} else {
+		r1 = ret.Get(1).(db.Cursor)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(db.EventFilter, db.Cursor, int) error); ok {
+		r2 = rf(filter, cursor, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // EventsPushed provides a mock function with given fields:
 func (_m *DBClient) EventsPushed() ([]contracts.Event, error) {
 	ret := _m.Called()
@@ -369,6 +658,73 @@ func (_m *DBClient) EventsWithLimit(limit int) ([]contracts.Event, error) {
 	return r0, r1
 }
 
+// QuarantinedEventById provides a mock function with given fields: id
+func (_m *DBClient) QuarantinedEventById(id string) (db.QuarantinedEvent, error) {
+	ret := _m.Called(id)
+
+	var r0 db.QuarantinedEvent
+	if rf, ok := ret.Get(0).(func(string) db.QuarantinedEvent); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(db.QuarantinedEvent)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QuarantinedEvents provides a mock function with given fields: limit
+func (_m *DBClient) QuarantinedEvents(limit int) ([]db.QuarantinedEvent, error) {
+	ret := _m.Called(limit)
+
+	var r0 []db.QuarantinedEvent
+	if rf, ok := ret.Get(0).(func(int) []db.QuarantinedEvent); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.QuarantinedEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QuarantinedEventsByDevice provides a mock function with given fields: device, limit
+func (_m *DBClient) QuarantinedEventsByDevice(device string, limit int) ([]db.QuarantinedEvent, error) {
+	ret := _m.Called(device, limit)
+
+	var r0 []db.QuarantinedEvent
+	if rf, ok := ret.Get(0).(func(string, int) []db.QuarantinedEvent); ok {
+		r0 = rf(device, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.QuarantinedEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(device, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadingById provides a mock function with given fields: id
 func (_m *DBClient) ReadingById(id string) (contracts.Reading, error) {
 	ret := _m.Called(id)
@@ -503,6 +859,52 @@ func (_m *DBClient) ReadingsByDeviceAndValueDescriptor(deviceId string, valueDes
 	return r0, r1
 }
 
+// ReadingsByOriginTime provides a mock function with given fields: start, end, limit
+func (_m *DBClient) ReadingsByOriginTime(start int64, end int64, limit int) ([]contracts.Reading, error) {
+	ret := _m.Called(start, end, limit)
+
+	var r0 []contracts.Reading
+	if rf, ok := ret.Get(0).(func(int64, int64, int) []contracts.Reading); ok {
+		r0 = rf(start, end, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]contracts.Reading)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64, int) error); ok {
+		r1 = rf(start, end, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadingsByQuery provides a mock function with given fields: query
+func (_m *DBClient) ReadingsByQuery(query db.ReadingQuery) ([]contracts.Reading, error) {
+	ret := _m.Called(query)
+
+	var r0 []contracts.Reading
+	if rf, ok := ret.Get(0).(func(db.ReadingQuery) []contracts.Reading); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]contracts.Reading)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(db.ReadingQuery) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadingsByValueDescriptor provides a mock function with given fields: name, limit
 func (_m *DBClient) ReadingsByValueDescriptor(name string, limit int) ([]contracts.Reading, error) {
 	ret := _m.Called(name, limit)
@@ -549,6 +951,64 @@ func (_m *DBClient) ReadingsByValueDescriptorNames(names []string, limit int) ([
 	return r0, r1
 }
 
+// ReadingsPage provides a mock function with given fields: filter, cursor, limit
+func (_m *DBClient) ReadingsPage(filter db.ReadingFilter, cursor db.Cursor, limit int) ([]contracts.Reading, db.Cursor, error) {
+	ret := _m.Called(filter, cursor, limit)
+
+	var r0 []contracts.Reading
+	if rf, ok := ret.Get(0).(func(db.ReadingFilter, db.Cursor, int) []contracts.Reading); ok {
+		r0 = rf(filter, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]contracts.Reading)
+		}
+	}
+
+	var r1 db.Cursor
+	if rf, ok := ret.Get(1).(func(db.ReadingFilter, db.Cursor, int) db.Cursor); ok {
+		r1 = rf(filter, cursor, limit)
+	} else {
+		r1 = ret.Get(1).(db.Cursor)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(db.ReadingFilter, db.Cursor, int) error); ok {
+		r2 = rf(filter, cursor, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReserveEventKey provides a mock function with given fields: key, expires
+func (_m *DBClient) ReserveEventKey(key string, expires int64) (string, bool, error) {
+	ret := _m.Called(key, expires)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, int64) string); ok {
+		r0 = rf(key, expires)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string, int64) bool); ok {
+		r1 = rf(key, expires)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, int64) error); ok {
+		r2 = rf(key, expires)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // ScrubAllEvents provides a mock function with given fields:
 func (_m *DBClient) ScrubAllEvents() error {
 	ret := _m.Called()
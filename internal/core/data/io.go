@@ -14,7 +14,9 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/ugorji/go/codec"
 
+	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/eventpb"
 )
 
 const (
@@ -33,6 +35,8 @@ func (e ErrUnsupportedContentType) Error() string {
 // EventReader unmarshals a request body into an Event type
 type EventReader interface {
 	Read(reader io.Reader, ctx *context.Context) (models.Event, error)
+	// ReadBatch unmarshals a request body holding many events
+	ReadBatch(reader io.Reader, ctx *context.Context) ([]models.Event, error)
 }
 
 // jsonReader handles unmarshaling of a JSON request body payload
@@ -52,30 +56,113 @@ func (jsonReader) Read(reader io.Reader, ctx *context.Context) (models.Event, er
 	return event, nil
 }
 
+// ReadBatch reads and converts the request's JSON array of events
+func (jsonReader) ReadBatch(reader io.Reader, ctx *context.Context) ([]models.Event, error) {
+	*ctx = context.WithValue(*ctx, clients.ContentType, clients.ContentTypeJSON)
+
+	var events []models.Event
+	err := json.NewDecoder(reader).Decode(&events)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
 // cborReader handles unmarshaling of a CBOR request body payload
 type cborReader struct{}
 
 // Read reads and converts the request's CBOR event data into an Event struct
 func (cborReader) Read(reader io.Reader, ctx *context.Context) (models.Event, error) {
-	c := context.WithValue(*ctx, clients.ContentType, clients.ContentTypeCBOR)
+	return readCodec(reader, ctx, clients.ContentTypeCBOR, &codec.CborHandle{})
+}
+
+// ReadBatch reads a CBOR sequence of events (RFC 8742), i.e. events encoded one after the other.
+// Each event keeps its own bytes and checksum so that it is published as if posted alone.
+func (cborReader) ReadBatch(reader io.Reader, ctx *context.Context) ([]models.Event, error) {
+	return readCodecSequence(reader, ctx, clients.ContentTypeCBOR, &codec.CborHandle{})
+}
+
+// msgpackReader handles unmarshaling of a MessagePack request body payload
+type msgpackReader struct{}
+
+// Read reads and converts the request's MessagePack event data into an Event struct
+func (msgpackReader) Read(reader io.Reader, ctx *context.Context) (models.Event, error) {
+	return readCodec(reader, ctx, internal.ContentTypeMsgPack, &codec.MsgpackHandle{})
+}
+
+// ReadBatch reads MessagePack events encoded one after the other
+func (msgpackReader) ReadBatch(reader io.Reader, ctx *context.Context) ([]models.Event, error) {
+	return readCodecSequence(reader, ctx, internal.ContentTypeMsgPack, &codec.MsgpackHandle{})
+}
+
+// protobufReader handles unmarshaling of a request body payload following the eventpb schema
+type protobufReader struct{}
+
+// Read reads and converts the request's Protobuf event data into an Event struct
+func (protobufReader) Read(reader io.Reader, ctx *context.Context) (models.Event, error) {
+	c := context.WithValue(*ctx, clients.ContentType, internal.ContentTypeProtobuf)
 	event := models.Event{}
 	bytes, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return event, err
 	}
 
-	x := codec.CborHandle{}
-	err = codec.NewDecoderBytes(bytes, &x).Decode(&event)
+	err = eventpb.Unmarshal(bytes, &event.Event)
 	if err != nil {
 		return event, err
 	}
 
-	switch Configuration.Writable.ChecksumAlgo {
-	case ChecksumAlgoxxHash:
-		event.Checksum = fmt.Sprintf("%x", xxhash.Checksum64(bytes))
-	default:
-		event.Checksum = fmt.Sprintf("%x", md5.Sum(bytes))
+	event.Checksum = checksum(bytes)
+	c = context.WithValue(c, checksumContextKey, event.Checksum)
+	*ctx = c
+	event.Bytes = bytes
+
+	return event, nil
+}
+
+// ReadBatch reads a stream of Protobuf events, each preceded by its length as a varint
+func (protobufReader) ReadBatch(reader io.Reader, ctx *context.Context) ([]models.Event, error) {
+	*ctx = context.WithValue(*ctx, clients.ContentType, internal.ContentTypeProtobuf)
+
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := eventpb.SplitDelimited(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.Event, len(messages))
+	for i, m := range messages {
+		err = eventpb.Unmarshal(m, &events[i].Event)
+		if err != nil {
+			return nil, err
+		}
+		events[i].Bytes = m
+		events[i].Checksum = checksum(m)
 	}
+
+	return events, nil
+}
+
+// Read an event encoded with one of the ugorji codecs, keeping its bytes and checksum
+func readCodec(reader io.Reader, ctx *context.Context, contentType string, h codec.Handle) (models.Event, error) {
+	c := context.WithValue(*ctx, clients.ContentType, contentType)
+	event := models.Event{}
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return event, err
+	}
+
+	err = codec.NewDecoderBytes(bytes, h).Decode(&event)
+	if err != nil {
+		return event, err
+	}
+
+	event.Checksum = checksum(bytes)
 	c = context.WithValue(c, checksumContextKey, event.Checksum)
 	*ctx = c
 	event.Bytes = bytes
@@ -83,6 +170,65 @@ func (cborReader) Read(reader io.Reader, ctx *context.Context) (models.Event, er
 	return event, nil
 }
 
+// Read events encoded one after the other with one of the ugorji codecs
+func readCodecSequence(reader io.Reader, ctx *context.Context, contentType string, h codec.Handle) ([]models.Event, error) {
+	*ctx = context.WithValue(*ctx, clients.ContentType, contentType)
+
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := codec.NewDecoderBytes(bytes, h)
+
+	var events []models.Event
+	for start := 0; start < len(bytes); {
+		event := models.Event{}
+		err = dec.Decode(&event)
+		if err != nil {
+			return nil, err
+		}
+
+		end := dec.NumBytesRead()
+		event.Bytes = bytes[start:end]
+		event.Checksum = checksum(event.Bytes)
+		events = append(events, event)
+		start = end
+	}
+
+	return events, nil
+}
+
+// Encode an event again in the binary content type it was received with, once core-data changed it.
+// Only the contract fields are encoded, as device services send them.
+func encodeEvent(e models.Event, contentType string) ([]byte, error) {
+	var h codec.Handle
+	switch contentType {
+	case clients.ContentTypeCBOR:
+		h = &codec.CborHandle{}
+	case internal.ContentTypeMsgPack:
+		h = &codec.MsgpackHandle{}
+	case internal.ContentTypeProtobuf:
+		return eventpb.Marshal(e.Event), nil
+	default:
+		return json.Marshal(e)
+	}
+
+	var bytes []byte
+	err := codec.NewEncoderBytes(&bytes, h).Encode(e.Event)
+	return bytes, err
+}
+
+// Compute the checksum identifying a binary payload with the configured algorithm
+func checksum(bytes []byte) string {
+	switch Configuration.Writable.ChecksumAlgo {
+	case ChecksumAlgoxxHash:
+		return fmt.Sprintf("%x", xxhash.Checksum64(bytes))
+	default:
+		return fmt.Sprintf("%x", md5.Sum(bytes))
+	}
+}
+
 // NewRequestReader returns a BodyReader capable of processing the request body
 func NewRequestReader(request *http.Request) EventReader {
 	contentType := request.Header.Get(clients.ContentType)
@@ -90,6 +236,10 @@ func NewRequestReader(request *http.Request) EventReader {
 	switch contentType {
 	case clients.ContentTypeCBOR:
 		return cborReader{}
+	case internal.ContentTypeMsgPack:
+		return msgpackReader{}
+	case internal.ContentTypeProtobuf:
+		return protobufReader{}
 	default:
 		return jsonReader{}
 	}
@@ -12,8 +12,11 @@ import (
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/ugorji/go/codec"
 
+	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/eventpb"
 )
 
 var TestEvent = contract.Event{
@@ -50,6 +53,20 @@ func TestNewReader(t *testing.T) {
 			},
 			want: cborReader{},
 		},
+		{
+			name: "Get MessagePack Reader",
+			args: args{
+				contentType: internal.ContentTypeMsgPack,
+			},
+			want: msgpackReader{},
+		},
+		{
+			name: "Get Protobuf Reader",
+			args: args{
+				contentType: internal.ContentTypeProtobuf,
+			},
+			want: protobufReader{},
+		},
 		{
 			name: "Get Reader for unknown type",
 			args: args{
@@ -105,6 +122,110 @@ func TestCborSerialization(t *testing.T) {
 
 }
 
+func TestMsgpackSerialization(t *testing.T) {
+	reset()
+	var data []byte
+	err := codec.NewEncoderBytes(&data, &codec.MsgpackHandle{WriteExt: true}).Encode(TestEvent)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding event: %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := msgpackReader{}.Read(bytes.NewReader(data), &ctx)
+	if err != nil {
+		t.Fatalf("Should not encounter an error: %v", err)
+	}
+	if !reflect.DeepEqual(*result.ToContract(), TestEvent) {
+		t.Errorf("TestMsgpackSerialization() = %v, want %v", result, TestEvent)
+	}
+	if result.Checksum != checksum(data) || ctx.Value(checksumContextKey) != result.Checksum {
+		t.Errorf("Checksum should be computed over the payload")
+	}
+	if ctx.Value(clients.ContentType) != internal.ContentTypeMsgPack {
+		t.Errorf("Content type should be set in the context")
+	}
+}
+
+func TestProtobufSerialization(t *testing.T) {
+	reset()
+	data := eventpb.Marshal(TestEvent)
+
+	ctx := context.Background()
+	result, err := protobufReader{}.Read(bytes.NewReader(data), &ctx)
+	if err != nil {
+		t.Fatalf("Should not encounter an error: %v", err)
+	}
+	if !reflect.DeepEqual(*result.ToContract(), TestEvent) {
+		t.Errorf("TestProtobufSerialization() = %v, want %v", result, TestEvent)
+	}
+	if result.Checksum != checksum(data) || !bytes.Equal(result.Bytes, data) {
+		t.Errorf("Checksum and bytes should be those of the payload")
+	}
+}
+
+func TestProtobufBatchSerialization(t *testing.T) {
+	reset()
+	second := TestEvent
+	second.Device = "OtherDevice"
+	data := eventpb.AppendDelimited(eventpb.AppendDelimited(nil, TestEvent), second)
+
+	ctx := context.Background()
+	result, err := protobufReader{}.ReadBatch(bytes.NewReader(data), &ctx)
+	if err != nil {
+		t.Fatalf("Should not encounter an error: %v", err)
+	}
+	if len(result) != 2 || result[1].Device != "OtherDevice" {
+		t.Fatalf("Unexpected batch %v", result)
+	}
+	if !bytes.Equal(result[1].Bytes, eventpb.Marshal(second)) || result[1].Checksum != checksum(result[1].Bytes) {
+		t.Errorf("Each event should keep its own bytes and checksum")
+	}
+}
+
+func TestCborBatchSerialization(t *testing.T) {
+	reset()
+	first := models.Event{Event: TestEvent}
+	second := models.Event{Event: TestEvent}
+	second.Device = "OtherDevice"
+	chunks := [][]byte{first.CBOR(), second.CBOR()}
+	r := ioutil.NopCloser(bytes.NewBuffer(append(append([]byte{}, chunks[0]...), chunks[1]...)))
+
+	cborReader := cborReader{}
+	ctx := context.Background()
+	result, err := cborReader.ReadBatch(r, &ctx)
+	if err != nil {
+		t.Fatalf("Should not encounter an error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(result))
+	}
+
+	for i, e := range result {
+		if !bytes.Equal(e.Bytes, chunks[i]) {
+			t.Errorf("Event %d should keep its own bytes", i)
+		}
+		if e.Checksum != checksum(chunks[i]) {
+			t.Errorf("Event %d should have the checksum of its own bytes", i)
+		}
+	}
+	if result[1].Device != "OtherDevice" {
+		t.Errorf("Second event decoded incorrectly: %v", result[1])
+	}
+}
+
+func TestJsonBatchSerialization(t *testing.T) {
+	r := strings.NewReader(`[{"device":"d1"},{"device":"d2"}]`)
+
+	ctx := context.Background()
+	result, err := jsonReader{}.ReadBatch(r, &ctx)
+	if err != nil {
+		t.Fatalf("Should not encounter an error: %v", err)
+	}
+	if len(result) != 2 || result[0].Device != "d1" || result[1].Device != "d2" {
+		t.Errorf("TestJsonBatchSerialization() = %v", result)
+	}
+}
+
 func newRequestWithContentType(contentType string) *http.Request {
 	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader("Test body"))
 	req.Header.Set(clients.ContentType, contentType)
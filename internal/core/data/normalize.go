@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/units"
+)
+
+// Convert the readings of an event into the canonical unit of their value descriptor when
+// normalization is enabled, keeping the values they were received with in the event's Originals.
+// Readings without a unit in the device profile or without a canonical unit are left as they are.
+// Value descriptors are kept in found as with validateReadings.
+func normalizeReadings(e models.Event, found map[string]contract.ValueDescriptor, ctx context.Context) (models.Event, error) {
+	if !Configuration.Writable.Normalization.Enabled || len(e.Readings) == 0 {
+		return e, nil
+	}
+
+	resources, err := cachedDeviceUnits(e.Device, ctx)
+	if err != nil {
+		return e, err
+	}
+
+	// The readings are copied as the caller's event shares them
+	readings := make([]contract.Reading, len(e.Readings))
+	copy(readings, e.Readings)
+
+	var originals []models.Original
+	for i, r := range readings {
+		from := resources[r.Name]
+		if from == "" {
+			continue
+		}
+		to, err := canonicalUnit(r.Name, found)
+		if err != nil {
+			return e, err
+		}
+		if to == "" || to == from {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(r.Value, 64)
+		if err != nil {
+			return e, errors.NewErrUnitConversion(r.Name, fmt.Errorf("value '%s' is not a number", r.Value))
+		}
+		v, err = units.Convert(v, from, to)
+		if err != nil {
+			return e, errors.NewErrUnitConversion(r.Name, err)
+		}
+
+		readings[i].Value = formatConverted(v)
+		originals = append(originals, models.Original{Index: i, Value: r.Value, Unit: from})
+	}
+
+	if len(originals) == 0 {
+		return e, nil
+	}
+
+	LoggingClient.Debug(fmt.Sprintf("converted %d readings of device %s", len(originals), e.Device))
+	e.Readings = readings
+	e.Originals = originals
+
+	// Binary payloads are published as received, so they must follow the converted values
+	if e.Bytes != nil {
+		e.Bytes, err = encodeEvent(e, clients.FromContext(clients.ContentType, ctx))
+		if err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// The unit readings of a value descriptor are stored in, empty when there is none
+func canonicalUnit(name string, found map[string]contract.ValueDescriptor) (string, error) {
+	if u, ok := Configuration.Writable.Normalization.Units[name]; ok {
+		return u, nil
+	}
+
+	vd, ok := found[name]
+	if !ok {
+		var err error
+		vd, err = cachedValueDescriptorByName(name)
+		if err == db.ErrNotFound {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		found[name] = vd
+	}
+	return vd.UomLabel, nil
+}
+
+// Converted values are rounded to 12 significant digits, so that 212°F is stored as 100°C
+func formatConverted(v float64) string {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 12, 64), 64)
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
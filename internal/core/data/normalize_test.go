@@ -0,0 +1,146 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata/mocks"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal"
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/eventpb"
+)
+
+// Set up a device reporting its temperature in Fahrenheit and its pressure in bar,
+// temperatures being stored in Celsius as their value descriptor says
+func setupNormalization(pressureUnit string) func() {
+	reset()
+	Configuration.Writable.Normalization = NormalizationInfo{
+		Enabled: true,
+		Units:   map[string]string{"Pressure": pressureUnit},
+	}
+
+	profile := contract.DeviceProfile{DeviceResources: []contract.DeviceResource{
+		{Name: "Temperature", Properties: contract.ProfileProperty{Units: contract.Units{DefaultValue: "F"}}},
+		{Name: "Pressure", Properties: contract.ProfileProperty{Units: contract.Units{DefaultValue: "bar"}}},
+	}}
+	client := &mocks.DeviceClient{}
+	client.On("CheckForDevice", testDeviceName, mock.Anything).Return(contract.Device{Name: testDeviceName, Profile: profile}, nil)
+
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", "Temperature").Return(contract.ValueDescriptor{Name: "Temperature", UomLabel: "C"}, nil)
+	dbClient = myMock
+
+	saved := mdc
+	mdc = client
+	return func() { mdc = saved }
+}
+
+func TestNormalizeReadings(t *testing.T) {
+	defer setupNormalization("kPa")()
+
+	e := models.Event{Event: testEvent}
+	result, err := normalizeReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Readings[0].Value != "7.22222222222" || result.Readings[1].Value != "101.325" {
+		t.Errorf("Unexpected converted readings %v", result.Readings)
+	}
+	expected := []models.Original{{Index: 0, Value: "45", Unit: "F"}, {Index: 1, Value: "1.01325", Unit: "bar"}}
+	if !reflect.DeepEqual(result.Originals, expected) {
+		t.Errorf("Expected originals %v, got %v", expected, result.Originals)
+	}
+	if testEvent.Readings[0].Value != "45" {
+		t.Errorf("The readings of the event passed in should be left alone")
+	}
+}
+
+func TestNormalizeReadingsDisabled(t *testing.T) {
+	reset()
+
+	e := models.Event{Event: testEvent}
+	result, err := normalizeReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+	if err != nil || !reflect.DeepEqual(result, e) {
+		t.Errorf("Events should pass unchanged, got %v %v", result, err)
+	}
+}
+
+func TestNormalizeReadingsBinary(t *testing.T) {
+	defer setupNormalization("bar")()
+
+	e := models.Event{Event: testEvent, Bytes: eventpb.Marshal(testEvent)}
+	ctx := context.WithValue(context.Background(), clients.ContentType, internal.ContentTypeProtobuf)
+	result, err := normalizeReadings(e, make(map[string]contract.ValueDescriptor), ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var published contract.Event
+	if err = eventpb.Unmarshal(result.Bytes, &published); err != nil {
+		t.Fatalf("Unexpected error decoding the payload: %v", err)
+	}
+	if published.Readings[0].Value != "7.22222222222" || published.Readings[1].Value != "1.01325" {
+		t.Errorf("The payload should hold the converted readings, got %v", published.Readings)
+	}
+}
+
+func TestNormalizeReadingsIncompatible(t *testing.T) {
+	defer setupNormalization("C")()
+
+	e := models.Event{Event: testEvent}
+	_, err := normalizeReadings(e, make(map[string]contract.ValueDescriptor), context.Background())
+	if _, ok := err.(*errors.ErrUnitConversion); !ok {
+		t.Fatalf("Expected a unit conversion error, got %v", err)
+	}
+}
+
+func TestPostEventIncompatibleUnit(t *testing.T) {
+	defer setupNormalization("C")()
+
+	// The event is rejected rather than reported as stored
+	body, _ := json.Marshal(testEvent)
+	req := httptest.NewRequest(http.MethodPost, clients.ApiEventRoute, bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Pressure") {
+		t.Errorf("Expected the conversion of the pressure to be rejected, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetEventWithOriginals(t *testing.T) {
+	reset()
+	stored := models.Event{Event: testEvent, Originals: []models.Original{{Index: 0, Value: "45", Unit: "F"}}}
+	myMock := &dbMock.DBClient{}
+	myMock.On("EventWithOriginalsById", testEvent.ID).Return(stored, nil)
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodGet, clients.ApiEventRoute+"/"+testEvent.ID, nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected %d getting the event, got %d", http.StatusOK, rr.Code)
+	}
+	var found struct {
+		Originals []models.Original `json:"originals"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &found); err != nil {
+		t.Fatalf("Unexpected error decoding the event: %v", err)
+	}
+	if !reflect.DeepEqual(found.Originals, stored.Originals) {
+		t.Errorf("Expected originals %v, got %v", stored.Originals, found.Originals)
+	}
+}
@@ -0,0 +1,172 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	// Query parameter carrying the cursor returned by the previous page
+	// An empty value asks for the first page
+	cursorParam = "cursor"
+	// Response header carrying the cursor of the next page, absent on the last page
+	nextCursorHeader = "X-Next-Cursor"
+	// Accept value asking for the whole listing to be streamed as newline delimited JSON
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// pager returns a page of at most limit items starting after the cursor,
+// along with the cursor of the next page (empty on the last page)
+type pager func(cursor db.Cursor, limit int) ([]interface{}, db.Cursor, error)
+
+// Whether the listing should be paged with a cursor or streamed instead of returned in one piece
+func pagedRequest(r *http.Request) bool {
+	_, ok := r.URL.Query()[cursorParam]
+	return ok || streamRequested(r)
+}
+
+func streamRequested(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), contentTypeNDJSON)
+}
+
+// Serve a paged listing
+// With a cursor, a single page of at most limit items is returned as a JSON array and the
+// cursor of the next page is set in the X-Next-Cursor header
+// When streaming, every page is written as one JSON object per line until the listing is
+// exhausted, limit being the number of items read from the database at a time
+func servePage(w http.ResponseWriter, r *http.Request, limit int, fetch pager) {
+	cursor, err := db.ParseCursor(r.URL.Query().Get(cursorParam))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	if streamRequested(r) {
+		streamPages(w, r, cursor, limit, fetch)
+		return
+	}
+
+	items, next, err := fetch(cursor, limit)
+	if err != nil {
+		pageError(w, err)
+		return
+	}
+
+	if !next.IsZero() {
+		w.Header().Set(nextCursorHeader, next.String())
+	}
+	encode(items, w)
+}
+
+func streamPages(w http.ResponseWriter, r *http.Request, cursor db.Cursor, limit int, fetch pager) {
+	ctx := r.Context()
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	started := false
+
+	for {
+		items, next, err := fetch(cursor, limit)
+		if err != nil {
+			// Once the first line is out the status can no longer be changed
+			if !started {
+				pageError(w, err)
+			}
+			LoggingClient.Error("Error streaming listing: " + err.Error())
+			return
+		}
+
+		if !started {
+			w.Header().Set("Content-Type", contentTypeNDJSON)
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+
+		for _, item := range items {
+			if err = enc.Encode(item); err != nil {
+				LoggingClient.Error("Error encoding the data: " + err.Error())
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if next.IsZero() || len(items) == 0 {
+			return
+		}
+		cursor = next
+
+		// Stop reading from the database once the client went away
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func pageError(w http.ResponseWriter, err error) {
+	if err == db.ErrInvalidCursor {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func eventPager(filter db.EventFilter) pager {
+	return func(cursor db.Cursor, limit int) ([]interface{}, db.Cursor, error) {
+		events, next, err := getEventsPage(filter, cursor, limit)
+		if err != nil {
+			return nil, next, err
+		}
+
+		items := make([]interface{}, len(events))
+		for i := range events {
+			items[i] = events[i]
+		}
+		return items, next, nil
+	}
+}
+
+func readingPager(filter db.ReadingFilter) pager {
+	return func(cursor db.Cursor, limit int) ([]interface{}, db.Cursor, error) {
+		readings, next, err := getReadingsPage(filter, cursor, limit)
+		if err != nil {
+			return nil, next, err
+		}
+
+		items := make([]interface{}, len(readings))
+		for i := range readings {
+			items[i] = readings[i]
+		}
+		return items, next, nil
+	}
+}
+
+// Readings for a set of value descriptors
+// Unlike an empty filter, an empty set of names matches no readings at all
+func readingsByNamesPager(names []string) pager {
+	if len(names) == 0 {
+		return func(db.Cursor, int) ([]interface{}, db.Cursor, error) {
+			return []interface{}{}, db.Cursor{}, nil
+		}
+	}
+	return readingPager(db.ReadingFilter{Names: names})
+}
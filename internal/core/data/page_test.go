@@ -0,0 +1,140 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Only the ID is decoded, as unmarshalling a contract event validates its content
+type pagedEvent struct {
+	ID string `json:"id"`
+}
+
+func newEventsPageMockDB() *dbMock.DBClient {
+	myMock := &dbMock.DBClient{}
+
+	second := db.Cursor{Created: 2, Id: "2"}
+	myMock.On("EventsPage", db.EventFilter{}, db.Cursor{}, mock.Anything).
+		Return([]models.Event{{ID: "1", Created: 1}, {ID: "2", Created: 2}}, second, nil)
+	myMock.On("EventsPage", db.EventFilter{}, second, mock.Anything).
+		Return([]models.Event{{ID: "3", Created: 3}}, db.Cursor{}, nil)
+
+	return myMock
+}
+
+func TestPagedEvents(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 2
+	dbClient = newEventsPageMockDB()
+
+	req := httptest.NewRequest(http.MethodGet, clients.ApiEventRoute+"?cursor=", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var events []pagedEvent
+	if err := json.Unmarshal(rr.Body.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	next := rr.Header().Get(nextCursorHeader)
+	if next == "" {
+		t.Fatalf("expected a cursor to the next page")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, clients.ApiEventRoute+"?cursor="+next, nil)
+	rr = httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	events = nil
+	if err := json.Unmarshal(rr.Body.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ID != "3" {
+		t.Fatalf("expected the last event, got %v", events)
+	}
+	if rr.Header().Get(nextCursorHeader) != "" {
+		t.Errorf("expected no cursor on the last page")
+	}
+}
+
+func TestPagedEventsInvalidCursor(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 2
+	dbClient = newEventsPageMockDB()
+
+	req := httptest.NewRequest(http.MethodGet, clients.ApiEventRoute+"?cursor=not-a-cursor", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 2
+	myMock := newEventsPageMockDB()
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodGet, clients.ApiEventRoute, nil)
+	req.Header.Set("Accept", contentTypeNDJSON)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if rr.Header().Get("Content-Type") != contentTypeNDJSON {
+		t.Errorf("expected content type %s, got %s", contentTypeNDJSON, rr.Header().Get("Content-Type"))
+	}
+
+	var ids []string
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var e pagedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[2] != "3" {
+		t.Errorf("expected events 1 to 3 in order, got %v", ids)
+	}
+
+	myMock.AssertExpectations(t)
+}
+
+func TestStreamReadingsEmptyNames(t *testing.T) {
+	reset()
+	dbClient = &dbMock.DBClient{}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", contentTypeNDJSON)
+	rr := httptest.NewRecorder()
+	servePage(rr, req, 10, readingsByNamesPager(nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("expected no readings, got %s", rr.Body.String())
+	}
+}
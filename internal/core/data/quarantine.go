@@ -0,0 +1,123 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	quarantineRoute = clients.ApiBase + "/quarantine"
+
+	replayContextKey = "quarantine-replay"
+)
+
+// Store an event as received along with the reason it was not accepted
+func quarantineEvent(e models.Event, reason string) error {
+	id, err := dbClient.AddQuarantinedEvent(db.QuarantinedEvent{Reason: reason, Event: e.Event})
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("error quarantining event of device %s: %s", e.Device, err.Error()))
+		return err
+	}
+	LoggingClient.Warn(fmt.Sprintf("event of device %s quarantined as %s: %s", e.Device, id, reason))
+	return errors.NewErrEventQuarantined(id, reason)
+}
+
+// Quarantine an event failing the device check or the validation of its readings when quarantine
+// is enabled, rather than losing it to a device or value descriptor that is yet to be fixed.
+// Other errors, and those of events being replayed, are returned as they are.
+func quarantineRejected(e models.Event, err error, ctx context.Context) error {
+	if !Configuration.Writable.Quarantine.Enabled || replaying(ctx) || !quarantinable(err) {
+		return err
+	}
+	return quarantineEvent(e, err.Error())
+}
+
+func quarantinable(err error) bool {
+	switch err := err.(type) {
+	case *types.ErrServiceClient:
+		return err.StatusCode == http.StatusNotFound
+	case *errors.ErrValueDescriptorNotFound, *errors.ErrValueDescriptorInvalid:
+		return true
+	default:
+		return false
+	}
+}
+
+func replaying(ctx context.Context) bool {
+	replay, _ := ctx.Value(replayContextKey).(bool)
+	return replay
+}
+
+func getQuarantinedEvents(device string, limit int) ([]db.QuarantinedEvent, error) {
+	var quarantined []db.QuarantinedEvent
+	var err error
+	if device == "" {
+		quarantined, err = dbClient.QuarantinedEvents(limit)
+	} else {
+		quarantined, err = dbClient.QuarantinedEventsByDevice(device, limit)
+	}
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, err
+	}
+	return quarantined, nil
+}
+
+// Add a quarantined event again, as it was received or as fixed, removing it from the quarantine
+// once added. Replayed events are not quarantined again, nor checked against the acceptance window.
+func replayQuarantinedEvent(id string, fixed *contract.Event, ctx context.Context) (string, error) {
+	q, err := dbClient.QuarantinedEventById(id)
+	if err != nil {
+		return "", err
+	}
+
+	e := q.Event
+	if fixed != nil {
+		e = *fixed
+	}
+
+	// An event whose readings are all suppressed is replayed, though nothing is stored
+	newId, err := addNewEvent(models.Event{Event: e}, context.WithValue(ctx, replayContextKey, true))
+	if _, suppressed := err.(*errors.ErrEventSuppressed); err != nil && !suppressed {
+		return "", err
+	}
+
+	LoggingClient.Info(fmt.Sprintf("quarantined event %s replayed as %s", id, newId))
+	if removeErr := dbClient.DeleteQuarantinedEventById(id); removeErr != nil {
+		LoggingClient.Error(fmt.Sprintf("error removing replayed event %s from quarantine: %s", id, removeErr.Error()))
+	}
+	return newId, err
+}
+
+func purgeQuarantinedEvents(before int64) (int, error) {
+	count, err := dbClient.DeleteQuarantinedEvents(before)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return 0, err
+	}
+
+	LoggingClient.Info(fmt.Sprintf("purged %d quarantined events", count))
+	return count, nil
+}
@@ -0,0 +1,178 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestQuarantinable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"device not found", types.NewErrServiceClient(http.StatusNotFound, []byte("not found")), true},
+		{"metadata unavailable", types.NewErrServiceClient(http.StatusServiceUnavailable, nil), false},
+		{"value descriptor not found", errors.NewErrValueDescriptorNotFound("temperature"), true},
+		{"invalid reading", errors.NewErrValueDescriptorInvalid("temperature", nil), true},
+		{"database", db.ErrNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if quarantinable(tt.err) != tt.expected {
+				t.Errorf("Expected %v for %v", tt.expected, tt.err)
+			}
+		})
+	}
+}
+
+func TestAddEventQuarantinedOnValidation(t *testing.T) {
+	reset()
+	Configuration.Writable.ValidateCheck = true
+	Configuration.Writable.Quarantine.Enabled = true
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", mock.Anything).Return(contract.ValueDescriptor{}, db.ErrNotFound)
+	myMock.On("AddQuarantinedEvent", mock.MatchedBy(func(q db.QuarantinedEvent) bool {
+		return q.Event.Device == testDeviceName && strings.Contains(q.Reason, "no value descriptor")
+	})).Return("q1", nil)
+	dbClient = myMock
+
+	_, err := addNewEvent(models.Event{Event: testEvent}, context.Background())
+	if _, ok := err.(*errors.ErrEventQuarantined); !ok {
+		t.Fatalf("Expected the event to be quarantined, got %v", err)
+	}
+
+	// Posted, the event is reported as accepted along with its quarantine id and reason
+	body, _ := json.Marshal(testEvent)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusAccepted || !strings.Contains(rr.Body.String(), "q1") {
+		t.Errorf("Expected the event to be quarantined as q1, got %d %s", rr.Code, rr.Body.String())
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestAddEventNotQuarantinedWhenDisabled(t *testing.T) {
+	reset()
+	Configuration.Writable.ValidateCheck = true
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", mock.Anything).Return(contract.ValueDescriptor{}, db.ErrNotFound)
+	dbClient = myMock
+
+	_, err := addNewEvent(models.Event{Event: testEvent}, context.Background())
+	if _, ok := err.(*errors.ErrValueDescriptorNotFound); !ok {
+		t.Fatalf("Expected the event to be rejected, got %v", err)
+	}
+	myMock.AssertNotCalled(t, "AddQuarantinedEvent", mock.Anything)
+}
+
+func TestReplayQuarantinedEvent(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.Quarantine.Enabled = true
+	// Replayed events are accepted whatever their origin
+	Configuration.Writable.Acceptance = AcceptanceInfo{MaxAge: "1h", Action: "Quarantine"}
+	myMock := &dbMock.DBClient{}
+	myMock.On("QuarantinedEventById", "q1").Return(db.QuarantinedEvent{ID: "q1", Event: testEvent}, nil)
+	myMock.On("AddEvent", mock.Anything).Return("new", nil)
+	myMock.On("DeleteQuarantinedEventById", "q1").Return(nil)
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodPost, quarantineRoute+"/q1/replay", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	<-chEvents
+	<-chEvents
+
+	if rr.Code != http.StatusOK || rr.Body.String() != "new" {
+		t.Fatalf("Expected the replayed event id, got %d %s", rr.Code, rr.Body.String())
+	}
+	myMock.AssertExpectations(t)
+}
+
+func TestReplayFixedEventStillInvalid(t *testing.T) {
+	reset()
+	Configuration.Writable.ValidateCheck = true
+	Configuration.Writable.Quarantine.Enabled = true
+	myMock := &dbMock.DBClient{}
+	myMock.On("QuarantinedEventById", "q1").Return(db.QuarantinedEvent{ID: "q1", Event: testEvent}, nil)
+	myMock.On("ValueDescriptorByName", mock.Anything).Return(contract.ValueDescriptor{}, db.ErrNotFound)
+	dbClient = myMock
+
+	fixed := testEvent
+	fixed.Readings = []contract.Reading{{Name: "Fixed", Value: "1"}}
+	body, _ := json.Marshal(fixed)
+	req := httptest.NewRequest(http.MethodPost, quarantineRoute+"/q1/replay", strings.NewReader(string(body)))
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, rr.Code)
+	}
+	myMock.AssertCalled(t, "ValueDescriptorByName", "Fixed")
+	myMock.AssertNotCalled(t, "AddQuarantinedEvent", mock.Anything)
+	myMock.AssertNotCalled(t, "DeleteQuarantinedEventById", mock.Anything)
+}
+
+func TestQuarantineHandler(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+	myMock.On("QuarantinedEventsByDevice", testDeviceName, 10).
+		Return([]db.QuarantinedEvent{{ID: "q1", Reason: "invalid", Event: testEvent}}, nil)
+	myMock.On("QuarantinedEventById", "unknown").Return(db.QuarantinedEvent{}, db.ErrNotFound)
+	myMock.On("DeleteQuarantinedEvents", int64(1000)).Return(3, nil)
+	dbClient = myMock
+
+	req := httptest.NewRequest(http.MethodGet, quarantineRoute+"?device="+url.QueryEscape(testDeviceName), nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"reason":"invalid"`) {
+		t.Errorf("Expected the quarantined events of the device, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, quarantineRoute+"?limit=50", nil)
+	rr = httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, quarantineRoute+"?limit=0", nil)
+	rr = httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, quarantineRoute+"/unknown", nil)
+	rr = httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, quarantineRoute+"?before=1000", nil)
+	rr = httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "3" {
+		t.Errorf("Expected 3 events purged, got %d %s", rr.Code, rr.Body.String())
+	}
+	myMock.AssertExpectations(t)
+}
@@ -0,0 +1,211 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// readingQuery combines predicates over readings, sent as a JSON body or as a query string
+// Labels, UoM labels and types select readings through their value descriptors
+type readingQuery struct {
+	Devices     []string            `json:"devices"`
+	Names       []string            `json:"names"`
+	Labels      []string            `json:"labels"`
+	UomLabels   []string            `json:"uomLabels"`
+	Types       []string            `json:"types"`
+	Start       int64               `json:"start"`
+	End         int64               `json:"end"`
+	OriginStart int64               `json:"originStart"`
+	OriginEnd   int64               `json:"originEnd"`
+	Values      []db.ValueCondition `json:"values"`
+	Sort        string              `json:"sort"`
+	Limit       int                 `json:"limit"`
+}
+
+// Read a reading query from the body of a POST or the query string of a GET
+// In a query string, value conditions are written as op:operand (e.g. value=gt:20)
+func decodeReadingQuery(r *http.Request) (rq readingQuery, err error) {
+	if r.Method == http.MethodPost {
+		if err = json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			return rq, errors.NewErrInvalidQuery(err.Error())
+		}
+		return rq, nil
+	}
+
+	params := r.URL.Query()
+	rq.Devices = params["device"]
+	rq.Names = params["name"]
+	rq.Labels = params["label"]
+	rq.UomLabels = params["uomlabel"]
+	rq.Types = params["type"]
+	rq.Sort = params.Get("sort")
+
+	for _, v := range params["value"] {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 {
+			return rq, errors.NewErrInvalidQuery("value condition '" + v + "' is not op:operand")
+		}
+		rq.Values = append(rq.Values, db.ValueCondition{Op: parts[0], Value: parts[1]})
+	}
+
+	times := map[string]*int64{
+		"start":       &rq.Start,
+		"end":         &rq.End,
+		"originStart": &rq.OriginStart,
+		"originEnd":   &rq.OriginEnd,
+	}
+	for name, t := range times {
+		if *t, err = queryInt(params, name); err != nil {
+			return rq, err
+		}
+	}
+
+	limit, err := queryInt(params, "limit")
+	if err != nil {
+		return rq, err
+	}
+	rq.Limit = int(limit)
+
+	return rq, nil
+}
+
+func queryInt(params url.Values, name string) (int64, error) {
+	v := params.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, errors.NewErrInvalidQuery(name + " is not an integer: " + v)
+	}
+	return i, nil
+}
+
+// Run a reading query, resolving its labels, UoM labels and types to value descriptor names
+// The limit defaults to the configured max result count
+func queryReadings(rq readingQuery) ([]contract.Reading, error) {
+	limit := rq.Limit
+	if limit == 0 {
+		limit = Configuration.Service.MaxResultCount
+	}
+	if err := checkMaxLimit(limit); err != nil {
+		return nil, err
+	}
+
+	query := db.ReadingQuery{
+		Devices:     rq.Devices,
+		Start:       rq.Start,
+		End:         rq.End,
+		OriginStart: rq.OriginStart,
+		OriginEnd:   rq.OriginEnd,
+		Values:      rq.Values,
+		Sort:        rq.Sort,
+		Limit:       limit,
+	}
+	if err := query.Validate(); err != nil {
+		return nil, errors.NewErrInvalidQuery(err.Error())
+	}
+
+	names, ok, err := valueDescriptorNames(rq)
+	if err != nil {
+		return nil, err
+	}
+	// No value descriptor satisfies the criteria, so neither does any reading
+	if !ok {
+		return []contract.Reading{}, nil
+	}
+	query.Names = names
+
+	readings, err := dbClient.ReadingsByQuery(query)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+// Narrow the value descriptor names of the query down to those also matching its labels,
+// UoM labels and types. ok is false when the criteria cannot match any value descriptor.
+func valueDescriptorNames(rq readingQuery) (names []string, ok bool, err error) {
+	var sets []map[string]bool
+	if len(rq.Names) > 0 {
+		set := make(map[string]bool)
+		for _, name := range rq.Names {
+			set[name] = true
+		}
+		sets = append(sets, set)
+	}
+
+	lookups := []struct {
+		values []string
+		lookup func(string) ([]contract.ValueDescriptor, error)
+	}{
+		{rq.Labels, getValueDescriptorsByLabel},
+		{rq.UomLabels, getValueDescriptorsByUomLabel},
+		{rq.Types, getValueDescriptorsByType},
+	}
+	for _, l := range lookups {
+		if len(l.values) == 0 {
+			continue
+		}
+
+		set := make(map[string]bool)
+		for _, v := range l.values {
+			vdList, err := l.lookup(v)
+			if err != nil {
+				if _, notFound := err.(*errors.ErrDbNotFound); notFound {
+					continue
+				}
+				return nil, false, err
+			}
+			for _, vd := range vdList {
+				set[vd.Name] = true
+			}
+		}
+		sets = append(sets, set)
+	}
+
+	if len(sets) == 0 {
+		return nil, true, nil
+	}
+
+	for name := range sets[0] {
+		inAll := true
+		for _, set := range sets[1:] {
+			if !set[name] {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, len(names) > 0, nil
+}
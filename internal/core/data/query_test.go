@@ -0,0 +1,131 @@
+package data
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestDecodeReadingQueryString(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/reading/query?device=d1&device=d2&label=L&start=10&value=gt:20&sort=-origin&limit=5", nil)
+
+	rq, err := decodeReadingQuery(req)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding query: %v", err)
+	}
+
+	if len(rq.Devices) != 2 || rq.Labels[0] != "L" || rq.Start != 10 || rq.Sort != "-origin" || rq.Limit != 5 {
+		t.Errorf("Query string decoded incorrectly: %+v", rq)
+	}
+	if len(rq.Values) != 1 || rq.Values[0].Op != db.OpGreater || rq.Values[0].Value != "20" {
+		t.Errorf("Value condition decoded incorrectly: %+v", rq.Values)
+	}
+}
+
+func TestDecodeReadingQueryBody(t *testing.T) {
+	body := `{"names":["temperature"],"values":[{"op":"lte","value":"30"}],"originEnd":99}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reading/query", strings.NewReader(body))
+
+	rq, err := decodeReadingQuery(req)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding query: %v", err)
+	}
+
+	if rq.Names[0] != "temperature" || rq.OriginEnd != 99 || rq.Values[0].Op != db.OpLessOrEqual {
+		t.Errorf("Body decoded incorrectly: %+v", rq)
+	}
+}
+
+func TestDecodeReadingQueryInvalid(t *testing.T) {
+	tests := []string{"value=20", "start=yesterday", "limit=ten"}
+	for _, q := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/reading/query?"+q, nil)
+
+		_, err := decodeReadingQuery(req)
+		if _, ok := err.(*errors.ErrInvalidQuery); !ok {
+			t.Errorf("Expected invalid query error for %s, got %v", q, err)
+		}
+	}
+}
+
+func TestQueryReadingsResolvesValueDescriptors(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("ValueDescriptorsByLabel", "L").Return([]models.ValueDescriptor{{Name: "a"}, {Name: "b"}}, nil)
+	myMock.On("ValueDescriptorsByType", "Float64").Return([]models.ValueDescriptor{{Name: "b"}, {Name: "c"}}, nil)
+	myMock.On("ReadingsByQuery", mock.MatchedBy(func(q db.ReadingQuery) bool {
+		return len(q.Names) == 1 && q.Names[0] == "b" && q.Limit == 10
+	})).Return([]models.Reading{{Name: "b"}}, nil)
+
+	dbClient = myMock
+
+	readings, err := queryReadings(readingQuery{Labels: []string{"L"}, Types: []string{"Float64"}})
+	if err != nil {
+		t.Fatalf("Unexpected error querying readings: %v", err)
+	}
+	if len(readings) != 1 {
+		t.Errorf("Expected 1 reading, got %d", len(readings))
+	}
+
+	myMock.AssertExpectations(t)
+}
+
+func TestQueryReadingsNoMatchingValueDescriptor(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("ValueDescriptorsByUomLabel", "C").Return([]models.ValueDescriptor{}, db.ErrNotFound)
+
+	dbClient = myMock
+
+	readings, err := queryReadings(readingQuery{UomLabels: []string{"C"}})
+	if err != nil {
+		t.Fatalf("Unexpected error querying readings: %v", err)
+	}
+	if len(readings) != 0 {
+		t.Errorf("Expected no readings, got %d", len(readings))
+	}
+
+	myMock.AssertNotCalled(t, "ReadingsByQuery", mock.Anything)
+}
+
+func TestQueryReadingsInvalid(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	dbClient = &dbMock.DBClient{}
+
+	tests := []readingQuery{
+		{Values: []db.ValueCondition{{Op: "like", Value: "1"}}},
+		{Values: []db.ValueCondition{{Op: db.OpGreater, Value: "warm"}}},
+		{Sort: "name"},
+	}
+	for _, rq := range tests {
+		_, err := queryReadings(rq)
+		if _, ok := err.(*errors.ErrInvalidQuery); !ok {
+			t.Errorf("Expected invalid query error for %+v, got %v", rq, err)
+		}
+	}
+}
+
+func TestQueryReadingsOverLimit(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 10
+	dbClient = &dbMock.DBClient{}
+
+	_, err := queryReadings(readingQuery{Limit: 11})
+	if _, ok := err.(*errors.ErrLimitExceeded); !ok {
+		t.Errorf("Expected limit exceeded error, got %v", err)
+	}
+}
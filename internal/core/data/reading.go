@@ -64,7 +64,7 @@ func decodeReading(reader io.Reader) (reading contract.Reading, err error) {
 
 func validateReading(reading contract.Reading) error {
 	// Check the value descriptor
-	vd, err := dbClient.ValueDescriptorByName(reading.Name)
+	vd, err := cachedValueDescriptorByName(reading.Name)
 	if err != nil {
 		LoggingClient.Error(err.Error())
 		if err == db.ErrNotFound {
@@ -231,6 +231,26 @@ func getReadingsByCreationTime(start int64, end int64, limit int) (readings []co
 	return readings, nil
 }
 
+func getReadingsByOriginTime(start int64, end int64, limit int) (readings []contract.Reading, err error) {
+	readings, err = dbClient.ReadingsByOriginTime(start, end, limit)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+func getReadingsPage(filter db.ReadingFilter, cursor db.Cursor, limit int) (readings []contract.Reading, next db.Cursor, err error) {
+	readings, next, err = dbClient.ReadingsPage(filter, cursor, limit)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return nil, db.Cursor{}, err
+	}
+
+	return readings, next, nil
+}
+
 func getReadingsByDeviceAndValueDescriptor(device string, name string, limit int) (readings []contract.Reading, err error) {
 	readings, err = dbClient.ReadingsByDeviceAndValueDescriptor(device, name, limit)
 	if err != nil {
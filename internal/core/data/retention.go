@@ -0,0 +1,239 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	defaultRetentionInterval  = 5 * time.Minute
+	defaultRetentionBatchSize = 1000
+)
+
+// Time between two maintenances of a database expiring or evicting data on its own
+const maintenanceInterval = time.Minute
+
+var chRetention chan struct{} // Closed to stop the retention janitor and the database maintenance
+
+// maintainer is implemented by the database clients expiring or evicting data on their own,
+// which need to be called periodically to keep their indexes coherent
+type maintainer interface {
+	Maintain() (expired int, evicted int, err error)
+}
+
+// Enforce the retention configuration periodically until stop is closed
+// The configuration is read on every pass so that changes from the Registry apply
+func runRetention(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+
+		case <-time.After(retentionInterval(Configuration.Writable.Retention)):
+			retention := Configuration.Writable.Retention
+			if retention.Enabled {
+				enforceRetention(retention, db.MakeTimestamp())
+			}
+		}
+	}
+}
+
+// Maintain the database periodically until stop is closed
+func runMaintenance(m maintainer, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+
+		case <-time.After(maintenanceInterval):
+			expired, evicted, err := m.Maintain()
+			if err != nil {
+				LoggingClient.Error("database maintenance failed: " + err.Error())
+			}
+			if expired > 0 || evicted > 0 {
+				LoggingClient.Info(fmt.Sprintf("database maintenance expired %d and evicted %d events and readings", expired, evicted))
+			}
+		}
+	}
+}
+
+func retentionInterval(r RetentionInfo) time.Duration {
+	if r.Interval == "" {
+		return defaultRetentionInterval
+	}
+
+	d, err := time.ParseDuration(r.Interval)
+	if err != nil || d <= 0 {
+		LoggingClient.Error(fmt.Sprintf("invalid retention interval '%s', using %s", r.Interval, defaultRetentionInterval))
+		return defaultRetentionInterval
+	}
+	return d
+}
+
+// Apply the device, global and value descriptor policies, then the size cap
+func enforceRetention(r RetentionInfo, now int64) {
+	batch := r.BatchSize
+	if batch <= 0 {
+		batch = defaultRetentionBatchSize
+	}
+
+	var overridden []string
+	for device := range r.Devices {
+		overridden = append(overridden, device)
+	}
+	sort.Strings(overridden)
+
+	for _, device := range overridden {
+		device := device
+		filter := db.PurgeFilter{Devices: []string{device}}
+		applyRetentionPolicy("device "+device, filter, r.Devices[device], now, batch, func() (int, error) {
+			return dbClient.EventCountByDeviceId(device)
+		})
+	}
+
+	global := RetentionPolicy{MaxAge: r.MaxAge, MaxEvents: r.MaxEvents, OnlyPushed: r.OnlyPushed}
+	filter := db.PurgeFilter{ExcludeDevices: overridden}
+	applyRetentionPolicy("all devices", filter, global, now, batch, func() (int, error) {
+		return countEventsExcluding(overridden)
+	})
+
+	var names []string
+	for name := range r.ValueDescriptors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Value descriptor policies only shorten how long readings are kept, events are left alone
+	for _, name := range names {
+		p := r.ValueDescriptors[name]
+		if p.MaxAge <= 0 {
+			continue
+		}
+
+		filter := db.PurgeFilter{Names: []string{name}, Before: now - p.MaxAge, OnlyPushed: p.OnlyPushed}
+		count, err := deleteInBatches(func(limit int) (int, error) {
+			return dbClient.DeleteReadings(filter, limit)
+		}, batch, -1)
+		logRetention(fmt.Sprintf("readings of %s older than %d ms", name, p.MaxAge), count, err)
+	}
+
+	if r.MaxBytes > 0 {
+		count, err := enforceMaxBytes(r.MaxBytes, r.OnlyPushed, batch)
+		logRetention(fmt.Sprintf("events above %d bytes", r.MaxBytes), count, err)
+	}
+}
+
+// Remove the events selected by filter that are older or more numerous than the policy allows
+func applyRetentionPolicy(scope string, filter db.PurgeFilter, p RetentionPolicy, now int64, batch int, count func() (int, error)) {
+	filter.OnlyPushed = p.OnlyPushed
+
+	if p.MaxAge > 0 {
+		aged := filter
+		aged.Before = now - p.MaxAge
+		n, err := deleteInBatches(func(limit int) (int, error) {
+			return dbClient.DeleteEvents(aged, limit)
+		}, batch, -1)
+		logRetention(fmt.Sprintf("events of %s older than %d ms", scope, p.MaxAge), n, err)
+	}
+
+	if p.MaxEvents > 0 {
+		c, err := count()
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("retention could not count the events of %s: %s", scope, err.Error()))
+			return
+		}
+		if c <= p.MaxEvents {
+			return
+		}
+
+		n, err := deleteInBatches(func(limit int) (int, error) {
+			return dbClient.DeleteEvents(filter, limit)
+		}, batch, c-p.MaxEvents)
+		logRetention(fmt.Sprintf("events of %s over %d", scope, p.MaxEvents), n, err)
+	}
+}
+
+func countEventsExcluding(devices []string) (int, error) {
+	count, err := dbClient.EventCount()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, device := range devices {
+		c, err := dbClient.EventCountByDeviceId(device)
+		if err != nil {
+			return 0, err
+		}
+		count -= c
+	}
+	return count, nil
+}
+
+// Remove the oldest events until the database is no larger than maxBytes
+func enforceMaxBytes(maxBytes int64, onlyPushed bool, batch int) (int, error) {
+	total := 0
+	for {
+		size, err := dbClient.DataSize()
+		if err != nil || size <= maxBytes {
+			return total, err
+		}
+
+		n, err := dbClient.DeleteEvents(db.PurgeFilter{OnlyPushed: onlyPushed}, batch)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			LoggingClient.Warn(fmt.Sprintf("retention cannot bring the data size of %d bytes under %d bytes", size, maxBytes))
+			return total, nil
+		}
+	}
+}
+
+// Call del with at most batch items at a time until it removes fewer than requested
+// or max items have been removed. A negative max removes everything del selects.
+func deleteInBatches(del func(limit int) (int, error), batch int, max int) (int, error) {
+	total := 0
+	for max < 0 || total < max {
+		limit := batch
+		if max >= 0 && max-total < limit {
+			limit = max - total
+		}
+
+		n, err := del(limit)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < limit {
+			break
+		}
+	}
+	return total, nil
+}
+
+func logRetention(what string, count int, err error) {
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("retention failed after removing %d %s: %s", count, what, err.Error()))
+		return
+	}
+	if count > 0 {
+		LoggingClient.Info(fmt.Sprintf("retention removed %d %s", count, what))
+	}
+}
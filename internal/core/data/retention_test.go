@@ -0,0 +1,148 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestDeleteInBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		available int
+		max       int
+		expected  int
+		calls     int
+	}{
+		{"all", 25, -1, 25, 3},
+		{"exact batches", 20, -1, 20, 3},
+		{"capped", 25, 12, 12, 2},
+		{"nothing", 0, -1, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			available := tt.available
+			calls := 0
+			del := func(limit int) (int, error) {
+				calls++
+				n := limit
+				if available < n {
+					n = available
+				}
+				available -= n
+				return n, nil
+			}
+
+			count, err := deleteInBatches(del, 10, tt.max)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if count != tt.expected || calls != tt.calls {
+				t.Errorf("Expected %d removed in %d calls, got %d in %d", tt.expected, tt.calls, count, calls)
+			}
+		})
+	}
+}
+
+func TestRetentionInterval(t *testing.T) {
+	reset()
+
+	tests := []struct {
+		interval string
+		expected time.Duration
+	}{
+		{"", defaultRetentionInterval},
+		{"30s", 30 * time.Second},
+		{"often", defaultRetentionInterval},
+		{"-1m", defaultRetentionInterval},
+	}
+	for _, tt := range tests {
+		if d := retentionInterval(RetentionInfo{Interval: tt.interval}); d != tt.expected {
+			t.Errorf("Interval '%s': expected %s, got %s", tt.interval, tt.expected, d)
+		}
+	}
+}
+
+func TestEnforceRetentionDeviceOverride(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+
+	// The device keeps at most 2 of its 5 events, pushed or not
+	myMock.On("EventCountByDeviceId", "d1").Return(5, nil)
+	myMock.On("DeleteEvents", db.PurgeFilter{Devices: []string{"d1"}}, 3).Return(3, nil)
+
+	// The other devices lose their pushed events older than a second
+	myMock.On("DeleteEvents", db.PurgeFilter{ExcludeDevices: []string{"d1"}, Before: 9000, OnlyPushed: true}, 100).Return(40, nil)
+
+	dbClient = myMock
+
+	enforceRetention(RetentionInfo{
+		BatchSize:  100,
+		MaxAge:     1000,
+		OnlyPushed: true,
+		Devices:    map[string]RetentionPolicy{"d1": {MaxEvents: 2}},
+	}, 10000)
+
+	myMock.AssertExpectations(t)
+}
+
+func TestEnforceRetentionGlobalCount(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+
+	// 250 events are over the limit once the 50 of the overridden device are left out
+	myMock.On("EventCount").Return(1300, nil)
+	myMock.On("EventCountByDeviceId", "d1").Return(50, nil)
+	myMock.On("DeleteEvents", db.PurgeFilter{ExcludeDevices: []string{"d1"}}, 100).Return(100, nil).Twice()
+	myMock.On("DeleteEvents", db.PurgeFilter{ExcludeDevices: []string{"d1"}}, 50).Return(50, nil).Once()
+
+	dbClient = myMock
+
+	enforceRetention(RetentionInfo{
+		BatchSize: 100,
+		MaxEvents: 1000,
+		Devices:   map[string]RetentionPolicy{"d1": {}},
+	}, 10000)
+
+	myMock.AssertExpectations(t)
+}
+
+func TestEnforceRetentionValueDescriptor(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("DeleteReadings", db.PurgeFilter{Names: []string{"temperature"}, Before: 9500}, defaultRetentionBatchSize).Return(7, nil)
+
+	dbClient = myMock
+
+	enforceRetention(RetentionInfo{
+		ValueDescriptors: map[string]RetentionPolicy{
+			"temperature": {MaxAge: 500},
+			// Only the age of a value descriptor policy applies
+			"humidity": {MaxEvents: 1},
+		},
+	}, 10000)
+
+	myMock.AssertExpectations(t)
+	myMock.AssertNotCalled(t, "DeleteEvents", mock.Anything, mock.Anything)
+}
+
+func TestEnforceRetentionMaxBytes(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("DataSize").Return(int64(3000), nil).Once()
+	myMock.On("DataSize").Return(int64(2500), nil).Once()
+	myMock.On("DataSize").Return(int64(1500), nil).Once()
+	myMock.On("DeleteEvents", db.PurgeFilter{}, 10).Return(10, nil).Twice()
+
+	dbClient = myMock
+
+	enforceRetention(RetentionInfo{BatchSize: 10, MaxBytes: 2000}, 10000)
+
+	myMock.AssertExpectations(t)
+}
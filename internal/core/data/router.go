@@ -14,20 +14,27 @@
 package data
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
+	"golang.org/x/net/websocket"
 
 	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
@@ -43,9 +50,21 @@ func LoadRestRoutes() *mux.Router {
 	// Metrics
 	r.HandleFunc(clients.ApiMetricsRoute, metricsHandler).Methods(http.MethodGet)
 
+	// Readiness
+	r.HandleFunc(readiness.Route, readiness.Handler(map[string]readiness.Check{
+		"database":   dbMonitor,
+		"messageBus": &busConnection,
+	})).Methods(http.MethodGet)
+
+	// Callbacks from metadata
+	r.HandleFunc(clients.ApiCallbackRoute, callbackHandler).Methods(http.MethodPut, http.MethodPost, http.MethodDelete)
+
 	// Events
 	r.HandleFunc(clients.ApiEventRoute, eventHandler).Methods(http.MethodGet, http.MethodPut, http.MethodPost)
 	e := r.PathPrefix(clients.ApiEventRoute).Subrouter()
+	e.HandleFunc("/batch", eventBatchHandler).Methods(http.MethodPost)
+	e.HandleFunc("/archive", eventArchiveHandler).Methods(http.MethodGet, http.MethodPost)
+	e.HandleFunc("/subscribe", eventSubscribeHandler).Methods(http.MethodGet)
 	e.HandleFunc("/scrub", scrubHandler).Methods(http.MethodDelete)
 	e.HandleFunc("/scruball", scrubAllHandler).Methods(http.MethodDelete)
 	e.HandleFunc("/count", eventCountHandler).Methods(http.MethodGet)
@@ -56,6 +75,7 @@ func LoadRestRoutes() *mux.Router {
 	e.HandleFunc("/device/{deviceId}/{limit:[0-9]+}", getEventByDeviceHandler).Methods(http.MethodGet)
 	e.HandleFunc("/device/{deviceId}", deleteByDeviceIdHandler).Methods(http.MethodDelete)
 	e.HandleFunc("/removeold/age/{age:[0-9]+}", eventByAgeHandler).Methods(http.MethodDelete)
+	e.HandleFunc("/origin/{start:[0-9]+}/{end:[0-9]+}/{limit:[0-9]+}", eventByOriginTimeHandler).Methods(http.MethodGet)
 	e.HandleFunc("/{start:[0-9]+}/{end:[0-9]+}/{limit:[0-9]+}", eventByCreationTimeHandler).Methods(http.MethodGet)
 
 	// Readings
@@ -63,12 +83,15 @@ func LoadRestRoutes() *mux.Router {
 	rd := r.PathPrefix(clients.ApiReadingRoute).Subrouter()
 	rd.HandleFunc("/count", readingCountHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/id/{id}", deleteReadingByIdHandler).Methods(http.MethodDelete)
+	rd.HandleFunc("/query", readingQueryHandler).Methods(http.MethodGet, http.MethodPost)
+	rd.HandleFunc("/aggregate", readingAggregateHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/{id}", getReadingByIdHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/device/{deviceId}/{limit:[0-9]+}", readingByDeviceHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/name/{name}/{limit:[0-9]+}", readingbyValueDescriptorHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/uomlabel/{uomLabel}/{limit:[0-9]+}", readingByUomLabelHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/label/{label}/{limit:[0-9]+}", readingByLabelHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/type/{type}/{limit:[0-9]+}", readingByTypeHandler).Methods(http.MethodGet)
+	rd.HandleFunc("/origin/{start:[0-9]+}/{end:[0-9]+}/{limit:[0-9]+}", readingByOriginTimeHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/{start:[0-9]+}/{end:[0-9]+}/{limit:[0-9]+}", readingByCreationTimeHandler).Methods(http.MethodGet)
 	rd.HandleFunc("/name/{name}/device/{device}/{limit:[0-9]+}", readingByValueDescriptorAndDeviceHandler).Methods(http.MethodGet)
 
@@ -83,9 +106,16 @@ func LoadRestRoutes() *mux.Router {
 	vd.HandleFunc("/devicename/{device}", valueDescriptorByDeviceHandler).Methods(http.MethodGet)
 	vd.HandleFunc("/deviceid/{id}", valueDescriptorByDeviceIdHandler).Methods(http.MethodGet)
 
+	// Quarantine
+	r.HandleFunc(quarantineRoute, quarantineHandler).Methods(http.MethodGet, http.MethodDelete)
+	q := r.PathPrefix(quarantineRoute).Subrouter()
+	q.HandleFunc("/{id}", quarantinedEventHandler).Methods(http.MethodGet, http.MethodDelete)
+	q.HandleFunc("/{id}/replay", replayQuarantinedEventHandler).Methods(http.MethodPost)
+
 	r.Use(correlation.ManageHeader)
 	r.Use(correlation.OnResponseComplete)
 	r.Use(correlation.OnRequestBegin)
+	r.Use(readiness.Guard(dbMonitor))
 
 	return r
 }
@@ -194,6 +224,11 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	// Get all events
 	case http.MethodGet:
+		if pagedRequest(r) {
+			servePage(w, r, Configuration.Service.MaxResultCount, eventPager(db.EventFilter{}))
+			return
+		}
+
 		events, err := getEvents(Configuration.Service.MaxResultCount)
 		if err != nil {
 			LoggingClient.Error(err.Error())
@@ -214,7 +249,19 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 			LoggingClient.Error(err.Error())
 			return
 		}
+		if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+			ctx = context.WithValue(ctx, idempotencyKeyContextKey, key)
+		}
 		newId, err := addNewEvent(evt, ctx)
+		if err != nil {
+			// Quarantined and suppressed events are accepted, along with the reason
+			status := eventErrorStatus(err)
+			if status != http.StatusAccepted {
+				LoggingClient.Error(err.Error())
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
 
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(newId))
@@ -260,6 +307,118 @@ func eventHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+/*
+Add many events in one request, as a JSON array or a CBOR sequence
+Each event gets its own result holding its id or the reason it was rejected
+/api/v1/event/batch
+*/
+func eventBatchHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	reader := NewRequestReader(r)
+
+	events, err := reader.ReadBatch(r.Body, &ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	results, err := addNewEvents(events, ctx)
+	if err != nil {
+		switch err.(type) {
+		case *errors.ErrLimitExceeded:
+			http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	encode(results, w)
+}
+
+/*
+GET writes the events of a time range and set of devices to a compressed archive
+POST imports an archive, skipping the events already in the database
+/api/v1/event/archive?start={start}&end={end}&device={device}&format={ndjson|cbor}
+*/
+func eventArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	switch r.Method {
+	case http.MethodGet:
+		q, err := decodeArchiveQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			LoggingClient.Error(err.Error())
+			return
+		}
+
+		w.Header().Set(clients.ContentType, contentTypeGzip)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archiveName(q)))
+
+		// Once streaming has started, a failure leaves the archive without its trailer,
+		// which an import then rejects
+		events, readings, err := writeArchive(w, q)
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("archive aborted after %d events and %d readings: %s", events, readings, err.Error()))
+			return
+		}
+		LoggingClient.Info(fmt.Sprintf("archived %d events and %d readings", events, readings))
+
+	case http.MethodPost:
+		body := r.Body
+		if max := Configuration.Writable.Archive.MaxSize; max > 0 {
+			body = http.MaxBytesReader(w, r.Body, max)
+		}
+		result, err := importArchive(body)
+		if err != nil {
+			switch err.(type) {
+			case *errors.ErrInvalidArchive:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			LoggingClient.Error(err.Error())
+			return
+		}
+
+		LoggingClient.Info(fmt.Sprintf("imported %d archived events, skipped %d, imported %d archived readings, skipped %d",
+			result.Imported, result.Skipped, result.ImportedReadings, result.SkippedReadings))
+		encode(result, w)
+	}
+}
+
+/*
+Stream the events ingested from now on, over a WebSocket when the request asks for an upgrade
+and as Server-Sent Events otherwise
+503 - the max number of subscriptions are open
+/api/v1/event/subscribe?device={device}&name={name}&label={label}
+*/
+func eventSubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	info := Configuration.Writable.Subscriptions
+	s, err := subscriptions.subscribe(decodeSubscriptionFilter(r), info)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		LoggingClient.Error(err.Error())
+		return
+	}
+	defer subscriptions.unsubscribe(s)
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{Handler: func(ws *websocket.Conn) {
+			serveWebSocket(ws, s, info)
+		}}.ServeHTTP(w, r)
+		return
+	}
+	serveEventStream(w, r, s, info)
+}
+
 // Undocumented feature to remove all readings and events from the database
 // This should primarily be used for debugging purposes
 func scrubAllHandler(w http.ResponseWriter, r *http.Request) {
@@ -290,8 +449,8 @@ func getEventByIdHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Get the event
-	e, err := getEventById(id)
+	// Get the event, with the values its readings were received with if they were converted
+	e, err := getEventWithOriginalsById(id)
 	if err != nil {
 		switch x := err.(type) {
 		case *errors.ErrEventNotFound:
@@ -356,6 +515,11 @@ func getEventByDeviceHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if pagedRequest(r) {
+			servePage(w, r, limitNum, eventPager(db.EventFilter{Device: deviceId}))
+			return
+		}
+
 		eventList, err := getEventsByDeviceIdLimit(limitNum, deviceId)
 
 		if err != nil {
@@ -554,6 +718,11 @@ func eventByCreationTimeHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if pagedRequest(r) {
+			servePage(w, r, limit, eventPager(db.EventFilter{Start: start, End: end}))
+			return
+		}
+
 		eventList, err := getEventsByCreationTime(limit, start, end)
 
 		if err != nil {
@@ -565,6 +734,36 @@ func eventByCreationTimeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Get events by origin time, the time their device took them
+// {start} - start time, {end} - end time, {limit} - max number of results
+// Sort the events by origin
+// 413 - number of results exceeds limit
+// api/v1/event/origin/{start}/{end}/{limit}
+func eventByOriginTimeHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	start, end, limit, err := timeRange(mux.Vars(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error("Problem converting the origin time range: " + err.Error())
+		return
+	}
+
+	err = checkMaxLimit(limit)
+	if err != nil {
+		http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	eventList, err := getEventsByOriginTime(start, end, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encode(eventList, w)
+}
+
 // Scrub all the events that have been pushed
 // Also remove the readings associated with the events
 // api/v1/event/scrub
@@ -585,6 +784,141 @@ func scrubHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+/*
+GET lists the quarantined events, oldest first, of all devices or of the device given
+DELETE purges the events quarantined before the time given, all of them by default
+/api/v1/quarantine?device={device}&limit={limit}
+/api/v1/quarantine?before={before}
+*/
+func quarantineHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+		limit := Configuration.Service.MaxResultCount
+		if value := query.Get("limit"); value != "" {
+			var err error
+			// A limit of 0 would list every quarantined event
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+				http.Error(w, "invalid limit: "+value, http.StatusBadRequest)
+				return
+			}
+		}
+		if err := checkMaxLimit(limit); err != nil {
+			http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		quarantined, err := getQuarantinedEvents(query.Get("device"), limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		encode(quarantined, w)
+	case http.MethodDelete:
+		before := db.MakeTimestamp() + 1
+		if value := query.Get("before"); value != "" {
+			var err error
+			if before, err = strconv.ParseInt(value, 10, 64); err != nil {
+				http.Error(w, "invalid time: "+value, http.StatusBadRequest)
+				return
+			}
+		}
+
+		count, err := purgeQuarantinedEvents(before)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(strconv.Itoa(count)))
+	}
+}
+
+/*
+GET returns a quarantined event with the reason it was quarantined
+DELETE purges a quarantined event
+/api/v1/quarantine/{id}
+*/
+func quarantinedEventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	id := mux.Vars(r)["id"]
+	switch r.Method {
+	case http.MethodGet:
+		q, err := dbClient.QuarantinedEventById(id)
+		if err != nil {
+			quarantineError(w, err)
+			return
+		}
+
+		encode(q, w)
+	case http.MethodDelete:
+		if err := dbClient.DeleteQuarantinedEventById(id); err != nil {
+			quarantineError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("true"))
+	}
+}
+
+/*
+Add a quarantined event again, as it was received, or as fixed when the body holds the event
+to add instead. The event leaves the quarantine once added, returning its new id.
+/api/v1/quarantine/{id}/replay
+*/
+func replayQuarantinedEventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var fixed *contract.Event
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		fixed = &contract.Event{}
+		if err = json.Unmarshal(body, fixed); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			LoggingClient.Error("Error decoding the fixed event: " + err.Error())
+			return
+		}
+	}
+
+	newId, err := replayQuarantinedEvent(mux.Vars(r)["id"], fixed, r.Context())
+	if err != nil {
+		if err == db.ErrNotFound {
+			quarantineError(w, err)
+			return
+		}
+		status := eventErrorStatus(err)
+		if status != http.StatusAccepted {
+			LoggingClient.Error(err.Error())
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(newId))
+}
+
+func quarantineError(w http.ResponseWriter, err error) {
+	if err == db.ErrNotFound {
+		http.Error(w, "quarantined event not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+	LoggingClient.Error(err.Error())
+}
+
 // Test if the service is working
 func pingHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
@@ -604,6 +938,11 @@ func readingHandler(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
+		if pagedRequest(r) {
+			servePage(w, r, Configuration.Service.MaxResultCount, readingPager(db.ReadingFilter{}))
+			return
+		}
+
 		r, err := getAllReadings()
 
 		if err != nil {
@@ -817,6 +1156,22 @@ func readingByDeviceHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if pagedRequest(r) {
+			if err := checkDevice(deviceId, ctx); err != nil {
+				LoggingClient.Error(fmt.Sprintf("error checking device %s %v", deviceId, err))
+				switch err := err.(type) {
+				case *types.ErrServiceClient:
+					http.Error(w, err.Error(), err.StatusCode)
+				default:
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+
+			servePage(w, r, limit, readingPager(db.ReadingFilter{Device: deviceId}))
+			return
+		}
+
 		readings, err := getReadingsByDevice(deviceId, limit, ctx)
 		if err != nil {
 			switch err := err.(type) {
@@ -855,6 +1210,22 @@ func readingbyValueDescriptorHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if pagedRequest(r) {
+		if err = checkMaxLimit(limit); err != nil {
+			http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if Configuration.Writable.ValidateCheck {
+			if _, err = getValueDescriptorByName(name); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		servePage(w, r, limit, readingPager(db.ReadingFilter{Names: []string{name}}))
+		return
+	}
+
 	read, err := getReadingsByValueDescriptor(name, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -906,6 +1277,11 @@ func readingByUomLabelHandler(w http.ResponseWriter, r *http.Request) {
 		vNames = append(vNames, v.Name)
 	}
 
+	if pagedRequest(r) {
+		servePage(w, r, limit, readingsByNamesPager(vNames))
+		return
+	}
+
 	readings, err := getReadingsByValueDescriptorNames(vNames, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -955,6 +1331,11 @@ func readingByLabelHandler(w http.ResponseWriter, r *http.Request) {
 		vdNames = append(vdNames, vd.Name)
 	}
 
+	if pagedRequest(r) {
+		servePage(w, r, limit, readingsByNamesPager(vdNames))
+		return
+	}
+
 	readings, err := getReadingsByValueDescriptorNames(vdNames, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -1005,6 +1386,11 @@ func readingByTypeHandler(w http.ResponseWriter, r *http.Request) {
 		vdNames = append(vdNames, vd.Name)
 	}
 
+	if pagedRequest(r) {
+		servePage(w, r, limit, readingsByNamesPager(vdNames))
+		return
+	}
+
 	readings, err := getReadingsByValueDescriptorNames(vdNames, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -1048,6 +1434,11 @@ func readingByCreationTimeHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if pagedRequest(r) {
+			servePage(w, r, limit, readingPager(db.ReadingFilter{Start: start, End: end}))
+			return
+		}
+
 		readings, err := getReadingsByCreationTime(start, end, limit)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -1058,6 +1449,45 @@ func readingByCreationTimeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Return a list of readings between the start and end (origin time)
+// /reading/origin/{start}/{end}/{limit}
+func readingByOriginTimeHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	start, end, limit, err := timeRange(mux.Vars(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error("Error converting the origin time range: " + err.Error())
+		return
+	}
+
+	err = checkMaxLimit(limit)
+	if err != nil {
+		http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	readings, err := getReadingsByOriginTime(start, end, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encode(readings, w)
+}
+
+// The start, end and limit of the time range routes
+func timeRange(vars map[string]string) (start int64, end int64, limit int, err error) {
+	if start, err = strconv.ParseInt(vars["start"], 10, 64); err != nil {
+		return
+	}
+	if end, err = strconv.ParseInt(vars["end"], 10, 64); err != nil {
+		return
+	}
+	limit, err = strconv.Atoi(vars["limit"])
+	return
+}
+
 // Return a list of redings associated with the device and value descriptor
 // Limit exceeded exception 413 if the limit exceeds the max limit
 // api/v1/reading/name/{name}/device/{device}/{limit}
@@ -1123,6 +1553,11 @@ func readingByValueDescriptorAndDeviceHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	if pagedRequest(r) {
+		servePage(w, r, limit, readingPager(db.ReadingFilter{Device: device, Names: []string{name}}))
+		return
+	}
+
 	readings, err := getReadingsByDeviceAndValueDescriptor(device, name, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -1132,6 +1567,73 @@ func readingByValueDescriptorAndDeviceHandler(w http.ResponseWriter, r *http.Req
 	encode(readings, w)
 }
 
+// Return the readings matching a combination of devices, value descriptor names, labels,
+// UoM labels, types, time ranges and value conditions
+// The query is a JSON body for POST or the query string for GET
+// 400 - the query is invalid
+// 413 - the limit exceeds the max limit
+// api/v1/reading/query
+func readingQueryHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	rq, err := decodeReadingQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	readings, err := queryReadings(rq)
+	if err != nil {
+		switch err.(type) {
+		case *errors.ErrInvalidQuery:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case *errors.ErrLimitExceeded:
+			http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	encode(readings, w)
+}
+
+// Return min, max, average and count of numeric readings per device, value descriptor and interval
+// 400 - the aggregation is invalid or a value descriptor is not numeric
+// 404 - a value descriptor does not exist
+// 413 - the window holds more intervals than the max limit
+// api/v1/reading/aggregate?name={name}&device={device}&start={start}&end={end}&interval={interval}
+func readingAggregateHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	q, err := decodeAggregateQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	buckets, err := aggregateReadings(q)
+	if err != nil {
+		switch err.(type) {
+		case *errors.ErrInvalidQuery:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case *errors.ErrDbNotFound:
+			http.Error(w, "Value descriptor not found", http.StatusNotFound)
+		case *errors.ErrLimitExceeded:
+			http.Error(w, maxExceededString, http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		LoggingClient.Error(err.Error())
+		return
+	}
+
+	encode(buckets, w)
+}
+
 // Value Descriptors
 
 // GET, POST, and PUT for value descriptors
@@ -1478,8 +1980,49 @@ func valueDescriptorByDeviceIdHandler(w http.ResponseWriter, r *http.Request) {
 	encode(vdList, w)
 }
 
+/*
+Drop cached lookups of an object changed in metadata
+Metadata sends the same alerts to device services: {"type":"DEVICE","id":"..."} or {"type":"PROFILE","id":"..."}
+/api/v1/callback
+*/
+func callbackHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var alert contract.CallbackAlert
+	err := json.NewDecoder(r.Body).Decode(&alert)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		LoggingClient.Error("Error decoding callback alert: " + err.Error())
+		return
+	}
+
+	// Objects added in metadata are not cached yet, so only changes matter
+	if r.Method != http.MethodPost {
+		switch alert.ActionType {
+		case contract.DEVICE:
+			LoggingClient.Debug("Dropping cached device " + alert.Id)
+			invalidateDevice(alert.Id)
+		case contract.PROFILE:
+			LoggingClient.Debug("Dropping cached units of profile " + alert.Id)
+			invalidateProfile()
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func metricsHandler(w http.ResponseWriter, _ *http.Request) {
-	s := telemetry.NewSystemUsage()
+	s := struct {
+		telemetry.SystemUsage
+		Cache      map[string]cacheStats
+		Suppressed map[string]uint64
+		Database   *db.Health
+	}{
+		SystemUsage: telemetry.NewSystemUsage(),
+		Cache:       cacheMetrics(),
+		Suppressed:  suppression.stats(),
+		Database:    dbMonitor.Health(),
+	}
 
 	encode(s, w)
 
@@ -0,0 +1,346 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	contentTypeEventStream = "text/event-stream"
+
+	defaultSubscriptionBuffer    = 100
+	defaultSubscriptionKeepAlive = 15 * time.Second
+	defaultSlowClientTimeout     = 30 * time.Second
+)
+
+// ErrTooManySubscriptions is returned when MaxConnections streams are already open
+var ErrTooManySubscriptions = errors.New("too many subscriptions")
+
+// The clients streaming the events published by core-data
+var subscriptions = newSubscriptionHub()
+
+type subscriptionHub struct {
+	mutex       sync.Mutex
+	subscribers map[*subscriber]bool
+	closed      bool
+}
+
+// subscriber receives the events matching its filter through a bounded queue.
+// Events that do not fit in the queue are dropped and counted, and a client whose queue
+// stays full for too long is evicted.
+type subscriber struct {
+	filter    subscriptionFilter
+	events    chan []byte
+	dropped   uint64        // Accessed atomically, reset when the client is told
+	fullSince time.Time     // Guarded by the hub's mutex
+	evicted   chan struct{} // Closed when the client is evicted or the hub closed
+}
+
+// subscriptionFilter selects events by device and their readings by value descriptor name and label.
+// Empty lists select everything.
+type subscriptionFilter struct {
+	Devices []string
+	Names   []string
+	Labels  []string
+}
+
+func newSubscriptionHub() *subscriptionHub {
+	return &subscriptionHub{subscribers: make(map[*subscriber]bool)}
+}
+
+func (h *subscriptionHub) subscribe(filter subscriptionFilter, info SubscriptionInfo) (*subscriber, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed || (info.MaxConnections > 0 && len(h.subscribers) >= info.MaxConnections) {
+		return nil, ErrTooManySubscriptions
+	}
+
+	size := info.BufferSize
+	if size <= 0 {
+		size = defaultSubscriptionBuffer
+	}
+	s := &subscriber{filter: filter, events: make(chan []byte, size), evicted: make(chan struct{})}
+	h.subscribers[s] = true
+	return s, nil
+}
+
+func (h *subscriptionHub) unsubscribe(s *subscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.subscribers, s)
+}
+
+// Hand an event to the subscribers whose filter it matches, without ever blocking ingestion
+func (h *subscriptionHub) publish(e contract.Event) {
+	h.mutex.Lock()
+	snapshot := make([]*subscriber, 0, len(h.subscribers))
+	for s := range h.subscribers {
+		snapshot = append(snapshot, s)
+	}
+	h.mutex.Unlock()
+
+	if len(snapshot) == 0 {
+		return
+	}
+
+	// Labels are only looked up when a subscriber filters on them, once per value descriptor
+	labels := make(map[string][]string)
+	labelsOf := func(name string) []string {
+		l, ok := labels[name]
+		if !ok {
+			if vd, err := cachedValueDescriptorByName(name); err == nil {
+				l = vd.Labels
+			}
+			labels[name] = l
+		}
+		return l
+	}
+
+	encoded := make(map[*subscriber][]byte)
+	for _, s := range snapshot {
+		matched, ok := s.filter.apply(e, labelsOf)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(matched)
+		if err != nil {
+			// Only this subscriber misses the event; the others still receive their own encoding
+			LoggingClient.Error(fmt.Sprintf("error marshaling event %s for subscriber on devices %v, names %v: %s", e.ID, s.filter.Devices, s.filter.Names, err.Error()))
+			continue
+		}
+		encoded[s] = data
+	}
+
+	timeout := subscriptionDuration(Configuration.Writable.Subscriptions.SlowClientTimeout, defaultSlowClientTimeout)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for s, data := range encoded {
+		if !h.subscribers[s] {
+			continue
+		}
+
+		select {
+		case s.events <- data:
+			s.fullSince = time.Time{}
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+			if s.fullSince.IsZero() {
+				s.fullSince = time.Now()
+			} else if time.Since(s.fullSince) > timeout {
+				LoggingClient.Warn("evicting a subscriber that stopped keeping up")
+				delete(h.subscribers, s)
+				close(s.evicted)
+			}
+		}
+	}
+}
+
+// End every subscription, as core-data shuts down
+func (h *subscriptionHub) close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.closed = true
+	for s := range h.subscribers {
+		close(s.evicted)
+	}
+	h.subscribers = make(map[*subscriber]bool)
+}
+
+func (h *subscriptionHub) count() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.subscribers)
+}
+
+// Return the readings of an event the filter selects, and whether any are left
+func (f subscriptionFilter) apply(e contract.Event, labelsOf func(string) []string) (contract.Event, bool) {
+	if len(f.Devices) > 0 && !contains(f.Devices, e.Device) {
+		return e, false
+	}
+	if len(f.Names) == 0 && len(f.Labels) == 0 {
+		return e, true
+	}
+
+	var readings []contract.Reading
+	for _, r := range e.Readings {
+		if len(f.Names) > 0 && !contains(f.Names, r.Name) {
+			continue
+		}
+		if len(f.Labels) > 0 && !containsAny(f.Labels, labelsOf(r.Name)) {
+			continue
+		}
+		readings = append(readings, r)
+	}
+
+	e.Readings = readings
+	return e, len(readings) > 0
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list []string, candidates []string) bool {
+	for _, c := range candidates {
+		if contains(list, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Read the filter of a subscription from the query string, each parameter may be repeated
+func decodeSubscriptionFilter(r *http.Request) subscriptionFilter {
+	params := r.URL.Query()
+	return subscriptionFilter{Devices: params["device"], Names: params["name"], Labels: params["label"]}
+}
+
+// Messages a subscriber sends its client
+const (
+	subscriptionEvent     = "event"
+	subscriptionDropped   = "dropped"
+	subscriptionKeepAlive = "keepalive"
+)
+
+// Pass the events of a subscription to send until the subscriber is evicted, done is closed or
+// send fails. The number of events dropped since the last one sent precedes each event.
+// A keep-alive is sent whenever keepAlive elapses without events, unless it is zero.
+func (s *subscriber) serve(send func(kind string, data []byte) error, keepAlive time.Duration, done <-chan struct{}) {
+	var tick <-chan time.Time
+	if keepAlive > 0 {
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-s.evicted:
+			return
+		case <-tick:
+			if err := send(subscriptionKeepAlive, nil); err != nil {
+				return
+			}
+		case data := <-s.events:
+			if n := atomic.SwapUint64(&s.dropped, 0); n > 0 {
+				if err := send(subscriptionDropped, []byte(fmt.Sprintf("%d", n))); err != nil {
+					return
+				}
+			}
+			if err := send(subscriptionEvent, data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Stream a subscription as Server-Sent Events, events being sent as unnamed JSON messages
+// and the number of dropped events as 'dropped' messages
+func serveEventStream(w http.ResponseWriter, r *http.Request, s *subscriber, info SubscriptionInfo) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(clients.ContentType, contentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(kind string, data []byte) error {
+		var err error
+		switch kind {
+		case subscriptionEvent:
+			_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		case subscriptionDropped:
+			_, err = fmt.Fprintf(w, "event: dropped\ndata: %s\n\n", data)
+		default:
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		}
+		if err == nil {
+			flusher.Flush()
+		}
+		return err
+	}
+	s.serve(send, subscriptionDuration(info.KeepAlive, defaultSubscriptionKeepAlive), r.Context().Done())
+}
+
+// Stream a subscription over a WebSocket, one JSON text message per event.
+// The number of dropped events is sent as {"dropped":n}.
+func serveWebSocket(ws *websocket.Conn, s *subscriber, info SubscriptionInfo) {
+	defer ws.Close()
+
+	// Clients are not expected to send anything, reading only tells when they leave
+	done := make(chan struct{})
+	go func() {
+		io.Copy(ioutil.Discard, ws)
+		close(done)
+	}()
+
+	timeout := subscriptionDuration(info.SlowClientTimeout, defaultSlowClientTimeout)
+	send := func(kind string, data []byte) error {
+		var message string
+		switch kind {
+		case subscriptionEvent:
+			message = string(data)
+		case subscriptionDropped:
+			message = `{"dropped":` + string(data) + `}`
+		default:
+			return nil
+		}
+		ws.SetWriteDeadline(time.Now().Add(timeout))
+		return websocket.Message.Send(ws, message)
+	}
+	s.serve(send, 0, done)
+}
+
+func subscriptionDuration(value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		LoggingClient.Error(fmt.Sprintf("invalid subscription duration '%s', using %s", value, defaultValue))
+		return defaultValue
+	}
+	return d
+}
@@ -0,0 +1,206 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"golang.org/x/net/websocket"
+)
+
+var subscriptionEvent1 = contract.Event{ID: "e1", Device: "thermostat", Readings: []contract.Reading{
+	{Name: "temperature", Value: "21.5"},
+	{Name: "humidity", Value: "40"},
+}}
+
+func resetSubscriptions() {
+	subscriptions = newSubscriptionHub()
+}
+
+func TestSubscriptionFilter(t *testing.T) {
+	labelsOf := func(name string) []string {
+		if name == "temperature" {
+			return []string{"climate"}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		filter   subscriptionFilter
+		matched  bool
+		readings int
+	}{
+		{"all", subscriptionFilter{}, true, 2},
+		{"device", subscriptionFilter{Devices: []string{"thermostat"}}, true, 2},
+		{"other device", subscriptionFilter{Devices: []string{"camera"}}, false, 0},
+		{"name", subscriptionFilter{Names: []string{"humidity"}}, true, 1},
+		{"label", subscriptionFilter{Labels: []string{"climate"}}, true, 1},
+		{"unknown label", subscriptionFilter{Labels: []string{"video"}}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := tt.filter.apply(subscriptionEvent1, labelsOf)
+			if ok != tt.matched {
+				t.Fatalf("Expected matched %t, got %t", tt.matched, ok)
+			}
+			if ok && len(e.Readings) != tt.readings {
+				t.Errorf("Expected %d readings, got %d", tt.readings, len(e.Readings))
+			}
+		})
+	}
+}
+
+func TestSubscriptionMaxConnections(t *testing.T) {
+	reset()
+	resetSubscriptions()
+	info := SubscriptionInfo{MaxConnections: 1}
+
+	s, err := subscriptions.subscribe(subscriptionFilter{}, info)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err = subscriptions.subscribe(subscriptionFilter{}, info); err != ErrTooManySubscriptions {
+		t.Errorf("Expected too many subscriptions, got %v", err)
+	}
+
+	subscriptions.unsubscribe(s)
+	if _, err = subscriptions.subscribe(subscriptionFilter{}, info); err != nil {
+		t.Errorf("Unexpected error once a subscription ended: %v", err)
+	}
+}
+
+func TestSubscriptionSlowClient(t *testing.T) {
+	reset()
+	resetSubscriptions()
+	Configuration.Writable.Subscriptions.SlowClientTimeout = "1ms"
+
+	s, _ := subscriptions.subscribe(subscriptionFilter{}, SubscriptionInfo{BufferSize: 1})
+	subscriptions.publish(subscriptionEvent1)
+	subscriptions.publish(subscriptionEvent1)
+	if s.dropped != 1 {
+		t.Errorf("Expected 1 dropped event, got %d", s.dropped)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	subscriptions.publish(subscriptionEvent1)
+	select {
+	case <-s.evicted:
+	default:
+		t.Fatal("Expected the subscriber to be evicted")
+	}
+	if subscriptions.count() != 0 {
+		t.Errorf("Expected no subscriber left, got %d", subscriptions.count())
+	}
+}
+
+func TestSubscriptionServeDropped(t *testing.T) {
+	reset()
+	resetSubscriptions()
+
+	s, _ := subscriptions.subscribe(subscriptionFilter{}, SubscriptionInfo{BufferSize: 1})
+	subscriptions.publish(subscriptionEvent1)
+	subscriptions.publish(subscriptionEvent1)
+	subscriptions.publish(subscriptionEvent1)
+
+	var kinds []string
+	done := make(chan struct{})
+	s.serve(func(kind string, data []byte) error {
+		kinds = append(kinds, kind+":"+string(data[:1]))
+		if kind == subscriptionEvent {
+			close(done)
+		}
+		return nil
+	}, 0, done)
+
+	if strings.Join(kinds, ",") != "dropped:2,event:{" {
+		t.Errorf("Unexpected messages %v", kinds)
+	}
+}
+
+// Wait until the handler of a stream has subscribed
+func waitForSubscriber(t *testing.T) {
+	for i := 0; subscriptions.count() == 0; i++ {
+		if i == 100 {
+			t.Fatal("Timed out waiting for the subscriber")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubscribeEventStream(t *testing.T) {
+	reset()
+	resetSubscriptions()
+	server := httptest.NewServer(testRoutes)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/event/subscribe?device=thermostat&name=temperature")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != contentTypeEventStream {
+		t.Fatalf("Unexpected content type %s", resp.Header.Get("Content-Type"))
+	}
+
+	waitForSubscriber(t)
+	subscriptions.publish(contract.Event{Device: "camera"})
+	subscriptions.publish(subscriptionEvent1)
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var e contract.Event
+	if err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+		t.Fatalf("Unexpected message %s: %v", line, err)
+	}
+	if e.ID != "e1" || len(e.Readings) != 1 || e.Readings[0].Name != "temperature" {
+		t.Errorf("Unexpected event %s", line)
+	}
+}
+
+func TestSubscribeWebSocket(t *testing.T) {
+	reset()
+	resetSubscriptions()
+	server := httptest.NewServer(testRoutes)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/event/subscribe?device=thermostat"
+	ws, err := websocket.Dial(url, "", server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer ws.Close()
+
+	waitForSubscriber(t)
+	subscriptions.publish(subscriptionEvent1)
+
+	var e contract.Event
+	if err = websocket.JSON.Receive(ws, &e); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if e.ID != "e1" || len(e.Readings) != 2 {
+		t.Errorf("Unexpected event %+v", e)
+	}
+}
+
+func TestSubscribeTooMany(t *testing.T) {
+	reset()
+	resetSubscriptions()
+	subscriptions.close()
+	defer resetSubscriptions()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/event/subscribe", nil)
+	rr := httptest.NewRecorder()
+	testRoutes.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
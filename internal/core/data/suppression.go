@@ -0,0 +1,225 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// The last reading accepted for each device and value descriptor, along with the number of
+// readings suppressed since core-data started
+var suppression = newSuppressionFilter()
+
+type suppressionFilter struct {
+	mutex      sync.Mutex
+	last       map[suppressionKey]acceptedReading
+	suppressed map[string]uint64 // By value descriptor name
+}
+
+type suppressionKey struct {
+	device string
+	name   string
+}
+
+type acceptedReading struct {
+	value string
+	at    int64
+}
+
+func newSuppressionFilter() *suppressionFilter {
+	return &suppressionFilter{
+		last:       make(map[suppressionKey]acceptedReading),
+		suppressed: make(map[string]uint64),
+	}
+}
+
+// A reading recorded as the last accepted one, undone when its event is not stored after all
+type suppressionChange struct {
+	key      suppressionKey
+	accepted acceptedReading
+	previous acceptedReading
+	existed  bool
+}
+
+// Tell whether a reading is to be suppressed, recording it as the last accepted one otherwise
+func (f *suppressionFilter) suppress(device string, r contract.Reading, at int64, p SuppressionPolicy) (bool, suppressionChange) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := suppressionKey{device: device, name: r.Name}
+	last, ok := f.last[key]
+	if ok && suppresses(p, last, r.Value, at) {
+		f.suppressed[r.Name]++
+		return true, suppressionChange{}
+	}
+
+	accepted := acceptedReading{value: r.Value, at: at}
+	f.last[key] = accepted
+	return false, suppressionChange{key: key, accepted: accepted, previous: last, existed: ok}
+}
+
+// Restore the last accepted readings an event replaced when it could not be stored, leaving
+// those replaced since by other events
+func (f *suppressionFilter) undo(changes []suppressionChange) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if f.last[c.key] != c.accepted {
+			continue
+		}
+		if c.existed {
+			f.last[c.key] = c.previous
+		} else {
+			delete(f.last, c.key)
+		}
+	}
+}
+
+func (f *suppressionFilter) clear() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.last = make(map[suppressionKey]acceptedReading)
+	f.suppressed = make(map[string]uint64)
+}
+
+func (f *suppressionFilter) stats() map[string]uint64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stats := make(map[string]uint64, len(f.suppressed))
+	for name, count := range f.suppressed {
+		stats[name] = count
+	}
+	return stats
+}
+
+// A reading arriving sooner than MinInterval after the last accepted one is always suppressed.
+// Otherwise it is suppressed when it does not differ enough from the last accepted one, unless
+// MaxInterval has elapsed since.
+func suppresses(p SuppressionPolicy, last acceptedReading, value string, at int64) bool {
+	elapsed := time.Duration(at-last.at) * time.Millisecond
+	if min := suppressionInterval(p.MinInterval); min > 0 && elapsed < min {
+		return true
+	}
+	if max := suppressionInterval(p.MaxInterval); max > 0 && elapsed >= max {
+		return false
+	}
+
+	if p.ChangeOfValue && value == last.value {
+		return true
+	}
+	if p.Deadband > 0 {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		l, err := strconv.ParseFloat(last.value, 64)
+		if err != nil {
+			return false
+		}
+		return math.Abs(v-l) <= p.Deadband
+	}
+	return false
+}
+
+func suppressionInterval(interval string) time.Duration {
+	if interval == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("invalid suppression interval '%s', ignored", interval))
+		return 0
+	}
+	return d
+}
+
+// Drop the readings of an event that the policy of their value descriptor suppresses.
+// Readings are compared by the time they were taken, falling back on the time they were received.
+// An event whose readings are all dropped is returned without readings, to be neither stored nor published.
+// The readings kept are recorded as the last accepted ones right away, so that the next events of a
+// batch are compared with them. The changes returned are to be undone if the event is not stored.
+func suppressReadings(e models.Event, ctx context.Context) (models.Event, []suppressionChange, error) {
+	s := Configuration.Writable.Suppression
+	if !s.Enabled || len(s.ValueDescriptors) == 0 {
+		return e, nil, nil
+	}
+
+	now := db.MakeTimestamp()
+	kept := make([]contract.Reading, 0, len(e.Readings))
+	positions := make(map[int]int) // Position of the kept readings in the event, by former position
+	var changes []suppressionChange
+	for i, r := range e.Readings {
+		if p, ok := s.ValueDescriptors[r.Name]; ok {
+			suppressed, change := suppression.suppress(e.Device, r, readingTime(e, r, now), p)
+			if suppressed {
+				continue
+			}
+			changes = append(changes, change)
+		}
+		positions[i] = len(kept)
+		kept = append(kept, r)
+	}
+
+	if len(kept) == len(e.Readings) {
+		return e, changes, nil
+	}
+
+	LoggingClient.Debug(fmt.Sprintf("suppressed %d readings of device %s", len(e.Readings)-len(kept), e.Device))
+	e.Readings = kept
+
+	var originals []models.Original
+	for _, o := range e.Originals {
+		if i, ok := positions[o.Index]; ok {
+			o.Index = i
+			originals = append(originals, o)
+		}
+	}
+	e.Originals = originals
+
+	if e.Bytes != nil && len(kept) > 0 {
+		var err error
+		e.Bytes, err = encodeEvent(e, clients.FromContext(clients.ContentType, ctx))
+		if err != nil {
+			suppression.undo(changes)
+			return e, nil, err
+		}
+	}
+	return e, changes, nil
+}
+
+func readingTime(e models.Event, r contract.Reading, now int64) int64 {
+	if r.Origin > 0 {
+		return r.Origin
+	}
+	if e.Origin > 0 {
+		return e.Origin
+	}
+	return now
+}
@@ -0,0 +1,168 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+)
+
+func TestSuppresses(t *testing.T) {
+	reset()
+	last := acceptedReading{value: "20.0", at: 10000}
+
+	tests := []struct {
+		name     string
+		policy   SuppressionPolicy
+		value    string
+		at       int64
+		expected bool
+	}{
+		{"no policy", SuppressionPolicy{}, "20.0", 20000, false},
+		{"unchanged", SuppressionPolicy{ChangeOfValue: true}, "20.0", 20000, true},
+		{"changed", SuppressionPolicy{ChangeOfValue: true}, "20.1", 20000, false},
+		{"within deadband", SuppressionPolicy{Deadband: 0.5}, "20.5", 20000, true},
+		{"out of deadband", SuppressionPolicy{Deadband: 0.5}, "19.4", 20000, false},
+		{"deadband on text", SuppressionPolicy{Deadband: 0.5}, "on", 20000, false},
+		{"too soon", SuppressionPolicy{MinInterval: "15s"}, "30", 20000, true},
+		{"late enough", SuppressionPolicy{MinInterval: "10s"}, "30", 20000, false},
+		{"heartbeat", SuppressionPolicy{ChangeOfValue: true, MaxInterval: "10s"}, "20.0", 20000, false},
+		{"before heartbeat", SuppressionPolicy{ChangeOfValue: true, MaxInterval: "1m"}, "20.0", 20000, true},
+	}
+	for _, tt := range tests {
+		if s := suppresses(tt.policy, last, tt.value, tt.at); s != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, s)
+		}
+	}
+}
+
+func TestSuppressReadings(t *testing.T) {
+	reset()
+	Configuration.Writable.Suppression = SuppressionInfo{
+		Enabled:          true,
+		ValueDescriptors: map[string]SuppressionPolicy{"Temperature": {Deadband: 1}},
+	}
+
+	first := models.Event{Event: testEvent}
+	result, _, _ := suppressReadings(first, context.Background())
+	if len(result.Readings) != 2 {
+		t.Fatalf("The first readings should be accepted, got %v", result.Readings)
+	}
+
+	// The temperature is within the deadband of the last accepted one, the pressure has no policy
+	second := models.Event{Event: testEvent, Originals: []models.Original{{Index: 1, Value: "1", Unit: "bar"}}}
+	second.Readings = buildReadings()
+	second.Readings[0].Value = "45.5"
+	result, _, _ = suppressReadings(second, context.Background())
+	if len(result.Readings) != 1 || result.Readings[0].Name != "Pressure" {
+		t.Fatalf("Only the pressure should be kept, got %v", result.Readings)
+	}
+	if !reflect.DeepEqual(result.Originals, []models.Original{{Index: 0, Value: "1", Unit: "bar"}}) {
+		t.Errorf("Originals should follow the kept readings, got %v", result.Originals)
+	}
+
+	if stats := suppression.stats(); !reflect.DeepEqual(stats, map[string]uint64{"Temperature": 1}) {
+		t.Errorf("Unexpected suppression counters %v", stats)
+	}
+}
+
+func TestAddNewEventSuppressed(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.Suppression = SuppressionInfo{
+		Enabled: true,
+		ValueDescriptors: map[string]SuppressionPolicy{
+			"Temperature": {ChangeOfValue: true},
+			"Pressure":    {ChangeOfValue: true},
+		},
+	}
+	myMock := &dbMock.DBClient{}
+	myMock.On("AddEvent", mock.Anything).Return(testBsonString, nil).Once()
+	dbClient = myMock
+
+	readings := []contract.Reading{{Name: "Temperature", Value: "45"}, {Name: "Pressure", Value: "1"}}
+	for i, expected := range []string{testBsonString, ""} {
+		id, err := addNewEvent(models.Event{Event: contract.Event{Device: testDeviceName, Readings: readings}}, context.Background())
+		// The second event is reported as suppressed rather than added
+		_, suppressed := err.(*errors.ErrEventSuppressed)
+		if id != expected || (i == 0 && err != nil) || (i == 1 && !suppressed) {
+			t.Errorf("Event %d: expected id '%s', got '%s' %v", i, expected, id, err)
+		}
+
+		// The device is reported even when its readings are suppressed
+		if _, ok := (<-chEvents).(DeviceLastReported); !ok {
+			t.Errorf("Event %d: expected the device to be reported", i)
+		}
+		<-chEvents
+	}
+
+	myMock.AssertExpectations(t)
+}
+
+func TestAddNewEventNotStoredIsNotSuppressing(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.Suppression = SuppressionInfo{
+		Enabled:          true,
+		ValueDescriptors: map[string]SuppressionPolicy{"Temperature": {ChangeOfValue: true}},
+	}
+	myMock := &dbMock.DBClient{}
+	myMock.On("AddEvent", mock.Anything).Return("", goerrors.New("database unavailable")).Once()
+	myMock.On("AddEvent", mock.Anything).Return(testBsonString, nil).Once()
+	dbClient = myMock
+
+	readings := []contract.Reading{{Name: "Temperature", Value: "45"}}
+	if _, err := addNewEvent(models.Event{Event: contract.Event{Device: testDeviceName, Readings: readings}}, context.Background()); err == nil {
+		t.Fatalf("Expected the error adding the event")
+	}
+
+	// The reading of the event that failed is not the last accepted one
+	id, err := addNewEvent(models.Event{Event: contract.Event{Device: testDeviceName, Readings: readings}}, context.Background())
+	if err != nil || id != testBsonString {
+		t.Errorf("Expected the event to be added, got '%s' %v", id, err)
+	}
+	<-chEvents
+	<-chEvents
+
+	myMock.AssertExpectations(t)
+}
+
+func TestPostEventSuppressed(t *testing.T) {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.Suppression = SuppressionInfo{
+		Enabled:          true,
+		ValueDescriptors: map[string]SuppressionPolicy{"Temperature": {ChangeOfValue: true}},
+	}
+	myMock := &dbMock.DBClient{}
+	myMock.On("AddEvent", mock.Anything).Return(testBsonString, nil).Once()
+	dbClient = myMock
+
+	body, _ := json.Marshal(contract.Event{Device: testDeviceName, Readings: []contract.Reading{{Name: "Temperature", Value: "45"}}})
+	for i, expected := range []int{http.StatusOK, http.StatusAccepted} {
+		rr := httptest.NewRecorder()
+		testRoutes.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/event", bytes.NewReader(body)))
+		<-chEvents
+		<-chEvents
+
+		if rr.Code != expected {
+			t.Errorf("Event %d: expected status %d, got %d %s", i, expected, rr.Code, rr.Body.String())
+		}
+		if i == 1 && !strings.Contains(rr.Body.String(), "suppressed") {
+			t.Errorf("Expected the event to be reported as suppressed, got %s", rr.Body.String())
+		}
+	}
+	myMock.AssertExpectations(t)
+}
@@ -117,3 +117,8 @@ func validJSON(reading models.Reading) error {
 	var js interface{}
 	return json.Unmarshal([]byte(reading.Value), &js)
 }
+
+// Only floating point and integer readings can be aggregated
+func isNumericValueDescriptor(vd models.ValueDescriptor) bool {
+	return vd.Type == "F" || vd.Type == "I"
+}
@@ -224,6 +224,7 @@ func updateValueDescriptor(from contract.ValueDescriptor) error {
 	if err != nil {
 		return err
 	}
+	name := to.Name
 
 	// Update the fields
 	if from.Description != "" {
@@ -291,6 +292,9 @@ func updateValueDescriptor(from contract.ValueDescriptor) error {
 			return err
 		}
 	}
+	invalidateValueDescriptors()
+	derivation.forget(name)
+	derivation.forget(to.Name)
 
 	return nil
 }
@@ -312,6 +316,8 @@ func deleteValueDescriptor(vd contract.ValueDescriptor) error {
 		LoggingClient.Error(err.Error())
 		return err
 	}
+	invalidateValueDescriptors()
+	derivation.forget(vd.Name)
 
 	return nil
 }
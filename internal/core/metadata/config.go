@@ -20,6 +20,7 @@ import (
 // Struct used to parse the JSON configuration file
 type ConfigurationStruct struct {
 	Writable      WritableInfo
+	Callbacks     CallbackInfo
 	Clients       map[string]config.ClientInfo
 	Databases     map[string]config.DatabaseInfo
 	Logging       config.LoggingInfo
@@ -31,3 +32,9 @@ type ConfigurationStruct struct {
 type WritableInfo struct {
 	LogLevel string
 }
+
+// CallbackInfo lists the Clients, besides the device services, which are sent the device and
+// profile callbacks
+type CallbackInfo struct {
+	Clients []string
+}
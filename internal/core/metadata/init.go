@@ -34,6 +34,7 @@ import (
 	"github.com/edgexfoundry/edgex-go/internal/core/metadata/interfaces"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/bolt"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/redis"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/startup"
@@ -43,6 +44,7 @@ import (
 // Global variables
 var Configuration *ConfigurationStruct
 var dbClient interfaces.DBClient
+var dbMonitor *db.HealthMonitor
 var LoggingClient logger.LoggingClient
 var registryClient registry.Client
 var nc notifications.NotificationsClient
@@ -100,12 +102,16 @@ func Init(useRegistry bool) bool {
 		go listenForConfigChanges()
 	}
 
+	dbMonitor = db.NewHealthMonitor(dbClient, LoggingClient)
+	dbMonitor.Start()
+
 	go telemetry.StartCpuUsageAverage()
 
 	return true
 }
 
 func Destruct() {
+	dbMonitor.Stop()
 	if dbClient != nil {
 		dbClient.CloseSession()
 		dbClient = nil
@@ -226,6 +232,14 @@ func connectToDatabase() error {
 		return fmt.Errorf("couldn't create database client: %v", err.Error())
 	}
 
+	primary := Configuration.Databases["Primary"]
+	err = db.MigrateSchema(dbClient, primary.SchemaVersion, primary.MigrationDryRun, LoggingClient)
+	if err != nil {
+		dbClient.CloseSession()
+		dbClient = nil
+		return fmt.Errorf("couldn't migrate database: %v", err.Error())
+	}
+
 	return nil
 }
 
@@ -248,6 +262,13 @@ func newDBClient(dbType string) (interfaces.DBClient, error) {
 			Port: Configuration.Databases["Primary"].Port,
 		}
 		return redis.NewClient(dbConfig) //TODO: Verify this also connects to Redis
+	case db.BoltDB:
+		dbConfig := db.Configuration{
+			Host:         Configuration.Databases["Primary"].Host,
+			Timeout:      Configuration.Databases["Primary"].Timeout,
+			DatabaseName: Configuration.Databases["Primary"].Name,
+		}
+		return bolt.NewClient(dbConfig)
 	default:
 		return nil, db.ErrUnsupportedDatabase
 	}
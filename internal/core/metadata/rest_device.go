@@ -1112,6 +1112,10 @@ func notifyDeviceAssociates(d models.Device, action string, ctx context.Context)
 		LoggingClient.Error(err.Error())
 		return err
 	}
+	if err := notifyCallbackClients(d.Id, action, models.DEVICE); err != nil {
+		LoggingClient.Error(err.Error())
+		return err
+	}
 
 	return nil
 }
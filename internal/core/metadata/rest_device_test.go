@@ -0,0 +1,90 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/metadata/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+type callbackRequest struct {
+	method string
+	path   string
+	alert  models.CallbackAlert
+}
+
+func TestNotifyDeviceAssociatesCallsBackClients(t *testing.T) {
+	reset()
+	received := make(chan callbackRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var alert models.CallbackAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("Unexpected error decoding the callback: %v", err)
+		}
+		received <- callbackRequest{r.Method, r.URL.Path, alert}
+	}))
+	defer server.Close()
+	Configuration.Clients = map[string]config.ClientInfo{"CoreData": clientInfo(t, server.URL)}
+	Configuration.Callbacks.Clients = []string{"CoreData"}
+
+	// The device service has no addressable, so the configured client is the only one called back
+	db := &dbMock.DBClient{}
+	db.On("GetDeviceServiceById", "ds1").Return(models.DeviceService{Name: "service"}, nil)
+	dbClient = db
+
+	device := models.Device{Id: "id1", Name: "device", Service: models.DeviceService{Id: "ds1"}}
+	if err := notifyDeviceAssociates(device, http.MethodPut, context.Background()); err != nil {
+		t.Fatalf("Unexpected error notifying associates: %v", err)
+	}
+
+	select {
+	case req := <-received:
+		expected := callbackRequest{http.MethodPut, clients.ApiCallbackRoute, models.CallbackAlert{ActionType: models.DEVICE, Id: "id1"}}
+		if req != expected {
+			t.Errorf("expected callback %v, received %v", expected, req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No callback received")
+	}
+}
+
+func clientInfo(t *testing.T, serverURL string) config.ClientInfo {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %s: %v", serverURL, err)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %s: %v", serverURL, err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %s: %v", serverURL, err)
+	}
+	return config.ClientInfo{Protocol: u.Scheme, Host: host, Port: p}
+}
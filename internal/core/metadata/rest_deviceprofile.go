@@ -675,6 +675,10 @@ func notifyProfileAssociates(dp models.DeviceProfile, action string) error {
 		LoggingClient.Error(err.Error())
 		return err
 	}
+	if err := notifyCallbackClients(dp.Id, action, models.PROFILE); err != nil {
+		LoggingClient.Error(err.Error())
+		return err
+	}
 
 	return nil
 }
@@ -23,6 +23,7 @@ import (
 	"strconv"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
 )
@@ -767,23 +768,43 @@ func notifyAssociates(deviceServices []models.DeviceService, id string, action s
 
 // Make the callback for the device service
 func callback(service models.DeviceService, id string, action string, actionType models.ActionType) error {
-	client := &http.Client{}
 	url := service.Addressable.GetCallbackURL()
 	if len(url) > 0 {
-		body, err := getBody(id, actionType)
-		if err != nil {
-			return err
+		return callbackURL(url, id, action, actionType)
+	}
+	LoggingClient.Info("callback::no addressable for " + service.Name)
+	return nil
+}
+
+// Make the callback for the services configured to follow the device and profile changes,
+// such as core data which caches the devices it validates the events against
+func notifyCallbackClients(id string, action string, actionType models.ActionType) error {
+	for _, name := range Configuration.Callbacks.Clients {
+		client, ok := Configuration.Clients[name]
+		if !ok {
+			LoggingClient.Warn("callback::no client configured for " + name)
+			continue
 		}
-		req, err := http.NewRequest(string(action), url, bytes.NewReader(body))
-		if err != nil {
+		if err := callbackURL(client.Url()+clients.ApiCallbackRoute, id, action, actionType); err != nil {
 			return err
 		}
-		req.Header.Add("Content-Type", "application/json")
+	}
+	return nil
+}
 
-		go makeRequest(client, req)
-	} else {
-		LoggingClient.Info("callback::no addressable for " + service.Name)
+func callbackURL(url string, id string, action string, actionType models.ActionType) error {
+	client := &http.Client{}
+	body, err := getBody(id, actionType)
+	if err != nil {
+		return err
 	}
+	req, err := http.NewRequest(string(action), url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	go makeRequest(client, req)
 	return nil
 }
 
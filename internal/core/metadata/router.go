@@ -21,6 +21,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
@@ -36,6 +38,9 @@ func LoadRestRoutes() *mux.Router {
 	// Metrics
 	r.HandleFunc(clients.ApiMetricsRoute, metricsHandler).Methods(http.MethodGet)
 
+	// Readiness
+	r.HandleFunc(readiness.Route, readiness.Handler(map[string]readiness.Check{"database": dbMonitor})).Methods(http.MethodGet)
+
 	b := r.PathPrefix(clients.ApiBase).Subrouter()
 
 	loadDeviceRoutes(b)
@@ -49,6 +54,7 @@ func LoadRestRoutes() *mux.Router {
 	r.Use(correlation.ManageHeader)
 	r.Use(correlation.OnResponseComplete)
 	r.Use(correlation.OnRequestBegin)
+	r.Use(readiness.Guard(dbMonitor))
 
 	return r
 }
@@ -220,7 +226,13 @@ func configHandler(w http.ResponseWriter, _ *http.Request) {
 }
 
 func metricsHandler(w http.ResponseWriter, _ *http.Request) {
-	s := telemetry.NewSystemUsage()
+	s := struct {
+		telemetry.SystemUsage
+		Database *db.Health
+	}{
+		SystemUsage: telemetry.NewSystemUsage(),
+		Database:    dbMonitor.Health(),
+	}
 
 	encode(s, w)
 
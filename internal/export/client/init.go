@@ -27,6 +27,7 @@ import (
 	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/bolt"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/redis"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/startup"
@@ -35,6 +36,7 @@ import (
 
 // Global variables
 var dbClient export.DBClient
+var dbMonitor *db.HealthMonitor
 var LoggingClient logger.LoggingClient
 var Configuration *ConfigurationStruct
 var dc distro.DistroClient
@@ -94,12 +96,16 @@ func Init(useRegistry bool) bool {
 		go listenForConfigChanges()
 	}
 
+	dbMonitor = db.NewHealthMonitor(dbClient, LoggingClient)
+	dbMonitor.Start()
+
 	go telemetry.StartCpuUsageAverage()
 
 	return true
 }
 
 func Destruct() {
+	dbMonitor.Stop()
 	if dbClient != nil {
 		dbClient.CloseSession()
 		dbClient = nil
@@ -123,6 +129,14 @@ func connectToDatabase() error {
 		return fmt.Errorf("couldn't create database client: %v", err.Error())
 	}
 
+	primary := Configuration.Databases["Primary"]
+	err = db.MigrateSchema(dbClient, primary.SchemaVersion, primary.MigrationDryRun, LoggingClient)
+	if err != nil {
+		dbClient.CloseSession()
+		dbClient = nil
+		return fmt.Errorf("couldn't migrate database: %v", err.Error())
+	}
+
 	return nil
 }
 
@@ -145,6 +159,13 @@ func newDBClient(dbType string) (export.DBClient, error) {
 			Port: Configuration.Databases["Primary"].Port,
 		}
 		return redis.NewClient(dbConfig) //TODO: Verify this also connects to Redis
+	case db.BoltDB:
+		dbConfig := db.Configuration{
+			Host:         Configuration.Databases["Primary"].Host,
+			Timeout:      Configuration.Databases["Primary"].Timeout,
+			DatabaseName: Configuration.Databases["Primary"].Name,
+		}
+		return bolt.NewClient(dbConfig)
 	default:
 		return nil, db.ErrUnsupportedDatabase
 	}
@@ -31,7 +31,10 @@ import (
  */
 
 type MemDB struct {
-	regs []contract.Registration
+	regs      []contract.Registration
+	templates map[string]string
+	filters   map[string]string
+	csv       map[string]string
 }
 
 func (m *MemDB) CloseSession() {
@@ -108,5 +111,104 @@ func (mc *MemDB) DeleteRegistrationByName(name string) error {
 
 func (mc *MemDB) ScrubAllRegistrations() error {
 	mc.regs = make([]contract.Registration, 0)
+	mc.templates = nil
+	mc.filters = nil
+	mc.csv = nil
+	return nil
+}
+
+func (mc *MemDB) Templates() (map[string]string, error) {
+	templates := make(map[string]string, len(mc.templates))
+	for name, t := range mc.templates {
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+func (mc *MemDB) TemplateByName(name string) (string, error) {
+	t, ok := mc.templates[name]
+	if !ok {
+		return "", db.ErrNotFound
+	}
+	return t, nil
+}
+
+func (mc *MemDB) UpdateTemplate(name string, template string) error {
+	if mc.templates == nil {
+		mc.templates = make(map[string]string)
+	}
+	mc.templates[name] = template
+	return nil
+}
+
+func (mc *MemDB) DeleteTemplateByName(name string) error {
+	if _, ok := mc.templates[name]; !ok {
+		return db.ErrNotFound
+	}
+	delete(mc.templates, name)
+	return nil
+}
+
+func (mc *MemDB) Filters() (map[string]string, error) {
+	filters := make(map[string]string, len(mc.filters))
+	for name, f := range mc.filters {
+		filters[name] = f
+	}
+	return filters, nil
+}
+
+func (mc *MemDB) FilterByName(name string) (string, error) {
+	f, ok := mc.filters[name]
+	if !ok {
+		return "", db.ErrNotFound
+	}
+	return f, nil
+}
+
+func (mc *MemDB) UpdateFilter(name string, expression string) error {
+	if mc.filters == nil {
+		mc.filters = make(map[string]string)
+	}
+	mc.filters[name] = expression
+	return nil
+}
+
+func (mc *MemDB) DeleteFilterByName(name string) error {
+	if _, ok := mc.filters[name]; !ok {
+		return db.ErrNotFound
+	}
+	delete(mc.filters, name)
+	return nil
+}
+
+func (mc *MemDB) CSVSettings() (map[string]string, error) {
+	settings := make(map[string]string, len(mc.csv))
+	for name, s := range mc.csv {
+		settings[name] = s
+	}
+	return settings, nil
+}
+
+func (mc *MemDB) CSVSettingsByName(name string) (string, error) {
+	s, ok := mc.csv[name]
+	if !ok {
+		return "", db.ErrNotFound
+	}
+	return s, nil
+}
+
+func (mc *MemDB) UpdateCSVSettings(name string, settings string) error {
+	if mc.csv == nil {
+		mc.csv = make(map[string]string)
+	}
+	mc.csv[name] = settings
+	return nil
+}
+
+func (mc *MemDB) DeleteCSVSettingsByName(name string) error {
+	if _, ok := mc.csv[name]; !ok {
+		return db.ErrNotFound
+	}
+	delete(mc.csv, name)
 	return nil
 }
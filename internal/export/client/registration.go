@@ -9,12 +9,16 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
+	"github.com/edgexfoundry/edgex-go/internal/export/csvformat"
+	"github.com/edgexfoundry/edgex-go/internal/export/filter"
+	"github.com/edgexfoundry/edgex-go/internal/export/template"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
@@ -138,6 +142,13 @@ func addReg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	values, err := optionsOf(data)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Invalid option of registration %s. Error: %s", reg.Name, err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	_, err = dbClient.RegistrationByName(reg.Name)
 	if err == nil {
 		LoggingClient.Error("Name already taken: " + reg.Name)
@@ -156,6 +167,23 @@ func addReg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The options were validated above; a registration whose option cannot be written is removed
+	// along with those written, so that it is not sent without them
+	for i, o := range registrationOptions {
+		if values[i] == nil || *values[i] == "" {
+			continue
+		}
+		if err = o.update(reg.Name, *values[i]); err != nil {
+			LoggingClient.Error(fmt.Sprintf("Failed to add the %s of registration %s. Error: %s", o.field, reg.Name, err.Error()))
+			if removeErr := dbClient.DeleteRegistrationById(id); removeErr != nil {
+				LoggingClient.Error(fmt.Sprintf("Failed to remove registration %s. Error: %s", reg.Name, removeErr.Error()))
+			}
+			deleteOptions(reg.Name)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	notifyUpdatedRegistrations(models.NotifyUpdate{Name: reg.Name,
 		Operation: "add"})
 
@@ -199,6 +227,23 @@ func updateReg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	values, err := optionsOf(data)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Invalid option of registration %s. Error: %s", toReg.Name, err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The registration and its options before the update, restored if an option cannot be written
+	original := toReg
+	previous, err := optionsByName(toReg.Name)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to query the options of registration %s. Error: %s", toReg.Name, err.Error()))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	oldName := toReg.Name
 	if fromReg.Name != "" {
 		toReg.Name = fromReg.Name
 	}
@@ -245,6 +290,18 @@ func updateReg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for i, o := range registrationOptions {
+		if err = o.apply(oldName, toReg.Name, values[i]); err != nil {
+			LoggingClient.Error(fmt.Sprintf("Failed to update the %s of registration %s. Error: %s", o.field, toReg.Name, err.Error()))
+			// Distro is notified whether or not the registration could be restored, as it was updated
+			name := restoreRegistration(original, toReg.Name, previous[:i])
+			notifyUpdatedRegistrations(models.NotifyUpdate{Name: name,
+				Operation: "update"})
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	notifyUpdatedRegistrations(models.NotifyUpdate{Name: toReg.Name,
 		Operation: "update"})
 
@@ -274,6 +331,7 @@ func delRegByID(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	deleteOptions(reg.Name)
 
 	notifyUpdatedRegistrations(models.NotifyUpdate{Name: reg.Name,
 		Operation: "delete"})
@@ -294,6 +352,7 @@ func delRegByName(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	deleteOptions(name)
 
 	notifyUpdatedRegistrations(models.NotifyUpdate{Name: name,
 		Operation: "delete"})
@@ -303,6 +362,227 @@ func delRegByName(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("true"))
 }
 
+func getTemplateByName(w http.ResponseWriter, r *http.Request) {
+	getOptionByName(w, r, templateOption)
+}
+
+func getExpressionByName(w http.ResponseWriter, r *http.Request) {
+	getOptionByName(w, r, expressionOption)
+}
+
+func getCSVByName(w http.ResponseWriter, r *http.Request) {
+	getOptionByName(w, r, csvOption)
+}
+
+func getOptionByName(w http.ResponseWriter, r *http.Request, o registrationOption) {
+	// URL parameters
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	text, err := o.get(name)
+	if err != nil {
+		if err == db.ErrNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			LoggingClient.Error(fmt.Sprintf("Failed to query %s by name: %s. Error: %s", o.field, name, err.Error()))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(text))
+}
+
+// An option sent along with a registration in a field of its own, as the contract has no room for
+// it, and stored apart under the name of the registration
+type registrationOption struct {
+	field string
+	// The field holds a JSON object, stored as its text, rather than a string
+	object   bool
+	validate func(text string) error
+	get      func(name string) (string, error)
+	update   func(name string, text string) error
+	remove   func(name string) error
+}
+
+var (
+	// Formats the events of the registration
+	templateOption = registrationOption{
+		field: "template",
+		validate: func(text string) error {
+			_, err := template.Parse(text)
+			return err
+		},
+		get:    func(name string) (string, error) { return dbClient.TemplateByName(name) },
+		update: func(name string, text string) error { return dbClient.UpdateTemplate(name, text) },
+		remove: func(name string) error { return dbClient.DeleteTemplateByName(name) },
+	}
+
+	// Selects the readings the registration sends
+	expressionOption = registrationOption{
+		field: "expression",
+		validate: func(text string) error {
+			_, err := filter.Parse(text)
+			return err
+		},
+		get:    func(name string) (string, error) { return dbClient.FilterByName(name) },
+		update: func(name string, text string) error { return dbClient.UpdateFilter(name, text) },
+		remove: func(name string) error { return dbClient.DeleteFilterByName(name) },
+	}
+
+	// Settings of the CSV format over those export-distro is configured with
+	csvOption = registrationOption{
+		field:  "csv",
+		object: true,
+		validate: func(text string) error {
+			_, err := csvformat.Parse(text, csvformat.Settings{})
+			return err
+		},
+		get:    func(name string) (string, error) { return dbClient.CSVSettingsByName(name) },
+		update: func(name string, text string) error { return dbClient.UpdateCSVSettings(name, text) },
+		remove: func(name string) error { return dbClient.DeleteCSVSettingsByName(name) },
+	}
+
+	registrationOptions = []registrationOption{templateOption, expressionOption, csvOption}
+)
+
+// Return the options sent along with a registration, in the order of registrationOptions and nil
+// for those not sent, once validated
+func optionsOf(data []byte) ([]*string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	values := make([]*string, len(registrationOptions))
+	for i, o := range registrationOptions {
+		m, ok := fields[o.field]
+		if !ok {
+			continue
+		}
+		var err error
+		if o.object {
+			values[i], err = objectText(m)
+		} else {
+			err = json.Unmarshal(m, &values[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s: %s", o.field, err.Error())
+		}
+		if values[i] != nil && *values[i] != "" {
+			if err := o.validate(*values[i]); err != nil {
+				return nil, fmt.Errorf("Invalid %s: %s", o.field, err.Error())
+			}
+		}
+	}
+	return values, nil
+}
+
+// Return the compact text of an object option, nil for null and empty for an empty object, which
+// removes the option as an empty string does
+func objectText(m json.RawMessage) (*string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(m, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, nil
+	}
+	text := ""
+	if len(fields) > 0 {
+		var b bytes.Buffer
+		if err := json.Compact(&b, m); err != nil {
+			return nil, err
+		}
+		text = b.String()
+	}
+	return &text, nil
+}
+
+// Apply the option sent along with an update, an empty one removing it, and keep the option of a
+// renamed registration. The option is left as it was if it cannot be written.
+func (o registrationOption) apply(oldName string, name string, text *string) error {
+	if text == nil && oldName != name {
+		t, err := o.get(oldName)
+		if err == db.ErrNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		text = &t
+	}
+
+	switch {
+	case text == nil:
+		return nil
+	case *text == "":
+		if err := o.remove(name); err != nil && err != db.ErrNotFound {
+			return err
+		}
+	default:
+		if err := o.update(name, *text); err != nil {
+			return err
+		}
+	}
+	// The option of a renamed registration is removed once written under the new name
+	if oldName != name {
+		o.delete(oldName)
+	}
+	return nil
+}
+
+// Return the options stored for a registration, in the order of registrationOptions and nil for
+// those it has not
+func optionsByName(name string) ([]*string, error) {
+	values := make([]*string, len(registrationOptions))
+	for i, o := range registrationOptions {
+		text, err := o.get(name)
+		if err == db.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		values[i] = &text
+	}
+	return values, nil
+}
+
+// Restore a registration updated under name, along with the first options it had, those updated
+// before one failed. Returns the name the registration is left with.
+func restoreRegistration(reg models.Registration, name string, options []*string) string {
+	if err := dbClient.UpdateRegistration(reg); err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to restore registration %s. Error: %s", reg.Name, err.Error()))
+		return name
+	}
+
+	for i, text := range options {
+		// An option the registration had not is removed
+		if text == nil {
+			empty := ""
+			text = &empty
+		}
+		if err := registrationOptions[i].apply(name, reg.Name, text); err != nil {
+			LoggingClient.Error(fmt.Sprintf("Failed to restore the %s of registration %s. Error: %s", registrationOptions[i].field, reg.Name, err.Error()))
+		}
+	}
+	return reg.Name
+}
+
+// The registration is gone whether or not its option could be removed
+func (o registrationOption) delete(name string) {
+	if err := o.remove(name); err != nil && err != db.ErrNotFound {
+		LoggingClient.Error(fmt.Sprintf("Failed to delete the %s of registration %s. Error: %s", o.field, name, err.Error()))
+	}
+}
+
+func deleteOptions(name string) {
+	for _, o := range registrationOptions {
+		o.delete(name)
+	}
+}
+
 func notifyUpdatedRegistrations(update models.NotifyUpdate) {
 	go func() {
 		err := dc.NotifyRegistrations(update, context.Background())
@@ -15,6 +15,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
@@ -43,7 +45,13 @@ func encode(i interface{}, w http.ResponseWriter) {
 }
 
 func metricsHandler(w http.ResponseWriter, _ *http.Request) {
-	s := telemetry.NewSystemUsage()
+	s := struct {
+		telemetry.SystemUsage
+		Database *db.Health
+	}{
+		SystemUsage: telemetry.NewSystemUsage(),
+		Database:    dbMonitor.Health(),
+	}
 
 	encode(s, w)
 
@@ -63,6 +71,9 @@ func httpServer() http.Handler {
 	// Metrics
 	r.HandleFunc(clients.ApiMetricsRoute, metricsHandler).Methods(http.MethodGet)
 
+	// Readiness
+	r.HandleFunc(readiness.Route, readiness.Handler(map[string]readiness.Check{"database": dbMonitor})).Methods(http.MethodGet)
+
 	// Registration
 	r.HandleFunc(clients.ApiRegistrationRoute, getAllReg).Methods(http.MethodGet)
 	r.HandleFunc(clients.ApiRegistrationRoute, addReg).Methods(http.MethodPost)
@@ -71,12 +82,16 @@ func httpServer() http.Handler {
 	reg.HandleFunc("/{id}", getRegByID).Methods(http.MethodGet)
 	reg.HandleFunc("/reference/{type}", getRegList).Methods(http.MethodGet)
 	reg.HandleFunc("/name/{name}", getRegByName).Methods(http.MethodGet)
+	reg.HandleFunc("/name/{name}/template", getTemplateByName).Methods(http.MethodGet)
+	reg.HandleFunc("/name/{name}/expression", getExpressionByName).Methods(http.MethodGet)
+	reg.HandleFunc("/name/{name}/csv", getCSVByName).Methods(http.MethodGet)
 	reg.HandleFunc("/id/{id}", delRegByID).Methods(http.MethodDelete)
 	reg.HandleFunc("/name/{name}", delRegByName).Methods(http.MethodDelete)
 
 	r.Use(correlation.ManageHeader)
 	r.Use(correlation.OnResponseComplete)
 	r.Use(correlation.OnRequestBegin)
+	r.Use(readiness.Guard(dbMonitor))
 
 	return r
 }
@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -18,10 +19,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 )
 
 //const regJson = `{"origin":1471806386919,"name":"OSIClient","addressable":{"origin":1471806386919,"name":"OSIMQTTBroker","protocol":"TCP","address":"m10.cloudmqtt.com","port":15421,"publisher":"EdgeXExportPublisher","user":"hukfgtoh","password":"uP6hJLYW6Ji4","topic":"EdgeXDataTopic"},"format":"JSON","filter":{"deviceIdentifiers":["livingroomthermosat", "hallwaythermostat"],"valueDescriptorIdentifiers":["temperature", "humidity"]},"encryption":{"encryptionAlgorithm":"AES","encryptionKey":"123","initializingVector":"123"},"compression":"GZIP","enable":true, "destination": "REST_ENDPOINT"}`
@@ -235,6 +239,344 @@ func TestRegistrationUpdate(t *testing.T) {
 	}
 }
 
+// Marshal the test registration along with a template, if any
+func registrationWithTemplate(t *testing.T, id string, name string, template ...string) string {
+	return registrationWithOption(t, id, name, "template", template...)
+}
+
+func registrationWithOption(t *testing.T, id string, name string, field string, value ...string) string {
+	r := testRegistration
+	r.ID = id
+	r.Name = name
+
+	var reg map[string]interface{}
+	b, _ := json.Marshal(r)
+	if err := json.Unmarshal(b, &reg); err != nil {
+		t.Fatalf("unmarshaling error %v", err)
+	}
+	if len(value) > 0 {
+		reg[field] = value[0]
+		// Object options are sent as they are rather than as strings
+		if field == "csv" {
+			reg[field] = json.RawMessage(value[0])
+		}
+	}
+	b, _ = json.Marshal(reg)
+	return string(b)
+}
+
+func getTemplate(t *testing.T, serverUrl string, name string) (int, string) {
+	return getOption(t, serverUrl, name, "template")
+}
+
+func getOption(t *testing.T, serverUrl string, name string, field string) (int, string) {
+	response, err := http.Get(serverUrl + clients.ApiRegistrationByNameRoute + "/" + name + "/" + field)
+	if err != nil {
+		t.Fatalf("Error getting %s %v", field, err)
+	}
+	defer response.Body.Close()
+	data, _ := ioutil.ReadAll(response.Body)
+	return response.StatusCode, string(data)
+}
+
+func TestRegistrationTemplate(t *testing.T) {
+	const template = `{"device":{{json .Device}}}`
+
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	// Templates that cannot render an event are rejected
+	response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+		strings.NewReader(registrationWithTemplate(t, "", "OSIClient", "{{.Unknown}}")))
+	if err != nil {
+		t.Fatalf("Error adding registration %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusBadRequest)
+	}
+
+	response, err = http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+		strings.NewReader(registrationWithTemplate(t, "", "OSIClient", template)))
+	if err != nil {
+		t.Fatalf("Error adding registration %v", err)
+	}
+	response.Body.Close()
+	if status, text := getTemplate(t, ts.URL, "OSIClient"); status != http.StatusOK || text != template {
+		t.Errorf("expected the template of the registration, found %d %s", status, text)
+	}
+
+	// The template follows the registration when it is renamed
+	reg, _ := dbClient.RegistrationByName("OSIClient")
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithTemplate(t, reg.ID, "Renamed")))
+	response.Body.Close()
+	if status, _ := getTemplate(t, ts.URL, "OSIClient"); status != http.StatusNotFound {
+		t.Errorf("expected the template to be renamed, found %d", status)
+	}
+	if status, text := getTemplate(t, ts.URL, "Renamed"); status != http.StatusOK || text != template {
+		t.Errorf("expected the template of the renamed registration, found %d %s", status, text)
+	}
+
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithTemplate(t, "", "Renamed", "{{.Device")))
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusBadRequest)
+	}
+
+	// An empty template removes it
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithTemplate(t, "", "Renamed", "")))
+	response.Body.Close()
+	if status, _ := getTemplate(t, ts.URL, "Renamed"); status != http.StatusNotFound {
+		t.Errorf("expected the template to be removed, found %d", status)
+	}
+
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithTemplate(t, "", "Renamed", "{{.Origin}}")))
+	response.Body.Close()
+	if status, text := getTemplate(t, ts.URL, "Renamed"); status != http.StatusOK || text != "{{.Origin}}" {
+		t.Errorf("expected the template to be set again, found %d %s", status, text)
+	}
+	response = requestMethod(t, http.MethodDelete, ts.URL+clients.ApiRegistrationRoute+"/name/Renamed", nil)
+	response.Body.Close()
+	if status, _ := getTemplate(t, ts.URL, "Renamed"); status != http.StatusNotFound {
+		t.Errorf("expected the template to be deleted with its registration, found %d", status)
+	}
+}
+
+func TestRegistrationExpression(t *testing.T) {
+	const expression = "value > 30 || contains(labels, 'alarm')"
+
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	// Expressions referring to anything but a reading are rejected
+	response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+		strings.NewReader(registrationWithOption(t, "", "OSIClient", "expression", "temperature > 30")))
+	if err != nil {
+		t.Fatalf("Error adding registration %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusBadRequest)
+	}
+
+	response, err = http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+		strings.NewReader(registrationWithOption(t, "", "OSIClient", "expression", expression)))
+	if err != nil {
+		t.Fatalf("Error adding registration %v", err)
+	}
+	response.Body.Close()
+	if status, text := getOption(t, ts.URL, "OSIClient", "expression"); status != http.StatusOK || text != expression {
+		t.Errorf("expected the expression of the registration, found %d %s", status, text)
+	}
+
+	// Setting a template leaves the expression alone
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithTemplate(t, "", "OSIClient", "{{.Device}}")))
+	response.Body.Close()
+	if status, text := getOption(t, ts.URL, "OSIClient", "expression"); status != http.StatusOK || text != expression {
+		t.Errorf("expected the expression to be kept, found %d %s", status, text)
+	}
+
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithOption(t, "", "OSIClient", "expression", "matches(value, '(')")))
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusBadRequest)
+	}
+
+	response = requestMethod(t, http.MethodDelete, ts.URL+clients.ApiRegistrationRoute+"/name/OSIClient", nil)
+	response.Body.Close()
+	if status, _ := getOption(t, ts.URL, "OSIClient", "expression"); status != http.StatusNotFound {
+		t.Errorf("expected the expression to be deleted with its registration, found %d", status)
+	}
+	if status, _ := getTemplate(t, ts.URL, "OSIClient"); status != http.StatusNotFound {
+		t.Errorf("expected the template to be deleted with its registration, found %d", status)
+	}
+}
+
+// Fails to write the filters, as a database going away would
+type failingFilterDB struct {
+	*MemDB
+}
+
+func (f failingFilterDB) UpdateFilter(name string, expression string) error {
+	return errors.New("database unavailable")
+}
+
+func TestRegistrationOptionNotAdded(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+	dbClient = failingFilterDB{&MemDB{}}
+
+	var reg map[string]interface{}
+	json.Unmarshal([]byte(registrationWithTemplate(t, "", "OSIClient", "{{.Device}}")), &reg)
+	reg["expression"] = "value > 10"
+	body, _ := json.Marshal(reg)
+
+	response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Error adding registration %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusInternalServerError)
+	}
+
+	// Neither the registration nor the option written before are left
+	if _, err := dbClient.RegistrationByName("OSIClient"); err != db.ErrNotFound {
+		t.Errorf("expected the registration to be removed, found %v", err)
+	}
+	if status, _ := getTemplate(t, ts.URL, "OSIClient"); status != http.StatusNotFound {
+		t.Errorf("expected the template to be removed, found %d", status)
+	}
+}
+
+// Fails to write the templates
+type failingTemplateDB struct {
+	*MemDB
+}
+
+func (f failingTemplateDB) UpdateTemplate(name string, template string) error {
+	return errors.New("database unavailable")
+}
+
+// Passes the registrations distro is notified of on to the test
+type notifiedDistroClient struct {
+	updates chan models.NotifyUpdate
+}
+
+func (d *notifiedDistroClient) NotifyRegistrations(update models.NotifyUpdate, ctx context.Context) error {
+	d.updates <- update
+	return nil
+}
+
+func TestRegistrationUpdateOptionFailed(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	mem := &MemDB{}
+	id, _ := mem.AddRegistration(testRegistration)
+	mem.UpdateTemplate("OSIClient", "{{.Device}}")
+	dbClient = failingTemplateDB{mem}
+	distro := &notifiedDistroClient{updates: make(chan models.NotifyUpdate, 1)}
+	dc = distro
+
+	var update map[string]interface{}
+	json.Unmarshal([]byte(registrationWithTemplate(t, id, "Renamed", "{{.Origin}}")), &update)
+	update["format"] = models.FormatXML
+	body, _ := json.Marshal(update)
+	response := requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute, bytes.NewReader(body))
+	response.Body.Close()
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusInternalServerError)
+	}
+
+	// The registration is restored along with its template, and distro told to reload it
+	if restored, err := dbClient.RegistrationByName("OSIClient"); err != nil || restored.Format != models.FormatJSON {
+		t.Errorf("expected the registration to be restored, found %v %v", restored, err)
+	}
+	if _, err := dbClient.RegistrationByName("Renamed"); err != db.ErrNotFound {
+		t.Errorf("expected the registration not to be renamed, found %v", err)
+	}
+	if status, text := getTemplate(t, ts.URL, "OSIClient"); status != http.StatusOK || text != "{{.Device}}" {
+		t.Errorf("expected the template of the registration, found %d %s", status, text)
+	}
+	select {
+	case u := <-distro.updates:
+		if u.Name != "OSIClient" || u.Operation != "update" {
+			t.Errorf("expected distro to be notified of the update of OSIClient, got %v", u)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("distro was not notified")
+	}
+}
+
+func TestRegistrationUpdateExpressionFailed(t *testing.T) {
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	mem := &MemDB{}
+	id, _ := mem.AddRegistration(testRegistration)
+	mem.UpdateFilter("OSIClient", "value > 10")
+	dbClient = failingFilterDB{mem}
+	distro := &notifiedDistroClient{updates: make(chan models.NotifyUpdate, 1)}
+	dc = distro
+
+	// The template is written before the expression fails
+	var update map[string]interface{}
+	json.Unmarshal([]byte(registrationWithTemplate(t, id, "Renamed", "{{.Origin}}")), &update)
+	update["expression"] = "value > 20"
+	body, _ := json.Marshal(update)
+	response := requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute, bytes.NewReader(body))
+	response.Body.Close()
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Returned status %d, should be %d", response.StatusCode, http.StatusInternalServerError)
+	}
+
+	if _, err := dbClient.RegistrationByName("OSIClient"); err != nil {
+		t.Errorf("expected the registration to be restored, found %v", err)
+	}
+	if status, _ := getTemplate(t, ts.URL, "OSIClient"); status != http.StatusNotFound {
+		t.Errorf("expected the template written to be removed, found %d", status)
+	}
+	if status, _ := getTemplate(t, ts.URL, "Renamed"); status != http.StatusNotFound {
+		t.Errorf("expected the template written to be removed, found %d", status)
+	}
+	if status, text := getOption(t, ts.URL, "OSIClient", "expression"); status != http.StatusOK || text != "value > 10" {
+		t.Errorf("expected the expression of the registration, found %d %s", status, text)
+	}
+	select {
+	case u := <-distro.updates:
+		if u.Name != "OSIClient" {
+			t.Errorf("expected distro to be notified of the update of OSIClient, got %v", u)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("distro was not notified")
+	}
+}
+
+func TestRegistrationCSV(t *testing.T) {
+	const settings = `{"columns":["name","value","uom"],"delimiter":";"}`
+
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	// Settings export-distro cannot apply are rejected
+	for _, invalid := range []string{`{"columns":["unit"]}`, `{"separator":";"}`, `"delimiter=;"`} {
+		response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+			strings.NewReader(registrationWithOption(t, "", "OSIClient", "csv", invalid)))
+		if err != nil {
+			t.Fatalf("Error adding registration %v", err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s returned status %d, should be %d", invalid, response.StatusCode, http.StatusBadRequest)
+		}
+	}
+
+	response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+		strings.NewReader(registrationWithOption(t, "", "OSIClient", "csv", settings)))
+	if err != nil {
+		t.Fatalf("Error adding registration %v", err)
+	}
+	response.Body.Close()
+	if status, text := getOption(t, ts.URL, "OSIClient", "csv"); status != http.StatusOK || text != settings {
+		t.Errorf("expected the CSV settings of the registration, found %d %s", status, text)
+	}
+
+	// An empty object removes them
+	response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+		strings.NewReader(registrationWithOption(t, "", "OSIClient", "csv", `{}`)))
+	response.Body.Close()
+	if status, _ := getOption(t, ts.URL, "OSIClient", "csv"); status != http.StatusNotFound {
+		t.Errorf("expected the CSV settings to be removed, found %d", status)
+	}
+}
+
 func TestRegistrationDelByName(t *testing.T) {
 	ts := prepareTest(t)
 	defer ts.Close()
@@ -0,0 +1,107 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package csvformat holds the settings of the CSV format, which sends a row per reading. The [CSV]
+// configuration of export-distro sets them for every registration, and a registration may set any
+// of them over it with a JSON object such as {"columns":["name","value","uom"],"delimiter":";"}.
+package csvformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Columns of the CSV format
+const (
+	Device  = "device"
+	Name    = "name"
+	Value   = "value"
+	Origin  = "origin"
+	Created = "created"
+	Uom     = "uom"
+)
+
+// Quoting of the CSV fields
+const (
+	// Only the fields holding a delimiter, a quote or a line break are quoted
+	QuoteMinimal = "minimal"
+	QuoteAll     = "all"
+)
+
+// DefaultColumns are sent when no column is set
+var DefaultColumns = []string{Device, Name, Value, Origin}
+
+// Settings of the CSV format
+type Settings struct {
+	// Any of 'device', 'name', 'value', 'origin', 'created' and 'uom', by default the first four
+	Columns []string
+	// Whether each message starts with a row naming the columns
+	Header bool
+	// A single character, ',' by default
+	Delimiter string
+	// 'minimal' to quote only the fields that need it, or 'all'
+	Quote string
+}
+
+// Parse returns the settings of a registration, a JSON object setting any of the fields of
+// Settings over the defaults
+func Parse(text string, defaults Settings) (Settings, error) {
+	s := defaults
+	// The columns decoded must not overwrite those of the defaults
+	s.Columns = append([]string(nil), defaults.Columns...)
+
+	d := json.NewDecoder(strings.NewReader(text))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&s); err != nil {
+		return Settings{}, err
+	}
+	if err := s.Validate(); err != nil {
+		return Settings{}, err
+	}
+	return s, nil
+}
+
+// Validate checks the columns, delimiter and quoting of the settings
+func (s Settings) Validate() error {
+	for _, c := range s.Columns {
+		switch c {
+		case Device, Name, Value, Origin, Created, Uom:
+		default:
+			return fmt.Errorf("CSV column not supported: %s", c)
+		}
+	}
+	if _, err := s.Comma(); err != nil {
+		return err
+	}
+	switch s.Quote {
+	case "", QuoteMinimal, QuoteAll:
+		return nil
+	default:
+		return fmt.Errorf("CSV quoting not supported: %s", s.Quote)
+	}
+}
+
+// Comma returns the character delimiting the fields
+func (s Settings) Comma() (rune, error) {
+	if s.Delimiter == "" {
+		return ',', nil
+	}
+	d, size := utf8.DecodeRuneInString(s.Delimiter)
+	if size != len(s.Delimiter) || d == '"' || d == '\r' || d == '\n' || d == utf8.RuneError {
+		return 0, fmt.Errorf("invalid CSV delimiter '%s'", s.Delimiter)
+	}
+	return d, nil
+}
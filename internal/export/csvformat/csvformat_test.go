@@ -0,0 +1,74 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package csvformat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	defaults := Settings{Columns: []string{Device, Value}, Header: true, Delimiter: ",", Quote: QuoteMinimal}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected Settings
+	}{
+		{"empty", `{}`, defaults},
+		{"columns", `{"columns":["name","uom"]}`,
+			Settings{Columns: []string{Name, Uom}, Header: true, Delimiter: ",", Quote: QuoteMinimal}},
+		{"header", `{"header":false,"delimiter":";"}`,
+			Settings{Columns: []string{Device, Value}, Delimiter: ";", Quote: QuoteMinimal}},
+		{"quote", `{"Quote":"all"}`,
+			Settings{Columns: []string{Device, Value}, Header: true, Delimiter: ",", Quote: QuoteAll}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.text, defaults)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(s, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, s)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(defaults.Columns, []string{Device, Value}) {
+		t.Errorf("the columns of the defaults were changed to %v", defaults.Columns)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"not an object", `["name"]`},
+		{"unknown field", `{"columns":["name"],"separator":";"}`},
+		{"column", `{"columns":["device","invalid"]}`},
+		{"delimiter", `{"delimiter":",;"}`},
+		{"quote delimiter", `{"delimiter":"\""}`},
+		{"quote", `{"quote":"invalid"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.text, Settings{}); err == nil {
+				t.Errorf("%s was not rejected", tt.text)
+			}
+		})
+	}
+}
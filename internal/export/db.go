@@ -54,6 +54,63 @@ type DBClient interface {
 	// NotFound - no registration with the ID was found
 	DeleteRegistrationByName(name string) error
 
-	// Delete all registrations
+	// Delete all registrations, along with their templates, filters and CSV settings
 	ScrubAllRegistrations() error
+
+	// ********************** TEMPLATE FUNCTIONS *****************************
+	// Return the templates formatting the events of registrations, by registration name
+	// UnexpectedError - failed to retrieve templates from the database
+	Templates() (map[string]string, error)
+
+	// Get the template of a registration
+	// UnexpectedError - problem getting in database
+	// NotFound - the registration has no template
+	TemplateByName(name string) (string, error)
+
+	// Set the template of a registration, replacing the one it had
+	// UnexpectedError - problem updating in database
+	UpdateTemplate(name string, template string) error
+
+	// Delete the template of a registration
+	// UnexpectedError - problem getting in database
+	// NotFound - the registration has no template
+	DeleteTemplateByName(name string) error
+
+	// ********************** FILTER FUNCTIONS *****************************
+	// Return the expressions filtering the readings of registrations, by registration name
+	// UnexpectedError - failed to retrieve filters from the database
+	Filters() (map[string]string, error)
+
+	// Get the filter expression of a registration
+	// UnexpectedError - problem getting in database
+	// NotFound - the registration has no filter
+	FilterByName(name string) (string, error)
+
+	// Set the filter expression of a registration, replacing the one it had
+	// UnexpectedError - problem updating in database
+	UpdateFilter(name string, expression string) error
+
+	// Delete the filter expression of a registration
+	// UnexpectedError - problem getting in database
+	// NotFound - the registration has no filter
+	DeleteFilterByName(name string) error
+
+	// ********************** CSV FUNCTIONS *****************************
+	// Return the CSV settings registrations set over those of export-distro, by registration name
+	// UnexpectedError - failed to retrieve CSV settings from the database
+	CSVSettings() (map[string]string, error)
+
+	// Get the CSV settings of a registration, a JSON object
+	// UnexpectedError - problem getting in database
+	// NotFound - the registration has no CSV settings
+	CSVSettingsByName(name string) (string, error)
+
+	// Set the CSV settings of a registration, replacing those it had
+	// UnexpectedError - problem updating in database
+	UpdateCSVSettings(name string, settings string) error
+
+	// Delete the CSV settings of a registration
+	// UnexpectedError - problem getting in database
+	// NotFound - the registration has no CSV settings
+	DeleteCSVSettingsByName(name string) error
 }
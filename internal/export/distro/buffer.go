@@ -0,0 +1,294 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package distro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	defaultRetryInterval    = time.Second
+	defaultMaxRetryInterval = 5 * time.Minute
+	// Number of queued messages forwarded before the registration goes back to its new messages
+	forwardBatch = 100
+)
+
+// The queues of the registrations, nil when buffering is disabled
+var buffers *bufferStore
+
+// A message a registration failed to send, ready to be sent again as it is
+type bufferedMessage struct {
+	Created       int64
+	Payload       []byte
+	ContentType   string `json:",omitempty"`
+	CorrelationID string `json:",omitempty"`
+	// Marks the event pushed once sent, by id for JSON events and by checksum for binary ones
+	EventID  string `json:",omitempty"`
+	Checksum string `json:",omitempty"`
+}
+
+// Depth of the queue of a registration, reported in the metrics
+type bufferStats struct {
+	Depth   int
+	Dropped int
+}
+
+// bufferStore holds a queue per registration in a bbolt file, so that the messages a registration
+// fails to send outlive a restart of the service
+type bufferStore struct {
+	db        *bbolt.DB
+	maxEvents int
+	maxAge    int64
+
+	mutex  sync.Mutex
+	queues map[string]*queue
+}
+
+func openBuffers(info BufferInfo) (*bufferStore, error) {
+	if err := os.MkdirAll(filepath.Dir(info.File), 0700); err != nil {
+		return nil, err
+	}
+	bdb, err := bbolt.Open(info.File, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("could not open buffer file %s: %v", info.File, err)
+	}
+
+	s := &bufferStore{db: bdb, maxEvents: info.MaxEvents, queues: make(map[string]*queue)}
+	if info.MaxAge != "" {
+		d, err := time.ParseDuration(info.MaxAge)
+		if err != nil || d <= 0 {
+			bdb.Close()
+			return nil, fmt.Errorf("invalid buffer age '%s'", info.MaxAge)
+		}
+		s.maxAge = int64(d / time.Millisecond)
+	}
+	return s, nil
+}
+
+func (s *bufferStore) close() error {
+	return s.db.Close()
+}
+
+// Return the queue of a registration, counting the messages left by a previous run
+func (s *bufferStore) queue(name string) (*queue, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if q, ok := s.queues[name]; ok {
+		return q, nil
+	}
+
+	q := &queue{store: s, name: []byte(name)}
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(q.name)
+		if err != nil {
+			return err
+		}
+		q.depth = b.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.queues[name] = q
+	return q, nil
+}
+
+// Drop the queue of a registration that was removed
+func (s *bufferStore) remove(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.queues, name)
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		err := tx.DeleteBucket([]byte(name))
+		if err == bbolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+func (s *bufferStore) stats() map[string]bufferStats {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := make(map[string]bufferStats, len(s.queues))
+	for name, q := range s.queues {
+		stats[name] = q.stats()
+	}
+	return stats
+}
+
+// queue of the messages a registration is to send again, oldest first
+type queue struct {
+	store *bufferStore
+	name  []byte
+
+	mutex   sync.Mutex
+	depth   int
+	dropped int
+}
+
+func (q *queue) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.depth
+}
+
+func (q *queue) stats() bufferStats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return bufferStats{Depth: q.depth, Dropped: q.dropped}
+}
+
+// Add a message at the end of the queue, dropping the oldest beyond the size limit
+func (q *queue) push(m bufferedMessage) error {
+	value, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	dropped := 0
+	err = q.store.db.Update(func(tx *bbolt.Tx) error {
+		b, err := q.bucket(tx)
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err = b.Put(sequenceKey(seq), value); err != nil {
+			return err
+		}
+
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && q.store.maxEvents > 0 && q.depth+1-dropped > q.store.maxEvents; k, _ = c.First() {
+			if err = c.Delete(); err != nil {
+				return err
+			}
+			dropped++
+		}
+		return nil
+	})
+	if err == nil {
+		q.depth += 1 - dropped
+		q.dropped += dropped
+	}
+	return err
+}
+
+// Return the oldest message of the queue, dropping those past the age limit on the way
+func (q *queue) front() (key []byte, m bufferedMessage, ok bool, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := db.MakeTimestamp()
+	dropped := 0
+	err = q.store.db.Update(func(tx *bbolt.Tx) error {
+		b, err := q.bucket(tx)
+		if err != nil {
+			return err
+		}
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.First() {
+			var candidate bufferedMessage
+			if err := json.Unmarshal(v, &candidate); err == nil &&
+				(q.store.maxAge == 0 || now-candidate.Created <= q.store.maxAge) {
+				key, m, ok = append([]byte(nil), k...), candidate, true
+				return nil
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			dropped++
+		}
+		return nil
+	})
+	if err == nil {
+		q.depth -= dropped
+		q.dropped += dropped
+	}
+	return key, m, ok, err
+}
+
+// Remove a message once sent
+func (q *queue) remove(key []byte) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	removed := false
+	err := q.store.db.Update(func(tx *bbolt.Tx) error {
+		b, err := q.bucket(tx)
+		if err != nil {
+			return err
+		}
+		if b.Get(key) == nil {
+			return nil
+		}
+		removed = true
+		return b.Delete(key)
+	})
+	if err == nil && removed {
+		q.depth--
+	}
+	return err
+}
+
+// The bucket of the queue, gone once the registration is removed
+func (q *queue) bucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	b := tx.Bucket(q.name)
+	if b == nil {
+		return nil, fmt.Errorf("the queue of registration %s was removed", q.name)
+	}
+	return b, nil
+}
+
+// Big endian sequences sort in the order the messages were queued
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func bufferInterval(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		LoggingClient.Error(fmt.Sprintf("invalid buffer retry interval '%s', using %s", value, fallback))
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,198 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package distro
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// A destination that can be taken down, recording the payloads it received
+type flakySender struct {
+	down     bool
+	received []string
+}
+
+func (s *flakySender) Send(data []byte, _ context.Context) bool {
+	if s.down {
+		return false
+	}
+	s.received = append(s.received, string(data))
+	return true
+}
+
+func openTestBuffers(t *testing.T, info BufferInfo) (*bufferStore, func()) {
+	dir, err := ioutil.TempDir("", "distro")
+	if err != nil {
+		t.Fatalf("Could not create directory: %v", err)
+	}
+	info.File = filepath.Join(dir, "buffer.db")
+	s, err := openBuffers(info)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Could not open buffers: %v", err)
+	}
+	return s, func() {
+		s.close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	s, closeBuffers := openTestBuffers(t, BufferInfo{MaxEvents: 2, MaxAge: "1h"})
+	defer closeBuffers()
+
+	q, err := s.queue("registration")
+	if err != nil {
+		t.Fatalf("Could not get the queue: %v", err)
+	}
+	now := db.MakeTimestamp()
+	for _, m := range []bufferedMessage{
+		{Created: now, Payload: []byte("dropped for the size")},
+		{Created: now - 2*60*60*1000, Payload: []byte("dropped for the age")},
+		{Created: now, Payload: []byte("kept")},
+	} {
+		if err = q.push(m); err != nil {
+			t.Fatalf("Could not queue: %v", err)
+		}
+	}
+
+	key, m, ok, err := q.front()
+	if err != nil || !ok || string(m.Payload) != "kept" {
+		t.Fatalf("expected the message within the limits, found %q (%v)", m.Payload, err)
+	}
+	if stats := s.stats()["registration"]; stats.Depth != 1 || stats.Dropped != 2 {
+		t.Errorf("expected 1 message queued and 2 dropped, found %+v", stats)
+	}
+
+	if err = q.remove(key); err != nil {
+		t.Fatalf("Could not remove: %v", err)
+	}
+	if _, _, ok, _ = q.front(); ok || q.len() != 0 {
+		t.Errorf("expected the queue to be empty, found %d messages", q.len())
+	}
+
+	if err = s.remove("registration"); err != nil {
+		t.Fatalf("Could not remove the queue: %v", err)
+	}
+	if _, ok := s.stats()["registration"]; ok {
+		t.Error("expected the queue of a removed registration to be gone")
+	}
+}
+
+func TestRemoveRegistrationQueue(t *testing.T) {
+	s, closeBuffers := openTestBuffers(t, BufferInfo{})
+	defer closeBuffers()
+	defer func(b *bufferStore) { buffers = b }(buffers)
+	buffers = s
+
+	const name = "removed"
+	r := validRegistration()
+	r.Name = name
+	ri := newRegistrationInfo()
+	ri.update(r)
+	done := make(chan struct{})
+	go func() {
+		registrationLoop(ri)
+		close(done)
+	}()
+
+	q, err := s.queue(name)
+	if err != nil {
+		t.Fatalf("Could not get the queue: %v", err)
+	}
+	running := map[string]*registrationInfo{name: ri}
+	if err = updateRunningRegistrations(running, contract.NotifyUpdate{Name: name, Operation: contract.NotifyUpdateDelete}); err != nil {
+		t.Fatalf("Could not delete the registration: %v", err)
+	}
+	<-done
+
+	// The loop drops the queue once its workers are done, and a late message is not queued
+	if _, ok := s.stats()[name]; ok {
+		t.Error("expected the queue of the deleted registration to be gone")
+	}
+	if err = q.push(bufferedMessage{Created: db.MakeTimestamp(), Payload: []byte("late")}); err == nil {
+		t.Error("expected a message queued after the removal to be rejected")
+	}
+}
+
+func TestForward(t *testing.T) {
+	s, closeBuffers := openTestBuffers(t, BufferInfo{})
+	defer closeBuffers()
+	q, err := s.queue("registration")
+	if err != nil {
+		t.Fatalf("Could not get the queue: %v", err)
+	}
+
+	sender := &flakySender{down: true}
+	ri := newRegistrationInfo()
+	ri.format = jsonFormatter{}
+	ri.sender = sender
+	ri.buffer = q
+
+	post := func(device string) {
+		e := models.Event{}
+		e.Device = device
+		msg := msgTypes.MessageEnvelope{ContentType: clients.ContentTypeJSON}
+		msg.Payload, _ = json.Marshal(e)
+		ri.processMessage(msg)
+	}
+
+	post("first")
+	if q.len() != 1 || len(ri.chQueued) != 1 {
+		t.Fatalf("expected the event to be queued and a retry scheduled, found %d queued", q.len())
+	}
+	// Sending fails while the destination is down
+	ri.forward()
+	firstBackoff := ri.backoff
+	ri.forward()
+	if firstBackoff <= 0 || ri.backoff != 2*firstBackoff {
+		t.Errorf("expected the retries to back off exponentially, found %s then %s", firstBackoff, ri.backoff)
+	}
+
+	// Events keep their order behind the queued ones, even with the destination back
+	sender.down = false
+	post("second")
+	if len(sender.received) != 0 || q.len() != 2 {
+		t.Fatalf("expected the event to be queued behind the first one, found %d queued", q.len())
+	}
+	ri.forward()
+	if len(sender.received) != 2 || q.len() != 0 || ri.backoff != 0 {
+		t.Fatalf("expected the queued events to be forwarded, found %d sent and %d queued", len(sender.received), q.len())
+	}
+	for i, device := range []string{"first", "second"} {
+		var e models.Event
+		if err = json.Unmarshal([]byte(sender.received[i]), &e); err != nil || e.Device != device {
+			t.Errorf("expected event %d to be from device %s, found %s (%v)", i, device, e.Device, err)
+		}
+	}
+
+	// Sent directly once the queue is empty
+	post("third")
+	if len(sender.received) != 3 || q.len() != 0 {
+		t.Errorf("expected the event to be sent directly, found %d sent and %d queued", len(sender.received), q.len())
+	}
+}
@@ -10,6 +10,7 @@ package distro
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
@@ -76,3 +77,27 @@ func getRegistrationByNameURL(url string) *contract.Registration {
 	}
 	return &reg
 }
+
+// Return an option of a registration, found false when the registration has none
+func getOption(name string, option string) (text string, found bool, err error) {
+	url := fmt.Sprintf("%s%s/%s/%s", Configuration.Clients["Export"].Url(), clients.ApiRegistrationByNameRoute, name, option)
+	return getOptionURL(url)
+}
+
+func getOptionURL(url string) (text string, found bool, err error) {
+	response, err := http.Get(url)
+	if err != nil {
+		return "", false, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		b, err := ioutil.ReadAll(response.Body)
+		return string(b), err == nil, err
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("could not get option from %s: %s", url, response.Status)
+	}
+}
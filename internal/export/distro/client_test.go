@@ -230,3 +230,34 @@ func TestClientRegistrationByNameError(t *testing.T) {
 		}
 	}
 }
+
+func TestClientOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		found  bool
+		err    bool
+	}{
+		{"found", http.StatusOK, true, false},
+		{"none", http.StatusNotFound, false, false},
+		{"error", http.StatusInternalServerError, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, "{{.Device}}")
+			}
+			ts := httptest.NewServer(http.HandlerFunc(handler))
+			defer ts.Close()
+
+			text, found, err := getOptionURL(ts.URL)
+			if found != tt.found || (err != nil) != tt.err {
+				t.Fatalf("expected found %v and error %v, found %v and %v", tt.found, tt.err, found, err)
+			}
+			if found && text != "{{.Device}}" {
+				t.Errorf("expected the option, found %s", text)
+			}
+		})
+	}
+}
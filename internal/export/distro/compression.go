@@ -7,52 +7,29 @@
 package distro
 
 import (
-	"bytes"
-	"compress/gzip"
-	"compress/zlib"
 	"encoding/base64"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/compression"
 )
 
 type gzipTransformer struct {
-	writer *gzip.Writer
+	compression.Gzip
 }
 
 func (gzt *gzipTransformer) Transform(data []byte) []byte {
-	var buf bytes.Buffer
-
-	if gzt.writer == nil {
-		gzt.writer = gzip.NewWriter(&buf)
-	} else {
-		gzt.writer.Reset(&buf)
-	}
-
-	gzt.writer.Write(data)
-	gzt.writer.Close()
-
-	return bytesBufferToBase64(buf)
+	return bytesToBase64(gzt.Compress(data))
 }
 
 type zlibTransformer struct {
-	writer *zlib.Writer
+	compression.Zlib
 }
 
 func (zlt *zlibTransformer) Transform(data []byte) []byte {
-	var buf bytes.Buffer
-
-	if zlt.writer == nil {
-		zlt.writer = zlib.NewWriter(&buf)
-	} else {
-		zlt.writer.Reset(&buf)
-	}
-
-	zlt.writer.Write(data)
-	zlt.writer.Close()
-
-	return bytesBufferToBase64(buf)
+	return bytesToBase64(zlt.Compress(data))
 }
 
-func bytesBufferToBase64(buf bytes.Buffer) []byte {
-	dst := make([]byte, base64.StdEncoding.EncodedLen(buf.Len()))
-	base64.StdEncoding.Encode(dst, buf.Bytes())
+func bytesToBase64(b []byte) []byte {
+	dst := make([]byte, base64.StdEncoding.EncodedLen(len(b)))
+	base64.StdEncoding.Encode(dst, b)
 	return dst
 }
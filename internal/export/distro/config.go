@@ -13,7 +13,10 @@
  *******************************************************************************/
 package distro
 
-import "github.com/edgexfoundry/edgex-go/internal/pkg/config"
+import (
+	"github.com/edgexfoundry/edgex-go/internal/export/csvformat"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+)
 
 type ConfigurationStruct struct {
 	Writable       WritableInfo
@@ -24,6 +27,10 @@ type ConfigurationStruct struct {
 	AnalyticsQueue config.MessageQueueInfo
 	Registry       config.RegistryInfo
 	Service        config.ServiceInfo
+	Buffer         BufferInfo
+	Distribution   DistributionInfo
+	// Settings of the CSV registrations, each of which may set its own over them
+	CSV csvformat.Settings
 }
 
 type WritableInfo struct {
@@ -31,7 +38,41 @@ type WritableInfo struct {
 	LogLevel   string
 }
 
+// BufferInfo configures the queue on disk of the events each registration fails to send, which are
+// sent again in order once the destination is back, instead of being dropped
+type BufferInfo struct {
+	Enabled bool
+	// File holding the queues of all the registrations
+	File string
+	// Maximum number of events queued per registration, the oldest being dropped beyond
+	MaxEvents int
+	// Age after which a queued event is dropped, e.g. '24h'
+	MaxAge string
+	// Delay before the first retry, doubled after each failed retry up to MaxRetryInterval
+	RetryInterval    string
+	MaxRetryInterval string
+}
+
 type CertificateInfo struct {
 	Cert string
 	Key  string
 }
+
+// DistributionInfo configures how the events are handed to the registrations, each having its own
+// queue and workers so that a slow destination does not hold back the others
+type DistributionInfo struct {
+	// Events waiting for a worker, per registration
+	QueueSize int
+	// What happens to an event arriving at a full queue: 'drop-oldest', 'drop-newest' or 'block'
+	Overflow string
+	// Events of a registration processed at the same time; more than one may send them out of order
+	Workers int
+	// Policies of particular registrations by name, overriding the values above they set
+	Registrations map[string]DistributionPolicy
+}
+
+type DistributionPolicy struct {
+	QueueSize int
+	Overflow  string
+	Workers   int
+}
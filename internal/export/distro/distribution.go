@@ -0,0 +1,205 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package distro
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+const (
+	// A message arriving at a full queue replaces the oldest one
+	overflowDropOldest = "drop-oldest"
+	// A message arriving at a full queue is dropped
+	overflowDropNewest = "drop-newest"
+	// A message arriving at a full queue waits for room, holding back the other registrations
+	overflowBlock = "block"
+
+	defaultQueueSize = 1000
+)
+
+// The registrations running, for their metrics
+var distribution = struct {
+	mutex         sync.Mutex
+	registrations map[string]*registrationInfo
+}{registrations: make(map[string]*registrationInfo)}
+
+// Flow of the messages through a registration, reported in the metrics
+type distributionStats struct {
+	QueueSize int
+	// Messages waiting to be processed
+	Queued  int
+	Workers int
+	// Workers processing a message
+	Busy      int
+	Received  uint64
+	Dropped   uint64
+	Processed uint64
+	// Milliseconds the last message took to process and the average over all of them
+	LastProcessingTime    float64
+	AverageProcessingTime float64
+}
+
+// Counters of a registration, updated atomically by the main loop and the workers
+type distributionCounters struct {
+	received       uint64
+	dropped        uint64
+	processed      uint64
+	processingTime int64 // Nanoseconds spent processing all messages
+	lastTime       int64 // Nanoseconds spent processing the last message
+}
+
+// Return the policy of a registration, its own when configured or else the default one
+func distributionPolicy(name string) DistributionPolicy {
+	d := Configuration.Distribution
+	p := DistributionPolicy{QueueSize: d.QueueSize, Overflow: d.Overflow, Workers: d.Workers}
+	if own, ok := d.Registrations[name]; ok {
+		if own.QueueSize > 0 {
+			p.QueueSize = own.QueueSize
+		}
+		if own.Overflow != "" {
+			p.Overflow = own.Overflow
+		}
+		if own.Workers > 0 {
+			p.Workers = own.Workers
+		}
+	}
+
+	if p.QueueSize <= 0 {
+		p.QueueSize = defaultQueueSize
+	}
+	switch p.Overflow {
+	case overflowDropOldest, overflowDropNewest, overflowBlock:
+	case "":
+		p.Overflow = overflowDropOldest
+	default:
+		LoggingClient.Warn(fmt.Sprintf("Overflow policy not supported: %s, using %s", p.Overflow, overflowDropOldest))
+		p.Overflow = overflowDropOldest
+	}
+	if p.Workers <= 0 {
+		p.Workers = 1
+	}
+	return p
+}
+
+// Size the queue and the workers of a registration before its loop starts
+func (reg *registrationInfo) setPolicy(p DistributionPolicy) {
+	reg.chMessages = make(chan msgTypes.MessageEnvelope, p.QueueSize)
+	reg.workers = make(chan struct{}, p.Workers)
+	reg.overflow = p.Overflow
+}
+
+// Queue a message for the registration, applying its overflow policy when the queue is full
+func (reg *registrationInfo) enqueue(msg msgTypes.MessageEnvelope) {
+	atomic.AddUint64(&reg.counters.received, 1)
+
+	if reg.overflow == overflowBlock {
+		reg.chMessages <- msg
+		return
+	}
+
+	for {
+		select {
+		case reg.chMessages <- msg:
+			return
+		default:
+		}
+
+		if reg.overflow == overflowDropNewest {
+			atomic.AddUint64(&reg.counters.dropped, 1)
+			return
+		}
+		// Make room, unless a worker just did
+		select {
+		case <-reg.chMessages:
+			atomic.AddUint64(&reg.counters.dropped, 1)
+		default:
+		}
+	}
+}
+
+// Process a message as soon as a worker is free
+func (reg *registrationInfo) dispatch(msg msgTypes.MessageEnvelope) {
+	reg.workers <- struct{}{}
+	go func() {
+		defer func() { <-reg.workers }()
+
+		start := time.Now()
+		reg.processMessage(msg)
+		elapsed := int64(time.Since(start))
+
+		atomic.AddUint64(&reg.counters.processed, 1)
+		atomic.AddInt64(&reg.counters.processingTime, elapsed)
+		atomic.StoreInt64(&reg.counters.lastTime, elapsed)
+	}()
+}
+
+// Wait for the workers to finish the messages they process
+func (reg *registrationInfo) wait() {
+	for i := 0; i < cap(reg.workers); i++ {
+		reg.workers <- struct{}{}
+	}
+	for i := 0; i < cap(reg.workers); i++ {
+		<-reg.workers
+	}
+}
+
+func (reg *registrationInfo) stats() distributionStats {
+	s := distributionStats{
+		QueueSize: cap(reg.chMessages),
+		Queued:    len(reg.chMessages),
+		Workers:   cap(reg.workers),
+		Busy:      len(reg.workers),
+		Received:  atomic.LoadUint64(&reg.counters.received),
+		Dropped:   atomic.LoadUint64(&reg.counters.dropped),
+		Processed: atomic.LoadUint64(&reg.counters.processed),
+	}
+	s.LastProcessingTime = float64(atomic.LoadInt64(&reg.counters.lastTime)) / float64(time.Millisecond)
+	if s.Processed > 0 {
+		total := float64(atomic.LoadInt64(&reg.counters.processingTime)) / float64(time.Millisecond)
+		s.AverageProcessingTime = total / float64(s.Processed)
+	}
+	return s
+}
+
+func trackRegistration(reg *registrationInfo) {
+	distribution.mutex.Lock()
+	defer distribution.mutex.Unlock()
+	distribution.registrations[reg.registration.Name] = reg
+}
+
+func untrackRegistration(reg *registrationInfo) {
+	distribution.mutex.Lock()
+	defer distribution.mutex.Unlock()
+	// A registration added again under the same name has replaced this one
+	if distribution.registrations[reg.registration.Name] == reg {
+		delete(distribution.registrations, reg.registration.Name)
+	}
+}
+
+func distributionMetrics() map[string]distributionStats {
+	distribution.mutex.Lock()
+	defer distribution.mutex.Unlock()
+
+	stats := make(map[string]distributionStats, len(distribution.registrations))
+	for name, reg := range distribution.registrations {
+		stats[name] = reg.stats()
+	}
+	return stats
+}
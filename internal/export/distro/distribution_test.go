@@ -0,0 +1,179 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package distro
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+)
+
+// A destination holding each send until released, recording how many were in progress at once
+type slowSender struct {
+	release chan struct{}
+
+	mutex   sync.Mutex
+	sending int
+	most    int
+	sent    int
+}
+
+func (s *slowSender) Send(_ []byte, _ context.Context) bool {
+	s.mutex.Lock()
+	s.sending++
+	if s.sending > s.most {
+		s.most = s.sending
+	}
+	s.mutex.Unlock()
+
+	<-s.release
+
+	s.mutex.Lock()
+	s.sending--
+	s.sent++
+	s.mutex.Unlock()
+	return true
+}
+
+func testMessage(device string) msgTypes.MessageEnvelope {
+	e := models.Event{}
+	e.Device = device
+	msg := msgTypes.MessageEnvelope{ContentType: clients.ContentTypeJSON, CorrelationID: device}
+	msg.Payload, _ = json.Marshal(e)
+	return msg
+}
+
+func TestDistributionPolicy(t *testing.T) {
+	defer func(d DistributionInfo) { Configuration.Distribution = d }(Configuration.Distribution)
+	Configuration.Distribution = DistributionInfo{
+		QueueSize: 10,
+		Overflow:  overflowDropNewest,
+		Registrations: map[string]DistributionPolicy{
+			"slow":    {Workers: 4},
+			"invalid": {Overflow: "invalid"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expected DistributionPolicy
+	}{
+		{"other", DistributionPolicy{QueueSize: 10, Overflow: overflowDropNewest, Workers: 1}},
+		{"slow", DistributionPolicy{QueueSize: 10, Overflow: overflowDropNewest, Workers: 4}},
+		{"invalid", DistributionPolicy{QueueSize: 10, Overflow: overflowDropOldest, Workers: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p := distributionPolicy(tt.name); p != tt.expected {
+				t.Errorf("expected %+v, found %+v", tt.expected, p)
+			}
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	tests := []struct {
+		overflow string
+		kept     []string
+	}{
+		{overflowDropOldest, []string{"second", "third"}},
+		{overflowDropNewest, []string{"first", "second"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overflow, func(t *testing.T) {
+			ri := newRegistrationInfo()
+			ri.setPolicy(DistributionPolicy{QueueSize: 2, Overflow: tt.overflow, Workers: 1})
+			for _, device := range []string{"first", "second", "third"} {
+				ri.enqueue(testMessage(device))
+			}
+
+			for _, device := range tt.kept {
+				if msg := <-ri.chMessages; msg.CorrelationID != device {
+					t.Errorf("expected the event of %s, found %s", device, msg.CorrelationID)
+				}
+			}
+			if s := ri.stats(); s.Received != 3 || s.Dropped != 1 || s.Queued != 0 {
+				t.Errorf("expected 3 events received and 1 dropped, found %+v", s)
+			}
+		})
+	}
+
+	t.Run(overflowBlock, func(t *testing.T) {
+		ri := newRegistrationInfo()
+		ri.setPolicy(DistributionPolicy{QueueSize: 1, Overflow: overflowBlock, Workers: 1})
+		ri.enqueue(testMessage("first"))
+
+		queued := make(chan struct{})
+		go func() {
+			ri.enqueue(testMessage("second"))
+			close(queued)
+		}()
+		select {
+		case <-queued:
+			t.Fatal("expected the event to wait for room in the queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		<-ri.chMessages
+		<-queued
+		if s := ri.stats(); s.Dropped != 0 || s.Queued != 1 {
+			t.Errorf("expected no event dropped, found %+v", s)
+		}
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	sender := &slowSender{release: make(chan struct{})}
+	ri := newRegistrationInfo()
+	ri.setPolicy(DistributionPolicy{QueueSize: 1, Workers: 2})
+	ri.format = jsonFormatter{}
+	ri.sender = sender
+
+	dispatched := make(chan struct{})
+	go func() {
+		for _, device := range []string{"first", "second", "third"} {
+			ri.dispatch(testMessage(device))
+		}
+		close(dispatched)
+	}()
+
+	// The third event waits for a worker
+	select {
+	case <-dispatched:
+		t.Fatal("expected the event to wait for a free worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if s := ri.stats(); s.Busy != 2 {
+		t.Errorf("expected both workers to be busy, found %+v", s)
+	}
+
+	close(sender.release)
+	<-dispatched
+	ri.wait()
+
+	if sender.sent != 3 || sender.most != 2 {
+		t.Errorf("expected 3 events sent at most 2 at a time, found %d sent and %d at a time", sender.sent, sender.most)
+	}
+	if s := ri.stats(); s.Processed != 3 || s.Busy != 0 {
+		t.Errorf("expected 3 events processed, found %+v", s)
+	}
+}
@@ -8,7 +8,10 @@ package distro
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/edgexfoundry/edgex-go/internal/export/filter"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -75,3 +78,66 @@ func (filter valueDescFilterDetails) Filter(event *contract.Event) (bool, *contr
 	}
 	return len(auxEvent.Readings) > 0, auxEvent
 }
+
+// expressionFilterDetails keeps the readings meeting the expression of a registration
+type expressionFilterDetails struct {
+	condition *filter.Filter
+
+	// Labels by value descriptor, looked up once per lifetime
+	mutex  sync.Mutex
+	labels map[string]cachedLabels
+}
+
+type cachedLabels struct {
+	labels  []string
+	expires time.Time
+}
+
+func newExpressionFilter(expression string) (filterer, error) {
+	condition, err := filter.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &expressionFilterDetails{condition: condition, labels: make(map[string]cachedLabels)}, nil
+}
+
+// A reading the expression cannot be evaluated for, such as a string compared to a number, is left out
+func (f *expressionFilterDetails) Filter(event *contract.Event) (bool, *contract.Event) {
+	if event == nil {
+		return false, nil
+	}
+
+	auxEvent := *event
+	auxEvent.Readings = []contract.Reading{}
+	now := time.Now()
+	for _, reading := range event.Readings {
+		match, err := f.condition.Match(event, reading, f.labelsOf, now)
+		if err != nil {
+			LoggingClient.Debug(fmt.Sprintf("Reading %s not evaluated by %s: %s", reading.Name, f.condition, err.Error()))
+			continue
+		}
+		if match {
+			auxEvent.Readings = append(auxEvent.Readings, reading)
+		}
+	}
+	return len(auxEvent.Readings) > 0, &auxEvent
+}
+
+// A value descriptor that cannot be read is asked for again by the next reading, the readings
+// needing its labels being left out meanwhile
+func (f *expressionFilterDetails) labelsOf(name string) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := time.Now()
+	if labels, ok := f.labels[name]; ok && now.Before(labels.expires) {
+		return labels.labels, nil
+	}
+	vd, err := valueDescriptor(name)
+	if err != nil {
+		LoggingClient.Warn(fmt.Sprintf("Could not find the labels of %s: %s", name, err.Error()))
+		return nil, err
+	}
+	f.labels[name] = cachedLabels{labels: vd.Labels, expires: now.Add(valueDescriptorLifetime)}
+	return vd.Labels, nil
+}
@@ -7,6 +7,7 @@
 package distro
 
 import (
+	"errors"
 	"testing"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -128,3 +129,91 @@ func TestFilterValue(t *testing.T) {
 		t.Fatal("Event should be one reading, there are ", len(res.Readings))
 	}
 }
+
+func TestFilterExpression(t *testing.T) {
+	defer func(f func(string) (contract.ValueDescriptor, error)) { valueDescriptor = f }(valueDescriptor)
+	lookups := 0
+	unavailable := true
+	valueDescriptor = func(name string) (contract.ValueDescriptor, error) {
+		lookups++
+		if name == descriptor1 {
+			return contract.ValueDescriptor{Name: name, Labels: []string{"alarm"}}, nil
+		}
+		if unavailable {
+			return contract.ValueDescriptor{}, errors.New("unavailable")
+		}
+		return contract.ValueDescriptor{Name: name}, nil
+	}
+
+	f, err := newExpressionFilter("contains(labels, 'alarm') || value > 30")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if accepted, _ := f.Filter(nil); accepted {
+		t.Fatal("Event should be filtered out")
+	}
+
+	event := contract.Event{ID: "event", Device: deviceID1}
+	event.Readings = append(event.Readings,
+		contract.Reading{Name: descriptor1, Value: "20"},
+		contract.Reading{Name: descriptor2, Value: "20"},
+		contract.Reading{Name: descriptor2, Value: "40"},
+		contract.Reading{Name: descriptor2, Value: "on"})
+	// The readings whose labels cannot be looked up are left out, and looked up again by each
+	accepted, res := f.Filter(&event)
+	if !accepted {
+		t.Fatal("Event should be accepted")
+	}
+	if len(res.Readings) != 1 || res.Readings[0].Name != descriptor1 {
+		t.Fatalf("Expected only the alarm, found %v", res.Readings)
+	}
+	if lookups != 4 {
+		t.Errorf("Expected the value descriptor not found to be looked up again, found %d lookups", lookups)
+	}
+
+	unavailable = false
+	lookups = 0
+	accepted, res = f.Filter(&event)
+	if !accepted {
+		t.Fatal("Event should be accepted")
+	}
+	if len(res.Readings) != 2 || res.Readings[0].Name != descriptor1 || res.Readings[1].Value != "40" {
+		t.Fatalf("Expected the alarm and the reading above 30, found %v", res.Readings)
+	}
+	if res.ID != event.ID || len(event.Readings) != 4 {
+		t.Fatal("The event should be copied with the readings kept")
+	}
+	if lookups != 1 {
+		t.Errorf("Expected each value descriptor to be looked up once, found %d lookups", lookups)
+	}
+
+	event.Readings = []contract.Reading{{Name: descriptor2, Value: "10"}}
+	if accepted, _ = f.Filter(&event); accepted {
+		t.Fatal("Event should be filtered out")
+	}
+
+	if _, err = newExpressionFilter("temperature > 30"); err == nil {
+		t.Error("Expected an error for an unknown variable")
+	}
+}
+
+func TestExpressionOption(t *testing.T) {
+	r := validRegistration()
+	r.Name = "expression"
+	r.Filter = contract.Filter{}
+	options.byName[r.Name] = registrationOptions{optionExpression: "value > 100"}
+	defer forgetOptions(r.Name)
+
+	ri := newRegistrationInfo()
+	if !ri.update(r) {
+		t.Fatal("This registration should be good")
+	}
+	if len(ri.filter) != 1 {
+		t.Fatalf("Expected the expression filter, found %d filters", len(ri.filter))
+	}
+
+	options.byName[r.Name] = registrationOptions{optionExpression: "value >"}
+	if ri.update(r) {
+		t.Error("Registration with invalid expression")
+	}
+}
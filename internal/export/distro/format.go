@@ -9,15 +9,22 @@
 package distro
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/edgex-go/internal/export/csvformat"
+	"github.com/edgexfoundry/edgex-go/internal/export/template"
 )
 
 type jsonFormatter struct {
@@ -275,3 +282,144 @@ func (af biotFormatter) Format(event *contract.Event) []byte {
 	}
 	return msg
 }
+
+// Return the value descriptor the readings are named after, replaced in the tests
+var valueDescriptor = func(name string) (contract.ValueDescriptor, error) {
+	return vdc.ValueDescriptorForName(name, context.Background())
+}
+
+// Units of measure and labels of value descriptors are looked up again after this lifetime, so
+// that a value descriptor updated in core data is followed
+var valueDescriptorLifetime = time.Minute
+
+// csvFormatter converts an event to CSV, one row per reading
+type csvFormatter struct {
+	columns   []string
+	header    bool
+	delimiter rune
+	quoteAll  bool
+
+	// Units of measure by value descriptor, looked up once per lifetime
+	mutex sync.Mutex
+	uoms  map[string]cachedUom
+}
+
+type cachedUom struct {
+	label   string
+	expires time.Time
+}
+
+func newCSVFormatter(s csvformat.Settings) (*csvFormatter, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	delimiter, _ := s.Comma()
+
+	f := &csvFormatter{
+		columns:   s.Columns,
+		header:    s.Header,
+		delimiter: delimiter,
+		quoteAll:  s.Quote == csvformat.QuoteAll,
+		uoms:      make(map[string]cachedUom),
+	}
+	if len(f.columns) == 0 {
+		f.columns = csvformat.DefaultColumns
+	}
+	return f, nil
+}
+
+func (f *csvFormatter) Format(event *contract.Event) []byte {
+	var rows [][]string
+	if f.header {
+		rows = append(rows, f.columns)
+	}
+	for _, reading := range event.Readings {
+		row := make([]string, len(f.columns))
+		for i, c := range f.columns {
+			row[i] = f.field(c, event, reading)
+		}
+		rows = append(rows, row)
+	}
+
+	var b bytes.Buffer
+	if f.quoteAll {
+		for _, row := range rows {
+			for i, field := range row {
+				if i > 0 {
+					b.WriteRune(f.delimiter)
+				}
+				b.WriteString(`"` + strings.Replace(field, `"`, `""`, -1) + `"`)
+			}
+			b.WriteString("\n")
+		}
+		return b.Bytes()
+	}
+
+	w := csv.NewWriter(&b)
+	w.Comma = f.delimiter
+	if err := w.WriteAll(rows); err != nil {
+		LoggingClient.Error(fmt.Sprintf("Error generating CSV: %s", err))
+		return []byte{}
+	}
+	return b.Bytes()
+}
+
+// Readings missing a device or timestamps take those of their event
+func (f *csvFormatter) field(column string, event *contract.Event, reading contract.Reading) string {
+	switch column {
+	case csvformat.Device:
+		if reading.Device != "" {
+			return reading.Device
+		}
+		return event.Device
+	case csvformat.Name:
+		return reading.Name
+	case csvformat.Value:
+		return reading.Value
+	case csvformat.Origin:
+		if reading.Origin != 0 {
+			return strconv.FormatInt(reading.Origin, 10)
+		}
+		return strconv.FormatInt(event.Origin, 10)
+	case csvformat.Created:
+		if reading.Created != 0 {
+			return strconv.FormatInt(reading.Created, 10)
+		}
+		return strconv.FormatInt(event.Created, 10)
+	case csvformat.Uom:
+		return f.uom(reading.Name)
+	}
+	return ""
+}
+
+// A value descriptor that cannot be read is left without unit until it is looked up again, rather
+// than asked for every reading
+func (f *csvFormatter) uom(name string) string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := time.Now()
+	if uom, ok := f.uoms[name]; ok && now.Before(uom.expires) {
+		return uom.label
+	}
+	vd, err := valueDescriptor(name)
+	if err != nil {
+		LoggingClient.Warn(fmt.Sprintf("Could not find the unit of measure of %s: %s", name, err.Error()))
+	}
+	f.uoms[name] = cachedUom{label: vd.UomLabel, expires: now.Add(valueDescriptorLifetime)}
+	return vd.UomLabel
+}
+
+// templateFormatter renders the events with the template of a registration
+type templateFormatter struct {
+	template *template.Template
+}
+
+func (tf templateFormatter) Format(event *contract.Event) []byte {
+	b, err := tf.template.Execute(event)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Error executing template: %s", err))
+		return []byte{}
+	}
+	return b
+}
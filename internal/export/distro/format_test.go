@@ -9,12 +9,16 @@ package distro
 import (
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/export/csvformat"
 )
 
 const (
@@ -149,3 +153,162 @@ func TestBIoT(t *testing.T) {
 		t.Fatalf("Error unmarshal the formatted string: %v %v", err, out)
 	}
 }
+
+func TestCSV(t *testing.T) {
+	defer func(f func(string) (contract.ValueDescriptor, error)) { valueDescriptor = f }(valueDescriptor)
+	lookups := 0
+	valueDescriptor = func(name string) (contract.ValueDescriptor, error) {
+		lookups++
+		if name == readingName1 {
+			return contract.ValueDescriptor{Name: name, UomLabel: "C"}, nil
+		}
+		return contract.ValueDescriptor{}, errors.New("not found")
+	}
+
+	eventIn := contract.Event{Device: devID1, Origin: 10, Created: 20}
+	eventIn.Readings = append(eventIn.Readings,
+		contract.Reading{Name: readingName1, Value: readingValue1},
+		contract.Reading{Device: "id2", Name: "sensor2", Value: `say "hi", twice`, Origin: 11, Created: 21},
+		contract.Reading{Name: readingName1, Value: "1"})
+
+	tests := []struct {
+		name     string
+		settings csvformat.Settings
+		expected string
+	}{
+		{"default", csvformat.Settings{},
+			"id1,sensor1,123.45,10\nid2,sensor2,\"say \"\"hi\"\", twice\",11\nid1,sensor1,1,10\n"},
+		{"header", csvformat.Settings{Columns: []string{"name", "created"}, Header: true},
+			"name,created\nsensor1,20\nsensor2,21\nsensor1,20\n"},
+		{"delimiter", csvformat.Settings{Columns: []string{"value", "uom"}, Delimiter: ";"},
+			"123.45;C\n\"say \"\"hi\"\", twice\";\n1;C\n"},
+		{"quote all", csvformat.Settings{Columns: []string{"device", "value"}, Header: true, Delimiter: "\t", Quote: "all"},
+			"\"device\"\t\"value\"\n\"id1\"\t\"123.45\"\n\"id2\"\t\"say \"\"hi\"\", twice\"\n\"id1\"\t\"1\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, err := newCSVFormatter(tt.settings)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := string(cf.Format(&eventIn)); out != tt.expected {
+				t.Errorf("expected %q, found %q", tt.expected, out)
+			}
+		})
+	}
+
+	// Each value descriptor is looked up once, found or not
+	if lookups != 2 {
+		t.Errorf("expected 2 units of measure looked up, found %d", lookups)
+	}
+}
+
+func TestCSVUomExpires(t *testing.T) {
+	defer func(f func(string) (contract.ValueDescriptor, error)) { valueDescriptor = f }(valueDescriptor)
+	uom := "C"
+	valueDescriptor = func(name string) (contract.ValueDescriptor, error) {
+		return contract.ValueDescriptor{Name: name, UomLabel: uom}, nil
+	}
+
+	cf, err := newCSVFormatter(csvformat.Settings{Columns: []string{"uom"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	eventIn := contract.Event{Device: devID1, Readings: []contract.Reading{{Name: readingName1, Value: readingValue1}}}
+	cf.Format(&eventIn)
+
+	// The unit is kept for its lifetime, then looked up again
+	uom = "F"
+	if out := string(cf.Format(&eventIn)); out != "C\n" {
+		t.Errorf("expected the unit looked up before, found %q", out)
+	}
+	cf.uoms[readingName1] = cachedUom{label: "C", expires: time.Now().Add(-time.Second)}
+	if out := string(cf.Format(&eventIn)); out != "F\n" {
+		t.Errorf("expected the updated unit, found %q", out)
+	}
+}
+
+func TestCSVInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings csvformat.Settings
+	}{
+		{"column", csvformat.Settings{Columns: []string{"device", "invalid"}}},
+		{"delimiter", csvformat.Settings{Delimiter: ",;"}},
+		{"quote delimiter", csvformat.Settings{Delimiter: `"`}},
+		{"quote", csvformat.Settings{Quote: "invalid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newCSVFormatter(tt.settings); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestCSVOption(t *testing.T) {
+	defer func(s csvformat.Settings) { Configuration.CSV = s }(Configuration.CSV)
+	Configuration.CSV = csvformat.Settings{Columns: []string{"device", "value"}, Header: true}
+
+	r := validRegistration()
+	r.Name = "csv"
+	r.Format = contract.FormatCSV
+	defer forgetOptions(r.Name)
+
+	eventIn := contract.Event{Device: devID1}
+	eventIn.Readings = append(eventIn.Readings, contract.Reading{Name: readingName1, Value: readingValue1})
+
+	tests := []struct {
+		name     string
+		options  registrationOptions
+		expected string
+	}{
+		{"configuration", registrationOptions{}, "device,value\nid1,123.45\n"},
+		{"registration", registrationOptions{optionCSV: `{"columns":["name","value"],"header":false,"delimiter":";"}`},
+			"sensor1;123.45\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options.byName[r.Name] = tt.options
+			ri := newRegistrationInfo()
+			if !ri.update(r) {
+				t.Fatal("This registration should be good")
+			}
+			if out := string(ri.format.Format(&eventIn)); out != tt.expected {
+				t.Errorf("expected %q, found %q", tt.expected, out)
+			}
+		})
+	}
+
+	options.byName[r.Name] = registrationOptions{optionCSV: `{"quote":"invalid"}`}
+	if newRegistrationInfo().update(r) {
+		t.Error("Registration with invalid CSV settings")
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	r := validRegistration()
+	r.Name = "template"
+	options.byName[r.Name] = registrationOptions{optionTemplate: `{"id":{{json .Device}},"value":{{value . "sensor1"}}}`}
+	defer forgetOptions(r.Name)
+
+	ri := newRegistrationInfo()
+	if !ri.update(r) {
+		t.Fatal("This registration should be good")
+	}
+	if _, ok := ri.format.(templateFormatter); !ok {
+		t.Fatalf("expected the template to take the place of the format, found %T", ri.format)
+	}
+
+	eventIn := contract.Event{Device: devID1}
+	eventIn.Readings = append(eventIn.Readings, contract.Reading{Name: readingName1, Value: readingValue1})
+	if out := string(ri.format.Format(&eventIn)); out != `{"id":"id1","value":123.45}` {
+		t.Errorf("Invalid templated payload: %s", out)
+	}
+
+	options.byName[r.Name] = registrationOptions{optionTemplate: "{{.Unknown}}"}
+	if ri.update(r) {
+		t.Error("Registration with invalid template")
+	}
+}
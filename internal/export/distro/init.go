@@ -27,17 +27,20 @@ import (
 
 	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/startup"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
 var LoggingClient logger.LoggingClient
 var ec coredata.EventClient
+var vdc coredata.ValueDescriptorClient
 var Configuration *ConfigurationStruct
 var registryClient registry.Client
 var registryErrors chan error        //A channel for "config wait errors" sourced from Registry
 var registryUpdates chan interface{} //A channel for "config updates" sourced from Registry
 var messageClient messaging.MessageClient
+var busConnection readiness.Connection
 var messageErrors chan error
 var messageEnvelopes chan msgTypes.MessageEnvelope
 var processStop chan bool
@@ -91,12 +94,21 @@ func Init(useRegistry bool) bool {
 	var err error
 	messageErrors, messageEnvelopes, err = initMessaging(messageClient)
 	processStop = make(chan bool)
+	busConnection.Record(err)
 
 	if err != nil {
 		LoggingClient.Error(err.Error())
 		return false
 	}
 
+	if Configuration.Buffer.Enabled {
+		buffers, err = openBuffers(Configuration.Buffer)
+		if err != nil {
+			LoggingClient.Error(err.Error())
+			return false
+		}
+	}
+
 	go telemetry.StartCpuUsageAverage()
 
 	return true
@@ -123,6 +135,10 @@ func Destruct() {
 	if messageEnvelopes != nil {
 		close(messageEnvelopes)
 	}
+
+	if buffers != nil {
+		buffers.close()
+	}
 }
 
 func connectToRegistry(conf *ConfigurationStruct) error {
@@ -169,6 +185,10 @@ func initializeClients(useRegistry bool) {
 
 	ec = coredata.NewEventClient(params, startup.Endpoint{RegistryClient: &registryClient})
 
+	params.Path = clients.ApiValueDescriptorRoute
+	params.Url = Configuration.Clients["CoreData"].Url() + clients.ApiValueDescriptorRoute
+	vdc = coredata.NewValueDescriptorClient(params, startup.Endpoint{RegistryClient: &registryClient})
+
 	// Create the messaging client
 	var err error
 	messageClient, err = messaging.NewMessageClient(msgTypes.MessageBusConfig{
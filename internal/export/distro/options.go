@@ -0,0 +1,72 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package distro
+
+import (
+	"sync"
+)
+
+// Options export-client keeps apart from a registration, as its contract has no room for them
+const (
+	// Template taking the place of the format
+	optionTemplate = "template"
+	// Condition selecting the readings sent
+	optionExpression = "expression"
+	// Settings of the CSV format over those of the configuration
+	optionCSV = "csv"
+)
+
+var optionNames = []string{optionTemplate, optionExpression, optionCSV}
+
+// The options a registration has, by name
+type registrationOptions map[string]string
+
+// The options of the registrations by registration name
+var options = struct {
+	mutex  sync.Mutex
+	byName map[string]registrationOptions
+}{byName: make(map[string]registrationOptions)}
+
+// Fetch the options of a registration before it starts or is updated
+func loadOptions(name string) error {
+	o := registrationOptions{}
+	for _, option := range optionNames {
+		text, found, err := getOption(name, option)
+		if err != nil {
+			return err
+		}
+		if found {
+			o[option] = text
+		}
+	}
+
+	options.mutex.Lock()
+	defer options.mutex.Unlock()
+	options.byName[name] = o
+	return nil
+}
+
+func forgetOptions(name string) {
+	options.mutex.Lock()
+	defer options.mutex.Unlock()
+	delete(options.byName, name)
+}
+
+func optionOf(name string, option string) (string, bool) {
+	options.mutex.Lock()
+	defer options.mutex.Unlock()
+	text, ok := options.byName[name][option]
+	return text, ok
+}
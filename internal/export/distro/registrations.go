@@ -11,10 +11,6 @@
 
 package distro
 
-// TODO:
-// - Event buffer management per sender(do not block distro.Loop on full
-//   registration channel)
-
 import (
 	"context"
 	"fmt"
@@ -26,6 +22,13 @@ import (
 	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
 
 	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+
+	"github.com/edgexfoundry/edgex-go/internal"
+	"github.com/edgexfoundry/edgex-go/internal/export/csvformat"
+	"github.com/edgexfoundry/edgex-go/internal/export/template"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/eventpb"
 )
 
 const (
@@ -46,8 +49,22 @@ type registrationInfo struct {
 
 	chRegistration chan *contract.Registration
 	chMessages     chan msgTypes.MessageEnvelope
+	// Applied by Loop when chMessages is full
+	overflow string
+	// A slot per message being processed
+	workers  chan struct{}
+	counters distributionCounters
+
+	// Messages that failed to send, forwarded when retry fires while it is not nil
+	buffer  *queue
+	retry   <-chan time.Time
+	backoff time.Duration
+	// Signals the loop that a worker queued a message
+	chQueued chan struct{}
 
 	deleteFlag bool
+	// Set before the loop is stopped when the registration was deleted, so that its queue goes too
+	removed bool
 }
 
 func RefreshRegistrations(update contract.NotifyUpdate) {
@@ -60,6 +77,9 @@ func newRegistrationInfo() *registrationInfo {
 
 	reg.chRegistration = make(chan *contract.Registration)
 	reg.chMessages = make(chan msgTypes.MessageEnvelope)
+	reg.workers = make(chan struct{}, 1)
+	reg.overflow = overflowBlock
+	reg.chQueued = make(chan struct{}, 1)
 	return reg
 }
 
@@ -81,7 +101,20 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 	case contract.FormatAWSJSON:
 		reg.format = awsFormatter{}
 	case contract.FormatCSV:
-		// TODO reg.format = distro.NewCsvFormat()
+		settings := Configuration.CSV
+		if text, ok := optionOf(newReg.Name, optionCSV); ok {
+			var err error
+			if settings, err = csvformat.Parse(text, settings); err != nil {
+				LoggingClient.Warn(fmt.Sprintf("CSV settings of registration %s not valid: %s", newReg.Name, err.Error()))
+				return false
+			}
+		}
+		f, err := newCSVFormatter(settings)
+		if err != nil {
+			LoggingClient.Warn(err.Error())
+			return false
+		}
+		reg.format = f
 	case contract.FormatThingsBoardJSON:
 		reg.format = thingsboardJSONFormatter{}
 	case contract.FormatNOOP:
@@ -90,6 +123,14 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 		LoggingClient.Warn(fmt.Sprintf("Format not supported: %s", newReg.Format))
 		return false
 	}
+	if text, ok := optionOf(newReg.Name, optionTemplate); ok {
+		t, err := template.Parse(text)
+		if err != nil {
+			LoggingClient.Warn(fmt.Sprintf("Template of registration %s not valid: %s", newReg.Name, err.Error()))
+			return false
+		}
+		reg.format = templateFormatter{t}
+	}
 
 	reg.compression = nil
 	switch newReg.Compression {
@@ -161,17 +202,26 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 		LoggingClient.Debug(fmt.Sprintf("Value descriptor filter added: %s", newReg.Filter.ValueDescriptorIDs))
 	}
 
+	if text, ok := optionOf(newReg.Name, optionExpression); ok {
+		f, err := newExpressionFilter(text)
+		if err != nil {
+			LoggingClient.Warn(fmt.Sprintf("Expression of registration %s not valid: %s", newReg.Name, err.Error()))
+			return false
+		}
+		reg.filter = append(reg.filter, f)
+		LoggingClient.Debug(fmt.Sprintf("Expression filter added: %s", text))
+	}
+
 	return true
 }
 
-func (reg registrationInfo) processMessage(msg msgTypes.MessageEnvelope) {
+func (reg *registrationInfo) processMessage(msg msgTypes.MessageEnvelope) {
 	var err error
-	ctx := context.WithValue(context.Background(), clients.CorrelationHeader, msg.CorrelationID)
 	switch msg.ContentType {
 	case clients.ContentTypeJSON:
-		err = reg.handleJSON(msg, ctx)
-	case clients.ContentTypeCBOR:
-		err = reg.handleCBOR(msg, ctx)
+		err = reg.handleJSON(msg)
+	case clients.ContentTypeCBOR, internal.ContentTypeMsgPack, internal.ContentTypeProtobuf:
+		err = reg.handleBinary(msg)
 	default:
 		err = errors.Errorf("unsupported %s provided: %s", clients.ContentType, msg.ContentType)
 	}
@@ -183,7 +233,7 @@ func (reg registrationInfo) processMessage(msg msgTypes.MessageEnvelope) {
 	LoggingClient.Debug(fmt.Sprintf("Sent event with registration: %s", reg.registration.Name))
 }
 
-func (reg registrationInfo) handleJSON(msg msgTypes.MessageEnvelope, ctx context.Context) (err error) {
+func (reg *registrationInfo) handleJSON(msg msgTypes.MessageEnvelope) (err error) {
 	str := string(msg.Payload)
 	event := parseEvent(str)
 	if event == nil {
@@ -215,33 +265,235 @@ func (reg registrationInfo) handleJSON(msg msgTypes.MessageEnvelope, ctx context
 	if reg.encrypt != nil {
 		bytes = reg.encrypt.Transform(compressed)
 	}
-	if reg.sender.Send(bytes, ctx) && Configuration.Writable.MarkPushed {
-		return ec.MarkPushed(event.ID, ctx)
+	return reg.send(bufferedMessage{Payload: bytes, CorrelationID: msg.CorrelationID, EventID: event.ID})
+}
+
+// Binary payloads are sent as they were posted to core-data, along with their content type, rather
+// than formatted. They are decoded for the filters of the registration, encoded again once readings
+// were filtered out, then compressed and encrypted as JSON events are.
+func (reg *registrationInfo) handleBinary(msg msgTypes.MessageEnvelope) (err error) {
+	payload := msg.Payload
+	if len(reg.filter) > 0 {
+		e, err := decodeBinaryEvent(msg.Payload, msg.ContentType)
+		if err != nil {
+			return errors.Wrap(err, "unable to decode "+msg.ContentType+" event")
+		}
+
+		data := &e
+		n := len(data.Readings)
+		for _, f := range reg.filter {
+			var accepted bool
+			accepted, data = f.Filter(data)
+			if !accepted {
+				LoggingClient.Debug("Event filtered " + e.ID)
+				return nil
+			}
+		}
+		if len(data.Readings) != n {
+			if payload, err = encodeBinaryEvent(*data, msg.ContentType); err != nil {
+				return errors.Wrap(err, "unable to encode "+msg.ContentType+" event")
+			}
+		}
 	}
-	return
+
+	if reg.compression != nil {
+		payload = reg.compression.Transform(payload)
+	}
+	if reg.encrypt != nil {
+		payload = reg.encrypt.Transform(payload)
+	}
+	// The checksum still identifies the event stored by core-data to mark it pushed
+	return reg.send(bufferedMessage{
+		Payload:       payload,
+		ContentType:   msg.ContentType,
+		CorrelationID: msg.CorrelationID,
+		Checksum:      msg.Checksum,
+	})
 }
 
-func (reg registrationInfo) handleCBOR(msg msgTypes.MessageEnvelope, ctx context.Context) (err error) {
-	ctxPublish := context.WithValue(ctx, clients.ContentType, msg.ContentType)
-	if reg.sender.Send(msg.Payload, ctxPublish) && Configuration.Writable.MarkPushed {
-		//CBOR content type not included here because we don't need that when calling back to core-data
-		return ec.MarkPushedByChecksum(msg.Checksum, ctx)
+func decodeBinaryEvent(payload []byte, contentType string) (contract.Event, error) {
+	var e contract.Event
+	var err error
+	switch contentType {
+	case clients.ContentTypeCBOR:
+		err = codec.NewDecoderBytes(payload, &codec.CborHandle{}).Decode(&e)
+	case internal.ContentTypeMsgPack:
+		err = codec.NewDecoderBytes(payload, &codec.MsgpackHandle{}).Decode(&e)
+	case internal.ContentTypeProtobuf:
+		err = eventpb.Unmarshal(payload, &e)
+	default:
+		err = errors.Errorf("unsupported %s provided: %s", clients.ContentType, contentType)
 	}
-	return
+	return e, err
+}
+
+func encodeBinaryEvent(e contract.Event, contentType string) ([]byte, error) {
+	var h codec.Handle
+	switch contentType {
+	case clients.ContentTypeCBOR:
+		h = &codec.CborHandle{}
+	case internal.ContentTypeMsgPack:
+		h = &codec.MsgpackHandle{}
+	case internal.ContentTypeProtobuf:
+		return eventpb.Marshal(e), nil
+	default:
+		return nil, errors.Errorf("unsupported %s provided: %s", clients.ContentType, contentType)
+	}
+
+	var bytes []byte
+	err := codec.NewEncoderBytes(&bytes, h).Encode(e)
+	return bytes, err
+}
+
+// Send a message, queueing it when sending fails or while older messages wait to be forwarded
+func (reg *registrationInfo) send(m bufferedMessage) error {
+	if reg.buffer != nil && reg.buffer.len() > 0 {
+		return reg.queue(m)
+	}
+
+	sent, err := reg.deliver(m)
+	if !sent && reg.buffer != nil {
+		return reg.queue(m)
+	}
+	return err
+}
+
+// Send a message to the destination, marking its event pushed once sent
+func (reg *registrationInfo) deliver(m bufferedMessage) (sent bool, err error) {
+	ctx := context.WithValue(context.Background(), clients.CorrelationHeader, m.CorrelationID)
+	ctxPublish := ctx
+	if m.ContentType != "" {
+		ctxPublish = context.WithValue(ctx, clients.ContentType, m.ContentType)
+	}
+	if !reg.sender.Send(m.Payload, ctxPublish) {
+		return false, nil
+	}
+
+	if Configuration.Writable.MarkPushed {
+		if m.EventID != "" {
+			return true, ec.MarkPushed(m.EventID, ctx)
+		}
+		//Binary content type not included here because we don't need that when calling back to core-data
+		return true, ec.MarkPushedByChecksum(m.Checksum, ctx)
+	}
+	return true, nil
+}
+
+func (reg *registrationInfo) queue(m bufferedMessage) error {
+	m.Created = db.MakeTimestamp()
+	if err := reg.buffer.push(m); err != nil {
+		return errors.Wrap(err, "could not queue the event of registration "+reg.registration.Name)
+	}
+	select {
+	case reg.chQueued <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Schedule the next forwarding of the queued messages, backing off exponentially after failures
+func (reg *registrationInfo) scheduleRetry(failed bool) {
+	switch {
+	case !failed:
+		reg.backoff = 0
+	case reg.backoff == 0:
+		reg.backoff = bufferInterval(Configuration.Buffer.RetryInterval, defaultRetryInterval)
+	default:
+		reg.backoff *= 2
+		if max := bufferInterval(Configuration.Buffer.MaxRetryInterval, defaultMaxRetryInterval); reg.backoff > max {
+			reg.backoff = max
+		}
+	}
+
+	if reg.backoff == 0 {
+		reg.retry = time.After(bufferInterval(Configuration.Buffer.RetryInterval, defaultRetryInterval))
+	} else {
+		reg.retry = time.After(reg.backoff)
+	}
+}
+
+// Send the queued messages in order, up to a batch at a time so that new messages are queued
+// meanwhile, and stop at the first one that fails to send
+func (reg *registrationInfo) forward() {
+	reg.retry = nil
+	for i := 0; i < forwardBatch; i++ {
+		key, m, ok, err := reg.buffer.front()
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("could not read the queue of registration %s: %s", reg.registration.Name, err.Error()))
+			reg.scheduleRetry(true)
+			return
+		}
+		if !ok {
+			if reg.backoff > 0 {
+				LoggingClient.Info(fmt.Sprintf("Registration %s forwarded its queued events", reg.registration.Name))
+			}
+			reg.backoff = 0
+			return
+		}
+
+		sent, err := reg.deliver(m)
+		if !sent {
+			reg.scheduleRetry(true)
+			return
+		}
+		if err != nil {
+			LoggingClient.Error(err.Error())
+		}
+		if err = reg.buffer.remove(key); err != nil {
+			LoggingClient.Error(fmt.Sprintf("could not remove an event from the queue of registration %s: %s", reg.registration.Name, err.Error()))
+		}
+	}
+	reg.retry = time.After(0)
 }
 
 func registrationLoop(reg *registrationInfo) {
 	LoggingClient.Info(fmt.Sprintf("registration loop started: %s", reg.registration.Name))
+	trackRegistration(reg)
+	defer untrackRegistration(reg)
+	if buffers != nil {
+		q, err := buffers.queue(reg.registration.Name)
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("events of registration %s will not be queued: %s", reg.registration.Name, err.Error()))
+		} else {
+			reg.buffer = q
+			if q.len() > 0 {
+				LoggingClient.Info(fmt.Sprintf("Registration %s has %d queued events to forward", reg.registration.Name, q.len()))
+				reg.retry = time.After(0)
+			}
+		}
+	}
+
 	for {
 		select {
 		case msg := <-reg.chMessages:
 			if reg.registration.Enable {
-				reg.processMessage(msg)
+				reg.dispatch(msg)
+			}
+
+		case <-reg.chQueued:
+			if reg.retry == nil {
+				reg.scheduleRetry(false)
+			}
+
+		case <-reg.retry:
+			// Queued messages go out in order, with no worker sending meanwhile
+			reg.wait()
+			if reg.registration.Enable {
+				reg.forward()
+			} else {
+				reg.scheduleRetry(false)
 			}
 
 		case newReg := <-reg.chRegistration:
+			reg.wait()
 			if newReg == nil {
 				LoggingClient.Info("Terminating registration goroutine")
+				// No worker is left to queue a message
+				if reg.removed && reg.buffer != nil {
+					if err := buffers.remove(string(reg.buffer.name)); err != nil {
+						LoggingClient.Error(fmt.Sprintf("could not remove the queue of registration %s: %s", reg.registration.Name, err.Error()))
+					}
+				}
 				return
 			} else {
 				if reg.update(*newReg) {
@@ -263,8 +515,10 @@ func updateRunningRegistrations(running map[string]*registrationInfo,
 	case contract.NotifyUpdateDelete:
 		for k, v := range running {
 			if k == update.Name {
+				v.removed = true
 				v.chRegistration <- nil
 				delete(running, k)
+				forgetOptions(k)
 				return nil
 			}
 		}
@@ -274,6 +528,9 @@ func updateRunningRegistrations(running map[string]*registrationInfo,
 		if reg == nil {
 			return fmt.Errorf("Could not find registration")
 		}
+		if err := loadOptions(reg.Name); err != nil {
+			return err
+		}
 		for k, v := range running {
 			if k == update.Name {
 				v.chRegistration <- reg
@@ -286,7 +543,11 @@ func updateRunningRegistrations(running map[string]*registrationInfo,
 		if reg == nil {
 			return fmt.Errorf("Could not find registration")
 		}
+		if err := loadOptions(reg.Name); err != nil {
+			return err
+		}
 		regInfo := newRegistrationInfo()
+		regInfo.setPolicy(distributionPolicy(reg.Name))
 		if regInfo.update(*reg) {
 			running[reg.Name] = regInfo
 			go registrationLoop(regInfo)
@@ -328,7 +589,12 @@ func Loop() {
 
 	// Create new goroutines for each registration
 	for _, reg := range allRegs {
+		if err := loadOptions(reg.Name); err != nil {
+			LoggingClient.Error(fmt.Sprintf("Could not get the options of registration %s: %s", reg.Name, err.Error()))
+			continue
+		}
 		regInfo := newRegistrationInfo()
+		regInfo.setPolicy(distributionPolicy(reg.Name))
 		if regInfo.update(reg) {
 			registrations[reg.Name] = regInfo
 			go registrationLoop(regInfo)
@@ -339,6 +605,7 @@ func Loop() {
 	for {
 		select {
 		case e := <-messageErrors:
+			busConnection.Record(e)
 			// kill all registration goroutines
 			stop(registrations)
 			LoggingClient.Error(fmt.Sprintf("exit msg: %s", e.Error()))
@@ -365,7 +632,7 @@ func Loop() {
 				if reg.deleteFlag {
 					delete(registrations, k)
 				} else {
-					reg.chMessages <- msgEnvelope
+					reg.enqueue(msgEnvelope)
 				}
 			}
 		}
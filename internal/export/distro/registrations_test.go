@@ -7,11 +7,14 @@
 package distro
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"testing"
 
+	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/eventpb"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
@@ -190,9 +193,13 @@ func TestRegistrationInfoLoop(t *testing.T) {
 	msg1 := msgTypes.MessageEnvelope{ContentType: clients.ContentTypeCBOR, CorrelationID: uuid.New().String(),
 		Payload: data, Checksum: "1234567890"}
 
+	msg2 := msgTypes.MessageEnvelope{ContentType: internal.ContentTypeProtobuf, CorrelationID: uuid.New().String(),
+		Payload: eventpb.Marshal(e1.Event), Checksum: "0987654321"}
+
 	go func() {
 		ri.chMessages <- msgTypes.MessageEnvelope{}
 		ri.chMessages <- msg1
+		ri.chMessages <- msg2
 		ri.chRegistration <- nil
 	}()
 	ri.format = &dummyStruct{}
@@ -200,10 +207,78 @@ func TestRegistrationInfoLoop(t *testing.T) {
 	ri.encrypt = &dummyStruct{}
 	ri.compression = &dummyStruct{}
 	ri.filter = nil
-	// Process two events (one JSON, one CBOR) and terminate
+	// Process three events (one JSON, one CBOR, one Protobuf) and terminate
 	registrationLoop(ri)
 }
 
+// Records the payloads sent
+type recordingSender struct {
+	payloads [][]byte
+}
+
+func (sender *recordingSender) Send(data []byte, ctx context.Context) bool {
+	sender.payloads = append(sender.payloads, data)
+	return true
+}
+
+// Prefixes the payloads, for the test to tell they were transformed
+type prefixTransformer string
+
+func (p prefixTransformer) Transform(data []byte) []byte {
+	return append([]byte(p), data...)
+}
+
+func TestRegistrationInfoBinary(t *testing.T) {
+	const dummyDev = "dummyDev"
+
+	sender := &recordingSender{}
+	ri := newRegistrationInfo()
+	ri.sender = sender
+	ri.compression = prefixTransformer("compressed:")
+	ri.encrypt = prefixTransformer("encrypted:")
+	ri.filter = []filterer{
+		newDevIdFilter(contract.Filter{DeviceIDs: []string{dummyDev}}),
+		newValueDescFilter(contract.Filter{ValueDescriptorIDs: []string{"Temperature"}}),
+	}
+
+	temperature := contract.Reading{Name: "Temperature", Value: "20"}
+	humidity := contract.Reading{Name: "Humidity", Value: "50"}
+
+	// Filtered out by device
+	var filteredOut []byte
+	codec.NewEncoderBytes(&filteredOut, &codec.CborHandle{}).Encode(contract.Event{Device: "filterOutDev", Readings: []contract.Reading{temperature}})
+	ri.processMessage(msgTypes.MessageEnvelope{ContentType: clients.ContentTypeCBOR, Payload: filteredOut})
+
+	// Sent without the humidity reading
+	ri.processMessage(msgTypes.MessageEnvelope{ContentType: internal.ContentTypeProtobuf,
+		Payload: eventpb.Marshal(contract.Event{Device: dummyDev, Readings: []contract.Reading{temperature, humidity}})})
+
+	// Sent as posted
+	var unchanged []byte
+	codec.NewEncoderBytes(&unchanged, &codec.MsgpackHandle{}).Encode(contract.Event{Device: dummyDev, Readings: []contract.Reading{temperature}})
+	ri.processMessage(msgTypes.MessageEnvelope{ContentType: internal.ContentTypeMsgPack, Payload: unchanged})
+
+	if len(sender.payloads) != 2 {
+		t.Fatalf("expected two events to be sent, got %d", len(sender.payloads))
+	}
+	for _, p := range sender.payloads {
+		if !bytes.HasPrefix(p, []byte("encrypted:compressed:")) {
+			t.Errorf("expected the event to be compressed then encrypted, got %q", p)
+		}
+	}
+
+	var e contract.Event
+	if err := eventpb.Unmarshal(bytes.TrimPrefix(sender.payloads[0], []byte("encrypted:compressed:")), &e); err != nil {
+		t.Fatalf("unexpected error decoding the filtered event: %s", err.Error())
+	}
+	if len(e.Readings) != 1 || e.Readings[0].Name != "Temperature" {
+		t.Errorf("expected only the temperature reading to be sent, got %v", e.Readings)
+	}
+	if !bytes.Equal(sender.payloads[1], append([]byte("encrypted:compressed:"), unchanged...)) {
+		t.Errorf("expected the event to be sent as posted, got %q", sender.payloads[1])
+	}
+}
+
 func TestUpdateRunningRegistrations(t *testing.T) {
 	running := make(map[string]*registrationInfo)
 
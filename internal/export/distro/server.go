@@ -18,6 +18,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
@@ -65,7 +66,15 @@ func replyNotifyRegistrations(w http.ResponseWriter, r *http.Request) {
 }
 
 func metricsHandler(w http.ResponseWriter, _ *http.Request) {
-	s := telemetry.NewSystemUsage()
+	s := struct {
+		telemetry.SystemUsage
+		Buffers       map[string]bufferStats
+		Registrations map[string]distributionStats
+	}{
+		SystemUsage:   telemetry.NewSystemUsage(),
+		Buffers:       buffers.stats(),
+		Registrations: distributionMetrics(),
+	}
 
 	encode(s, w)
 
@@ -99,6 +108,9 @@ func httpServer() http.Handler {
 	// Metrics
 	r.HandleFunc(clients.ApiMetricsRoute, metricsHandler).Methods(http.MethodGet)
 
+	// Readiness
+	r.HandleFunc(readiness.Route, readiness.Handler(map[string]readiness.Check{"messageBus": &busConnection})).Methods(http.MethodGet)
+
 	r.HandleFunc(clients.ApiNotifyRegistrationRoute, replyNotifyRegistrations).Methods(http.MethodPut)
 
 	r.Use(correlation.ManageHeader)
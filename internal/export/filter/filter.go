@@ -0,0 +1,146 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package filter selects the readings sent by export registrations with a condition written in the
+// syntax of internal/pkg/expression, so that a registration only ships the readings that matter.
+//
+// The condition is evaluated for every reading of an event, with these variables:
+//
+//	value   the value of the reading, a number or a boolean when it reads as one, else a string
+//	name    the name of the reading
+//	device  the device of the reading, or of its event
+//	origin  the origin of the reading, or of its event, in milliseconds
+//	age     milliseconds elapsed since the origin
+//	labels  the labels of the value descriptor of the reading, a list for contains
+//
+// For example: (name == 'Temperature' && (value < 5 || value > 30)) || contains(labels, 'alarm')
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/expression"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Filter is a parsed condition, safe for concurrent use
+type Filter struct {
+	x *expression.Expression
+}
+
+// Labels looks up the labels of the value descriptor a reading is named after
+type Labels func(name string) ([]string, error)
+
+var variables = map[string]bool{
+	"value":  true,
+	"name":   true,
+	"device": true,
+	"origin": true,
+	"age":    true,
+	"labels": true,
+}
+
+// Reading a condition is evaluated against to be validated
+var sample = contract.Reading{Name: "reading", Device: "device", Value: "1", Origin: 1}
+
+// Parse a condition, rejecting the names which are not variables of a reading and the expressions
+// which do not give a boolean
+func Parse(source string) (*Filter, error) {
+	x, err := expression.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range x.Variables() {
+		if !variables[name] {
+			return nil, fmt.Errorf("unknown variable '%s'", name)
+		}
+	}
+
+	// Whether a comparison holds depends on the reading, but its result is a boolean for all of them
+	vars := readingVariables(&contract.Event{}, sample, func(string) ([]string, error) { return nil, nil }, time.Now(), nil)
+	if v, err := x.Eval(vars); err == nil {
+		if _, ok := v.(bool); !ok {
+			return nil, fmt.Errorf("'%s' is not a condition", source)
+		}
+	}
+	return &Filter{x: x}, nil
+}
+
+func (f *Filter) String() string {
+	return f.x.String()
+}
+
+// Match tells whether a reading of an event meets the condition at a given time
+// The labels are only looked up when the condition refers to them.
+func (f *Filter) Match(event *contract.Event, reading contract.Reading, labels Labels, now time.Time) (bool, error) {
+	var lookupErr error
+	match, err := f.x.Bool(readingVariables(event, reading, labels, now, &lookupErr))
+	if lookupErr != nil {
+		return false, lookupErr
+	}
+	return match, err
+}
+
+func readingVariables(event *contract.Event, reading contract.Reading, labels Labels, now time.Time, lookupErr *error) expression.Variables {
+	return func(name string) (interface{}, bool) {
+		switch name {
+		case "value":
+			return valueOf(reading.Value), true
+		case "name":
+			return reading.Name, true
+		case "device":
+			if reading.Device != "" {
+				return reading.Device, true
+			}
+			return event.Device, true
+		case "origin":
+			return float64(originOf(event, reading)), true
+		case "age":
+			ms := now.UnixNano() / int64(time.Millisecond)
+			return float64(ms - originOf(event, reading)), true
+		case "labels":
+			l, err := labels(reading.Name)
+			if err != nil {
+				if lookupErr != nil {
+					*lookupErr = err
+				}
+				return nil, false
+			}
+			return l, true
+		}
+		return nil, false
+	}
+}
+
+func valueOf(s string) interface{} {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return s
+}
+
+func originOf(event *contract.Event, reading contract.Reading) int64 {
+	if reading.Origin != 0 {
+		return reading.Origin
+	}
+	return event.Origin
+}
@@ -0,0 +1,108 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Unix(1559826000, 0)
+	event := contract.Event{Device: "thermostat", Origin: 1559826000000 - 5000}
+	temperature := contract.Reading{Name: "Temperature", Value: "31.5", Origin: 1559826000000 - 500}
+	state := contract.Reading{Name: "State", Value: "overheating", Device: "sensor"}
+	alarm := contract.Reading{Name: "Alarm", Value: "true"}
+	labels := func(name string) ([]string, error) {
+		if name == "Temperature" {
+			return []string{"celsius", "alarm"}, nil
+		}
+		return nil, nil
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		reading   contract.Reading
+		expected  bool
+	}{
+		{"threshold", "value > 30", temperature, true},
+		{"range", "value >= 5 && value <= 30", temperature, false},
+		{"regex", "matches(value, '^over')", state, true},
+		{"boolean", "value == true", alarm, true},
+		{"name", "name == 'Temperature' && value > 30", state, false},
+		{"label", "contains(labels, 'alarm')", temperature, true},
+		{"no labels", "contains(labels, 'alarm')", state, false},
+		{"reading age", "age < 1000", temperature, true},
+		{"event age", "age < 1000", alarm, false},
+		{"event device", "device == 'thermostat'", temperature, true},
+		{"reading device", "device == 'thermostat'", state, false},
+		{"combination", "(name == 'Temperature' && age < 1000) || matches(value, 'heat')", state, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.condition)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			match, err := f.Match(&event, tt.reading, labels, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if match != tt.expected {
+				t.Errorf("expected %t, found %t", tt.expected, match)
+			}
+		})
+	}
+}
+
+func TestMatchLabelsError(t *testing.T) {
+	f, err := Parse("value > 30 || contains(labels, 'alarm')")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lookup := errors.New("value descriptor unavailable")
+	labels := func(string) ([]string, error) { return nil, lookup }
+
+	// The labels are only looked up when the rest of the condition does not decide it
+	if match, err := f.Match(&contract.Event{}, contract.Reading{Value: "40"}, labels, time.Now()); err != nil || !match {
+		t.Errorf("expected a match, found %t, %v", match, err)
+	}
+	if _, err := f.Match(&contract.Event{}, contract.Reading{Value: "20"}, labels, time.Now()); err != lookup {
+		t.Errorf("expected the lookup error, found %v", err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+	}{
+		{"syntax", "value >"},
+		{"variable", "temperature > 30"},
+		{"pattern", "matches(value, '[')"},
+		{"not a condition", "value + 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.condition); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
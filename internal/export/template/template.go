@@ -0,0 +1,130 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package template renders the events sent by export registrations with Go text/templates, so that
+// a destination expecting its own payload does not need a formatter of its own.
+//
+// The template is executed with the contract.Event as its data, along with these functions:
+//
+//	json v                 v encoded as JSON, quoted and escaped for a string
+//	reading . name         the first reading of the event with the name, empty if there is none
+//	readings . name        all the readings of the event with the name
+//	value . name           the value of the first reading with the name
+//	timestamp ms [layout]  a timestamp in milliseconds formatted in UTC, RFC 3339 by default
+//	seconds ms             a timestamp in milliseconds as seconds since the epoch
+//
+// For example: {"id":{{json .Device}},"temperature":{{value . "Temperature"}}}
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Template is a parsed template, safe for concurrent use
+type Template struct {
+	t *template.Template
+}
+
+var funcs = template.FuncMap{
+	"json":      toJSON,
+	"reading":   reading,
+	"readings":  readings,
+	"value":     value,
+	"timestamp": timestamp,
+	"seconds":   seconds,
+}
+
+// Event a template is executed against to be validated, with a reading of every field set
+var sample = contract.Event{
+	ID:       "sample",
+	Device:   "device",
+	Origin:   1,
+	Created:  1,
+	Modified: 1,
+	Readings: []contract.Reading{{Id: "sample", Device: "device", Name: "reading", Value: "1", Origin: 1, Created: 1, Modified: 1}},
+}
+
+// Parse a template and execute it against a sample event, so that a template which cannot render
+// an event is rejected up front rather than on every event
+func Parse(text string) (*Template, error) {
+	t, err := template.New("registration").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := &Template{t: t}
+	if _, err = tpl.Execute(&sample); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Execute the template against an event
+func (tpl *Template) Execute(event *contract.Event) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tpl.t.Execute(&b, event); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func reading(event *contract.Event, name string) contract.Reading {
+	for _, r := range event.Readings {
+		if r.Name == name {
+			return r
+		}
+	}
+	return contract.Reading{}
+}
+
+func readings(event *contract.Event, name string) []contract.Reading {
+	var found []contract.Reading
+	for _, r := range event.Readings {
+		if r.Name == name {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+func value(event *contract.Event, name string) string {
+	return reading(event, name).Value
+}
+
+func timestamp(ms int64, layout ...string) (string, error) {
+	t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+	switch len(layout) {
+	case 0:
+		return t.Format(time.RFC3339Nano), nil
+	case 1:
+		return t.Format(layout[0]), nil
+	default:
+		return "", fmt.Errorf("timestamp takes a single layout, found %d", len(layout))
+	}
+}
+
+func seconds(ms int64) int64 {
+	return ms / 1000
+}
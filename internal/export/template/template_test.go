@@ -0,0 +1,78 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package template
+
+import (
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestExecute(t *testing.T) {
+	event := contract.Event{Device: `sensor "A"`, Origin: 1559826000123}
+	event.Readings = []contract.Reading{
+		{Name: "Temperature", Value: "21.5", Origin: 1559826000000},
+		{Name: "Humidity", Value: "40"},
+		{Name: "Temperature", Value: "21.7"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"fields", "{{.Device}} {{len .Readings}}", `sensor "A" 3`},
+		{"json", `{"device":{{json .Device}}}`, `{"device":"sensor \"A\""}`},
+		{"value", `{{value . "Humidity"}} {{value . "Pressure"}}`, "40 "},
+		{"reading", `{{with reading . "Temperature"}}{{.Value}}@{{seconds .Origin}}{{end}}`, "21.5@1559826000"},
+		{"readings", `{{range readings . "Temperature"}}{{.Value}};{{end}}`, "21.5;21.7;"},
+		{"timestamp", `{{timestamp .Origin}}`, "2019-06-06T13:00:00.123Z"},
+		{"timestamp layout", `{{timestamp .Origin "2006-01-02 15:04"}}`, "2019-06-06 13:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := Parse(tt.template)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			out, err := tpl.Execute(&event)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("expected %q, found %q", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"syntax", "{{.Device"},
+		{"function", "{{unknown .Device}}"},
+		{"field", "{{.Unknown}}"},
+		{"arguments", `{{timestamp .Origin "2006" "01"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.template); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package archive reads and writes the files events are moved with between sites without a network.
+//
+// An archive is gzip compressed. It holds the events one after the other, either as newline
+// delimited JSON or as a CBOR sequence, followed by the readings that belong to no event, each
+// wrapped as {"reading": ...}. It ends with a trailer: a line of JSON giving the number of events and
+// readings and the SHA-256 checksum of everything before the trailer. A CBOR sequence is followed
+// by a newline so that the trailer starts a line. An archive cut short has no valid trailer,
+// so it is rejected as a whole rather than read in part.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/ugorji/go/codec"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/compression"
+)
+
+const (
+	FormatNDJSON = "ndjson"
+	FormatCBOR   = "cbor"
+
+	// Version of the archive layout written in the trailer
+	// Version 2 added the readings that belong to no event
+	Version = 2
+)
+
+// ErrChecksum is returned when the events of an archive do not match its trailer
+var ErrChecksum = errors.New("archive: checksum mismatch")
+
+// ErrNoTrailer is returned for an archive that does not end with a trailer, e.g. one cut short
+var ErrNoTrailer = errors.New("archive: missing trailer")
+
+// ErrTooLarge is returned for an archive larger than the limit once decompressed
+var ErrTooLarge = errors.New("archive: too large")
+
+// Trailer closes an archive, describing the events before it
+type Trailer struct {
+	Version  int    `json:"version"`
+	Format   string `json:"format"`
+	Count    int    `json:"count"`
+	Readings int    `json:"readings,omitempty"`
+	SHA256   string `json:"sha256"`
+}
+
+// A reading on its own, told apart from the events as it has a reading field
+type readingRecord struct {
+	Reading *contract.Reading `json:"reading" codec:"reading"`
+}
+
+// Writer writes events to an archive
+type Writer struct {
+	gzip     *gzip.Writer
+	hash     hash.Hash
+	out      io.Writer
+	format   string
+	encoder  *codec.Encoder
+	count    int
+	readings int
+}
+
+// NewWriter starts an archive of the given format on w
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	if format != FormatNDJSON && format != FormatCBOR {
+		return nil, fmt.Errorf("archive: unsupported format '%s'", format)
+	}
+
+	var c compression.Gzip
+	aw := &Writer{gzip: c.Writer(w), hash: sha256.New(), format: format}
+	aw.out = io.MultiWriter(aw.gzip, aw.hash)
+	if format == FormatCBOR {
+		aw.encoder = codec.NewEncoder(aw.out, &codec.CborHandle{})
+	}
+	return aw, nil
+}
+
+// Write adds an event to the archive
+func (w *Writer) Write(e contract.Event) error {
+	if w.readings > 0 {
+		return errors.New("archive: events are written before the readings")
+	}
+	if err := w.encode(e); err != nil {
+		return err
+	}
+
+	w.count++
+	return nil
+}
+
+// WriteReading adds a reading that belongs to no event, once all the events are written
+func (w *Writer) WriteReading(r contract.Reading) error {
+	if err := w.encode(readingRecord{Reading: &r}); err != nil {
+		return err
+	}
+
+	w.readings++
+	return nil
+}
+
+func (w *Writer) encode(v interface{}) error {
+	if w.encoder != nil {
+		return w.encoder.Encode(v)
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		_, err = w.out.Write(append(data, '\n'))
+	}
+	return err
+}
+
+// Count returns the number of events written so far
+func (w *Writer) Count() int {
+	return w.count
+}
+
+// ReadingCount returns the number of readings written so far
+func (w *Writer) ReadingCount() int {
+	return w.readings
+}
+
+// Close writes the trailer and flushes the archive, leaving the underlying writer open
+func (w *Writer) Close() error {
+	if w.format == FormatCBOR {
+		if _, err := w.out.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+
+	t := Trailer{Version: Version, Format: w.format, Count: w.count, Readings: w.readings, SHA256: hex.EncodeToString(w.hash.Sum(nil))}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if _, err = w.gzip.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return w.gzip.Close()
+}
+
+// Content is what an archive holds
+type Content struct {
+	Events   []contract.Event
+	Readings []contract.Reading
+}
+
+// Read reads a whole archive, decoding its events and readings as they are decompressed and
+// checking them against the trailer before returning them.
+// Archives larger than limit bytes once decompressed are rejected, a limit of 0 reading any size.
+func Read(r io.Reader, limit int64) (Content, Trailer, error) {
+	var c Content
+	var t Trailer
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return c, t, err
+	}
+	lr := &limitReader{r: gz, left: limit, limited: limit > 0}
+	in := &hashReader{r: bufio.NewReader(lr), hash: sha256.New()}
+
+	// The trailer giving the format comes last, so the format is told by the first byte:
+	// newline delimited JSON starts with a brace, be it that of the trailer, while a CBOR
+	// sequence starts with a map or with the newline ending an empty sequence
+	first, err := in.r.Peek(1)
+	if err == io.EOF {
+		return c, t, ErrNoTrailer
+	} else if err != nil {
+		return c, t, err
+	}
+
+	var line []byte
+	if first[0] == '{' {
+		line, err = readNDJSON(in, &c)
+	} else {
+		line, err = readCBOR(in, &c)
+	}
+	if lr.exceeded() {
+		// The decoder wraps the errors of the reader
+		return c, t, ErrTooLarge
+	}
+	if err != nil {
+		return c, t, err
+	}
+
+	if err = json.Unmarshal(line, &t); err != nil || t.Version == 0 {
+		return c, t, ErrNoTrailer
+	}
+	if t.Version > Version {
+		return c, t, fmt.Errorf("archive: unsupported version %d", t.Version)
+	}
+	if hex.EncodeToString(in.hash.Sum(nil)) != t.SHA256 {
+		return c, t, ErrChecksum
+	}
+	if (t.Format != FormatNDJSON && t.Format != FormatCBOR) || (first[0] == '{') != (t.Format == FormatNDJSON) {
+		return c, t, fmt.Errorf("archive: unsupported format '%s'", t.Format)
+	}
+	if len(c.Events) != t.Count || len(c.Readings) != t.Readings {
+		return c, t, fmt.Errorf("archive: %d events and %d readings found, %d and %d expected",
+			len(c.Events), len(c.Readings), t.Count, t.Readings)
+	}
+	return c, t, nil
+}
+
+// Decode the lines of newline delimited JSON, returning the last one which is the trailer.
+// A line is only hashed once the next one is read, so that the trailer is left out.
+func readNDJSON(in *hashReader, c *Content) ([]byte, error) {
+	var last []byte
+	for {
+		line, err := in.r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 || last == nil {
+				return nil, ErrNoTrailer
+			}
+			return last, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if last != nil {
+			in.hash.Write(last)
+			if err = decodeRecord(json.Unmarshal, last, c); err != nil {
+				return nil, err
+			}
+		}
+		last = line
+	}
+}
+
+// Decode a CBOR sequence up to the newline ending it, returning the trailer after it.
+// The sequence is decoded through in so that exactly the bytes decoded are hashed.
+func readCBOR(in *hashReader, c *Content) ([]byte, error) {
+	dec := codec.NewDecoder(in, &codec.CborHandle{})
+	for {
+		next, err := in.r.Peek(1)
+		if err == io.EOF {
+			return nil, ErrNoTrailer
+		} else if err != nil {
+			return nil, err
+		}
+		if next[0] == '\n' {
+			break
+		}
+
+		var raw codec.Raw
+		if err = dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		err = decodeRecord(func(data []byte, v interface{}) error {
+			return codec.NewDecoderBytes(data, &codec.CborHandle{}).Decode(v)
+		}, raw, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := in.ReadByte(); err != nil {
+		return nil, err
+	}
+	in.flush()
+	line, err := ioutil.ReadAll(in.r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[len(line)-1] != '\n' || bytes.IndexByte(line, '\n') != len(line)-1 {
+		return nil, ErrNoTrailer
+	}
+	return line, nil
+}
+
+// Decode an event, or a reading once the readings have started
+func decodeRecord(unmarshal func([]byte, interface{}) error, data []byte, c *Content) error {
+	var rr readingRecord
+	if err := unmarshal(data, &rr); err != nil {
+		return err
+	}
+	if rr.Reading != nil {
+		c.Readings = append(c.Readings, *rr.Reading)
+		return nil
+	}
+	if len(c.Readings) > 0 {
+		return errors.New("archive: event after the readings")
+	}
+
+	var e contract.Event
+	if err := unmarshal(data, &e); err != nil {
+		return err
+	}
+	c.Events = append(c.Events, e)
+	return nil
+}
+
+// hashReader hashes the bytes read through it. A byte read then unread, as the decoder does
+// when peeking, is only hashed once it is read for good.
+type hashReader struct {
+	r       *bufio.Reader
+	hash    hash.Hash
+	last    byte
+	pending bool
+}
+
+func (h *hashReader) flush() {
+	if h.pending {
+		h.hash.Write([]byte{h.last})
+		h.pending = false
+	}
+}
+
+func (h *hashReader) Read(p []byte) (int, error) {
+	h.flush()
+	n, err := h.r.Read(p)
+	h.hash.Write(p[:n])
+	return n, err
+}
+
+func (h *hashReader) ReadByte() (byte, error) {
+	h.flush()
+	b, err := h.r.ReadByte()
+	if err == nil {
+		h.last, h.pending = b, true
+	}
+	return b, err
+}
+
+func (h *hashReader) UnreadByte() error {
+	if !h.pending {
+		return errors.New("archive: no byte to unread")
+	}
+	h.pending = false
+	return h.r.UnreadByte()
+}
+
+// limitReader fails with ErrTooLarge once more than left bytes are read
+type limitReader struct {
+	r       io.Reader
+	left    int64
+	limited bool
+}
+
+func (l *limitReader) exceeded() bool {
+	return l.limited && l.left < 0
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if !l.limited {
+		return l.r.Read(p)
+	}
+	if l.left < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,142 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+var testEvents = []contract.Event{
+	{ID: "e1", Device: "thermostat", Created: 1000, Origin: 990, Readings: []contract.Reading{
+		{Id: "r1", Device: "thermostat", Name: "temperature", Value: "21.5", Created: 1000},
+	}},
+	{ID: "e2", Device: "camera", Created: 2000, Readings: []contract.Reading{
+		{Id: "r2", Device: "camera", Name: "snapshot", BinaryValue: []byte{0x0a, 0x00, 0xff}, Created: 2000},
+	}},
+}
+
+var testReadings = []contract.Reading{
+	{Id: "r3", Device: "thermostat", Name: "humidity", Value: "40", Created: 1500},
+}
+
+func writeArchive(t *testing.T, format string, events []contract.Event, readings ...contract.Reading) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, e := range events {
+		if err = w.Write(e); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, r := range readings {
+		if err = w.WriteReading(r); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatNDJSON, FormatCBOR} {
+		for _, content := range []Content{{testEvents, testReadings}, {testEvents, nil}, {nil, testReadings}, {}} {
+			data := writeArchive(t, format, content.Events, content.Readings...)
+
+			result, trailer, err := Read(bytes.NewReader(data), 0)
+			if err != nil {
+				t.Fatalf("%s: unexpected error %v", format, err)
+			}
+			// Decoding marks events as validated, so they are compared as JSON
+			expected, _ := json.Marshal(content)
+			actual, _ := json.Marshal(result)
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("%s: expected %v, got %v", format, content, result)
+			}
+			if trailer.Count != len(content.Events) || trailer.Readings != len(content.Readings) || trailer.Format != format {
+				t.Errorf("%s: unexpected trailer %+v", format, trailer)
+			}
+		}
+	}
+}
+
+// Rewrite the uncompressed content of an archive
+func alter(t *testing.T, data []byte, change func([]byte) []byte) []byte {
+	gz, _ := gzip.NewReader(bytes.NewReader(data))
+	content, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(change(content))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestReadInvalid(t *testing.T) {
+	data := writeArchive(t, FormatNDJSON, testEvents)
+
+	tampered := alter(t, data, func(c []byte) []byte {
+		return bytes.Replace(c, []byte("21.5"), []byte("31.5"), 1)
+	})
+	if _, _, err := Read(bytes.NewReader(tampered), 0); err != ErrChecksum {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	truncated := alter(t, data, func(c []byte) []byte {
+		return c[:bytes.IndexByte(c, '\n')+1]
+	})
+	if _, _, err := Read(bytes.NewReader(truncated), 0); err != ErrNoTrailer {
+		t.Errorf("Expected a missing trailer, got %v", err)
+	}
+
+	if _, _, err := Read(bytes.NewReader([]byte("not gzip")), 0); err == nil {
+		t.Errorf("Expected an error reading an uncompressed file")
+	}
+}
+
+func TestReadLimit(t *testing.T) {
+	for _, format := range []string{FormatNDJSON, FormatCBOR} {
+		data := writeArchive(t, format, testEvents, testReadings...)
+		gz, _ := gzip.NewReader(bytes.NewReader(data))
+		content, _ := ioutil.ReadAll(gz)
+
+		if _, _, err := Read(bytes.NewReader(data), int64(len(content))); err != nil {
+			t.Errorf("%s: expected an archive within the limit to be read, got %v", format, err)
+		}
+		for _, limit := range []int{10, len(content) - 1} {
+			if _, _, err := Read(bytes.NewReader(data), int64(limit)); err != ErrTooLarge {
+				t.Errorf("%s: expected an archive over %d bytes to be rejected, got %v", format, limit, err)
+			}
+		}
+	}
+}
+
+func TestNewWriterFormat(t *testing.T) {
+	if _, err := NewWriter(ioutil.Discard, "xml"); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2017 Cavium
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package compression holds the compressors shared by export-distro and core-data.
+// A compressor reuses its writer from one payload to the next, so it must not be shared
+// between goroutines.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+)
+
+// Gzip compresses payloads in the gzip format
+type Gzip struct {
+	writer *gzip.Writer
+}
+
+// Writer returns the compressor's writer, reset to write to w
+func (c *Gzip) Writer(w io.Writer) *gzip.Writer {
+	if c.writer == nil {
+		c.writer = gzip.NewWriter(w)
+	} else {
+		c.writer.Reset(w)
+	}
+	return c.writer
+}
+
+// Compress returns data compressed in one piece
+func (c *Gzip) Compress(data []byte) []byte {
+	var buf bytes.Buffer
+
+	w := c.Writer(&buf)
+	w.Write(data)
+	w.Close()
+
+	return buf.Bytes()
+}
+
+// Zlib compresses payloads in the zlib format
+type Zlib struct {
+	writer *zlib.Writer
+}
+
+// Writer returns the compressor's writer, reset to write to w
+func (c *Zlib) Writer(w io.Writer) *zlib.Writer {
+	if c.writer == nil {
+		c.writer = zlib.NewWriter(w)
+	} else {
+		c.writer.Reset(w)
+	}
+	return c.writer
+}
+
+// Compress returns data compressed in one piece
+func (c *Zlib) Compress(data []byte) []byte {
+	var buf bytes.Buffer
+
+	w := c.Writer(&buf)
+	w.Write(data)
+	w.Close()
+
+	return buf.Bytes()
+}
@@ -100,6 +100,16 @@ type DatabaseInfo struct {
 	Username string
 	Password string
 	Name     string
+	// Version the schema of the database is migrated to at startup, the latest one when zero.
+	// An earlier version than the one recorded rolls the database back.
+	SchemaVersion int
+	// Only log the migrations startup would run
+	MigrationDryRun bool
+	// Redis only: time events and readings are kept after their creation, e.g. '72h', forever when empty
+	TTL string
+	// Redis only: bytes of memory used by the server above which the oldest events and readings
+	// are evicted, no limit when zero
+	MaxMemory int64
 }
 
 type IntervalInfo struct {
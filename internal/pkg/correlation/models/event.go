@@ -24,9 +24,17 @@ type Event struct {
 	Bytes         []byte // This will NOT be marshaled via the JSON below. It is only populated and read as an instance member.
 	CorrelationId string
 	Checksum      string
+	Originals     []Original // Values of the readings core-data converted to another unit
 	contract.Event
 }
 
+// Original is the value a reading was received with, before being converted to another unit
+type Original struct {
+	Index int    `json:"index"` // Position of the reading in the event
+	Value string `json:"value"`
+	Unit  string `json:"unit"`
+}
+
 // Returns an instance of just the public contract portion of the model Event.
 // I don't like returning a pointer from this method but I have to in order to
 // satisfy the Filter, Format interfaces.
@@ -57,6 +65,7 @@ func (e Event) MarshalJSON() ([]byte, error) {
 		Modified      int64              `json:"modified,omitempty"`
 		Origin        int64              `json:"origin,omitempty"`
 		Readings      []contract.Reading `json:"readings,omitempty"` // List of readings
+		Originals     []Original         `json:"originals,omitempty"`
 	}{
 		Pushed:   e.Pushed,
 		Created:  e.Created,
@@ -84,6 +93,9 @@ func (e Event) MarshalJSON() ([]byte, error) {
 	if len(e.Readings) > 0 {
 		test.Readings = e.Readings
 	}
+	if len(e.Originals) > 0 {
+		test.Originals = e.Originals
+	}
 
 	return json.Marshal(test)
 }
@@ -0,0 +1,43 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+// AggregateQuery selects the readings to summarize in buckets of Interval milliseconds.
+// Buckets are aligned on multiples of Interval since the epoch, so that consecutive
+// windows produce the same buckets. An empty list of devices matches every device.
+type AggregateQuery struct {
+	Names    []string
+	Devices  []string
+	Start    int64
+	End      int64
+	Interval int64
+}
+
+// Bucket summarizes the numeric readings of one device and value descriptor created
+// in [Start, Start+Interval). Readings whose value is not a number are left out.
+type Bucket struct {
+	Device string  `json:"device"`
+	Name   string  `json:"name"`
+	Start  int64   `json:"start"`
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Avg    float64 `json:"avg"`
+}
+
+// BucketStart returns the start of the bucket holding a reading created at the given time
+func BucketStart(created, interval int64) int64 {
+	return created - created%interval
+}
@@ -0,0 +1,234 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package backup takes snapshots of the databases of the EdgeX services and restores them.
+//
+// Snapshots are read and written through the DBClient of each service, so that a snapshot of one
+// backend restores into any other. A snapshot is gzip compressed newline delimited JSON: a header
+// giving the version of the layout and the collections held, a record per object in the contract
+// form of its model, then a trailer giving the number of records and the SHA-256 checksum of
+// everything before it. Collections are written in the order they are restored, referenced
+// objects before the objects referring to them.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/compression"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Version of the snapshot layout written in the header
+const Version = 1
+
+// ErrChecksum is returned when the records of a snapshot do not match its trailer
+var ErrChecksum = errors.New("backup: checksum mismatch")
+
+// ErrNoTrailer is returned for a snapshot that does not end with a trailer, e.g. one cut short
+var ErrNoTrailer = errors.New("backup: missing trailer")
+
+// Header opens a snapshot
+type Header struct {
+	Version     int      `json:"version"`
+	Created     int64    `json:"created"`
+	Collections []string `json:"collections"`
+}
+
+// Record holds an object of a collection
+type Record struct {
+	Collection string          `json:"collection"`
+	Object     json.RawMessage `json:"object"`
+}
+
+// Trailer closes a snapshot
+type Trailer struct {
+	Count  int    `json:"count"`
+	SHA256 string `json:"sha256"`
+}
+
+// Counts of the objects of a collection handled by a restore
+type Counts struct {
+	Added int `json:"added"`
+	// Objects already in the database, or of a service without a database to restore into
+	Skipped int `json:"skipped"`
+}
+
+// Summary of a restore by collection
+type Summary map[string]*Counts
+
+// Backup writes a snapshot of the databases of the clients to w
+func Backup(w io.Writer, clients Clients) error {
+	var c compression.Gzip
+	gz := c.Writer(w)
+	h := sha256.New()
+	out := io.MultiWriter(gz, h)
+
+	var included []*collection
+	header := Header{Version: Version, Created: db.MakeTimestamp(), Collections: []string{}}
+	for _, coll := range collections {
+		if coll.present(clients) {
+			included = append(included, coll)
+			header.Collections = append(header.Collections, coll.name)
+		}
+	}
+	if err := writeLine(out, header); err != nil {
+		return err
+	}
+
+	count := 0
+	for _, coll := range included {
+		err := coll.dump(clients, func(object interface{}) error {
+			data, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			count++
+			return writeLine(out, Record{Collection: coll.name, Object: data})
+		})
+		if err != nil {
+			return fmt.Errorf("backup of %s failed: %v", coll.name, err)
+		}
+	}
+
+	if err := writeLine(gz, Trailer{Count: count, SHA256: hex.EncodeToString(h.Sum(nil))}); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Verify checks the checksum of a snapshot and returns its header
+func Verify(r io.Reader) (Header, error) {
+	return scan(r, nil)
+}
+
+// Restore adds the objects of a snapshot to the databases of the clients. The whole snapshot is
+// verified before anything is added, hence the seeker.
+func Restore(r io.ReadSeeker, clients Clients) (Summary, error) {
+	if _, err := Verify(r); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	summary := Summary{}
+	ids := idMap{}
+	_, err := scan(r, func(record Record) error {
+		coll, ok := collectionsByName[record.Collection]
+		if !ok {
+			return fmt.Errorf("unknown collection %s", record.Collection)
+		}
+		counts, ok := summary[coll.name]
+		if !ok {
+			counts = &Counts{}
+			summary[coll.name] = counts
+		}
+
+		if !coll.present(clients) {
+			counts.Skipped++
+			return nil
+		}
+		added, err := coll.restore(clients, record.Object, ids)
+		if err != nil {
+			return fmt.Errorf("restore of %s failed: %v", coll.name, err)
+		}
+		if added {
+			counts.Added++
+		} else {
+			counts.Skipped++
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// Read a snapshot, checking its header and trailer, handing each record to the function if any
+func scan(r io.Reader, handle func(Record) error) (Header, error) {
+	var header Header
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return header, err
+	}
+	defer gz.Close()
+
+	h := sha256.New()
+	reader := bufio.NewReader(gz)
+	count := -1
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return header, ErrNoTrailer
+		} else if err != nil {
+			return header, err
+		}
+
+		if count < 0 {
+			if err = json.Unmarshal(line, &header); err != nil {
+				return header, err
+			}
+			if header.Version > Version {
+				return header, fmt.Errorf("backup: unsupported snapshot version %d", header.Version)
+			}
+			h.Write(line)
+			count = 0
+			continue
+		}
+
+		if !bytes.HasPrefix(line, []byte(`{"collection"`)) {
+			return header, checkTrailer(line, count, h)
+		}
+		h.Write(line)
+		count++
+		if handle == nil {
+			continue
+		}
+
+		var record Record
+		if err = json.Unmarshal(line, &record); err != nil {
+			return header, err
+		}
+		if err = handle(record); err != nil {
+			return header, err
+		}
+	}
+}
+
+func checkTrailer(line []byte, count int, h hash.Hash) error {
+	var trailer Trailer
+	if err := json.Unmarshal(line, &trailer); err != nil || trailer.SHA256 == "" {
+		return ErrNoTrailer
+	}
+	if trailer.Count != count || trailer.SHA256 != hex.EncodeToString(h.Sum(nil)) {
+		return ErrChecksum
+	}
+	return nil
+}
+
+func writeLine(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
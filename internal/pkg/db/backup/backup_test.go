@@ -0,0 +1,305 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/bolt"
+)
+
+// Connect every service to a bbolt file in a temporary directory
+func newTestClients(t *testing.T) (Clients, func()) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatalf("Could not create directory: %v", err)
+	}
+
+	info := config.DatabaseInfo{Type: db.BoltDB, Host: dir, Name: "edgex", Timeout: 1000}
+	databases := map[string]config.DatabaseInfo{}
+	for _, key := range services {
+		databases[key] = info
+	}
+	c, err := Connect(databases)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Could not connect: %v", err)
+	}
+	return c, func() {
+		c.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func populate(t *testing.T, c Clients) {
+	check := func(_ string, err error) {
+		if err != nil {
+			t.Fatalf("Could not populate the database: %v", err)
+		}
+	}
+
+	a := contract.Addressable{Name: "addressable", Protocol: "HTTP", Address: "localhost", Port: 49990}
+	var err error
+	a.Id, err = c.Metadata.AddAddressable(a)
+	check(a.Id, err)
+	check(c.Metadata.AddCommand(contract.Command{Name: "unused"}))
+
+	ds := contract.DeviceService{Name: "service", Addressable: a, AdminState: contract.Unlocked, OperatingState: contract.Enabled}
+	ds.Id, err = c.Metadata.AddDeviceService(ds)
+	check(ds.Id, err)
+	dp := contract.DeviceProfile{Name: "profile", CoreCommands: []contract.Command{{Name: "temperature"}}}
+	dp.Id, err = c.Metadata.AddDeviceProfile(dp)
+	check(dp.Id, err)
+	dp, err = c.Metadata.GetDeviceProfileById(dp.Id)
+	check(dp.Id, err)
+	protocols := map[string]contract.ProtocolProperties{"http": {"host": "localhost"}}
+	check(c.Metadata.AddDevice(contract.Device{
+		Name:           "device",
+		AdminState:     contract.Unlocked,
+		OperatingState: contract.Enabled,
+		Service:        ds,
+		Profile:        dp,
+		Protocols:      protocols,
+	}))
+	check(c.Metadata.AddProvisionWatcher(contract.ProvisionWatcher{Name: "watcher", OperatingState: contract.Enabled, Service: ds, Profile: dp}))
+	check(c.Metadata.AddDeviceReport(contract.DeviceReport{Name: "report", Device: "device", Action: "temperature"}))
+
+	check(c.Data.AddValueDescriptor(contract.ValueDescriptor{Name: "temperature", Type: "Int64"}))
+	for i := 0; i < 3; i++ {
+		e := contract.Event{Device: "device", Origin: int64(i + 1)}
+		e.Readings = []contract.Reading{{Name: "temperature", Device: "device", Value: "21"}}
+		check(c.Data.AddEvent(models.Event{Event: e}))
+	}
+	check(c.Data.AddReading(contract.Reading{Name: "temperature", Device: "device", Value: "22"}))
+	check(c.Data.AddQuarantinedEvent(db.QuarantinedEvent{Reason: "unknown device", Event: contract.Event{Device: "other"}}))
+
+	check(c.Export.AddRegistration(contract.Registration{
+		Name:        "registration",
+		Format:      contract.FormatJSON,
+		Compression: contract.CompNone,
+		Destination: contract.DestRest,
+		Encryption:  contract.EncryptionDetails{Algo: contract.EncNone},
+		Addressable: a,
+	}))
+	check("", c.Export.UpdateTemplate("registration", `{"device":{{json .Device}}}`))
+	check("", c.Export.UpdateFilter("registration", "value > 10"))
+	check("", c.Export.UpdateCSVSettings("registration", `{"delimiter":";"}`))
+
+	check(c.Notifications.AddSubscription(contract.Subscription{Slug: "subscription", Receiver: "receiver"}))
+	n := contract.Notification{Slug: "notification", Sender: "sender", Category: contract.Swhealth, Severity: contract.Normal}
+	n.ID, err = c.Notifications.AddNotification(n)
+	check(n.ID, err)
+	check(c.Notifications.AddTransmission(contract.Transmission{Notification: n, Receiver: "receiver", Status: contract.Sent}))
+
+	check(c.Scheduler.AddInterval(contract.Interval{Name: "hourly", Frequency: "PT1H"}))
+	check(c.Scheduler.AddIntervalAction(contract.IntervalAction{Name: "action", Interval: "hourly", Target: "target"}))
+}
+
+func TestRoundTrip(t *testing.T) {
+	from, closeFrom := newTestClients(t)
+	defer closeFrom()
+	populate(t, from)
+
+	var snapshot bytes.Buffer
+	if err := Backup(&snapshot, from); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	header, err := Verify(bytes.NewReader(snapshot.Bytes()))
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(header.Collections) != len(collections) {
+		t.Errorf("expected %d collections, found %v", len(collections), header.Collections)
+	}
+
+	to, closeTo := newTestClients(t)
+	defer closeTo()
+	summary, err := Restore(bytes.NewReader(snapshot.Bytes()), to)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	expected := map[string]int{
+		db.Addressable: 1, db.Command: 1, db.DeviceService: 1, db.DeviceProfile: 1, db.Device: 1,
+		db.ProvisionWatcher: 1, db.DeviceReport: 1, db.ValueDescriptorCollection: 1, db.EventsCollection: 3,
+		db.ReadingsCollection:   1,
+		db.QuarantineCollection: 1, db.ExportCollection: 1, db.ExportTemplateCollection: 1,
+		db.ExportFilterCollection: 1, db.ExportCSVCollection: 1, db.Subscription: 1, db.Notification: 1,
+		db.Transmission: 1, db.Interval: 1, db.IntervalAction: 1,
+	}
+	for name, added := range expected {
+		if counts := summary[name]; counts == nil || counts.Added != added || counts.Skipped != 0 {
+			t.Errorf("%s: expected %d added, found %+v", name, added, counts)
+		}
+	}
+
+	// The references between the restored objects hold
+	d, err := to.Metadata.GetDeviceByName("device")
+	if err != nil {
+		t.Fatalf("Could not get the device: %v", err)
+	}
+	if d.Service.Name != "service" || d.Profile.Name != "profile" || len(d.Profile.CoreCommands) != 1 {
+		t.Errorf("device not restored with its service and profile: %v", d)
+	}
+	commands, err := to.Metadata.GetAllCommands()
+	if err != nil || len(commands) != 2 {
+		t.Errorf("expected the command of the profile and the unused one, found %v (%v)", commands, err)
+	}
+	readings, err := to.Data.Readings()
+	if err != nil || len(readings) != 4 {
+		t.Errorf("expected the readings of the events and the one added on its own, found %d (%v)", len(readings), err)
+	}
+
+	// Restoring again adds nothing
+	summary, err = Restore(bytes.NewReader(snapshot.Bytes()), to)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	for name, skipped := range expected {
+		if counts := summary[name]; counts == nil || counts.Added != 0 || counts.Skipped != skipped {
+			t.Errorf("%s: expected %d skipped, found %+v", name, skipped, counts)
+		}
+	}
+}
+
+func TestConnectBoltInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatalf("Could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The file is held as by a running service
+	held, err := bolt.NewClient(db.Configuration{Host: dir, DatabaseName: "edgex", Timeout: 1000})
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	defer held.CloseSession()
+
+	info := config.DatabaseInfo{Type: db.BoltDB, Host: dir, Name: "edgex", Timeout: 1000}
+	_, err = Connect(map[string]config.DatabaseInfo{clients.CoreDataServiceKey: info})
+	if err == nil || !strings.Contains(err.Error(), ErrDatabaseInUse.Error()) {
+		t.Errorf("Expected the file to be reported in use, got %v", err)
+	}
+}
+
+func TestRestoreWithoutDatabase(t *testing.T) {
+	from, closeFrom := newTestClients(t)
+	defer closeFrom()
+	populate(t, from)
+
+	var snapshot bytes.Buffer
+	if err := Backup(&snapshot, from); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	to, closeTo := newTestClients(t)
+	defer closeTo()
+	to.Scheduler = nil
+	summary, err := Restore(bytes.NewReader(snapshot.Bytes()), to)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if counts := summary[db.Interval]; counts == nil || counts.Added != 0 || counts.Skipped != 1 {
+		t.Errorf("expected the interval to be skipped, found %+v", counts)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	c, closeClients := newTestClients(t)
+	defer closeClients()
+	populate(t, c)
+
+	var snapshot bytes.Buffer
+	if err := Backup(&snapshot, c); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	content, err := ioutil.ReadAll(gzipReader(t, snapshot.Bytes()))
+	if err != nil {
+		t.Fatalf("Could not read the snapshot: %v", err)
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	tests := []struct {
+		name     string
+		content  []byte
+		expected error
+	}{
+		{"Valid", content, nil},
+		{"Altered", bytes.Replace(content, []byte(`"device"`), []byte(`"other1"`), 1), ErrChecksum},
+		{"Record removed", bytes.Join(append(lines[:1:1], lines[2:]...), nil), ErrChecksum},
+		{"Cut short", bytes.Join(lines[:len(lines)-2], nil), ErrNoTrailer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Verify(bytes.NewReader(gzipBytes(t, tt.content))); err != tt.expected {
+				t.Errorf("expected %v, found %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCollections(t *testing.T) {
+	for _, coll := range collections {
+		if _, ok := excluded[coll.name]; ok {
+			t.Errorf("%s is both held by snapshots and excluded from them", coll.name)
+		}
+	}
+	// Together they account for every collection of db.go
+	all := []string{
+		db.EventsCollection, db.ReadingsCollection, db.ValueDescriptorCollection, db.EventKeysCollection,
+		db.QuarantineCollection, db.ExportCollection, db.ExportTemplateCollection, db.ExportFilterCollection,
+		db.ExportCSVCollection, db.LogsCollection, db.Device, db.DeviceProfile, db.DeviceService,
+		db.Addressable, db.Command, db.DeviceReport, db.ProvisionWatcher, db.Interval, db.IntervalAction,
+		db.Notification, db.Subscription, db.Transmission, db.SchemaVersionCollection,
+	}
+	for _, name := range all {
+		_, held := collectionsByName[name]
+		_, left := excluded[name]
+		if !held && !left {
+			t.Errorf("%s is neither held by snapshots nor excluded from them", name)
+		}
+	}
+}
+
+func gzipReader(t *testing.T, b []byte) *gzip.Reader {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Could not read the snapshot: %v", err)
+	}
+	return r
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatalf("Could not write the snapshot: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Could not write the snapshot: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,162 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"go.etcd.io/bbolt"
+
+	dataInterfaces "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces"
+	metadataInterfaces "github.com/edgexfoundry/edgex-go/internal/core/metadata/interfaces"
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/bolt"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/redis"
+	notificationsInterfaces "github.com/edgexfoundry/edgex-go/internal/support/notifications/interfaces"
+	schedulerInterfaces "github.com/edgexfoundry/edgex-go/internal/support/scheduler/interfaces"
+)
+
+// ErrDatabaseInUse tells that a bbolt file is held by a running service. Unlike a database server,
+// bbolt only lets one process open a file, so the services using it must be stopped for their
+// database to be backed up or restored.
+var ErrDatabaseInUse = errors.New("the bbolt file is in use, stop the services using it first")
+
+// Clients of the databases of the services, nil for a service left out of the snapshot
+type Clients struct {
+	Data          dataInterfaces.DBClient
+	Metadata      metadataInterfaces.DBClient
+	Export        export.DBClient
+	Notifications notificationsInterfaces.DBClient
+	Scheduler     schedulerInterfaces.DBClient
+
+	sessions []session
+}
+
+type session interface {
+	CloseSession()
+}
+
+// The services whose database is held by a snapshot, by service key
+var services = []string{
+	clients.CoreDataServiceKey,
+	clients.CoreMetaDataServiceKey,
+	clients.ExportClientServiceKey,
+	clients.SupportNotificationsServiceKey,
+	clients.SupportSchedulerServiceKey,
+}
+
+// Connect to the databases of the services, given by service key as configured for the service.
+// Services missing from the map are left out. Services sharing a database share the client, as
+// the Redis client is a single pool and a bbolt file can only be opened once.
+func Connect(databases map[string]config.DatabaseInfo) (Clients, error) {
+	var c Clients
+	opened := map[connection]session{}
+	for _, key := range services {
+		info, ok := databases[key]
+		if !ok {
+			continue
+		}
+
+		conn := connection{info.Type, info.Host, info.Port, info.Name}
+		client, ok := opened[conn]
+		if !ok {
+			var err error
+			if client, err = newDBClient(info); err != nil {
+				c.Close()
+				return Clients{}, fmt.Errorf("couldn't connect to the database of %s: %v", key, err)
+			}
+			opened[conn] = client
+			c.sessions = append(c.sessions, client)
+		}
+
+		if !c.set(key, client) {
+			c.Close()
+			return Clients{}, fmt.Errorf("%s does not support the database of %s", info.Type, key)
+		}
+	}
+	return c, nil
+}
+
+// Close the clients opened by Connect
+func (c Clients) Close() {
+	for _, s := range c.sessions {
+		s.CloseSession()
+	}
+}
+
+// What identifies a database
+type connection struct {
+	dbType string
+	host   string
+	port   int
+	name   string
+}
+
+func newDBClient(info config.DatabaseInfo) (session, error) {
+	switch info.Type {
+	case db.MongoDB:
+		return mongo.NewClient(db.Configuration{
+			Host:         info.Host,
+			Port:         info.Port,
+			Timeout:      info.Timeout,
+			DatabaseName: info.Name,
+			Username:     info.Username,
+			Password:     info.Password,
+		})
+	case db.RedisDB:
+		return redis.NewClient(db.Configuration{
+			Host:     info.Host,
+			Port:     info.Port,
+			Password: info.Password,
+		})
+	case db.BoltDB:
+		// Failing at once rather than waiting for the service holding the file to release it
+		client, err := bolt.NewClient(db.Configuration{
+			Host:         info.Host,
+			Timeout:      1,
+			DatabaseName: info.Name,
+		})
+		if err == bbolt.ErrTimeout {
+			return nil, ErrDatabaseInUse
+		} else if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, db.ErrUnsupportedDatabase
+	}
+}
+
+// Set the client of a service, returning false when it lacks the functions the service needs
+func (c *Clients) set(key string, client session) (ok bool) {
+	switch key {
+	case clients.CoreDataServiceKey:
+		c.Data, ok = client.(dataInterfaces.DBClient)
+	case clients.CoreMetaDataServiceKey:
+		c.Metadata, ok = client.(metadataInterfaces.DBClient)
+	case clients.ExportClientServiceKey:
+		c.Export, ok = client.(export.DBClient)
+	case clients.SupportNotificationsServiceKey:
+		c.Notifications, ok = client.(notificationsInterfaces.DBClient)
+	case clients.SupportSchedulerServiceKey:
+		c.Scheduler, ok = client.(schedulerInterfaces.DBClient)
+	}
+	return ok
+}
@@ -0,0 +1,816 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package backup
+
+import (
+	"encoding/json"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Number of events read at once
+const eventBatchSize = 1000
+
+// A collection of db.go held by snapshots
+type collection struct {
+	name    string
+	present func(c Clients) bool
+	// Hand each object of the collection to emit
+	dump func(c Clients, emit func(interface{}) error) error
+	// Add an object unless it is already stored, returning whether it was added
+	restore func(c Clients, raw json.RawMessage, ids idMap) (bool, error)
+}
+
+// Collections of db.go left out of snapshots
+var excluded = map[string]string{
+	db.EventKeysCollection:     "the keys only guard against adding an event twice for a short while",
+	db.LogsCollection:          "support-logging does not read its entries through a DBClient",
+	db.SchemaVersionCollection: "each database records the version of its own schema",
+}
+
+// In the order they are restored, referenced objects before the objects referring to them
+var collections = []*collection{
+	{db.Addressable, hasMetadata, dumpAddressables, restoreAddressable},
+	{db.Command, hasMetadata, dumpCommands, restoreCommand},
+	{db.DeviceService, hasMetadata, dumpDeviceServices, restoreDeviceService},
+	{db.DeviceProfile, hasMetadata, dumpDeviceProfiles, restoreDeviceProfile},
+	{db.Device, hasMetadata, dumpDevices, restoreDevice},
+	{db.ProvisionWatcher, hasMetadata, dumpProvisionWatchers, restoreProvisionWatcher},
+	{db.DeviceReport, hasMetadata, dumpDeviceReports, restoreDeviceReport},
+	{db.ValueDescriptorCollection, hasData, dumpValueDescriptors, restoreValueDescriptor},
+	{db.EventsCollection, hasData, dumpEvents, restoreEvent},
+	{db.ReadingsCollection, hasData, dumpReadings, restoreReading},
+	{db.QuarantineCollection, hasData, dumpQuarantine, restoreQuarantinedEvent},
+	{db.ExportCollection, hasExport, dumpRegistrations, restoreRegistration},
+	{db.ExportTemplateCollection, hasExport, dumpTemplates, restoreTemplate},
+	{db.ExportFilterCollection, hasExport, dumpFilters, restoreFilter},
+	{db.ExportCSVCollection, hasExport, dumpCSVSettings, restoreCSVSettings},
+	{db.Subscription, hasNotifications, dumpSubscriptions, restoreSubscription},
+	{db.Notification, hasNotifications, dumpNotifications, restoreNotification},
+	{db.Transmission, hasNotifications, dumpTransmissions, restoreTransmission},
+	{db.Interval, hasScheduler, dumpIntervals, restoreInterval},
+	{db.IntervalAction, hasScheduler, dumpIntervalActions, restoreIntervalAction},
+}
+
+var collectionsByName = map[string]*collection{}
+
+func init() {
+	for _, coll := range collections {
+		collectionsByName[coll.name] = coll
+	}
+}
+
+func hasData(c Clients) bool          { return c.Data != nil }
+func hasMetadata(c Clients) bool      { return c.Metadata != nil }
+func hasExport(c Clients) bool        { return c.Export != nil }
+func hasNotifications(c Clients) bool { return c.Notifications != nil }
+func hasScheduler(c Clients) bool     { return c.Scheduler != nil }
+
+// The ids the restored objects were stored under, by collection and id in the snapshot.
+// Metadata objects refer to each other by id, which changes when a backend cannot keep it.
+type idMap map[string]map[string]string
+
+func (ids idMap) set(collection string, from string, to string) {
+	if ids[collection] == nil {
+		ids[collection] = map[string]string{}
+	}
+	ids[collection][from] = to
+}
+
+// The id an object of the snapshot is stored under, the id of the snapshot if it was not restored
+func (ids idMap) get(collection string, from string) string {
+	if to, ok := ids[collection][from]; ok {
+		return to
+	}
+	return from
+}
+
+// Ids other than UUIDs, such as the ObjectIds of Mongo, are only meaningful to their backend.
+// The objects holding them are given a new id instead.
+func portable(id string) string {
+	if _, err := uuid.Parse(id); err != nil {
+		return ""
+	}
+	return id
+}
+
+// Add an object unless it is found, recording the id it is stored under
+func restoreObject(ids idMap, collection string, id string, find func() (string, error), add func() (string, error)) (bool, error) {
+	found, err := find()
+	if err == nil {
+		ids.set(collection, id, found)
+		return false, nil
+	} else if err != db.ErrNotFound {
+		return false, err
+	}
+
+	added, err := add()
+	if err != nil {
+		return false, err
+	}
+	ids.set(collection, id, added)
+	return true, nil
+}
+
+// Find an object by the id of the snapshot, for the collections without a unique name
+func findById(id string, get func(string) (string, error)) func() (string, error) {
+	return func() (string, error) {
+		if id = portable(id); id == "" {
+			return "", db.ErrNotFound
+		}
+		return get(id)
+	}
+}
+
+/* ---------------------------------- Metadata ---------------------------------- */
+
+func dumpAddressables(c Clients, emit func(interface{}) error) error {
+	addressables, err := c.Metadata.GetAddressables()
+	if err != nil {
+		return err
+	}
+	for _, a := range addressables {
+		if err = emit(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreAddressable(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var a contract.Addressable
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return false, err
+	}
+	id := a.Id
+	a.Id = portable(a.Id)
+	return restoreObject(ids, db.Addressable, id, func() (string, error) {
+		found, err := c.Metadata.GetAddressableByName(a.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Metadata.AddAddressable(a)
+	})
+}
+
+// Only the commands of no profile are written here, the others are added along with their profile
+func dumpCommands(c Clients, emit func(interface{}) error) error {
+	profiles, err := c.Metadata.GetAllDeviceProfiles()
+	if err != nil {
+		return err
+	}
+	used := map[string]bool{}
+	for _, dp := range profiles {
+		for _, cmd := range dp.CoreCommands {
+			used[cmd.Id] = true
+		}
+	}
+
+	commands, err := c.Metadata.GetAllCommands()
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if used[cmd.Id] {
+			continue
+		}
+		if err = emit(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreCommand(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var cmd contract.Command
+	if err := json.Unmarshal(raw, &cmd); err != nil {
+		return false, err
+	}
+	id := cmd.Id
+	cmd.Id = portable(cmd.Id)
+	return restoreObject(ids, db.Command, id, findById(id, func(id string) (string, error) {
+		found, err := c.Metadata.GetCommandById(id)
+		return found.Id, err
+	}), func() (string, error) {
+		return c.Metadata.AddCommand(cmd)
+	})
+}
+
+func dumpDeviceServices(c Clients, emit func(interface{}) error) error {
+	services, err := c.Metadata.GetAllDeviceServices()
+	if err != nil {
+		return err
+	}
+	for _, ds := range services {
+		if err = emit(ds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreDeviceService(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var ds contract.DeviceService
+	if err := json.Unmarshal(raw, &ds); err != nil {
+		return false, err
+	}
+	id := ds.Id
+	ds.Id = portable(ds.Id)
+	remapDeviceService(&ds, ids)
+	return restoreObject(ids, db.DeviceService, id, func() (string, error) {
+		found, err := c.Metadata.GetDeviceServiceByName(ds.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Metadata.AddDeviceService(ds)
+	})
+}
+
+func dumpDeviceProfiles(c Clients, emit func(interface{}) error) error {
+	profiles, err := c.Metadata.GetAllDeviceProfiles()
+	if err != nil {
+		return err
+	}
+	for _, dp := range profiles {
+		if err = emit(dp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The backends add the commands of a profile along with it
+func restoreDeviceProfile(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var dp contract.DeviceProfile
+	if err := json.Unmarshal(raw, &dp); err != nil {
+		return false, err
+	}
+	id := dp.Id
+	dp.Id = portable(dp.Id)
+	commands := map[string]string{}
+	for i := range dp.CoreCommands {
+		commands[dp.CoreCommands[i].Name] = dp.CoreCommands[i].Id
+		dp.CoreCommands[i].Id = portable(dp.CoreCommands[i].Id)
+	}
+
+	added, err := restoreObject(ids, db.DeviceProfile, id, func() (string, error) {
+		found, err := c.Metadata.GetDeviceProfileByName(dp.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Metadata.AddDeviceProfile(dp)
+	})
+	if err != nil || len(commands) == 0 {
+		return added, err
+	}
+
+	// Command names are unique within a profile
+	stored, err := c.Metadata.GetDeviceProfileById(ids.get(db.DeviceProfile, id))
+	if err != nil {
+		return added, err
+	}
+	for _, cmd := range stored.CoreCommands {
+		if from, ok := commands[cmd.Name]; ok {
+			ids.set(db.Command, from, cmd.Id)
+		}
+	}
+	return added, nil
+}
+
+func dumpDevices(c Clients, emit func(interface{}) error) error {
+	devices, err := c.Metadata.GetAllDevices()
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if err = emit(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreDevice(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var d contract.Device
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return false, err
+	}
+	id := d.Id
+	d.Id = portable(d.Id)
+	remapDeviceService(&d.Service, ids)
+	remapDeviceProfile(&d.Profile, ids)
+	return restoreObject(ids, db.Device, id, func() (string, error) {
+		found, err := c.Metadata.GetDeviceByName(d.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Metadata.AddDevice(d)
+	})
+}
+
+func dumpProvisionWatchers(c Clients, emit func(interface{}) error) error {
+	watchers, err := c.Metadata.GetAllProvisionWatchers()
+	if err != nil {
+		return err
+	}
+	for _, pw := range watchers {
+		if err = emit(pw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreProvisionWatcher(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var pw contract.ProvisionWatcher
+	if err := json.Unmarshal(raw, &pw); err != nil {
+		return false, err
+	}
+	id := pw.Id
+	pw.Id = portable(pw.Id)
+	remapDeviceService(&pw.Service, ids)
+	remapDeviceProfile(&pw.Profile, ids)
+	return restoreObject(ids, db.ProvisionWatcher, id, func() (string, error) {
+		found, err := c.Metadata.GetProvisionWatcherByName(pw.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Metadata.AddProvisionWatcher(pw)
+	})
+}
+
+func dumpDeviceReports(c Clients, emit func(interface{}) error) error {
+	reports, err := c.Metadata.GetAllDeviceReports()
+	if err != nil {
+		return err
+	}
+	for _, dr := range reports {
+		if err = emit(dr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreDeviceReport(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var dr contract.DeviceReport
+	if err := json.Unmarshal(raw, &dr); err != nil {
+		return false, err
+	}
+	id := dr.Id
+	dr.Id = portable(dr.Id)
+	return restoreObject(ids, db.DeviceReport, id, func() (string, error) {
+		found, err := c.Metadata.GetDeviceReportByName(dr.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Metadata.AddDeviceReport(dr)
+	})
+}
+
+func remapDeviceService(ds *contract.DeviceService, ids idMap) {
+	ds.Id = ids.get(db.DeviceService, ds.Id)
+	ds.Addressable.Id = ids.get(db.Addressable, ds.Addressable.Id)
+}
+
+func remapDeviceProfile(dp *contract.DeviceProfile, ids idMap) {
+	dp.Id = ids.get(db.DeviceProfile, dp.Id)
+	for i := range dp.CoreCommands {
+		dp.CoreCommands[i].Id = ids.get(db.Command, dp.CoreCommands[i].Id)
+	}
+}
+
+/* ------------------------------------ Data ------------------------------------ */
+
+func dumpValueDescriptors(c Clients, emit func(interface{}) error) error {
+	descriptors, err := c.Data.ValueDescriptors()
+	if err != nil {
+		return err
+	}
+	for _, v := range descriptors {
+		if err = emit(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreValueDescriptor(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var v contract.ValueDescriptor
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return false, err
+	}
+	id := v.Id
+	v.Id = portable(v.Id)
+	return restoreObject(ids, db.ValueDescriptorCollection, id, func() (string, error) {
+		found, err := c.Data.ValueDescriptorByName(v.Name)
+		return found.Id, err
+	}, func() (string, error) {
+		return c.Data.AddValueDescriptor(v)
+	})
+}
+
+// Events are read a page at a time as they are by far the largest collection
+func dumpEvents(c Clients, emit func(interface{}) error) error {
+	var cursor db.Cursor
+	for {
+		events, next, err := c.Data.EventsPage(db.EventFilter{}, cursor, eventBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err = emit(e); err != nil {
+				return err
+			}
+		}
+		if next.IsZero() {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// Events are only looked up by id, so those with an id of another backend are added each time
+func restoreEvent(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var e contract.Event
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return false, err
+	}
+	id := e.ID
+	e.ID = portable(e.ID)
+	for i := range e.Readings {
+		e.Readings[i].Id = portable(e.Readings[i].Id)
+	}
+	return restoreObject(ids, db.EventsCollection, id, findById(id, func(id string) (string, error) {
+		found, err := c.Data.EventById(id)
+		return found.ID, err
+	}), func() (string, error) {
+		return c.Data.AddEvent(models.Event{Event: e})
+	})
+}
+
+// Only the readings added on their own are held here, those of events being held by their events
+func dumpReadings(c Clients, emit func(interface{}) error) error {
+	held := map[string]bool{}
+	var cursor db.Cursor
+	for {
+		events, next, err := c.Data.EventsPage(db.EventFilter{}, cursor, eventBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			for _, r := range e.Readings {
+				held[r.Id] = true
+			}
+		}
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+
+	cursor = db.Cursor{}
+	for {
+		readings, next, err := c.Data.ReadingsPage(db.ReadingFilter{}, cursor, eventBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, r := range readings {
+			if held[r.Id] {
+				continue
+			}
+			if err = emit(r); err != nil {
+				return err
+			}
+		}
+		if next.IsZero() {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// Readings are only looked up by id, so those with an id of another backend are added each time
+func restoreReading(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var r contract.Reading
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return false, err
+	}
+	id := r.Id
+	r.Id = portable(r.Id)
+	return restoreObject(ids, db.ReadingsCollection, id, findById(id, func(id string) (string, error) {
+		found, err := c.Data.ReadingById(id)
+		return found.Id, err
+	}), func() (string, error) {
+		return c.Data.AddReading(r)
+	})
+}
+
+func dumpQuarantine(c Clients, emit func(interface{}) error) error {
+	quarantined, err := c.Data.QuarantinedEvents(0)
+	if err != nil {
+		return err
+	}
+	for _, q := range quarantined {
+		if err = emit(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreQuarantinedEvent(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var q db.QuarantinedEvent
+	if err := json.Unmarshal(raw, &q); err != nil {
+		return false, err
+	}
+	id := q.ID
+	q.ID = portable(q.ID)
+	return restoreObject(ids, db.QuarantineCollection, id, findById(id, func(id string) (string, error) {
+		found, err := c.Data.QuarantinedEventById(id)
+		return found.ID, err
+	}), func() (string, error) {
+		return c.Data.AddQuarantinedEvent(q)
+	})
+}
+
+/* ----------------------------------- Export ----------------------------------- */
+
+func dumpRegistrations(c Clients, emit func(interface{}) error) error {
+	registrations, err := c.Export.Registrations()
+	if err != nil {
+		return err
+	}
+	for _, r := range registrations {
+		if err = emit(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreRegistration(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var r contract.Registration
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return false, err
+	}
+	id := r.ID
+	r.ID = portable(r.ID)
+	return restoreObject(ids, db.ExportCollection, id, func() (string, error) {
+		found, err := c.Export.RegistrationByName(r.Name)
+		return found.ID, err
+	}, func() (string, error) {
+		return c.Export.AddRegistration(r)
+	})
+}
+
+// Templates are held by the names of their registrations
+type template struct {
+	Name     string
+	Template string
+}
+
+func dumpTemplates(c Clients, emit func(interface{}) error) error {
+	templates, err := c.Export.Templates()
+	if err != nil {
+		return err
+	}
+	for name, t := range templates {
+		if err = emit(template{Name: name, Template: t}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreTemplate(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var t template
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return false, err
+	}
+	return restoreObject(ids, db.ExportTemplateCollection, t.Name, func() (string, error) {
+		_, err := c.Export.TemplateByName(t.Name)
+		return t.Name, err
+	}, func() (string, error) {
+		return t.Name, c.Export.UpdateTemplate(t.Name, t.Template)
+	})
+}
+
+// Filter expressions are held by the names of their registrations, as templates are
+type filter struct {
+	Name       string
+	Expression string
+}
+
+func dumpFilters(c Clients, emit func(interface{}) error) error {
+	filters, err := c.Export.Filters()
+	if err != nil {
+		return err
+	}
+	for name, f := range filters {
+		if err = emit(filter{Name: name, Expression: f}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreFilter(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var f filter
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return false, err
+	}
+	return restoreObject(ids, db.ExportFilterCollection, f.Name, func() (string, error) {
+		_, err := c.Export.FilterByName(f.Name)
+		return f.Name, err
+	}, func() (string, error) {
+		return f.Name, c.Export.UpdateFilter(f.Name, f.Expression)
+	})
+}
+
+// CSV settings are held by the names of their registrations too, as the text of a JSON object
+type csvSettings struct {
+	Name     string
+	Settings string
+}
+
+func dumpCSVSettings(c Clients, emit func(interface{}) error) error {
+	settings, err := c.Export.CSVSettings()
+	if err != nil {
+		return err
+	}
+	for name, s := range settings {
+		if err = emit(csvSettings{Name: name, Settings: s}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreCSVSettings(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var s csvSettings
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, err
+	}
+	return restoreObject(ids, db.ExportCSVCollection, s.Name, func() (string, error) {
+		_, err := c.Export.CSVSettingsByName(s.Name)
+		return s.Name, err
+	}, func() (string, error) {
+		return s.Name, c.Export.UpdateCSVSettings(s.Name, s.Settings)
+	})
+}
+
+/* -------------------------------- Notifications ------------------------------- */
+
+func dumpSubscriptions(c Clients, emit func(interface{}) error) error {
+	subscriptions, err := c.Notifications.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, s := range subscriptions {
+		if err = emit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreSubscription(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var s contract.Subscription
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, err
+	}
+	id := s.ID
+	s.ID = portable(s.ID)
+	return restoreObject(ids, db.Subscription, id, func() (string, error) {
+		found, err := c.Notifications.GetSubscriptionBySlug(s.Slug)
+		return found.ID, err
+	}, func() (string, error) {
+		return c.Notifications.AddSubscription(s)
+	})
+}
+
+func dumpNotifications(c Clients, emit func(interface{}) error) error {
+	notifications, err := c.Notifications.GetNotifications()
+	if err != nil {
+		return err
+	}
+	for _, n := range notifications {
+		if err = emit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreNotification(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var n contract.Notification
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return false, err
+	}
+	id := n.ID
+	n.ID = portable(n.ID)
+	return restoreObject(ids, db.Notification, id, func() (string, error) {
+		found, err := c.Notifications.GetNotificationBySlug(n.Slug)
+		return found.ID, err
+	}, func() (string, error) {
+		return c.Notifications.AddNotification(n)
+	})
+}
+
+func dumpTransmissions(c Clients, emit func(interface{}) error) error {
+	transmissions, err := c.Notifications.GetTransmissionsByStart(0, 0)
+	if err != nil {
+		return err
+	}
+	for _, t := range transmissions {
+		if err = emit(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreTransmission(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var t contract.Transmission
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return false, err
+	}
+	id := t.ID
+	t.ID = portable(t.ID)
+	t.Notification.ID = ids.get(db.Notification, t.Notification.ID)
+	return restoreObject(ids, db.Transmission, id, findById(id, func(id string) (string, error) {
+		found, err := c.Notifications.GetTransmissionById(id)
+		return found.ID, err
+	}), func() (string, error) {
+		return c.Notifications.AddTransmission(t)
+	})
+}
+
+/* ---------------------------------- Scheduler --------------------------------- */
+
+func dumpIntervals(c Clients, emit func(interface{}) error) error {
+	intervals, err := c.Scheduler.Intervals()
+	if err != nil {
+		return err
+	}
+	for _, i := range intervals {
+		if err = emit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreInterval(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var i contract.Interval
+	if err := json.Unmarshal(raw, &i); err != nil {
+		return false, err
+	}
+	id := i.ID
+	i.ID = portable(i.ID)
+	return restoreObject(ids, db.Interval, id, func() (string, error) {
+		found, err := c.Scheduler.IntervalByName(i.Name)
+		return found.ID, err
+	}, func() (string, error) {
+		return c.Scheduler.AddInterval(i)
+	})
+}
+
+func dumpIntervalActions(c Clients, emit func(interface{}) error) error {
+	actions, err := c.Scheduler.IntervalActions()
+	if err != nil {
+		return err
+	}
+	for _, ia := range actions {
+		if err = emit(ia); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreIntervalAction(c Clients, raw json.RawMessage, ids idMap) (bool, error) {
+	var ia contract.IntervalAction
+	if err := json.Unmarshal(raw, &ia); err != nil {
+		return false, err
+	}
+	id := ia.ID
+	ia.ID = portable(ia.ID)
+	return restoreObject(ids, db.IntervalAction, id, func() (string, error) {
+		found, err := c.Scheduler.IntervalActionByName(ia.Name)
+		return found.ID, err
+	}, func() (string, error) {
+		return c.Scheduler.AddIntervalAction(ia)
+	})
+}
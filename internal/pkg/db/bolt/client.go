@@ -0,0 +1,195 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package bolt stores the data of a service in an embedded bbolt file, so that gateways do not
+// need to run a database server. Each collection is a bucket of JSON documents keyed by id.
+// Lookups by id are direct while other queries scan their collection, which suits the volume
+// of data kept on a single gateway.
+package bolt
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// The database file is created in the working directory when no host directory is configured
+	defaultDirectory = "."
+	fileExtension    = ".db"
+
+	// The event keys in order of expiry
+	eventKeyExpiryCollection = db.EventKeysCollection + "Expiry"
+)
+
+// Every collection of every service, so that each bucket exists before it is read
+var collections = []string{
+	db.EventsCollection, db.ReadingsCollection, db.ValueDescriptorCollection, db.EventKeysCollection,
+	eventKeyExpiryCollection,
+	db.QuarantineCollection, db.ExportCollection, db.ExportTemplateCollection, db.ExportFilterCollection,
+	db.ExportCSVCollection, db.LogsCollection,
+	db.Device, db.DeviceProfile, db.DeviceService, db.Addressable, db.Command, db.DeviceReport,
+	db.ProvisionWatcher, db.Interval, db.IntervalAction,
+	db.Notification, db.Subscription, db.Transmission,
+}
+
+// Client represents a bbolt database file
+type Client struct {
+	db *bbolt.DB
+}
+
+// Return a client of the file named after the database in the directory given as host
+// Both are created when needed. The timeout bounds the wait for another process to release the file.
+func NewClient(config db.Configuration) (*Client, error) {
+	dir := config.Host
+	if dir == "" {
+		dir = defaultDirectory
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, config.DatabaseName+fileExtension)
+	options := &bbolt.Options{Timeout: time.Duration(config.Timeout) * time.Millisecond}
+	b, err := bbolt.Open(path, 0600, options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.Update(func(tx *bbolt.Tx) error {
+		for _, c := range collections {
+			if _, err := tx.CreateBucketIfNotExists([]byte(c)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+
+	return &Client{db: b}, nil
+}
+
+// CloseSession closes the database file
+func (c *Client) CloseSession() {
+	if c.db != nil {
+		_ = c.db.Close()
+		c.db = nil
+	}
+}
+
+// Ping checks that the file is still open, as there is no server to lose
+func (c *Client) Ping() error {
+	if c.db == nil {
+		return bbolt.ErrDatabaseNotOpen
+	}
+	return c.db.View(func(*bbolt.Tx) error { return nil })
+}
+
+// PoolStats gives the open read transactions, which are what hold the file
+func (c *Client) PoolStats() db.PoolStats {
+	if c.db == nil {
+		return db.PoolStats{}
+	}
+	open := c.db.Stats().OpenTxN
+	return db.PoolStats{Open: open, InUse: open}
+}
+
+// Store an object under its id, replacing any previous version
+func putObject(tx *bbolt.Tx, collection string, id string, o interface{}) error {
+	m, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(collection)).Put([]byte(id), m)
+}
+
+// Read the object stored under an id
+// NotFound - no object with the id in the collection
+func getObject(tx *bbolt.Tx, collection string, id string, o interface{}) error {
+	m := tx.Bucket([]byte(collection)).Get([]byte(id))
+	if m == nil {
+		return db.ErrNotFound
+	}
+	return json.Unmarshal(m, o)
+}
+
+// Remove the object stored under an id
+// NotFound - no object with the id in the collection
+func deleteObject(tx *bbolt.Tx, collection string, id string) error {
+	b := tx.Bucket([]byte(collection))
+	if b.Get([]byte(id)) == nil {
+		return db.ErrNotFound
+	}
+	return b.Delete([]byte(id))
+}
+
+// Remove the objects of a collection selected by a predicate
+func deleteObjects(tx *bbolt.Tx, collection string, selected func(o []byte) (bool, error)) error {
+	cur := tx.Bucket([]byte(collection)).Cursor()
+	for k, o := cur.First(); k != nil; {
+		remove, err := selected(o)
+		if err != nil {
+			return err
+		}
+		if !remove {
+			k, o = cur.Next()
+			continue
+		}
+
+		// The cursor is left out of place by a delete, so it is moved back to the key that followed
+		key := append([]byte(nil), k...)
+		if err = cur.Delete(); err != nil {
+			return err
+		}
+		k, o = cur.Seek(key)
+	}
+	return nil
+}
+
+// Call fn with every object of a collection, in the order of their ids
+// The objects are only valid until fn returns
+func forEachObject(tx *bbolt.Tx, collection string, fn func(o []byte) error) error {
+	return tx.Bucket([]byte(collection)).ForEach(func(_, o []byte) error {
+		return fn(o)
+	})
+}
+
+// Count the objects of a collection
+func countObjects(tx *bbolt.Tx, collection string) int {
+	return tx.Bucket([]byte(collection)).Stats().KeyN
+}
+
+// Remove every object of a collection
+func clearCollection(tx *bbolt.Tx, collection string) error {
+	if err := tx.DeleteBucket([]byte(collection)); err != nil && err != bbolt.ErrBucketNotFound {
+		return err
+	}
+	_, err := tx.CreateBucket([]byte(collection))
+	return err
+}
+
+// Apply a limit to a number of results, a limit of 0 or less meaning no limit
+func limitOf(n int, limit int) int {
+	if limit > 0 && limit < n {
+		return limit
+	}
+	return n
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2019 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/test"
+	"go.etcd.io/bbolt"
+)
+
+// Unlike the mongo and redis tests this one needs no running server, the files are created in a
+// temporary directory
+func TestBoltDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bolt")
+	if err != nil {
+		t.Fatalf("Could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := db.Configuration{
+		Host:         dir,
+		DatabaseName: "coredata",
+		Timeout:      1000,
+	}
+	bolt, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	test.TestDataDB(t, bolt)
+	bolt.CloseSession()
+
+	config.DatabaseName = "metadata"
+	bolt, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	test.TestMetadataDB(t, bolt)
+	bolt.CloseSession()
+
+	config.DatabaseName = "export"
+	bolt, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	test.TestExportDB(t, bolt)
+	bolt.CloseSession()
+
+	config.DatabaseName = "scheduler"
+	bolt, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	test.TestSchedulerDB(t, bolt)
+	bolt.CloseSession()
+
+	config.DatabaseName = "notifications"
+	bolt, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	test.TestNotificationsDB(t, bolt)
+	bolt.CloseSession()
+}
+
+func TestEventKeysPruned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bolt")
+	if err != nil {
+		t.Fatalf("Could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	bolt, err := NewClient(db.Configuration{Host: dir, DatabaseName: "coredata", Timeout: 1000})
+	if err != nil {
+		t.Fatalf("Could not open: %v", err)
+	}
+	defer bolt.CloseSession()
+
+	now := db.MakeTimestamp()
+	if err = bolt.AddEventKey("short", "event1", now+20); err != nil {
+		t.Fatalf("Error adding event key: %v", err)
+	}
+	if _, _, err = bolt.ReserveEventKey("long", now+60000); err != nil {
+		t.Fatalf("Error reserving event key: %v", err)
+	}
+	if err = bolt.AddEventKey("long", "event2", now+60000); err != nil {
+		t.Fatalf("Error adding event key: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// Adding a key removes the expired one along with its expiry
+	if err = bolt.AddEventKey("other", "event3", db.MakeTimestamp()+60000); err != nil {
+		t.Fatalf("Error adding event key: %v", err)
+	}
+	err = bolt.db.View(func(tx *bbolt.Tx) error {
+		if n := countObjects(tx, db.EventKeysCollection); n != 2 {
+			t.Errorf("Expected 2 keys, got %d", n)
+		}
+		if n := countObjects(tx, eventKeyExpiryCollection); n != 2 {
+			t.Errorf("Expected 2 expiries, got %d", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error reading keys: %v", err)
+	}
+}
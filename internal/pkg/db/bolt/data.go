@@ -0,0 +1,1208 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"github.com/imdario/mergo"
+	"go.etcd.io/bbolt"
+)
+
+// Events are stored apart from their readings, which they reference by id
+type boltEvent struct {
+	ID        string
+	Checksum  string
+	Pushed    int64
+	Device    string
+	Created   int64
+	Modified  int64
+	Origin    int64
+	Readings  []string
+	Originals []db.ReadingOriginal `json:",omitempty"`
+}
+
+// An event key is kept until it expires, then ignored and eventually removed
+type boltEventKey struct {
+	ID      string
+	Expires int64
+}
+
+// ********************** EVENT FUNCTIONS *******************************
+// Return all the events, oldest first
+// UnexpectedError - failed to retrieve events from the database
+func (c *Client) Events() ([]contract.Event, error) {
+	return c.findEvents(nil, eventCreatedBefore, 0)
+}
+
+// Return events up to the number specified, oldest first
+// UnexpectedError - failed to retrieve events from the database
+func (c *Client) EventsWithLimit(limit int) ([]contract.Event, error) {
+	return c.findEvents(nil, eventCreatedBefore, limit)
+}
+
+// Add a new event along with its readings
+// UnexpectedError - failed to add to database
+func (c *Client) AddEvent(e correlation.Event) (id string, err error) {
+	if err = validateEventIds(e); err != nil {
+		return "", err
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addEvent(tx, e)
+		return err
+	})
+	return id, err
+}
+
+// Add events and their readings in a single transaction
+// The ids of the events are returned in the same order
+func (c *Client) AddEvents(events []correlation.Event) (ids []string, err error) {
+	for _, e := range events {
+		if err = validateEventIds(e); err != nil {
+			return nil, err
+		}
+	}
+
+	ids = make([]string, len(events))
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		for i, e := range events {
+			if ids[i], err = addEvent(tx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Update an event - do NOT update readings
+// UnexpectedError - problem updating in database
+// NotFound - no event with the ID was found
+func (c *Client) UpdateEvent(e correlation.Event) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var o boltEvent
+		if err := getObject(tx, db.EventsCollection, e.ID, &o); err != nil {
+			return err
+		}
+
+		u := boltEvent{
+			ID:       e.ID,
+			Checksum: e.Checksum,
+			Pushed:   e.Pushed,
+			Device:   e.Device,
+			Created:  e.Created,
+			Modified: db.MakeTimestamp(),
+			Origin:   e.Origin,
+		}
+		if err := mergo.Merge(&u, o); err != nil {
+			return err
+		}
+		u.Readings = o.Readings
+		u.Originals = o.Originals
+
+		return putObject(tx, db.EventsCollection, u.ID, u)
+	})
+}
+
+// Get an event by id
+func (c *Client) EventById(id string) (event contract.Event, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var e boltEvent
+		if err := getObject(tx, db.EventsCollection, id, &e); err != nil {
+			return err
+		}
+		event, err = e.toContract(tx)
+		return err
+	})
+	return event, err
+}
+
+// Get an event by id along with the values its readings were received with
+func (c *Client) EventWithOriginalsById(id string) (event correlation.Event, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var e boltEvent
+		if err := getObject(tx, db.EventsCollection, id, &e); err != nil {
+			return err
+		}
+		event.Event, err = e.toContract(tx)
+		event.Originals = db.EventOriginals(event.Readings, e.Originals)
+		return err
+	})
+	return event, err
+}
+
+// Get all events with a matching checksum
+// NotFound - no event has the checksum
+func (c *Client) EventsByChecksum(checksum string) ([]contract.Event, error) {
+	events, err := c.findEvents(func(e boltEvent) bool {
+		return e.Checksum == checksum
+	}, eventCreatedBefore, 0)
+	if err == nil && len(events) == 0 {
+		return events, db.ErrNotFound
+	}
+	return events, err
+}
+
+// Get the number of events in Core Data
+func (c *Client) EventCount() (count int, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		count = countObjects(tx, db.EventsCollection)
+		return nil
+	})
+	return count, err
+}
+
+// Get the number of events in Core Data for the device specified by id
+func (c *Client) EventCountByDeviceId(id string) (count int, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachEvent(tx, func(e boltEvent) error {
+			if e.Device == id {
+				count++
+			}
+			return nil
+		})
+	})
+	return count, err
+}
+
+// Delete an event by ID. Readings are not deleted as this should be handled by the contract layer
+// 404 - Event not found
+// 503 - Unexpected problems
+func (c *Client) DeleteEventById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.EventsCollection, id)
+	})
+}
+
+// Get a list of events based on the device id and limit, oldest first
+func (c *Client) EventsForDeviceLimit(id string, limit int) ([]contract.Event, error) {
+	return c.findEvents(func(e boltEvent) bool {
+		return e.Device == id
+	}, eventCreatedBefore, limit)
+}
+
+// Get a list of events based on the device id, oldest first
+func (c *Client) EventsForDevice(id string) ([]contract.Event, error) {
+	return c.EventsForDeviceLimit(id, 0)
+}
+
+// Return a list of events whose creation time is between startTime and endTime, oldest first
+// Limit the number of results by limit
+func (c *Client) EventsByCreationTime(startTime, endTime int64, limit int) ([]contract.Event, error) {
+	return c.findEvents(func(e boltEvent) bool {
+		return e.Created >= startTime && e.Created <= endTime
+	}, eventCreatedBefore, limit)
+}
+
+// Return a list of events whose origin time is between startTime and endTime, oldest first
+// Limit the number of results by limit
+func (c *Client) EventsByOriginTime(startTime, endTime int64, limit int) ([]contract.Event, error) {
+	return c.findEvents(func(e boltEvent) bool {
+		return e.Origin >= startTime && e.Origin <= endTime
+	}, func(a, b boltEvent) bool {
+		if a.Origin != b.Origin {
+			return a.Origin < b.Origin
+		}
+		return a.ID < b.ID
+	}, limit)
+}
+
+// Return a page of events matching the filter, sorted by creation time (oldest first)
+// The returned cursor is empty once there are no more events to read
+func (c *Client) EventsPage(filter db.EventFilter, cursor db.Cursor, limit int) (events []contract.Event, next db.Cursor, err error) {
+	if limit <= 0 {
+		return []contract.Event{}, next, nil
+	}
+	if err = validateCursor(cursor); err != nil {
+		return events, next, err
+	}
+
+	events, err = c.findEvents(func(e boltEvent) bool {
+		return (filter.Device == "" || e.Device == filter.Device) &&
+			within(e.Created, filter.Start, filter.End) &&
+			after(e.Created, e.ID, cursor)
+	}, eventCreatedBefore, limit+1)
+	if err != nil {
+		return events, next, err
+	}
+
+	if len(events) > limit {
+		events = events[:limit]
+		next = db.Cursor{Created: events[limit-1].Created, Id: events[limit-1].ID}
+	}
+	return events, next, nil
+}
+
+// Return a list of readings for a device filtered by the value descriptor and limited by the limit
+func (c *Client) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]contract.Reading, error) {
+	return c.findReadings(func(r contract.Reading) bool {
+		return r.Device == deviceId && r.Name == valueDescriptor
+	}, readingCreatedBefore, limit)
+}
+
+// Get events that are older than a age
+func (c *Client) EventsOlderThanAge(age int64) ([]contract.Event, error) {
+	expireDate := db.MakeTimestamp() - age
+
+	return c.EventsByCreationTime(0, expireDate, 0)
+}
+
+// Get events that have been pushed (pushed field is not 0)
+func (c *Client) EventsPushed() ([]contract.Event, error) {
+	return c.findEvents(func(e boltEvent) bool {
+		return e.Pushed != 0
+	}, eventCreatedBefore, 0)
+}
+
+// Delete all readings and events
+func (c *Client) ScrubAllEvents() error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := clearCollection(tx, db.EventsCollection); err != nil {
+			return err
+		}
+		return clearCollection(tx, db.ReadingsCollection)
+	})
+}
+
+// Delete up to limit of the oldest events matching the filter, along with their readings
+// Return the number of events deleted
+func (c *Client) DeleteEvents(filter db.PurgeFilter, limit int) (count int, err error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		var found []boltEvent
+		err := forEachEvent(tx, func(e boltEvent) error {
+			if purged(filter, e.Device, e.Created, e.Pushed) {
+				found = append(found, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(found, func(i, j int) bool { return eventCreatedBefore(found[i], found[j]) })
+		for _, e := range found[:limitOf(len(found), limit)] {
+			for _, rid := range e.Readings {
+				if err = deleteObject(tx, db.ReadingsCollection, rid); err != nil && err != db.ErrNotFound {
+					return err
+				}
+			}
+			if err = deleteObject(tx, db.EventsCollection, e.ID); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Return the number of bytes of the keys and values of events and readings
+func (c *Client) DataSize() (size int64, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+			err := tx.Bucket([]byte(collection)).ForEach(func(k, v []byte) error {
+				size += int64(len(k) + len(v))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Get the id of the event recorded under a key, unless the key expired
+func (c *Client) EventIdByKey(key string) (id string, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var k boltEventKey
+		if err := getObject(tx, db.EventKeysCollection, key, &k); err != nil {
+			return err
+		}
+		if k.Expires <= db.MakeTimestamp() || k.ID == "" {
+			return db.ErrNotFound
+		}
+		id = k.ID
+		return nil
+	})
+	return id, err
+}
+
+// Reserve a key, checking it within the same transaction
+func (c *Client) ReserveEventKey(key string, expires int64) (id string, reserved bool, err error) {
+	now := db.MakeTimestamp()
+	if expires <= now {
+		return "", false, nil
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		var k boltEventKey
+		err := getObject(tx, db.EventKeysCollection, key, &k)
+		if err == nil && k.Expires > now {
+			id = k.ID
+			return nil
+		}
+		if err != nil && err != db.ErrNotFound {
+			return err
+		}
+
+		if err = pruneEventKeys(tx, now); err != nil {
+			return err
+		}
+		reserved = true
+		return putEventKey(tx, key, boltEventKey{Expires: expires})
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return id, reserved, nil
+}
+
+// Record the id of an event under a key until it expires
+// Expired keys are removed whenever a key is added or reserved
+func (c *Client) AddEventKey(key string, id string, expires int64) error {
+	now := db.MakeTimestamp()
+	if expires <= now {
+		return nil
+	}
+
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := pruneEventKeys(tx, now); err != nil {
+			return err
+		}
+		return putEventKey(tx, key, boltEventKey{ID: id, Expires: expires})
+	})
+}
+
+func (c *Client) DeleteEventKey(key string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteEventKey(tx, key)
+	})
+}
+
+// Event keys are also indexed by expiry, the big-endian expiry time followed by the key, so that
+// the expired ones are found first without reading the others
+func eventKeyExpiry(key string, expires int64) []byte {
+	k := make([]byte, 8, 8+len(key))
+	binary.BigEndian.PutUint64(k, uint64(expires))
+	return append(k, key...)
+}
+
+// Store an event key along with its expiry, replacing the expiry of a previous version
+func putEventKey(tx *bbolt.Tx, key string, k boltEventKey) error {
+	if err := deleteEventKey(tx, key); err != nil {
+		return err
+	}
+	if err := putObject(tx, db.EventKeysCollection, key, k); err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(eventKeyExpiryCollection)).Put(eventKeyExpiry(key, k.Expires), nil)
+}
+
+func deleteEventKey(tx *bbolt.Tx, key string) error {
+	var k boltEventKey
+	err := getObject(tx, db.EventKeysCollection, key, &k)
+	if err == db.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err = tx.Bucket([]byte(eventKeyExpiryCollection)).Delete(eventKeyExpiry(key, k.Expires)); err != nil {
+		return err
+	}
+	return deleteObject(tx, db.EventKeysCollection, key)
+}
+
+// Remove the keys expired by now, walking the expiry index from the earliest
+func pruneEventKeys(tx *bbolt.Tx, now int64) error {
+	keys := tx.Bucket([]byte(db.EventKeysCollection))
+	cur := tx.Bucket([]byte(eventKeyExpiryCollection)).Cursor()
+	for k, _ := cur.First(); k != nil && int64(binary.BigEndian.Uint64(k[:8])) <= now; k, _ = cur.First() {
+		if err := keys.Delete(k[8:]); err != nil {
+			return err
+		}
+		if err := cur.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ********************* QUARANTINE FUNCTIONS *************************
+// Hold back an event, returning the id of the quarantined event
+func (c *Client) AddQuarantinedEvent(q db.QuarantinedEvent) (string, error) {
+	if q.Created == 0 {
+		q.Created = db.MakeTimestamp()
+	}
+	if q.ID == "" {
+		q.ID = uuid.New().String()
+	}
+
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		return putObject(tx, db.QuarantineCollection, q.ID, q)
+	})
+	if err != nil {
+		return "", err
+	}
+	return q.ID, nil
+}
+
+// Return the quarantined events, oldest first
+func (c *Client) QuarantinedEvents(limit int) ([]db.QuarantinedEvent, error) {
+	return c.findQuarantinedEvents(func(db.QuarantinedEvent) bool { return true }, limit)
+}
+
+// Return the quarantined events of a device, oldest first
+func (c *Client) QuarantinedEventsByDevice(device string, limit int) ([]db.QuarantinedEvent, error) {
+	return c.findQuarantinedEvents(func(q db.QuarantinedEvent) bool {
+		return q.Event.Device == device
+	}, limit)
+}
+
+// Return a quarantined event by its id
+func (c *Client) QuarantinedEventById(id string) (q db.QuarantinedEvent, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.QuarantineCollection, id, &q)
+	})
+	return q, err
+}
+
+// Remove a quarantined event by its id
+func (c *Client) DeleteQuarantinedEventById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.QuarantineCollection, id)
+	})
+}
+
+// Remove the events quarantined before a time
+func (c *Client) DeleteQuarantinedEvents(before int64) (count int, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObjects(tx, db.QuarantineCollection, func(o []byte) (bool, error) {
+			var q db.QuarantinedEvent
+			if err := json.Unmarshal(o, &q); err != nil {
+				return false, err
+			}
+			if q.Created >= before {
+				return false, nil
+			}
+			count++
+			return true, nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ********************* READING FUNCTIONS *************************
+// Return all the readings, newest first
+func (c *Client) Readings() ([]contract.Reading, error) {
+	return c.findReadings(nil, func(a, b contract.Reading) bool {
+		return readingCreatedBefore(b, a)
+	}, 0)
+}
+
+// Post a new reading
+func (c *Client) AddReading(r contract.Reading) (id string, err error) {
+	if r.Id != "" {
+		if _, err = uuid.Parse(r.Id); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addReading(tx, r)
+		return err
+	})
+	return id, err
+}
+
+// Update a reading
+// 404 - reading cannot be found
+// 503 - unknown issues
+func (c *Client) UpdateReading(r contract.Reading) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var o contract.Reading
+		if err := getObject(tx, db.ReadingsCollection, r.Id, &o); err != nil {
+			return err
+		}
+
+		r.Modified = db.MakeTimestamp()
+		if err := mergo.Merge(&r, o); err != nil {
+			return err
+		}
+		return putObject(tx, db.ReadingsCollection, r.Id, r)
+	})
+}
+
+// Get a reading by ID
+func (c *Client) ReadingById(id string) (reading contract.Reading, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.ReadingsCollection, id, &reading)
+	})
+	return reading, err
+}
+
+// Get the number of readings in core data
+func (c *Client) ReadingCount() (count int, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		count = countObjects(tx, db.ReadingsCollection)
+		return nil
+	})
+	return count, err
+}
+
+// Delete a reading by ID
+// 404 - can't find the reading with the given id
+func (c *Client) DeleteReadingById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.ReadingsCollection, id)
+	})
+}
+
+// Delete up to limit of the oldest readings matching the filter
+// Events no longer list the readings deleted
+// Return the number of readings deleted
+func (c *Client) DeleteReadings(filter db.PurgeFilter, limit int) (count int, err error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	names := stringSet(filter.Names)
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		var found []contract.Reading
+		err := forEachReading(tx, func(r contract.Reading) error {
+			if (names == nil || names[r.Name]) && purged(filter, r.Device, r.Created, r.Pushed) {
+				found = append(found, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(found, func(i, j int) bool { return readingCreatedBefore(found[i], found[j]) })
+		for _, r := range found[:limitOf(len(found), limit)] {
+			if err = deleteObject(tx, db.ReadingsCollection, r.Id); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Return a list of readings for the given device, oldest first
+func (c *Client) ReadingsByDevice(id string, limit int) ([]contract.Reading, error) {
+	return c.findReadings(func(r contract.Reading) bool {
+		return r.Device == id
+	}, readingCreatedBefore, limit)
+}
+
+// Return a list of readings for the given value descriptor, oldest first
+func (c *Client) ReadingsByValueDescriptor(name string, limit int) ([]contract.Reading, error) {
+	return c.findReadings(func(r contract.Reading) bool {
+		return r.Name == name
+	}, readingCreatedBefore, limit)
+}
+
+// Return a list of readings whose name is in the list of value descriptor names, oldest first
+func (c *Client) ReadingsByValueDescriptorNames(names []string, limit int) ([]contract.Reading, error) {
+	set := stringSet(names)
+	if set == nil {
+		return []contract.Reading{}, nil
+	}
+	return c.findReadings(func(r contract.Reading) bool {
+		return set[r.Name]
+	}, readingCreatedBefore, limit)
+}
+
+// Return a list of readings whose created time is between the start and end times, oldest first
+func (c *Client) ReadingsByCreationTime(start, end int64, limit int) ([]contract.Reading, error) {
+	return c.findReadings(func(r contract.Reading) bool {
+		return r.Created >= start && r.Created <= end
+	}, readingCreatedBefore, limit)
+}
+
+// Return a list of readings whose origin time is between start and end, oldest first
+func (c *Client) ReadingsByOriginTime(start, end int64, limit int) ([]contract.Reading, error) {
+	return c.findReadings(func(r contract.Reading) bool {
+		return r.Origin >= start && r.Origin <= end
+	}, func(a, b contract.Reading) bool {
+		if a.Origin != b.Origin {
+			return a.Origin < b.Origin
+		}
+		return a.Id < b.Id
+	}, limit)
+}
+
+// Return a page of readings matching the filter, sorted by creation time (oldest first)
+// The returned cursor is empty once there are no more readings to read
+func (c *Client) ReadingsPage(filter db.ReadingFilter, cursor db.Cursor, limit int) (readings []contract.Reading, next db.Cursor, err error) {
+	if limit <= 0 {
+		return []contract.Reading{}, next, nil
+	}
+	if err = validateCursor(cursor); err != nil {
+		return readings, next, err
+	}
+
+	names := stringSet(filter.Names)
+	readings, err = c.findReadings(func(r contract.Reading) bool {
+		return (filter.Device == "" || r.Device == filter.Device) && (names == nil || names[r.Name]) &&
+			within(r.Created, filter.Start, filter.End) &&
+			after(r.Created, r.Id, cursor)
+	}, readingCreatedBefore, limit+1)
+	if err != nil {
+		return readings, next, err
+	}
+
+	if len(readings) > limit {
+		readings = readings[:limit]
+		next = db.Cursor{Created: readings[limit-1].Created, Id: readings[limit-1].Id}
+	}
+	return readings, next, nil
+}
+
+// Return the readings matching every predicate of the query, sorted by the query sort order
+func (c *Client) ReadingsByQuery(query db.ReadingQuery) ([]contract.Reading, error) {
+	if query.Limit <= 0 {
+		return []contract.Reading{}, nil
+	}
+
+	devices := stringSet(query.Devices)
+	names := stringSet(query.Names)
+	match := func(r contract.Reading) bool {
+		if (devices != nil && !devices[r.Device]) || (names != nil && !names[r.Name]) ||
+			!within(r.Created, query.Start, query.End) || !within(r.Origin, query.OriginStart, query.OriginEnd) {
+			return false
+		}
+		for _, v := range query.Values {
+			if !v.Satisfied(r.Value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Ties are broken by id in the same direction as the requested order
+	var less func(a, b contract.Reading) bool
+	switch query.SortOrder() {
+	case db.SortCreated:
+		less = readingCreatedBefore
+	case db.SortOrigin:
+		less = readingOriginBefore
+	case db.SortOriginDescending:
+		less = func(a, b contract.Reading) bool { return readingOriginBefore(b, a) }
+	default:
+		less = func(a, b contract.Reading) bool { return readingCreatedBefore(b, a) }
+	}
+
+	return c.findReadings(match, less, query.Limit)
+}
+
+// Summarize the numeric readings matching the query in time buckets
+// Buckets are sorted by device, value descriptor name and start
+func (c *Client) AggregateReadings(query db.AggregateQuery) ([]db.Bucket, error) {
+	if query.Interval <= 0 || len(query.Names) == 0 {
+		return []db.Bucket{}, nil
+	}
+
+	devices := stringSet(query.Devices)
+	names := stringSet(query.Names)
+	type bucketKey struct {
+		device string
+		name   string
+		start  int64
+	}
+	index := map[bucketKey]int{}
+	buckets := []db.Bucket{}
+
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		return forEachReading(tx, func(r contract.Reading) error {
+			if !names[r.Name] || (devices != nil && !devices[r.Device]) || !within(r.Created, query.Start, query.End) {
+				return nil
+			}
+			value, err := strconv.ParseFloat(r.Value, 64)
+			if err != nil {
+				return nil
+			}
+
+			k := bucketKey{r.Device, r.Name, db.BucketStart(r.Created, query.Interval)}
+			i, ok := index[k]
+			if !ok {
+				i = len(buckets)
+				index[k] = i
+				buckets = append(buckets, db.Bucket{Device: k.device, Name: k.name, Start: k.start, Min: value, Max: value})
+			}
+			b := &buckets[i]
+			b.Count++
+			b.Avg += value
+			if value < b.Min {
+				b.Min = value
+			}
+			if value > b.Max {
+				b.Max = value
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []db.Bucket{}, err
+	}
+
+	// The sum of the values is held in Avg until every reading was seen
+	for i := range buckets {
+		buckets[i].Avg /= float64(buckets[i].Count)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Device != buckets[j].Device {
+			return buckets[i].Device < buckets[j].Device
+		}
+		if buckets[i].Name != buckets[j].Name {
+			return buckets[i].Name < buckets[j].Name
+		}
+		return buckets[i].Start < buckets[j].Start
+	})
+	return buckets, nil
+}
+
+// ************************** VALUE DESCRIPTOR FUNCTIONS ***************************
+// Add a value descriptor
+// 409 - Formatting is bad or it is not unique
+func (c *Client) AddValueDescriptor(v contract.ValueDescriptor) (id string, err error) {
+	if v.Id != "" {
+		if _, err = uuid.Parse(v.Id); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		v.Id = uuid.New().String()
+	}
+	if v.Created == 0 {
+		v.Created = db.MakeTimestamp()
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		if err := checkUniqueName(tx, db.ValueDescriptorCollection, v.Name); err != nil {
+			return err
+		}
+		return putObject(tx, db.ValueDescriptorCollection, v.Id, v)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.Id, nil
+}
+
+// Return a list of all the value descriptors
+func (c *Client) ValueDescriptors() ([]contract.ValueDescriptor, error) {
+	return c.findValueDescriptors(func(contract.ValueDescriptor) bool { return true })
+}
+
+// Update a value descriptor identified by its ID
+// NotFound - no value descriptor with the ID
+// NotUnique - another value descriptor has the name
+func (c *Client) UpdateValueDescriptor(v contract.ValueDescriptor) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var o contract.ValueDescriptor
+		if err := getObject(tx, db.ValueDescriptorCollection, v.Id, &o); err != nil {
+			return err
+		}
+
+		other, err := idByName(tx, db.ValueDescriptorCollection, v.Name)
+		if err != nil && err != db.ErrNotFound {
+			return err
+		}
+		if err == nil && other != v.Id {
+			return db.ErrNotUnique
+		}
+
+		v.Modified = db.MakeTimestamp()
+		if err = mergo.Merge(&v, o); err != nil {
+			return err
+		}
+		return putObject(tx, db.ValueDescriptorCollection, v.Id, v)
+	})
+}
+
+// Delete a value descriptor based on the ID
+func (c *Client) DeleteValueDescriptorById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.ValueDescriptorCollection, id)
+	})
+}
+
+// Return a value descriptor based on the name
+func (c *Client) ValueDescriptorByName(name string) (value contract.ValueDescriptor, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObjectByName(tx, db.ValueDescriptorCollection, name, &value)
+	})
+	return value, err
+}
+
+// Return value descriptors based on the names, leaving out the names not found
+func (c *Client) ValueDescriptorsByName(names []string) ([]contract.ValueDescriptor, error) {
+	set := stringSet(names)
+	return c.findValueDescriptors(func(v contract.ValueDescriptor) bool {
+		return set[v.Name]
+	})
+}
+
+// Return a value descriptor based on the id
+func (c *Client) ValueDescriptorById(id string) (value contract.ValueDescriptor, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.ValueDescriptorCollection, id, &value)
+	})
+	return value, err
+}
+
+// Return value descriptors based on the unit of measure label
+func (c *Client) ValueDescriptorsByUomLabel(uomLabel string) ([]contract.ValueDescriptor, error) {
+	return c.findValueDescriptors(func(v contract.ValueDescriptor) bool {
+		return v.UomLabel == uomLabel
+	})
+}
+
+// Return value descriptors based on the label
+func (c *Client) ValueDescriptorsByLabel(label string) ([]contract.ValueDescriptor, error) {
+	return c.findValueDescriptors(func(v contract.ValueDescriptor) bool {
+		return contains(v.Labels, label)
+	})
+}
+
+// Return a list of value descriptors based on their type
+func (c *Client) ValueDescriptorsByType(t string) ([]contract.ValueDescriptor, error) {
+	return c.findValueDescriptors(func(v contract.ValueDescriptor) bool {
+		return v.Type == t
+	})
+}
+
+// Delete all value descriptors
+func (c *Client) ScrubAllValueDescriptors() error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return clearCollection(tx, db.ValueDescriptorCollection)
+	})
+}
+
+// ************************** HELPER FUNCTIONS ***************************
+func validateEventIds(e correlation.Event) error {
+	if e.ID != "" {
+		if _, err := uuid.Parse(e.ID); err != nil {
+			return db.ErrInvalidObjectId
+		}
+	}
+	for _, r := range e.Readings {
+		if r.Id != "" {
+			if _, err := uuid.Parse(r.Id); err != nil {
+				return db.ErrInvalidObjectId
+			}
+		}
+	}
+	return nil
+}
+
+// Readings take the creation time and device of their event
+func addEvent(tx *bbolt.Tx, e correlation.Event) (string, error) {
+	if e.Created == 0 {
+		e.Created = db.MakeTimestamp()
+	}
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+
+	s := boltEvent{
+		ID:       e.ID,
+		Checksum: e.Checksum,
+		Pushed:   e.Pushed,
+		Device:   e.Device,
+		Created:  e.Created,
+		Modified: e.Modified,
+		Origin:   e.Origin,
+		Readings: make([]string, len(e.Readings)),
+	}
+	for i, r := range e.Readings {
+		r.Created = e.Created
+		r.Device = e.Device
+
+		id, err := addReading(tx, r)
+		if err != nil {
+			return "", err
+		}
+		s.Readings[i] = id
+		e.Readings[i].Id = id
+	}
+	s.Originals = db.ReadingOriginals(e)
+
+	if err := putObject(tx, db.EventsCollection, s.ID, s); err != nil {
+		return "", err
+	}
+	return s.ID, nil
+}
+
+func addReading(tx *bbolt.Tx, r contract.Reading) (string, error) {
+	if r.Created == 0 {
+		r.Created = db.MakeTimestamp()
+	}
+	if r.Id == "" {
+		r.Id = uuid.New().String()
+	}
+
+	if err := putObject(tx, db.ReadingsCollection, r.Id, r); err != nil {
+		return "", err
+	}
+	return r.Id, nil
+}
+
+// Readings deleted on their own are left out of their event
+func (e boltEvent) toContract(tx *bbolt.Tx) (contract.Event, error) {
+	event := contract.Event{
+		ID:       e.ID,
+		Pushed:   e.Pushed,
+		Device:   e.Device,
+		Created:  e.Created,
+		Modified: e.Modified,
+		Origin:   e.Origin,
+		Readings: make([]contract.Reading, 0, len(e.Readings)),
+	}
+
+	for _, id := range e.Readings {
+		var r contract.Reading
+		err := getObject(tx, db.ReadingsCollection, id, &r)
+		if err == db.ErrNotFound {
+			continue
+		} else if err != nil {
+			return event, err
+		}
+		event.Readings = append(event.Readings, r)
+	}
+	return event, nil
+}
+
+func forEachEvent(tx *bbolt.Tx, fn func(e boltEvent) error) error {
+	return forEachObject(tx, db.EventsCollection, func(o []byte) error {
+		var e boltEvent
+		if err := json.Unmarshal(o, &e); err != nil {
+			return err
+		}
+		return fn(e)
+	})
+}
+
+func forEachReading(tx *bbolt.Tx, fn func(r contract.Reading) error) error {
+	return forEachObject(tx, db.ReadingsCollection, func(o []byte) error {
+		var r contract.Reading
+		if err := json.Unmarshal(o, &r); err != nil {
+			return err
+		}
+		return fn(r)
+	})
+}
+
+// Return the events matching a predicate, or all events when it is nil, in the given order
+// Limit the number of results by limit unless it is 0
+func (c *Client) findEvents(match func(e boltEvent) bool, less func(a, b boltEvent) bool, limit int) (events []contract.Event, err error) {
+	events = []contract.Event{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var found []boltEvent
+		err := forEachEvent(tx, func(e boltEvent) error {
+			if match == nil || match(e) {
+				found = append(found, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(found, func(i, j int) bool { return less(found[i], found[j]) })
+		for _, e := range found[:limitOf(len(found), limit)] {
+			event, err := e.toContract(tx)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return []contract.Event{}, err
+	}
+	return events, nil
+}
+
+// Return the readings matching a predicate, or all readings when it is nil, in the given order
+// Limit the number of results by limit unless it is 0
+func (c *Client) findReadings(match func(r contract.Reading) bool, less func(a, b contract.Reading) bool, limit int) (readings []contract.Reading, err error) {
+	readings = []contract.Reading{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachReading(tx, func(r contract.Reading) error {
+			if match == nil || match(r) {
+				readings = append(readings, r)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.Reading{}, err
+	}
+
+	sort.Slice(readings, func(i, j int) bool { return less(readings[i], readings[j]) })
+	return readings[:limitOf(len(readings), limit)], nil
+}
+
+func (c *Client) findQuarantinedEvents(match func(q db.QuarantinedEvent) bool, limit int) (quarantined []db.QuarantinedEvent, err error) {
+	quarantined = []db.QuarantinedEvent{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.QuarantineCollection, func(o []byte) error {
+			var q db.QuarantinedEvent
+			if err := json.Unmarshal(o, &q); err != nil {
+				return err
+			}
+			if match(q) {
+				quarantined = append(quarantined, q)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(quarantined, func(i, j int) bool {
+		if quarantined[i].Created != quarantined[j].Created {
+			return quarantined[i].Created < quarantined[j].Created
+		}
+		return quarantined[i].ID < quarantined[j].ID
+	})
+	return quarantined[:limitOf(len(quarantined), limit)], nil
+}
+
+func (c *Client) findValueDescriptors(match func(v contract.ValueDescriptor) bool) (values []contract.ValueDescriptor, err error) {
+	values = []contract.ValueDescriptor{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.ValueDescriptorCollection, func(o []byte) error {
+			var v contract.ValueDescriptor
+			if err := json.Unmarshal(o, &v); err != nil {
+				return err
+			}
+			if match(v) {
+				values = append(values, v)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.ValueDescriptor{}, err
+	}
+	return values, nil
+}
+
+func eventCreatedBefore(a, b boltEvent) bool {
+	if a.Created != b.Created {
+		return a.Created < b.Created
+	}
+	return a.ID < b.ID
+}
+
+func readingCreatedBefore(a, b contract.Reading) bool {
+	if a.Created != b.Created {
+		return a.Created < b.Created
+	}
+	return a.Id < b.Id
+}
+
+func readingOriginBefore(a, b contract.Reading) bool {
+	if a.Origin != b.Origin {
+		return a.Origin < b.Origin
+	}
+	return a.Id < b.Id
+}
+
+// Check an inclusive range, ignoring bounds set to 0
+func within(v, start, end int64) bool {
+	return (start == 0 || v >= start) && (end == 0 || v <= end)
+}
+
+// Whether an item comes after the cursor in the order of creation
+func after(created int64, id string, cursor db.Cursor) bool {
+	if cursor.IsZero() {
+		return true
+	}
+	return created > cursor.Created || (created == cursor.Created && id > cursor.Id)
+}
+
+// Whether an event or reading is selected by a purge filter
+func purged(filter db.PurgeFilter, device string, created int64, pushed int64) bool {
+	return (filter.Before == 0 || created < filter.Before) &&
+		(!filter.OnlyPushed || pushed != 0) &&
+		(len(filter.Devices) == 0 || contains(filter.Devices, device)) &&
+		!contains(filter.ExcludeDevices, device)
+}
+
+// Cursors hold the id of the last item returned, which is a UUID
+func validateCursor(cursor db.Cursor) error {
+	if cursor.IsZero() {
+		return nil
+	}
+	if _, err := uuid.Parse(cursor.Id); err != nil {
+		return db.ErrInvalidCursor
+	}
+	return nil
+}
+
+// Return a set of strings, nil when the list is empty
+func stringSet(list []string) map[string]bool {
+	if len(list) == 0 {
+		return nil
+	}
+	s := make(map[string]bool, len(list))
+	for _, v := range list {
+		s[v] = true
+	}
+	return s
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package bolt
+
+import (
+	"encoding/json"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+// Registrations are read back without the validation of the contract, which the registrations
+// stored by export-client do not always pass
+type boltRegistration contract.Registration
+
+// ********************** REGISTRATION FUNCTIONS *****************************
+// Return all the registrations
+// UnexpectedError - failed to retrieve registrations from the database
+func (c *Client) Registrations() (r []contract.Registration, err error) {
+	r = []contract.Registration{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.ExportCollection, func(o []byte) error {
+			var reg contract.Registration
+			if err := json.Unmarshal(o, (*boltRegistration)(&reg)); err != nil {
+				return err
+			}
+			r = append(r, reg)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Add a new registration
+// UnexpectedError - failed to add to database
+// NotUnique - a registration already has the name
+func (c *Client) AddRegistration(reg contract.Registration) (id string, err error) {
+	if reg.ID != "" {
+		if _, err = uuid.Parse(reg.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addRegistration(tx, reg)
+		return err
+	})
+	return id, err
+}
+
+// Update a registration
+// UnexpectedError - problem updating in database
+// NotFound - no registration with the ID was found
+func (c *Client) UpdateRegistration(reg contract.Registration) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.ExportCollection, reg.ID); err != nil {
+			return err
+		}
+		_, err := addRegistration(tx, reg)
+		return err
+	})
+}
+
+// Get a registration by ID
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the ID was found
+func (c *Client) RegistrationById(id string) (r contract.Registration, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.ExportCollection, id, (*boltRegistration)(&r))
+	})
+	return r, err
+}
+
+// Get a registration by name
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the name was found
+func (c *Client) RegistrationByName(name string) (r contract.Registration, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObjectByName(tx, db.ExportCollection, name, (*boltRegistration)(&r))
+	})
+	return r, err
+}
+
+// Delete a registration by ID
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the ID was found
+func (c *Client) DeleteRegistrationById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.ExportCollection, id)
+	})
+}
+
+// Delete a registration by name
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the name was found
+func (c *Client) DeleteRegistrationByName(name string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		id, err := idByName(tx, db.ExportCollection, name)
+		if err != nil {
+			return err
+		}
+		return deleteObject(tx, db.ExportCollection, id)
+	})
+}
+
+// ScrubAllRegistrations deletes all export related data
+func (c *Client) ScrubAllRegistrations() error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		for _, collection := range []string{db.ExportCollection, db.ExportTemplateCollection, db.ExportFilterCollection, db.ExportCSVCollection} {
+			if err := clearCollection(tx, collection); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ********************** TEMPLATE FUNCTIONS *****************************
+// The templates are stored under the names of their registrations
+
+// Return the templates of the registrations by registration name
+// UnexpectedError - failed to retrieve templates from the database
+func (c *Client) Templates() (map[string]string, error) {
+	return c.registrationOptions(db.ExportTemplateCollection)
+}
+
+// Get the template of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no template
+func (c *Client) TemplateByName(name string) (string, error) {
+	return c.registrationOption(db.ExportTemplateCollection, name)
+}
+
+// Set the template of a registration, replacing the one it had
+// UnexpectedError - problem updating in database
+func (c *Client) UpdateTemplate(name string, template string) error {
+	return c.updateRegistrationOption(db.ExportTemplateCollection, name, template)
+}
+
+// Delete the template of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no template
+func (c *Client) DeleteTemplateByName(name string) error {
+	return c.deleteRegistrationOption(db.ExportTemplateCollection, name)
+}
+
+// ********************** FILTER FUNCTIONS *****************************
+// The filter expressions are stored under the names of their registrations, as the templates are
+
+// Return the filter expressions of the registrations by registration name
+// UnexpectedError - failed to retrieve filters from the database
+func (c *Client) Filters() (map[string]string, error) {
+	return c.registrationOptions(db.ExportFilterCollection)
+}
+
+// Get the filter expression of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no filter
+func (c *Client) FilterByName(name string) (string, error) {
+	return c.registrationOption(db.ExportFilterCollection, name)
+}
+
+// Set the filter expression of a registration, replacing the one it had
+// UnexpectedError - problem updating in database
+func (c *Client) UpdateFilter(name string, expression string) error {
+	return c.updateRegistrationOption(db.ExportFilterCollection, name, expression)
+}
+
+// Delete the filter expression of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no filter
+func (c *Client) DeleteFilterByName(name string) error {
+	return c.deleteRegistrationOption(db.ExportFilterCollection, name)
+}
+
+// ********************** CSV FUNCTIONS *****************************
+// Return the CSV settings of the registrations by registration name
+// UnexpectedError - failed to retrieve CSV settings from the database
+func (c *Client) CSVSettings() (map[string]string, error) {
+	return c.registrationOptions(db.ExportCSVCollection)
+}
+
+// Get the CSV settings of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no CSV settings
+func (c *Client) CSVSettingsByName(name string) (string, error) {
+	return c.registrationOption(db.ExportCSVCollection, name)
+}
+
+// Set the CSV settings of a registration, replacing those it had
+// UnexpectedError - problem updating in database
+func (c *Client) UpdateCSVSettings(name string, settings string) error {
+	return c.updateRegistrationOption(db.ExportCSVCollection, name, settings)
+}
+
+// Delete the CSV settings of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no CSV settings
+func (c *Client) DeleteCSVSettingsByName(name string) error {
+	return c.deleteRegistrationOption(db.ExportCSVCollection, name)
+}
+
+func (c *Client) registrationOptions(collection string) (map[string]string, error) {
+	options := map[string]string{}
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(collection)).ForEach(func(name, o []byte) error {
+			var value string
+			if err := json.Unmarshal(o, &value); err != nil {
+				return err
+			}
+			options[string(name)] = value
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+func (c *Client) registrationOption(collection string, name string) (value string, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, collection, name, &value)
+	})
+	return value, err
+}
+
+func (c *Client) updateRegistrationOption(collection string, name string, value string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return putObject(tx, collection, name, value)
+	})
+}
+
+func (c *Client) deleteRegistrationOption(collection string, name string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, collection, name)
+	})
+}
+
+func addRegistration(tx *bbolt.Tx, r contract.Registration) (string, error) {
+	if err := checkUniqueName(tx, db.ExportCollection, r.Name); err != nil {
+		return "", err
+	}
+
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	r.Created, r.Modified = timestamps(r.Created)
+
+	return r.ID, putRegistration(tx, r)
+}
+
+// The addressable still validates itself when read back, so a registration stored without
+// one leaves the addressable out of its document
+func putRegistration(tx *bbolt.Tx, r contract.Registration) error {
+	if r.Addressable.Id != "" || r.Addressable.Name != "" {
+		return putObject(tx, db.ExportCollection, r.ID, r)
+	}
+
+	o, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(o, &fields); err != nil {
+		return err
+	}
+	delete(fields, "addressable")
+	return putObject(tx, db.ExportCollection, r.ID, fields)
+}
@@ -0,0 +1,1046 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+)
+
+// Devices reference their service and profile by id
+type boltDevice struct {
+	contract.DescribedObject
+	Id             string
+	Name           string
+	AdminState     contract.AdminState
+	OperatingState contract.OperatingState
+	Protocols      map[string]contract.ProtocolProperties
+	AutoEvents     []contract.AutoEvent
+	LastConnected  int64
+	LastReported   int64
+	Labels         []string
+	Location       interface{}
+	Service        string
+	Profile        string
+}
+
+// Device profiles reference their core commands, stored as commands, by id
+type boltDeviceProfile struct {
+	contract.DescribedObject
+	Id              string
+	Name            string
+	Manufacturer    string
+	Model           string
+	Labels          []string
+	DeviceResources []contract.DeviceResource
+	DeviceCommands  []contract.ProfileResource
+	Commands        []string
+}
+
+// Device services reference their addressable by id
+type boltDeviceService struct {
+	contract.DescribedObject
+	Id             string
+	Name           string
+	LastConnected  int64
+	LastReported   int64
+	OperatingState contract.OperatingState
+	Addressable    string
+	Labels         []string
+	AdminState     contract.AdminState
+}
+
+// Provision watchers reference their profile and service by id
+type boltProvisionWatcher struct {
+	contract.Timestamps
+	Id             string
+	Name           string
+	Identifiers    map[string]string
+	Profile        string
+	Service        string
+	OperatingState contract.OperatingState
+}
+
+/* ----------------------------- Device Report ----------------------------------*/
+func (c *Client) GetAllDeviceReports() ([]contract.DeviceReport, error) {
+	return c.findDeviceReports(func(contract.DeviceReport) bool { return true })
+}
+
+func (c *Client) GetDeviceReportByName(n string) (dr contract.DeviceReport, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObjectByName(tx, db.DeviceReport, n, &dr)
+	})
+	return dr, err
+}
+
+func (c *Client) GetDeviceReportByDeviceName(n string) ([]contract.DeviceReport, error) {
+	return c.findDeviceReports(func(dr contract.DeviceReport) bool {
+		return dr.Device == n
+	})
+}
+
+func (c *Client) GetDeviceReportById(id string) (dr contract.DeviceReport, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.DeviceReport, id, &dr)
+	})
+	return dr, err
+}
+
+func (c *Client) GetDeviceReportsByAction(n string) ([]contract.DeviceReport, error) {
+	return c.findDeviceReports(func(dr contract.DeviceReport) bool {
+		return dr.Action == n
+	})
+}
+
+func (c *Client) AddDeviceReport(dr contract.DeviceReport) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addDeviceReport(tx, dr)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) UpdateDeviceReport(dr contract.DeviceReport) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.DeviceReport, dr.Id); err != nil {
+			return err
+		}
+		_, err := addDeviceReport(tx, dr)
+		return err
+	})
+}
+
+func (c *Client) DeleteDeviceReportById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.DeviceReport, id)
+	})
+}
+
+func addDeviceReport(tx *bbolt.Tx, dr contract.DeviceReport) (string, error) {
+	if err := checkUniqueName(tx, db.DeviceReport, dr.Name); err != nil {
+		return "", err
+	}
+
+	dr.Id = validIdOrNew(dr.Id)
+	dr.Created, dr.Modified = timestamps(dr.Created)
+
+	return dr.Id, putObject(tx, db.DeviceReport, dr.Id, dr)
+}
+
+/* ----------------------------- Device ---------------------------------- */
+func (c *Client) AddDevice(d contract.Device) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addDevice(tx, d)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) UpdateDevice(d contract.Device) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.Device, d.Id); err != nil {
+			return err
+		}
+		_, err := addDevice(tx, d)
+		return err
+	})
+}
+
+func (c *Client) DeleteDeviceById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.Device, id)
+	})
+}
+
+func (c *Client) GetAllDevices() ([]contract.Device, error) {
+	return c.findDevices(func(boltDevice) bool { return true })
+}
+
+func (c *Client) GetDevicesByProfileId(id string) ([]contract.Device, error) {
+	d, err := c.findDevices(func(d boltDevice) bool {
+		return d.Profile == id
+	})
+	// should always be checking for database.ErrNotFound but too often it is checking for nil
+	if err == nil && len(d) == 0 {
+		err = db.ErrNotFound
+	}
+	return d, err
+}
+
+func (c *Client) GetDeviceById(id string) (d contract.Device, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var s boltDevice
+		if err := getObject(tx, db.Device, id, &s); err != nil {
+			return err
+		}
+		d, err = s.toContract(tx)
+		return err
+	})
+	return d, err
+}
+
+func (c *Client) GetDeviceByName(n string) (d contract.Device, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var s boltDevice
+		if err := getObjectByName(tx, db.Device, n, &s); err != nil {
+			return err
+		}
+		d, err = s.toContract(tx)
+		return err
+	})
+	return d, err
+}
+
+func (c *Client) GetDevicesByServiceId(id string) ([]contract.Device, error) {
+	d, err := c.findDevices(func(d boltDevice) bool {
+		return d.Service == id
+	})
+	// should always be checking for database.ErrNotFound but too often it is checking for nil
+	if err == nil && len(d) == 0 {
+		err = db.ErrNotFound
+	}
+	return d, err
+}
+
+func (c *Client) GetDevicesWithLabel(l string) ([]contract.Device, error) {
+	return c.findDevices(func(d boltDevice) bool {
+		return contains(d.Labels, l)
+	})
+}
+
+func addDevice(tx *bbolt.Tx, d contract.Device) (string, error) {
+	if err := checkUniqueName(tx, db.Device, d.Name); err != nil {
+		return "", err
+	}
+
+	d.Id = validIdOrNew(d.Id)
+	d.Created, d.Modified = timestamps(d.Created)
+
+	s := boltDevice{
+		DescribedObject: d.DescribedObject,
+		Id:              d.Id,
+		Name:            d.Name,
+		AdminState:      d.AdminState,
+		OperatingState:  d.OperatingState,
+		Protocols:       d.Protocols,
+		AutoEvents:      d.AutoEvents,
+		LastConnected:   d.LastConnected,
+		LastReported:    d.LastReported,
+		Labels:          d.Labels,
+		Location:        d.Location,
+		Service:         d.Service.Id,
+		Profile:         d.Profile.Id,
+	}
+	return s.Id, putObject(tx, db.Device, s.Id, s)
+}
+
+func (s boltDevice) toContract(tx *bbolt.Tx) (d contract.Device, err error) {
+	d = contract.Device{
+		DescribedObject: s.DescribedObject,
+		Id:              s.Id,
+		Name:            s.Name,
+		AdminState:      s.AdminState,
+		OperatingState:  s.OperatingState,
+		Protocols:       s.Protocols,
+		AutoEvents:      s.AutoEvents,
+		LastConnected:   s.LastConnected,
+		LastReported:    s.LastReported,
+		Labels:          s.Labels,
+		Location:        s.Location,
+	}
+
+	if d.Service, err = deviceServiceById(tx, s.Service); err != nil {
+		return d, err
+	}
+	d.Profile, err = deviceProfileById(tx, s.Profile)
+	return d, err
+}
+
+/* -----------------------------Device Profile -----------------------------*/
+func (c *Client) GetDeviceProfileById(id string) (dp contract.DeviceProfile, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		dp, err = deviceProfileById(tx, id)
+		return err
+	})
+	return dp, err
+}
+
+func (c *Client) GetAllDeviceProfiles() ([]contract.DeviceProfile, error) {
+	return c.findDeviceProfiles(func(boltDeviceProfile) bool { return true })
+}
+
+func (c *Client) GetDeviceProfilesByModel(model string) ([]contract.DeviceProfile, error) {
+	return c.findDeviceProfiles(func(dp boltDeviceProfile) bool {
+		return dp.Model == model
+	})
+}
+
+func (c *Client) GetDeviceProfilesWithLabel(l string) ([]contract.DeviceProfile, error) {
+	return c.findDeviceProfiles(func(dp boltDeviceProfile) bool {
+		return contains(dp.Labels, l)
+	})
+}
+
+func (c *Client) GetDeviceProfilesByManufacturerModel(man string, mod string) ([]contract.DeviceProfile, error) {
+	return c.findDeviceProfiles(func(dp boltDeviceProfile) bool {
+		return dp.Manufacturer == man && dp.Model == mod
+	})
+}
+
+func (c *Client) GetDeviceProfilesByManufacturer(man string) ([]contract.DeviceProfile, error) {
+	return c.findDeviceProfiles(func(dp boltDeviceProfile) bool {
+		return dp.Manufacturer == man
+	})
+}
+
+func (c *Client) GetDeviceProfileByName(n string) (dp contract.DeviceProfile, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var s boltDeviceProfile
+		if err := getObjectByName(tx, db.DeviceProfile, n, &s); err != nil {
+			return err
+		}
+		dp, err = s.toContract(tx)
+		return err
+	})
+	return dp, err
+}
+
+func (c *Client) GetDeviceProfilesByCommandId(id string) ([]contract.DeviceProfile, error) {
+	dp, err := c.findDeviceProfiles(func(dp boltDeviceProfile) bool {
+		return contains(dp.Commands, id)
+	})
+	// should always be checking for database.ErrNotFound but too often it is checking for nil
+	if err == nil && len(dp) == 0 {
+		err = db.ErrNotFound
+	}
+	return dp, err
+}
+
+func (c *Client) AddDeviceProfile(dp contract.DeviceProfile) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addDeviceProfile(tx, dp)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) UpdateDeviceProfile(dp contract.DeviceProfile) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.DeviceProfile, dp.Id); err != nil {
+			return err
+		}
+		_, err := addDeviceProfile(tx, dp)
+		return err
+	})
+}
+
+func (c *Client) DeleteDeviceProfileById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.DeviceProfile, id)
+	})
+}
+
+// The core commands of the profile are stored as commands, keeping their id when they have one
+func addDeviceProfile(tx *bbolt.Tx, dp contract.DeviceProfile) (string, error) {
+	if err := checkUniqueName(tx, db.DeviceProfile, dp.Name); err != nil {
+		return "", err
+	}
+
+	dp.Id = validIdOrNew(dp.Id)
+	dp.Created, dp.Modified = timestamps(dp.Created)
+
+	s := boltDeviceProfile{
+		DescribedObject: dp.DescribedObject,
+		Id:              dp.Id,
+		Name:            dp.Name,
+		Manufacturer:    dp.Manufacturer,
+		Model:           dp.Model,
+		Labels:          dp.Labels,
+		DeviceResources: dp.DeviceResources,
+		DeviceCommands:  dp.DeviceCommands,
+		Commands:        make([]string, len(dp.CoreCommands)),
+	}
+	for i, cmd := range dp.CoreCommands {
+		id, err := addCommand(tx, cmd)
+		if err != nil {
+			return "", err
+		}
+		s.Commands[i] = id
+	}
+	return s.Id, putObject(tx, db.DeviceProfile, s.Id, s)
+}
+
+func deviceProfileById(tx *bbolt.Tx, id string) (contract.DeviceProfile, error) {
+	var s boltDeviceProfile
+	if err := getObject(tx, db.DeviceProfile, id, &s); err != nil {
+		return contract.DeviceProfile{}, err
+	}
+	return s.toContract(tx)
+}
+
+// Commands removed since the profile was stored are left out
+func (s boltDeviceProfile) toContract(tx *bbolt.Tx) (contract.DeviceProfile, error) {
+	dp := contract.DeviceProfile{
+		DescribedObject: s.DescribedObject,
+		Id:              s.Id,
+		Name:            s.Name,
+		Manufacturer:    s.Manufacturer,
+		Model:           s.Model,
+		Labels:          s.Labels,
+		DeviceResources: s.DeviceResources,
+		DeviceCommands:  s.DeviceCommands,
+		CoreCommands:    make([]contract.Command, 0, len(s.Commands)),
+	}
+
+	for _, id := range s.Commands {
+		var cmd contract.Command
+		err := getObject(tx, db.Command, id, &cmd)
+		if err == db.ErrNotFound {
+			continue
+		} else if err != nil {
+			return dp, err
+		}
+		dp.CoreCommands = append(dp.CoreCommands, cmd)
+	}
+	return dp, nil
+}
+
+/* -----------------------------------Addressable -------------------------- */
+func (c *Client) UpdateAddressable(a contract.Addressable) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.Addressable, a.Id); err != nil {
+			return err
+		}
+		_, err := addAddressable(tx, a)
+		return err
+	})
+}
+
+func (c *Client) AddAddressable(a contract.Addressable) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addAddressable(tx, a)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) GetAddressableById(id string) (a contract.Addressable, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.Addressable, id, &a)
+	})
+	return a, err
+}
+
+func (c *Client) GetAddressableByName(n string) (a contract.Addressable, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObjectByName(tx, db.Addressable, n, &a)
+	})
+	return a, err
+}
+
+func (c *Client) GetAddressablesByTopic(t string) ([]contract.Addressable, error) {
+	return c.findAddressables(func(a contract.Addressable) bool {
+		return a.Topic == t
+	})
+}
+
+func (c *Client) GetAddressablesByPort(p int) ([]contract.Addressable, error) {
+	return c.findAddressables(func(a contract.Addressable) bool {
+		return a.Port == p
+	})
+}
+
+func (c *Client) GetAddressablesByPublisher(p string) ([]contract.Addressable, error) {
+	return c.findAddressables(func(a contract.Addressable) bool {
+		return a.Publisher == p
+	})
+}
+
+func (c *Client) GetAddressablesByAddress(add string) ([]contract.Addressable, error) {
+	return c.findAddressables(func(a contract.Addressable) bool {
+		return a.Address == add
+	})
+}
+
+func (c *Client) GetAddressables() ([]contract.Addressable, error) {
+	return c.findAddressables(func(contract.Addressable) bool { return true })
+}
+
+func (c *Client) DeleteAddressableById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.Addressable, id)
+	})
+}
+
+func addAddressable(tx *bbolt.Tx, a contract.Addressable) (string, error) {
+	if err := checkUniqueName(tx, db.Addressable, a.Name); err != nil {
+		return a.Id, err
+	}
+
+	a.Id = validIdOrNew(a.Id)
+	a.Created, a.Modified = timestamps(a.Created)
+
+	return a.Id, putObject(tx, db.Addressable, a.Id, a)
+}
+
+/* ----------------------------- Device Service ----------------------------------*/
+func (c *Client) GetDeviceServiceByName(n string) (ds contract.DeviceService, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var s boltDeviceService
+		if err := getObjectByName(tx, db.DeviceService, n, &s); err != nil {
+			return err
+		}
+		ds, err = s.toContract(tx)
+		return err
+	})
+	return ds, err
+}
+
+func (c *Client) GetDeviceServiceById(id string) (ds contract.DeviceService, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		ds, err = deviceServiceById(tx, id)
+		return err
+	})
+	return ds, err
+}
+
+func (c *Client) GetAllDeviceServices() ([]contract.DeviceService, error) {
+	return c.findDeviceServices(func(boltDeviceService) bool { return true })
+}
+
+// XXX This should really return an ErrNotFound when there are none, as with the other backends it does not
+func (c *Client) GetDeviceServicesByAddressableId(id string) ([]contract.DeviceService, error) {
+	return c.findDeviceServices(func(ds boltDeviceService) bool {
+		return ds.Addressable == id
+	})
+}
+
+func (c *Client) GetDeviceServicesWithLabel(l string) ([]contract.DeviceService, error) {
+	return c.findDeviceServices(func(ds boltDeviceService) bool {
+		return contains(ds.Labels, l)
+	})
+}
+
+func (c *Client) AddDeviceService(ds contract.DeviceService) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addDeviceService(tx, ds)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) UpdateDeviceService(ds contract.DeviceService) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.DeviceService, ds.Id); err != nil {
+			return err
+		}
+		_, err := addDeviceService(tx, ds)
+		return err
+	})
+}
+
+func (c *Client) DeleteDeviceServiceById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.DeviceService, id)
+	})
+}
+
+// The addressable of the service must exist, referenced either by id or by name
+func addDeviceService(tx *bbolt.Tx, ds contract.DeviceService) (string, error) {
+	if err := checkUniqueName(tx, db.DeviceService, ds.Name); err != nil {
+		return "", err
+	}
+
+	aid, err := resolveId(tx, db.Addressable, ds.Addressable.Id, ds.Addressable.Name)
+	if err == db.ErrNotFound {
+		return "", errors.New("Invalid addressable")
+	} else if err != nil {
+		return "", err
+	}
+
+	ds.Id = validIdOrNew(ds.Id)
+	ds.Created, ds.Modified = timestamps(ds.Created)
+
+	s := boltDeviceService{
+		DescribedObject: ds.DescribedObject,
+		Id:              ds.Id,
+		Name:            ds.Name,
+		LastConnected:   ds.LastConnected,
+		LastReported:    ds.LastReported,
+		OperatingState:  ds.OperatingState,
+		Addressable:     aid,
+		Labels:          ds.Labels,
+		AdminState:      ds.AdminState,
+	}
+	return s.Id, putObject(tx, db.DeviceService, s.Id, s)
+}
+
+func deviceServiceById(tx *bbolt.Tx, id string) (contract.DeviceService, error) {
+	var s boltDeviceService
+	if err := getObject(tx, db.DeviceService, id, &s); err != nil {
+		return contract.DeviceService{}, err
+	}
+	return s.toContract(tx)
+}
+
+func (s boltDeviceService) toContract(tx *bbolt.Tx) (ds contract.DeviceService, err error) {
+	ds = contract.DeviceService{
+		DescribedObject: s.DescribedObject,
+		Id:              s.Id,
+		Name:            s.Name,
+		LastConnected:   s.LastConnected,
+		LastReported:    s.LastReported,
+		OperatingState:  s.OperatingState,
+		Labels:          s.Labels,
+		AdminState:      s.AdminState,
+	}
+	err = getObject(tx, db.Addressable, s.Addressable, &ds.Addressable)
+	return ds, err
+}
+
+/* ----------------------------- Provision Watcher ----------------------------------*/
+func (c *Client) GetProvisionWatcherById(id string) (pw contract.ProvisionWatcher, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var s boltProvisionWatcher
+		if err := getObject(tx, db.ProvisionWatcher, id, &s); err != nil {
+			return err
+		}
+		pw, err = s.toContract(tx)
+		return err
+	})
+	return pw, err
+}
+
+func (c *Client) GetAllProvisionWatchers() ([]contract.ProvisionWatcher, error) {
+	return c.findProvisionWatchers(func(boltProvisionWatcher) bool { return true })
+}
+
+func (c *Client) GetProvisionWatcherByName(n string) (pw contract.ProvisionWatcher, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		var s boltProvisionWatcher
+		if err := getObjectByName(tx, db.ProvisionWatcher, n, &s); err != nil {
+			return err
+		}
+		pw, err = s.toContract(tx)
+		return err
+	})
+	return pw, err
+}
+
+func (c *Client) GetProvisionWatchersByProfileId(id string) ([]contract.ProvisionWatcher, error) {
+	pw, err := c.findProvisionWatchers(func(pw boltProvisionWatcher) bool {
+		return pw.Profile == id
+	})
+	// should always be checking for database.ErrNotFound but too often it is checking for nil
+	if err == nil && len(pw) == 0 {
+		err = db.ErrNotFound
+	}
+	return pw, err
+}
+
+func (c *Client) GetProvisionWatchersByServiceId(id string) ([]contract.ProvisionWatcher, error) {
+	pw, err := c.findProvisionWatchers(func(pw boltProvisionWatcher) bool {
+		return pw.Service == id
+	})
+	// should always be checking for database.ErrNotFound but too often it is checking for nil
+	if err == nil && len(pw) == 0 {
+		err = db.ErrNotFound
+	}
+	return pw, err
+}
+
+func (c *Client) GetProvisionWatchersByIdentifier(k string, v string) ([]contract.ProvisionWatcher, error) {
+	return c.findProvisionWatchers(func(pw boltProvisionWatcher) bool {
+		value, ok := pw.Identifiers[k]
+		return ok && value == v
+	})
+}
+
+func (c *Client) AddProvisionWatcher(pw contract.ProvisionWatcher) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addProvisionWatcher(tx, pw)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) UpdateProvisionWatcher(pw contract.ProvisionWatcher) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.ProvisionWatcher, pw.Id); err != nil {
+			return err
+		}
+		_, err := addProvisionWatcher(tx, pw)
+		return err
+	})
+}
+
+func (c *Client) DeleteProvisionWatcherById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.ProvisionWatcher, id)
+	})
+}
+
+// The profile and service of the watcher must exist, referenced either by id or by name
+func addProvisionWatcher(tx *bbolt.Tx, pw contract.ProvisionWatcher) (string, error) {
+	if err := checkUniqueName(tx, db.ProvisionWatcher, pw.Name); err != nil {
+		return "", err
+	}
+
+	pid, err := resolveId(tx, db.DeviceProfile, pw.Profile.Id, pw.Profile.Name)
+	if err == db.ErrNotFound {
+		return "", errors.New("Invalid Device Profile")
+	} else if err != nil {
+		return "", err
+	}
+
+	sid, err := resolveId(tx, db.DeviceService, pw.Service.Id, pw.Service.Name)
+	if err == db.ErrNotFound {
+		return "", errors.New("Invalid Device Service")
+	} else if err != nil {
+		return "", err
+	}
+
+	pw.Id = validIdOrNew(pw.Id)
+	pw.Created, pw.Modified = timestamps(pw.Created)
+
+	s := boltProvisionWatcher{
+		Timestamps:     pw.Timestamps,
+		Id:             pw.Id,
+		Name:           pw.Name,
+		Identifiers:    pw.Identifiers,
+		Profile:        pid,
+		Service:        sid,
+		OperatingState: pw.OperatingState,
+	}
+	return s.Id, putObject(tx, db.ProvisionWatcher, s.Id, s)
+}
+
+func (s boltProvisionWatcher) toContract(tx *bbolt.Tx) (pw contract.ProvisionWatcher, err error) {
+	pw = contract.ProvisionWatcher{
+		Timestamps:     s.Timestamps,
+		Id:             s.Id,
+		Name:           s.Name,
+		Identifiers:    s.Identifiers,
+		OperatingState: s.OperatingState,
+	}
+
+	if pw.Profile, err = deviceProfileById(tx, s.Profile); err != nil {
+		return pw, err
+	}
+	pw.Service, err = deviceServiceById(tx, s.Service)
+	return pw, err
+}
+
+/* ------------------------------------Command -----------------------------------*/
+func (c *Client) GetAllCommands() ([]contract.Command, error) {
+	return c.findCommands(func(contract.Command) bool { return true })
+}
+
+func (c *Client) GetCommandById(id string) (cmd contract.Command, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.Command, id, &cmd)
+	})
+	return cmd, err
+}
+
+func (c *Client) GetCommandByName(n string) ([]contract.Command, error) {
+	return c.findCommands(func(cmd contract.Command) bool {
+		return cmd.Name == n
+	})
+}
+
+func (c *Client) AddCommand(cmd contract.Command) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addCommand(tx, cmd)
+		return err
+	})
+	return id, err
+}
+
+// Update command uses the ID of the command for identification
+func (c *Client) UpdateCommand(cmd contract.Command) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.Command, cmd.Id); err != nil {
+			return err
+		}
+		_, err := addCommand(tx, cmd)
+		return err
+	})
+}
+
+// Delete the command by ID
+func (c *Client) DeleteCommandById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.Command, id)
+	})
+}
+
+func addCommand(tx *bbolt.Tx, cmd contract.Command) (string, error) {
+	cmd.Id = validIdOrNew(cmd.Id)
+	cmd.Created, cmd.Modified = timestamps(cmd.Created)
+
+	return cmd.Id, putObject(tx, db.Command, cmd.Id, cmd)
+}
+
+func (c *Client) ScrubMetadata() error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		cols := []string{
+			db.Addressable, db.Command, db.DeviceService, db.DeviceReport, db.DeviceProfile,
+			db.Device, db.ProvisionWatcher,
+		}
+		for _, col := range cols {
+			if err := clearCollection(tx, col); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/* ------------------------------------ Helpers -----------------------------------*/
+// Keep an id that is a UUID, generating one otherwise
+func validIdOrNew(id string) string {
+	if _, err := uuid.Parse(id); err != nil {
+		return uuid.New().String()
+	}
+	return id
+}
+
+// Return the creation and modification times of an object stored now
+func timestamps(created int64) (int64, int64) {
+	ts := db.MakeTimestamp()
+	if created == 0 {
+		created = ts
+	}
+	return created, ts
+}
+
+// Read the object of a collection with a name
+// NotFound - no object with the name in the collection
+func getObjectByName(tx *bbolt.Tx, collection string, name string, o interface{}) error {
+	id, err := idByName(tx, collection, name)
+	if err != nil {
+		return err
+	}
+	return getObject(tx, collection, id, o)
+}
+
+// Return the id of the object of a collection with a name
+// NotFound - no object with the name in the collection
+func idByName(tx *bbolt.Tx, collection string, name string) (id string, err error) {
+	err = db.ErrNotFound
+	scan := forEachObject(tx, collection, func(o []byte) error {
+		// Field names are matched regardless of case, which covers both the contract and the stored structs
+		var named struct {
+			Id   string
+			Name string
+		}
+		if err := json.Unmarshal(o, &named); err != nil {
+			return err
+		}
+		if named.Name == name {
+			id, err = named.Id, nil
+		}
+		return nil
+	})
+	if scan != nil {
+		return "", scan
+	}
+	return id, err
+}
+
+// NotUnique - an object of the collection already has the name
+func checkUniqueName(tx *bbolt.Tx, collection string, name string) error {
+	_, err := idByName(tx, collection, name)
+	if err == nil {
+		return db.ErrNotUnique
+	} else if err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// Return the id of an object referenced by id, or by name when no object has the id
+// NotFound - the reference matches no object of the collection
+func resolveId(tx *bbolt.Tx, collection string, id string, name string) (string, error) {
+	if id != "" && tx.Bucket([]byte(collection)).Get([]byte(id)) != nil {
+		return id, nil
+	}
+	return idByName(tx, collection, name)
+}
+
+func (c *Client) findDeviceReports(match func(dr contract.DeviceReport) bool) (reports []contract.DeviceReport, err error) {
+	reports = []contract.DeviceReport{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.DeviceReport, func(o []byte) error {
+			var dr contract.DeviceReport
+			if err := json.Unmarshal(o, &dr); err != nil {
+				return err
+			}
+			if match(dr) {
+				reports = append(reports, dr)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.DeviceReport{}, err
+	}
+	return reports, nil
+}
+
+func (c *Client) findDevices(match func(d boltDevice) bool) (devices []contract.Device, err error) {
+	devices = []contract.Device{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Device, func(o []byte) error {
+			var s boltDevice
+			if err := json.Unmarshal(o, &s); err != nil {
+				return err
+			}
+			if !match(s) {
+				return nil
+			}
+			d, err := s.toContract(tx)
+			if err != nil {
+				return err
+			}
+			devices = append(devices, d)
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.Device{}, err
+	}
+	return devices, nil
+}
+
+func (c *Client) findDeviceProfiles(match func(dp boltDeviceProfile) bool) (profiles []contract.DeviceProfile, err error) {
+	profiles = []contract.DeviceProfile{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.DeviceProfile, func(o []byte) error {
+			var s boltDeviceProfile
+			if err := json.Unmarshal(o, &s); err != nil {
+				return err
+			}
+			if !match(s) {
+				return nil
+			}
+			dp, err := s.toContract(tx)
+			if err != nil {
+				return err
+			}
+			profiles = append(profiles, dp)
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.DeviceProfile{}, err
+	}
+	return profiles, nil
+}
+
+func (c *Client) findAddressables(match func(a contract.Addressable) bool) (addressables []contract.Addressable, err error) {
+	addressables = []contract.Addressable{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Addressable, func(o []byte) error {
+			var a contract.Addressable
+			if err := json.Unmarshal(o, &a); err != nil {
+				return err
+			}
+			if match(a) {
+				addressables = append(addressables, a)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.Addressable{}, err
+	}
+	return addressables, nil
+}
+
+func (c *Client) findDeviceServices(match func(ds boltDeviceService) bool) (services []contract.DeviceService, err error) {
+	services = []contract.DeviceService{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.DeviceService, func(o []byte) error {
+			var s boltDeviceService
+			if err := json.Unmarshal(o, &s); err != nil {
+				return err
+			}
+			if !match(s) {
+				return nil
+			}
+			ds, err := s.toContract(tx)
+			if err != nil {
+				return err
+			}
+			services = append(services, ds)
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.DeviceService{}, err
+	}
+	return services, nil
+}
+
+func (c *Client) findProvisionWatchers(match func(pw boltProvisionWatcher) bool) (watchers []contract.ProvisionWatcher, err error) {
+	watchers = []contract.ProvisionWatcher{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.ProvisionWatcher, func(o []byte) error {
+			var s boltProvisionWatcher
+			if err := json.Unmarshal(o, &s); err != nil {
+				return err
+			}
+			if !match(s) {
+				return nil
+			}
+			pw, err := s.toContract(tx)
+			if err != nil {
+				return err
+			}
+			watchers = append(watchers, pw)
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.ProvisionWatcher{}, err
+	}
+	return watchers, nil
+}
+
+func (c *Client) findCommands(match func(cmd contract.Command) bool) (commands []contract.Command, err error) {
+	commands = []contract.Command{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Command, func(o []byte) error {
+			var cmd contract.Command
+			if err := json.Unmarshal(o, &cmd); err != nil {
+				return err
+			}
+			if match(cmd) {
+				commands = append(commands, cmd)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.Command{}, err
+	}
+	return commands, nil
+}
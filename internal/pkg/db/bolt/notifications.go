@@ -0,0 +1,522 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package bolt
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+)
+
+// ******************************* NOTIFICATIONS **********************************
+func (c *Client) AddNotification(n contract.Notification) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addNotification(tx, n)
+		return err
+	})
+	return id, err
+}
+
+func (c *Client) UpdateNotification(n contract.Notification) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.Notification, n.ID); err != nil {
+			return err
+		}
+		n.Modified = db.MakeTimestamp()
+		_, err := addNotification(tx, n)
+		return err
+	})
+}
+
+// Get all notifications, oldest first
+func (c *Client) GetNotifications() ([]contract.Notification, error) {
+	return c.findNotifications(func(contract.Notification) bool { return true }, 0)
+}
+
+func (c *Client) GetNotificationById(id string) (n contract.Notification, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.Notification, id, &n)
+	})
+	return n, err
+}
+
+func (c *Client) GetNotificationBySlug(slug string) (n contract.Notification, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		id, err := idBySlug(tx, db.Notification, slug)
+		if err != nil {
+			return err
+		}
+		return getObject(tx, db.Notification, id, &n)
+	})
+	return n, err
+}
+
+func (c *Client) GetNotificationBySender(sender string, limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		return n.Sender == sender
+	}, limit)
+}
+
+func (c *Client) GetNotificationsByLabels(labels []string, limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		for _, label := range labels {
+			if contains(n.Labels, label) {
+				return true
+			}
+		}
+		return false
+	}, limit)
+}
+
+func (c *Client) GetNotificationsByStartEnd(start int64, end int64, limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		return n.Created >= start && n.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetNotificationsByStart(start int64, limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		return n.Created >= start
+	}, limit)
+}
+
+func (c *Client) GetNotificationsByEnd(end int64, limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		return n.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetNewNotifications(limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		return n.Status == contract.New
+	}, limit)
+}
+
+func (c *Client) GetNewNormalNotifications(limit int) ([]contract.Notification, error) {
+	return c.findNotifications(func(n contract.Notification) bool {
+		return n.Status == contract.New && n.Severity == contract.Normal
+	}, limit)
+}
+
+func (c *Client) MarkNotificationProcessed(n contract.Notification) error {
+	n.Status = contract.NotificationsStatus(contract.Processed)
+	return c.UpdateNotification(n)
+}
+
+// Delete a notification along with its transmissions
+// A notification that does not exist is not an error
+func (c *Client) DeleteNotificationById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var n contract.Notification
+		if err := getObject(tx, db.Notification, id, &n); err != nil {
+			return nil
+		}
+		return deleteNotification(tx, n)
+	})
+}
+
+func (c *Client) DeleteNotificationBySlug(slug string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		id, err := idBySlug(tx, db.Notification, slug)
+		if err != nil {
+			return err
+		}
+
+		var n contract.Notification
+		if err = getObject(tx, db.Notification, id, &n); err != nil {
+			return err
+		}
+		return deleteNotification(tx, n)
+	})
+}
+
+// DeleteNotificationsOld remove all the processed notifications that are older than the given age
+func (c *Client) DeleteNotificationsOld(age int) error {
+	end := db.MakeTimestamp() - int64(age)
+
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObjects(tx, db.Notification, func(o []byte) (bool, error) {
+			var n contract.Notification
+			if err := json.Unmarshal(o, &n); err != nil {
+				return false, err
+			}
+			return n.Status == contract.Processed && n.Modified <= end, nil
+		})
+	})
+}
+
+// ******************************* SUBSCRIPTIONS **********************************
+func (c *Client) AddSubscription(s contract.Subscription) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addSubscription(tx, s)
+		return err
+	})
+	return id, err
+}
+
+// A subscription that does not exist is not an error
+func (c *Client) UpdateSubscription(s contract.Subscription) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.Subscription, s.ID); err != nil {
+			return nil
+		}
+		s.Modified = db.MakeTimestamp()
+		_, err := addSubscription(tx, s)
+		return err
+	})
+}
+
+func (c *Client) GetSubscriptions() ([]contract.Subscription, error) {
+	return c.findSubscriptions(func(contract.Subscription) bool { return true })
+}
+
+func (c *Client) GetSubscriptionById(id string) (s contract.Subscription, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.Subscription, id, &s)
+	})
+	return s, err
+}
+
+func (c *Client) DeleteSubscriptionById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.Subscription, id)
+	})
+}
+
+func (c *Client) GetSubscriptionBySlug(slug string) (s contract.Subscription, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		id, err := idBySlug(tx, db.Subscription, slug)
+		if err != nil {
+			return err
+		}
+		return getObject(tx, db.Subscription, id, &s)
+	})
+	return s, err
+}
+
+func (c *Client) GetSubscriptionByReceiver(receiver string) ([]contract.Subscription, error) {
+	return c.findSubscriptions(func(s contract.Subscription) bool {
+		return s.Receiver == receiver
+	})
+}
+
+// Return the subscriptions to any of the categories
+func (c *Client) GetSubscriptionByCategories(categories []string) ([]contract.Subscription, error) {
+	return c.GetSubscriptionByCategoriesLabels(categories, nil)
+}
+
+// Return the subscriptions to any of the labels
+func (c *Client) GetSubscriptionByLabels(labels []string) ([]contract.Subscription, error) {
+	return c.GetSubscriptionByCategoriesLabels(nil, labels)
+}
+
+// Return the subscriptions to any of the categories or any of the labels
+func (c *Client) GetSubscriptionByCategoriesLabels(categories []string, labels []string) ([]contract.Subscription, error) {
+	return c.findSubscriptions(func(s contract.Subscription) bool {
+		for _, category := range s.SubscribedCategories {
+			if contains(categories, string(category)) {
+				return true
+			}
+		}
+		for _, label := range s.SubscribedLabels {
+			if contains(labels, label) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (c *Client) DeleteSubscriptionBySlug(slug string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		id, err := idBySlug(tx, db.Subscription, slug)
+		if err != nil {
+			return err
+		}
+		return deleteObject(tx, db.Subscription, id)
+	})
+}
+
+// ******************************* TRANSMISSIONS **********************************
+func (c *Client) AddTransmission(t contract.Transmission) (id string, err error) {
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		id, err = addTransmission(tx, t)
+		return err
+	})
+	return id, err
+}
+
+// A transmission that does not exist is not an error
+func (c *Client) UpdateTransmission(t contract.Transmission) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		if err := deleteObject(tx, db.Transmission, t.ID); err != nil {
+			return nil
+		}
+		t.Modified = db.MakeTimestamp()
+		_, err := addTransmission(tx, t)
+		return err
+	})
+}
+
+func (c *Client) GetTransmissionsByNotificationSlug(slug string, limit int) ([]contract.Transmission, error) {
+	return c.findTransmissions(func(t contract.Transmission) bool {
+		return t.Notification.Slug == slug
+	}, limit)
+}
+
+func (c *Client) GetTransmissionsByStartEnd(start int64, end int64, limit int) ([]contract.Transmission, error) {
+	return c.findTransmissions(func(t contract.Transmission) bool {
+		return t.Created >= start && t.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetTransmissionsByStart(start int64, limit int) ([]contract.Transmission, error) {
+	return c.findTransmissions(func(t contract.Transmission) bool {
+		return t.Created >= start
+	}, limit)
+}
+
+func (c *Client) GetTransmissionById(id string) (t contract.Transmission, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.Transmission, id, &t)
+	})
+	return t, err
+}
+
+func (c *Client) GetTransmissionsByEnd(end int64, limit int) ([]contract.Transmission, error) {
+	return c.findTransmissions(func(t contract.Transmission) bool {
+		return t.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetTransmissionsByStatus(limit int, status contract.TransmissionStatus) ([]contract.Transmission, error) {
+	return c.findTransmissions(func(t contract.Transmission) bool {
+		return t.Status == status
+	}, limit)
+}
+
+// DeleteTransmission delete old transmission with specified status
+func (c *Client) DeleteTransmission(age int64, status contract.TransmissionStatus) error {
+	end := db.MakeTimestamp() - age
+
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObjects(tx, db.Transmission, func(o []byte) (bool, error) {
+			var t contract.Transmission
+			if err := json.Unmarshal(o, &t); err != nil {
+				return false, err
+			}
+			return t.Status == status && t.Modified <= end, nil
+		})
+	})
+}
+
+// Cleanup delete all notifications and associated transmissions
+func (c *Client) Cleanup() error {
+	return c.CleanupOld(0)
+}
+
+// Cleanup delete old notifications and associated transmissions
+func (c *Client) CleanupOld(age int) error {
+	end := db.MakeTimestamp() - int64(age)
+
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var old []contract.Notification
+		err := forEachObject(tx, db.Notification, func(o []byte) error {
+			var n contract.Notification
+			if err := json.Unmarshal(o, &n); err != nil {
+				return err
+			}
+			if n.Created <= end {
+				old = append(old, n)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, n := range old {
+			if err = deleteNotification(tx, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ************************** HELPER FUNCTIONS ***************************
+func addNotification(tx *bbolt.Tx, n contract.Notification) (string, error) {
+	if err := checkUniqueSlug(tx, db.Notification, n.Slug); err != nil {
+		return "", err
+	}
+
+	if n.Created == 0 {
+		n.Created = db.MakeTimestamp()
+		n.Modified = n.Created
+	}
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+
+	return n.ID, putObject(tx, db.Notification, n.ID, n)
+}
+
+// Transmissions of the notification are deleted with it
+func deleteNotification(tx *bbolt.Tx, n contract.Notification) error {
+	err := deleteObjects(tx, db.Transmission, func(o []byte) (bool, error) {
+		var t contract.Transmission
+		if err := json.Unmarshal(o, &t); err != nil {
+			return false, err
+		}
+		return t.Notification.Slug == n.Slug, nil
+	})
+	if err != nil {
+		return err
+	}
+	return deleteObject(tx, db.Notification, n.ID)
+}
+
+func addSubscription(tx *bbolt.Tx, s contract.Subscription) (string, error) {
+	if err := checkUniqueSlug(tx, db.Subscription, s.Slug); err != nil {
+		return "", err
+	}
+
+	if s.Created == 0 {
+		s.Created = db.MakeTimestamp()
+		s.Modified = s.Created
+	}
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+
+	return s.ID, putObject(tx, db.Subscription, s.ID, s)
+}
+
+func addTransmission(tx *bbolt.Tx, t contract.Transmission) (string, error) {
+	if t.Created == 0 {
+		t.Created = db.MakeTimestamp()
+		t.Modified = t.Created
+	}
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+
+	return t.ID, putObject(tx, db.Transmission, t.ID, t)
+}
+
+// Return the id of the object of a collection with a slug
+// NotFound - no object with the slug in the collection
+func idBySlug(tx *bbolt.Tx, collection string, slug string) (id string, err error) {
+	err = db.ErrNotFound
+	scan := forEachObject(tx, collection, func(o []byte) error {
+		var slugged struct {
+			ID   string
+			Slug string
+		}
+		if err := json.Unmarshal(o, &slugged); err != nil {
+			return err
+		}
+		if slugged.Slug == slug {
+			id, err = slugged.ID, nil
+		}
+		return nil
+	})
+	if scan != nil {
+		return "", scan
+	}
+	return id, err
+}
+
+func checkUniqueSlug(tx *bbolt.Tx, collection string, slug string) error {
+	_, err := idBySlug(tx, collection, slug)
+	if err == nil {
+		return errors.Errorf("%v, slug=%v", db.ErrNotUnique, slug)
+	} else if err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// Return the notifications matching a predicate, oldest first
+// Limit the number of results by limit unless it is 0
+func (c *Client) findNotifications(match func(n contract.Notification) bool, limit int) (notifications []contract.Notification, err error) {
+	notifications = []contract.Notification{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Notification, func(o []byte) error {
+			var n contract.Notification
+			if err := json.Unmarshal(o, &n); err != nil {
+				return err
+			}
+			if match(n) {
+				notifications = append(notifications, n)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool { return notifications[i].Created < notifications[j].Created })
+	return notifications[:limitOf(len(notifications), limit)], nil
+}
+
+func (c *Client) findSubscriptions(match func(s contract.Subscription) bool) (subscriptions []contract.Subscription, err error) {
+	subscriptions = []contract.Subscription{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Subscription, func(o []byte) error {
+			var s contract.Subscription
+			if err := json.Unmarshal(o, &s); err != nil {
+				return err
+			}
+			if match(s) {
+				subscriptions = append(subscriptions, s)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Return the transmissions matching a predicate, oldest first
+// Limit the number of results by limit unless it is 0
+func (c *Client) findTransmissions(match func(t contract.Transmission) bool, limit int) (transmissions []contract.Transmission, err error) {
+	transmissions = []contract.Transmission{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Transmission, func(o []byte) error {
+			var t contract.Transmission
+			if err := json.Unmarshal(o, &t); err != nil {
+				return err
+			}
+			if match(t) {
+				transmissions = append(transmissions, t)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(transmissions, func(i, j int) bool { return transmissions[i].Created < transmissions[j].Created })
+	return transmissions[:limitOf(len(transmissions), limit)], nil
+}
@@ -0,0 +1,269 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package bolt
+
+import (
+	"encoding/json"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"github.com/imdario/mergo"
+	"go.etcd.io/bbolt"
+)
+
+// Return all the schedule interval(s)
+func (c *Client) Intervals() ([]contract.Interval, error) {
+	return c.IntervalsWithLimit(0)
+}
+
+// Return schedule interval(s) up to the number specified
+func (c *Client) IntervalsWithLimit(limit int) (intervals []contract.Interval, err error) {
+	intervals = []contract.Interval{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.Interval, func(o []byte) error {
+			if limit > 0 && len(intervals) == limit {
+				return nil
+			}
+			var i contract.Interval
+			if err := json.Unmarshal(o, &i); err != nil {
+				return err
+			}
+			intervals = append(intervals, i)
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.Interval{}, err
+	}
+	return intervals, nil
+}
+
+// Return schedule interval by name
+func (c *Client) IntervalByName(name string) (interval contract.Interval, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObjectByName(tx, db.Interval, name, &interval)
+	})
+	return interval, err
+}
+
+// Return schedule interval by ID
+func (c *Client) IntervalById(id string) (interval contract.Interval, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.Interval, id, &interval)
+	})
+	return interval, err
+}
+
+// Add a new schedule interval
+func (c *Client) AddInterval(interval contract.Interval) (string, error) {
+	if interval.ID != "" {
+		if _, err := uuid.Parse(interval.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		interval.ID = uuid.New().String()
+	}
+	if interval.Timestamps.Created == 0 {
+		ts := db.MakeTimestamp()
+		interval.Timestamps.Created = ts
+		interval.Timestamps.Modified = ts
+	}
+
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		if err := checkUniqueName(tx, db.Interval, interval.Name); err != nil {
+			return err
+		}
+		return putObject(tx, db.Interval, interval.ID, interval)
+	})
+	if err != nil {
+		return "", err
+	}
+	return interval.ID, nil
+}
+
+// Update a schedule interval, keeping the stored values of the fields left empty
+func (c *Client) UpdateInterval(interval contract.Interval) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var stored contract.Interval
+		if err := getObject(tx, db.Interval, interval.ID, &stored); err != nil {
+			return err
+		}
+		if err := checkRenamed(tx, db.Interval, interval.ID, interval.Name); err != nil {
+			return err
+		}
+
+		interval.Timestamps.Modified = db.MakeTimestamp()
+		if err := mergo.Merge(&interval, stored); err != nil {
+			return err
+		}
+		return putObject(tx, db.Interval, interval.ID, interval)
+	})
+}
+
+// Remove schedule interval by ID
+func (c *Client) DeleteIntervalById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.Interval, id)
+	})
+}
+
+// Scrub all scheduler intervals from the database (only used in test)
+func (c *Client) ScrubAllIntervals() (int, error) {
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		return clearCollection(tx, db.Interval)
+	})
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// Get all schedule interval action(s)
+func (c *Client) IntervalActions() ([]contract.IntervalAction, error) {
+	return c.findIntervalActions(func(contract.IntervalAction) bool { return true }, 0)
+}
+
+// Return schedule interval action(s) up to the number specified
+func (c *Client) IntervalActionsWithLimit(limit int) ([]contract.IntervalAction, error) {
+	return c.findIntervalActions(func(contract.IntervalAction) bool { return true }, limit)
+}
+
+// Get all schedule interval action(s) by interval name
+func (c *Client) IntervalActionsByIntervalName(name string) ([]contract.IntervalAction, error) {
+	return c.findIntervalActions(func(action contract.IntervalAction) bool {
+		return action.Interval == name
+	}, 0)
+}
+
+// Get all schedule interval action(s) by target name
+func (c *Client) IntervalActionsByTarget(name string) ([]contract.IntervalAction, error) {
+	return c.findIntervalActions(func(action contract.IntervalAction) bool {
+		return action.Target == name
+	}, 0)
+}
+
+// Get schedule interval action by id
+func (c *Client) IntervalActionById(id string) (action contract.IntervalAction, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObject(tx, db.IntervalAction, id, &action)
+	})
+	return action, err
+}
+
+// Get schedule interval action by name
+func (c *Client) IntervalActionByName(name string) (action contract.IntervalAction, err error) {
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return getObjectByName(tx, db.IntervalAction, name, &action)
+	})
+	return action, err
+}
+
+// Add schedule interval action
+func (c *Client) AddIntervalAction(action contract.IntervalAction) (string, error) {
+	if action.ID != "" {
+		if _, err := uuid.Parse(action.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		action.ID = uuid.New().String()
+	}
+	if action.Created == 0 {
+		ts := db.MakeTimestamp()
+		action.Created = ts
+		action.Modified = ts
+	}
+
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		if err := checkUniqueName(tx, db.IntervalAction, action.Name); err != nil {
+			return err
+		}
+		return putObject(tx, db.IntervalAction, action.ID, action)
+	})
+	if err != nil {
+		return "", err
+	}
+	return action.ID, nil
+}
+
+// Update schedule interval action, keeping the stored values of the fields left empty
+func (c *Client) UpdateIntervalAction(action contract.IntervalAction) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		var stored contract.IntervalAction
+		if err := getObject(tx, db.IntervalAction, action.ID, &stored); err != nil {
+			return err
+		}
+		if err := checkRenamed(tx, db.IntervalAction, action.ID, action.Name); err != nil {
+			return err
+		}
+
+		action.Modified = db.MakeTimestamp()
+		if err := mergo.Merge(&action, stored); err != nil {
+			return err
+		}
+		return putObject(tx, db.IntervalAction, action.ID, action)
+	})
+}
+
+// Remove schedule interval action by id
+func (c *Client) DeleteIntervalActionById(id string) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return deleteObject(tx, db.IntervalAction, id)
+	})
+}
+
+// Scrub all scheduler interval actions from the database data (only used in test)
+func (c *Client) ScrubAllIntervalActions() (int, error) {
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		return clearCollection(tx, db.IntervalAction)
+	})
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// NotUnique - another object of the collection has the name
+func checkRenamed(tx *bbolt.Tx, collection string, id string, name string) error {
+	other, err := idByName(tx, collection, name)
+	if err == nil && other != id {
+		return db.ErrNotUnique
+	} else if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) findIntervalActions(match func(action contract.IntervalAction) bool, limit int) (actions []contract.IntervalAction, err error) {
+	actions = []contract.IntervalAction{}
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		return forEachObject(tx, db.IntervalAction, func(o []byte) error {
+			if limit > 0 && len(actions) == limit {
+				return nil
+			}
+			var action contract.IntervalAction
+			if err := json.Unmarshal(o, &action); err != nil {
+				return err
+			}
+			if match(action) {
+				actions = append(actions, action)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.IntervalAction{}, err
+	}
+	return actions, nil
+}
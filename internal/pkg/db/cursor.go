@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Cursor marks a position in a list of events or readings ordered by creation time.
+// Created is the creation time of the last item returned and Id is the backend specific
+// identifier used to break ties between items created in the same millisecond.
+type Cursor struct {
+	Created int64
+	Id      string
+}
+
+// IsZero returns true when the cursor points to the start of the list
+func (c Cursor) IsZero() bool {
+	return c.Created == 0 && c.Id == ""
+}
+
+// String encodes the cursor as an opaque, URL safe token
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.Created, 10) + ":" + c.Id))
+}
+
+// ParseCursor decodes a token produced by Cursor.String. An empty token is the start of the list.
+func ParseCursor(token string) (Cursor, error) {
+	if token == "" {
+		return Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	created, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{Created: created, Id: parts[1]}, nil
+}
+
+// EventFilter restricts a paged listing of events
+// An End of 0 means there is no upper bound on the creation time
+type EventFilter struct {
+	Device string
+	Start  int64
+	End    int64
+}
+
+// ReadingFilter restricts a paged listing of readings
+// Names are value descriptor names, any of which will match
+// An End of 0 means there is no upper bound on the creation time
+type ReadingFilter struct {
+	Device string
+	Names  []string
+	Start  int64
+	End    int64
+}
@@ -23,14 +23,20 @@ const (
 	// Databases
 	MongoDB = "mongodb"
 	RedisDB = "redisdb"
+	BoltDB  = "boltdb"
 
 	// Data
 	EventsCollection          = "event"
 	ReadingsCollection        = "reading"
 	ValueDescriptorCollection = "valueDescriptor"
+	EventKeysCollection       = "eventKey"
+	QuarantineCollection      = "quarantine"
 
 	//Export
-	ExportCollection = "exportConfiguration"
+	ExportCollection         = "exportConfiguration"
+	ExportTemplateCollection = "exportTemplate"
+	ExportFilterCollection   = "exportFilter"
+	ExportCSVCollection      = "exportCSV"
 
 	//Logging
 	LogsCollection = "logEntry"
@@ -50,16 +56,21 @@ const (
 	Notification = "notification"
 	Subscription = "subscription"
 	Transmission = "transmission"
+
+	// Schema
+	SchemaVersionCollection = "schemaVersion"
 )
 
 var (
-	ErrNotFound            = errors.New("Item not found")
-	ErrUnsupportedDatabase = errors.New("Unsupported database type")
-	ErrInvalidObjectId     = errors.New("Invalid object ID")
-	ErrNotUnique           = errors.New("Resource already exists")
-	ErrCommandStillInUse   = errors.New("Command is still in use by device profiles")
-	ErrSlugEmpty           = errors.New("Slug is nil or empty")
-	ErrNameEmpty           = errors.New("Name is required")
+	ErrNotFound              = errors.New("Item not found")
+	ErrUnsupportedDatabase   = errors.New("Unsupported database type")
+	ErrInvalidObjectId       = errors.New("Invalid object ID")
+	ErrNotUnique             = errors.New("Resource already exists")
+	ErrCommandStillInUse     = errors.New("Command is still in use by device profiles")
+	ErrSlugEmpty             = errors.New("Slug is nil or empty")
+	ErrNameEmpty             = errors.New("Name is required")
+	ErrInvalidCursor         = errors.New("Invalid cursor")
+	ErrIrreversibleMigration = errors.New("Migration cannot be rolled back")
 )
 
 type Configuration struct {
@@ -70,6 +81,11 @@ type Configuration struct {
 	DatabaseName string
 	Username     string
 	Password     string
+	// Milliseconds events and readings are kept after their creation, forever when zero (Redis only)
+	TTL int64
+	// Bytes of memory used by the server above which the oldest events and readings are evicted,
+	// no limit when zero (Redis only)
+	MaxMemory int64
 }
 
 func MakeTimestamp() int64 {
@@ -0,0 +1,219 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+const (
+	// Time between the checks of a database that is up
+	healthCheckInterval = 5 * time.Second
+	// The first check of a database that went down waits this long, then twice as long each time
+	minReconnectBackoff = 500 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// Pinger is implemented by the clients able to check their connection to the database
+type Pinger interface {
+	Ping() error
+}
+
+// Reconnector is implemented by the clients that must be told to reconnect once their database
+// is back, rather than doing so on their own
+type Reconnector interface {
+	Reconnect() error
+}
+
+// PoolReporter is implemented by the clients holding a pool of connections
+type PoolReporter interface {
+	PoolStats() PoolStats
+}
+
+// PoolStats gives the state of the connections of a client
+type PoolStats struct {
+	// Connections open, in use or not
+	Open int `json:"open"`
+	// Connections in use
+	InUse int `json:"inUse"`
+}
+
+// Health of the connection to a database
+type Health struct {
+	State string `json:"state"`
+	// Error of the last failed check while the database is down
+	Error string `json:"error,omitempty"`
+	// When the state last changed
+	Since     int64 `json:"since"`
+	LastCheck int64 `json:"lastCheck"`
+	// Failed checks since the database went down
+	Failures int `json:"failures"`
+	// Times the database came back since the service started
+	Reconnects int        `json:"reconnects"`
+	Pool       *PoolStats `json:"pool,omitempty"`
+}
+
+// HealthMonitor checks the connection of a database client in the background, checking it again
+// with an exponential backoff while the database is down and reconnecting the client once it is
+// back. Services report the health on their readiness endpoint and refuse the requests needing
+// the database while it is down, instead of having each of them wait for the driver to give up.
+//
+// The methods of a nil monitor report a healthy database.
+type HealthMonitor struct {
+	client     Pinger
+	lc         logger.LoggingClient
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mutex  sync.RWMutex
+	health Health
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHealthMonitor returns a monitor of the client, which is always reported healthy when it cannot
+// be pinged
+func NewHealthMonitor(client interface{}, lc logger.LoggingClient) *HealthMonitor {
+	m := &HealthMonitor{
+		lc:         lc,
+		interval:   healthCheckInterval,
+		minBackoff: minReconnectBackoff,
+		maxBackoff: maxReconnectBackoff,
+		health:     Health{State: HealthUp, Since: MakeTimestamp()},
+	}
+	m.client, _ = client.(Pinger)
+	return m
+}
+
+// Start checks the database once, then keeps checking it in the background until Stop is called
+func (m *HealthMonitor) Start() {
+	if m == nil || m.client == nil || m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	m.check()
+	go m.run()
+}
+
+// Stop the background checks
+func (m *HealthMonitor) Stop() {
+	if m == nil || m.stop == nil {
+		return
+	}
+	close(m.stop)
+	<-m.done
+	m.stop = nil
+}
+
+// Ready returns whether the database is up
+func (m *HealthMonitor) Ready() bool {
+	if m == nil {
+		return true
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.health.State == HealthUp
+}
+
+// Status returns the health of the database, satisfying the readiness checks
+func (m *HealthMonitor) Status() interface{} {
+	return m.Health()
+}
+
+// Health returns the health of the database along with the state of the connections of the client
+func (m *HealthMonitor) Health() *Health {
+	if m == nil {
+		return nil
+	}
+	m.mutex.RLock()
+	h := m.health
+	m.mutex.RUnlock()
+
+	if p, ok := m.client.(PoolReporter); ok {
+		stats := p.PoolStats()
+		h.Pool = &stats
+	}
+	return &h
+}
+
+func (m *HealthMonitor) run() {
+	defer close(m.done)
+
+	backoff := m.minBackoff
+	for {
+		wait := m.interval
+		if !m.Ready() {
+			wait = backoff
+			if backoff *= 2; backoff > m.maxBackoff {
+				backoff = m.maxBackoff
+			}
+		} else {
+			backoff = m.minBackoff
+		}
+
+		select {
+		case <-m.stop:
+			return
+		case <-time.After(wait):
+			m.check()
+		}
+	}
+}
+
+// Ping the database, reconnecting the client when the database is down
+func (m *HealthMonitor) check() {
+	err := m.client.Ping()
+	if r, ok := m.client.(Reconnector); ok && err != nil {
+		if err = r.Reconnect(); err == nil {
+			err = m.client.Ping()
+		}
+	}
+	m.record(err)
+}
+
+func (m *HealthMonitor) record(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := MakeTimestamp()
+	m.health.LastCheck = now
+	if err == nil {
+		if m.health.State == HealthDown {
+			m.health.Reconnects++
+			m.health.State, m.health.Since = HealthUp, now
+			m.lc.Info(fmt.Sprintf("database is back after %d failed checks", m.health.Failures))
+		}
+		m.health.Failures = 0
+		m.health.Error = ""
+		return
+	}
+
+	m.health.Failures++
+	m.health.Error = err.Error()
+	if m.health.State == HealthUp {
+		m.health.State, m.health.Since = HealthDown, now
+		m.lc.Error("database is down: " + err.Error())
+	}
+}
@@ -0,0 +1,121 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// A database going down and coming back on demand, which comes back only once reconnected
+type testPinger struct {
+	mutex      sync.Mutex
+	down       bool
+	back       bool
+	reconnects int
+}
+
+func (p *testPinger) Ping() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (p *testPinger) Reconnect() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.reconnects++
+	if p.back {
+		p.down = false
+	}
+	return nil
+}
+
+func (p *testPinger) PoolStats() PoolStats {
+	return PoolStats{Open: 2, InUse: 1}
+}
+
+func (p *testPinger) set(down bool, back bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.down, p.back = down, back
+}
+
+func TestHealthMonitor(t *testing.T) {
+	p := &testPinger{}
+	m := NewHealthMonitor(p, logger.MockLogger{})
+	m.interval, m.minBackoff, m.maxBackoff = time.Millisecond, time.Millisecond, 4*time.Millisecond
+	m.Start()
+	defer m.Stop()
+
+	if !m.Ready() {
+		t.Fatal("expected the database to be up")
+	}
+	if h := m.Health(); h.Pool == nil || h.Pool.Open != 2 || h.Pool.InUse != 1 {
+		t.Errorf("expected the pool stats of the client, found %v", h.Pool)
+	}
+
+	p.set(true, false)
+	waitFor(t, "the database to go down", func() bool { return !m.Ready() })
+	waitFor(t, "failed checks", func() bool { return m.Health().Failures > 1 })
+	if h := m.Health(); h.State != HealthDown || h.Error == "" {
+		t.Errorf("expected the database to be reported down, found %+v", h)
+	}
+
+	p.set(true, true)
+	waitFor(t, "the database to come back", m.Ready)
+	h := m.Health()
+	if h.State != HealthUp || h.Error != "" || h.Failures != 0 || h.Reconnects != 1 {
+		t.Errorf("expected the database to be reported up again, found %+v", h)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.reconnects == 0 {
+		t.Error("expected the client to be reconnected")
+	}
+}
+
+func TestHealthMonitorWithoutPing(t *testing.T) {
+	m := NewHealthMonitor(struct{}{}, logger.MockLogger{})
+	m.Start()
+	m.Stop()
+	if !m.Ready() || m.Health().Pool != nil {
+		t.Errorf("expected a client that cannot be pinged to be reported up, found %+v", m.Health())
+	}
+
+	var nilMonitor *HealthMonitor
+	nilMonitor.Start()
+	nilMonitor.Stop()
+	if !nilMonitor.Ready() || nilMonitor.Health() != nil {
+		t.Error("expected a nil monitor to report a healthy database")
+	}
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// BaseSchemaVersion is the version of a database in which no version was recorded, that is one
+// laid out by a release without migrations
+const BaseSchemaVersion = 1
+
+// Migration moves the layout of a database from the previous version to its own
+type Migration struct {
+	Version     int
+	Description string
+	Up          func() error
+	// Restores the layout of the previous version, nil when the migration cannot be rolled back
+	Down func() error
+}
+
+// Migrator is implemented by the clients of the databases whose layout is versioned
+type Migrator interface {
+	// Return the version recorded in the database, BaseSchemaVersion when none was
+	SchemaVersion() (int, error)
+	SetSchemaVersion(version int) error
+	// Return the migrations of the database in order of version
+	Migrations() []Migration
+}
+
+// Migrate the database to a version, the latest one when zero. A version below the recorded one
+// rolls back the later migrations, latest first. The version is recorded after each migration so
+// that a failure leaves the database at the last one that completed.
+// Return the migrations run, or on a dry run those that would have been.
+func Migrate(m Migrator, version int, dryRun bool) ([]Migration, error) {
+	migrations := m.Migrations()
+	latest := BaseSchemaVersion
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	if version == 0 {
+		version = latest
+	}
+	if version < BaseSchemaVersion || version > latest {
+		return nil, fmt.Errorf("unknown schema version %d, the latest is %d", version, latest)
+	}
+
+	current, err := m.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if current > latest {
+		return nil, fmt.Errorf("database schema version %d is newer than the latest known %d", current, latest)
+	}
+
+	if version >= current {
+		return migrateUp(m, migrations, current, version, dryRun)
+	}
+	return migrateDown(m, migrations, current, version, dryRun)
+}
+
+// MigrateSchema migrates the database of a client at startup as configured, logging each
+// migration. The clients of databases that are not versioned are left alone.
+func MigrateSchema(client interface{}, version int, dryRun bool, lc logger.LoggingClient) error {
+	m, ok := client.(Migrator)
+	if !ok {
+		return nil
+	}
+	current, err := m.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	action := "Migrated"
+	if version != 0 && version < current {
+		action = "Rolled back"
+	}
+	if dryRun {
+		action = "Dry run, would have " + strings.ToLower(action)
+	}
+
+	migrations, err := Migrate(m, version, dryRun)
+	for _, migration := range migrations {
+		lc.Info(fmt.Sprintf("%s database schema version %d: %s", action, migration.Version, migration.Description))
+	}
+	return err
+}
+
+func migrateUp(m Migrator, migrations []Migration, current int, version int, dryRun bool) ([]Migration, error) {
+	run := []Migration{}
+	for _, migration := range migrations {
+		if migration.Version <= current || migration.Version > version {
+			continue
+		}
+		if !dryRun {
+			if err := migration.Up(); err != nil {
+				return run, fmt.Errorf("migration to version %d failed: %v", migration.Version, err)
+			}
+			if err := m.SetSchemaVersion(migration.Version); err != nil {
+				return run, err
+			}
+		}
+		run = append(run, migration)
+	}
+	return run, nil
+}
+
+func migrateDown(m Migrator, migrations []Migration, current int, version int, dryRun bool) ([]Migration, error) {
+	// Check every migration first so that a rollback is not left halfway
+	var rollback []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version <= version || migrations[i].Version > current {
+			continue
+		}
+		if migrations[i].Down == nil {
+			return nil, fmt.Errorf("%v: version %d", ErrIrreversibleMigration, migrations[i].Version)
+		}
+		rollback = append(rollback, migrations[i])
+	}
+
+	run := []Migration{}
+	for i, migration := range rollback {
+		if !dryRun {
+			if err := migration.Down(); err != nil {
+				return run, fmt.Errorf("rollback of version %d failed: %v", migration.Version, err)
+			}
+			previous := version
+			if i+1 < len(rollback) {
+				previous = rollback[i+1].Version
+			}
+			if err := m.SetSchemaVersion(previous); err != nil {
+				return run, err
+			}
+		}
+		run = append(run, migration)
+	}
+	return run, nil
+}
@@ -0,0 +1,121 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// Records the migrations run in the order they ran, up as positive and down as negative versions
+type testMigrator struct {
+	version      int
+	run          []int
+	irreversible int
+	failing      int
+}
+
+func (m *testMigrator) SchemaVersion() (int, error) {
+	return m.version, nil
+}
+
+func (m *testMigrator) SetSchemaVersion(version int) error {
+	m.version = version
+	return nil
+}
+
+func (m *testMigrator) Migrations() []Migration {
+	var migrations []Migration
+	for v := 2; v <= 4; v++ {
+		version := v
+		migration := Migration{
+			Version: version,
+			Up: func() error {
+				if version == m.failing {
+					return errors.New("failed")
+				}
+				m.run = append(m.run, version)
+				return nil
+			},
+			Down: func() error {
+				m.run = append(m.run, -version)
+				return nil
+			},
+		}
+		if version == m.irreversible {
+			migration.Down = nil
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name         string
+		from         int
+		to           int
+		dryRun       bool
+		irreversible int
+		failing      int
+		run          []int
+		version      int
+		reported     int
+		expectErr    bool
+	}{
+		{"Latest", BaseSchemaVersion, 0, false, 0, 0, []int{2, 3, 4}, 4, 3, false},
+		{"Up to a version", BaseSchemaVersion, 3, false, 0, 0, []int{2, 3}, 3, 2, false},
+		{"Up from a version", 3, 0, false, 0, 0, []int{4}, 4, 1, false},
+		{"Already latest", 4, 0, false, 0, 0, nil, 4, 0, false},
+		{"Rollback", 4, 2, false, 0, 0, []int{-4, -3}, 2, 2, false},
+		{"Rollback to base", 3, BaseSchemaVersion, false, 0, 0, []int{-3, -2}, BaseSchemaVersion, 2, false},
+		{"Dry run", BaseSchemaVersion, 0, true, 0, 0, nil, BaseSchemaVersion, 3, false},
+		{"Dry run rollback", 4, 2, true, 0, 0, nil, 4, 2, false},
+		{"Irreversible", 4, 2, false, 3, 0, nil, 4, 0, true},
+		{"Irreversible not rolled back", 4, 3, false, 3, 0, []int{-4}, 3, 1, false},
+		{"Failing", BaseSchemaVersion, 0, false, 0, 3, []int{2}, 2, 1, true},
+		{"Unknown version", BaseSchemaVersion, 5, false, 0, 0, nil, BaseSchemaVersion, 0, true},
+		{"Newer database", 5, 0, false, 0, 0, nil, 5, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &testMigrator{version: tt.from, irreversible: tt.irreversible, failing: tt.failing}
+			migrations, err := Migrate(m, tt.to, tt.dryRun)
+			if tt.expectErr && err == nil {
+				t.Errorf("expected an error")
+			} else if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(m.run, tt.run) {
+				t.Errorf("expected migrations %v to run, ran %v", tt.run, m.run)
+			}
+			if m.version != tt.version {
+				t.Errorf("expected version %d, found %d", tt.version, m.version)
+			}
+			if len(migrations) != tt.reported {
+				t.Errorf("expected %d migrations reported, found %d", tt.reported, len(migrations))
+			}
+		})
+	}
+}
+
+func TestMigrateSchemaNotVersioned(t *testing.T) {
+	if err := MigrateSchema(struct{}{}, 0, false, logger.MockLogger{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
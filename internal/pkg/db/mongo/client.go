@@ -15,6 +15,7 @@ package mongo
 
 import (
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
@@ -23,6 +24,9 @@ import (
 
 var currentMongoClient MongoClient // Singleton used so that mongoEvent can use it to de-reference readings
 
+// The socket statistics of the driver are global, they are only enabled once
+var enableStats sync.Once
+
 type MongoClient struct {
 	session  *mgo.Session  // Mongo database session
 	database *mgo.Database // Mongo database
@@ -41,6 +45,7 @@ func NewClient(config db.Configuration) (MongoClient, error) {
 		Username: config.Username,
 		Password: config.Password,
 	}
+	enableStats.Do(func() { mgo.SetStats(true) })
 	session, err := mgo.DialWithInfo(mongoDBDialInfo)
 	if err != nil {
 		return m, err
@@ -60,6 +65,27 @@ func (mc MongoClient) CloseSession() {
 	}
 }
 
+// Ping checks the connection to the server
+func (mc MongoClient) Ping() error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	return s.Ping()
+}
+
+// Reconnect drops the sockets of the session, whose copies otherwise keep failing on the sockets
+// of a server that went away
+func (mc MongoClient) Reconnect() error {
+	mc.session.Refresh()
+	return nil
+}
+
+// PoolStats gives the sockets of the driver, shared by the clients of the process
+func (mc MongoClient) PoolStats() db.PoolStats {
+	stats := mgo.GetStats()
+	return db.PoolStats{Open: stats.SocketsAlive, InUse: stats.SocketsInUse}
+}
+
 // Get the current Mongo Client
 func getCurrentMongoClient() (MongoClient, error) {
 	return currentMongoClient, nil
@@ -55,6 +55,13 @@ func TestMongoDB(t *testing.T) {
 		t.Fatalf("Could not connect: %v", err)
 	}
 	test.TestSchedulerDB(t, mongo)
+
+	config.DatabaseName = "metadata"
+	mongo, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect: %v", err)
+	}
+	test.TestMigrationDB(t, mongo)
 }
 
 func BenchmarkMongoDB(b *testing.B) {
@@ -14,9 +14,14 @@
 package mongo
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
 
 	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
@@ -91,6 +96,84 @@ func (mc MongoClient) AddEvent(e correlation.Event) (string, error) {
 	return id, nil
 }
 
+// Add events and their readings with a single insert per collection
+// The ids of the events are returned in the same order
+func (mc MongoClient) AddEvents(events []correlation.Event) ([]string, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	refs := batchReadings{}
+	var readings []interface{}
+	for _, e := range events {
+		for i, reading := range e.Readings {
+			var r models.Reading
+			id, err := r.FromContract(reading)
+			if err != nil {
+				return nil, err
+			}
+
+			r.TimestampForAdd()
+
+			if reading.Device == "" {
+				r.Device = e.Device
+			}
+			// Readings identified by a UUID get their object id now so events can refer to it
+			if !r.Id.Valid() {
+				r.Id = bson.NewObjectId()
+			}
+			refs[id] = r.Id
+
+			readings = append(readings, r)
+
+			e.Readings[i].Id = id
+		}
+	}
+
+	ids := make([]string, len(events))
+	mapped := make([]interface{}, len(events))
+	for i, e := range events {
+		var m models.Event
+		id, err := m.FromContract(e, refs)
+		if err != nil {
+			return nil, err
+		}
+
+		m.TimestampForAdd()
+
+		ids[i] = id
+		mapped[i] = m
+	}
+
+	if len(readings) > 0 {
+		if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Insert(readings...); err != nil {
+			return nil, errorMap(err)
+		}
+	}
+	if err := s.DB(mc.database.Name).C(db.EventsCollection).Insert(mapped...); err != nil {
+		return nil, errorMap(err)
+	}
+	return ids, nil
+}
+
+// batchReadings refers to the readings inserted along with a batch of events by their
+// contract id, sparing a lookup of each of them in the database
+type batchReadings map[string]bson.ObjectId
+
+func (b batchReadings) ReadingToDBRef(r models.Reading) (dbRef mgo.DBRef, err error) {
+	id, ok := b[r.Uuid]
+	if !ok {
+		id, ok = b[r.Id.Hex()]
+	}
+	if !ok {
+		return dbRef, db.ErrNotFound
+	}
+	return mgo.DBRef{Collection: db.ReadingsCollection, Id: id}, nil
+}
+
+func (b batchReadings) DBRefToReading(dbRef mgo.DBRef) (r models.Reading, err error) {
+	return r, db.ErrNotFound
+}
+
 // Update an event - do NOT update readings
 // UnexpectedError - problem updating in database
 // NotFound - no event with the ID was found
@@ -103,24 +186,54 @@ func (mc MongoClient) UpdateEvent(e correlation.Event) error {
 
 	mapped.TimestampForUpdate()
 
+	// The readings are not updated, nor what they were received with
+	if len(mapped.Originals) == 0 {
+		var stored models.Event
+		if stored, err = mc.eventById(id); err != nil {
+			return err
+		}
+		mapped.Originals = stored.Originals
+	}
+
 	return mc.updateId(db.EventsCollection, id, mapped)
 }
 
 // Get an event by id
 func (mc MongoClient) EventById(id string) (contract.Event, error) {
+	evt, err := mc.eventById(id)
+	if err != nil {
+		return contract.Event{}, err
+	}
+	return evt.ToContract(mc)
+}
+
+// Get an event by id along with the values its readings were received with
+func (mc MongoClient) EventWithOriginalsById(id string) (correlation.Event, error) {
+	evt, err := mc.eventById(id)
+	if err != nil {
+		return correlation.Event{}, err
+	}
+	c, err := evt.ToContract(mc)
+	if err != nil {
+		return correlation.Event{}, err
+	}
+	return correlation.Event{Event: c, Originals: db.EventOriginals(c.Readings, evt.Originals)}, nil
+}
+
+func (mc MongoClient) eventById(id string) (models.Event, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
 
 	query, err := idToBsonM(id)
 	if err != nil {
-		return contract.Event{}, err
+		return models.Event{}, err
 	}
 
 	var evt models.Event
 	if err := s.DB(mc.database.Name).C(db.EventsCollection).Find(query).One(&evt); err != nil {
-		return contract.Event{}, errorMap(err)
+		return models.Event{}, errorMap(err)
 	}
-	return evt.ToContract(mc)
+	return evt, nil
 }
 
 // EventsByChecksum get events with matching checksum
@@ -190,6 +303,43 @@ func (mc MongoClient) EventsByCreationTime(startTime, endTime int64, limit int)
 	return mc.mapEvents(mc.getEventsLimit(query, limit))
 }
 
+// Return a list of events whose origin time is between startTime and endTime, oldest first
+// Limit the number of results by limit
+func (mc MongoClient) EventsByOriginTime(startTime, endTime int64, limit int) ([]contract.Event, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	if limit == 0 {
+		return []contract.Event{}, nil
+	}
+
+	var me []models.Event
+	query := bson.M{"origin": bson.M{"$gte": startTime, "$lte": endTime}}
+	err := s.DB(mc.database.Name).C(db.EventsCollection).Find(query).Sort("origin").Limit(limit).All(&me)
+	return mc.mapEvents(me, errorMap(err))
+}
+
+// Return a page of events matching the filter, sorted by creation time (oldest first)
+// The page starts after the cursor and the returned cursor is empty when no events remain
+func (mc MongoClient) EventsPage(filter db.EventFilter, cursor db.Cursor, limit int) ([]contract.Event, db.Cursor, error) {
+	q := bson.M{}
+	if filter.Device != "" {
+		q["device"] = filter.Device
+	}
+
+	q, err := pageQuery(q, filter.Start, filter.End, cursor)
+	if err != nil {
+		return []contract.Event{}, db.Cursor{}, err
+	}
+
+	me, next, err := mc.getEventsPage(q, limit)
+	events, err := mc.mapEvents(me, err)
+	if err != nil {
+		return []contract.Event{}, db.Cursor{}, err
+	}
+	return events, next, nil
+}
+
 // Get Events that are older than the given age (defined by age = now - created)
 func (mc MongoClient) EventsOlderThanAge(age int64) ([]contract.Event, error) {
 	expireDate := (db.MakeTimestamp()) - age
@@ -219,6 +369,243 @@ func (mc MongoClient) ScrubAllEvents() error {
 	return nil
 }
 
+// Delete the oldest events matching the filter along with their readings
+func (mc MongoClient) DeleteEvents(filter db.PurgeFilter, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var events []models.Event
+	err := s.DB(mc.database.Name).C(db.EventsCollection).Find(purgeQuery(filter)).
+		Select(bson.M{"_id": 1, "readings": 1}).Sort("created").Limit(limit).All(&events)
+	if err != nil {
+		return 0, errorMap(err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	var eventIds, readingIds []interface{}
+	for _, e := range events {
+		eventIds = append(eventIds, e.Id)
+		for _, r := range e.Readings {
+			readingIds = append(readingIds, r.Id)
+		}
+	}
+
+	if len(readingIds) > 0 {
+		_, err = s.DB(mc.database.Name).C(db.ReadingsCollection).RemoveAll(bson.M{"_id": bson.M{"$in": readingIds}})
+		if err != nil {
+			return 0, errorMap(err)
+		}
+	}
+
+	info, err := s.DB(mc.database.Name).C(db.EventsCollection).RemoveAll(bson.M{"_id": bson.M{"$in": eventIds}})
+	if err != nil {
+		return 0, errorMap(err)
+	}
+	return info.Removed, nil
+}
+
+// Return the size of the event and reading collections
+func (mc MongoClient) DataSize() (int64, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var total int64
+	for _, c := range []string{db.EventsCollection, db.ReadingsCollection} {
+		var stats struct {
+			Size int64 `bson:"size"`
+		}
+		if err := s.DB(mc.database.Name).Run(bson.D{{Name: "collStats", Value: c}}, &stats); err != nil {
+			return 0, errorMap(err)
+		}
+		total += stats.Size
+	}
+	return total, nil
+}
+
+// Get the id of the event recorded under a key, unless the key expired
+func (mc MongoClient) EventIdByKey(key string) (string, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var k models.EventKey
+	query := bson.M{"_id": key, "expires": bson.M{"$gt": time.Now()}}
+	if err := s.DB(mc.database.Name).C(db.EventKeysCollection).Find(query).One(&k); err != nil {
+		return "", errorMap(err)
+	}
+	if k.EventId == "" {
+		return "", db.ErrNotFound
+	}
+	return k.EventId, nil
+}
+
+// Reserve a key by inserting it, the key being the _id of its document
+func (mc MongoClient) ReserveEventKey(key string, expires int64) (string, bool, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	col, err := eventKeys(s, mc.database.Name)
+	if err != nil {
+		return "", false, err
+	}
+
+	k := models.EventKey{Key: key, Expires: time.Unix(0, expires*int64(time.Millisecond))}
+	err = col.Insert(k)
+	if err == nil {
+		return "", true, nil
+	}
+	if !mgo.IsDup(err) {
+		return "", false, errorMap(err)
+	}
+
+	var found models.EventKey
+	if err = col.FindId(key).One(&found); err != nil {
+		if err == mgo.ErrNotFound {
+			// Removed in between, telling the caller to try again as if it was only reserved
+			return "", false, nil
+		}
+		return "", false, errorMap(err)
+	}
+	if found.Expires.After(time.Now()) {
+		return found.EventId, false, nil
+	}
+
+	// The key expired without being removed yet, and is replaced unless another request did first
+	err = col.Update(bson.M{"_id": key, "expires": found.Expires}, k)
+	if err == mgo.ErrNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errorMap(err)
+	}
+	return "", true, nil
+}
+
+// Record the id of an event under a key until it expires
+func (mc MongoClient) AddEventKey(key string, id string, expires int64) error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	col, err := eventKeys(s, mc.database.Name)
+	if err != nil {
+		return err
+	}
+
+	k := models.EventKey{Key: key, EventId: id, Expires: time.Unix(0, expires*int64(time.Millisecond))}
+	_, err = col.UpsertId(key, k)
+	return errorMap(err)
+}
+
+func (mc MongoClient) DeleteEventKey(key string) error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	err := s.DB(mc.database.Name).C(db.EventKeysCollection).RemoveId(key)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return errorMap(err)
+}
+
+// The event keys, which mongo removes in the background once expired, mgo only creating the
+// index once per session
+func eventKeys(s *mgo.Session, database string) (*mgo.Collection, error) {
+	col := s.DB(database).C(db.EventKeysCollection)
+	if err := col.EnsureIndex(mgo.Index{Key: []string{"expires"}, ExpireAfter: time.Second}); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// ********************* QUARANTINE FUNCTIONS *************************
+// Hold back an event
+func (mc MongoClient) AddQuarantinedEvent(q db.QuarantinedEvent) (string, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	if q.ID == "" {
+		q.ID = uuid.New().String()
+	}
+	if q.Created == 0 {
+		q.Created = db.MakeTimestamp()
+	}
+
+	var mq models.QuarantinedEvent
+	if err := mq.FromContract(q); err != nil {
+		return "", err
+	}
+	if err := s.DB(mc.database.Name).C(db.QuarantineCollection).Insert(mq); err != nil {
+		return "", errorMap(err)
+	}
+	return q.ID, nil
+}
+
+// Return the quarantined events, oldest first
+func (mc MongoClient) QuarantinedEvents(limit int) ([]db.QuarantinedEvent, error) {
+	return mc.getQuarantinedEvents(bson.M{}, limit)
+}
+
+// Return the quarantined events of a device, oldest first
+func (mc MongoClient) QuarantinedEventsByDevice(device string, limit int) ([]db.QuarantinedEvent, error) {
+	return mc.getQuarantinedEvents(bson.M{"device": device}, limit)
+}
+
+// Return a quarantined event by its id
+func (mc MongoClient) QuarantinedEventById(id string) (db.QuarantinedEvent, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var mq models.QuarantinedEvent
+	if err := s.DB(mc.database.Name).C(db.QuarantineCollection).FindId(id).One(&mq); err != nil {
+		return db.QuarantinedEvent{}, errorMap(err)
+	}
+	return mq.ToContract()
+}
+
+// Remove a quarantined event by its id
+func (mc MongoClient) DeleteQuarantinedEventById(id string) error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	return errorMap(s.DB(mc.database.Name).C(db.QuarantineCollection).RemoveId(id))
+}
+
+// Remove the events quarantined before a time
+func (mc MongoClient) DeleteQuarantinedEvents(before int64) (int, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	info, err := s.DB(mc.database.Name).C(db.QuarantineCollection).RemoveAll(bson.M{"created": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, errorMap(err)
+	}
+	return info.Removed, nil
+}
+
+func (mc MongoClient) getQuarantinedEvents(q bson.M, limit int) ([]db.QuarantinedEvent, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var mq []models.QuarantinedEvent
+	err := s.DB(mc.database.Name).C(db.QuarantineCollection).Find(q).Sort("created").Limit(limit).All(&mq)
+	if err != nil {
+		return nil, errorMap(err)
+	}
+
+	quarantined := make([]db.QuarantinedEvent, len(mq))
+	for i, m := range mq {
+		if quarantined[i], err = m.ToContract(); err != nil {
+			return nil, err
+		}
+	}
+	return quarantined, nil
+}
+
 // Get events for the passed query
 func (mc MongoClient) getEvents(q bson.M) (me []models.Event, err error) {
 	s := mc.getSessionCopy()
@@ -249,6 +636,29 @@ func (mc MongoClient) getEventsLimit(q bson.M, limit int) (me []models.Event, er
 	return
 }
 
+// Get a page of events for the passed query, sorted by creation time then ID
+// One extra event is requested to find out whether another page follows
+func (mc MongoClient) getEventsPage(q bson.M, limit int) (me []models.Event, next db.Cursor, err error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	if limit <= 0 {
+		return []models.Event{}, db.Cursor{}, nil
+	}
+
+	err = s.DB(mc.database.Name).C(db.EventsCollection).Find(q).Sort("created", "_id").Limit(limit + 1).All(&me)
+	if err != nil {
+		return []models.Event{}, db.Cursor{}, errorMap(err)
+	}
+
+	if len(me) > limit {
+		me = me[:limit]
+		last := me[limit-1]
+		next = db.Cursor{Created: last.Created, Id: last.Id.Hex()}
+	}
+	return
+}
+
 // ************************ READINGS ************************************8
 
 func (mc MongoClient) DBRefToReading(dbRef mgo.DBRef) (a models.Reading, err error) {
@@ -393,6 +803,209 @@ func (mc MongoClient) ReadingsByCreationTime(start, end int64, limit int) ([]con
 	return mapReadings(mc.getReadingsLimit(bson.M{"created": bson.M{"$gte": start, "$lte": end}}, limit))
 }
 
+// Return a list of readings whose origin time is between start and end, oldest first
+func (mc MongoClient) ReadingsByOriginTime(start, end int64, limit int) ([]contract.Reading, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	if limit == 0 {
+		return []contract.Reading{}, nil
+	}
+
+	var readings []models.Reading
+	query := bson.M{"origin": bson.M{"$gte": start, "$lte": end}}
+	err := s.DB(mc.database.Name).C(db.ReadingsCollection).Find(query).Sort("origin").Limit(limit).All(&readings)
+	return mapReadings(readings, errorMap(err))
+}
+
+// Return a page of readings matching the filter, sorted by creation time (oldest first)
+// The page starts after the cursor and the returned cursor is empty when no readings remain
+func (mc MongoClient) ReadingsPage(filter db.ReadingFilter, cursor db.Cursor, limit int) ([]contract.Reading, db.Cursor, error) {
+	q := bson.M{}
+	if filter.Device != "" {
+		q["device"] = filter.Device
+	}
+	if len(filter.Names) > 0 {
+		q["name"] = bson.M{"$in": filter.Names}
+	}
+
+	q, err := pageQuery(q, filter.Start, filter.End, cursor)
+	if err != nil {
+		return []contract.Reading{}, db.Cursor{}, err
+	}
+
+	mr, next, err := mc.getReadingsPage(q, limit)
+	readings, err := mapReadings(mr, err)
+	if err != nil {
+		return []contract.Reading{}, db.Cursor{}, err
+	}
+	return readings, next, nil
+}
+
+// Delete the oldest readings matching the filter, pulling their references from the events
+func (mc MongoClient) DeleteReadings(filter db.PurgeFilter, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	q := purgeQuery(filter)
+	if len(filter.Names) > 0 {
+		q["name"] = bson.M{"$in": filter.Names}
+	}
+
+	var readings []models.Reading
+	err := s.DB(mc.database.Name).C(db.ReadingsCollection).Find(q).
+		Select(bson.M{"_id": 1}).Sort("created").Limit(limit).All(&readings)
+	if err != nil {
+		return 0, errorMap(err)
+	}
+	if len(readings) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]interface{}, len(readings))
+	refs := make([]interface{}, len(readings))
+	for i, r := range readings {
+		ids[i] = r.Id
+		refs[i] = mgo.DBRef{Collection: db.ReadingsCollection, Id: r.Id}
+	}
+
+	_, err = s.DB(mc.database.Name).C(db.EventsCollection).UpdateAll(
+		bson.M{"readings": bson.M{"$in": refs}},
+		bson.M{"$pull": bson.M{"readings": bson.M{"$in": refs}}})
+	if err != nil {
+		return 0, errorMap(err)
+	}
+
+	info, err := s.DB(mc.database.Name).C(db.ReadingsCollection).RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, errorMap(err)
+	}
+	return info.Removed, nil
+}
+
+// Return the readings matching every predicate of the query, sorted by the query sort order
+// Numeric value conditions rely on $convert, which needs MongoDB 4.0 or later
+func (mc MongoClient) ReadingsByQuery(query db.ReadingQuery) ([]contract.Reading, error) {
+	if query.Limit <= 0 {
+		return []contract.Reading{}, nil
+	}
+
+	var and []bson.M
+	if len(query.Devices) > 0 {
+		and = append(and, bson.M{"device": bson.M{"$in": query.Devices}})
+	}
+	if len(query.Names) > 0 {
+		and = append(and, bson.M{"name": bson.M{"$in": query.Names}})
+	}
+	if r := timeRange(query.Start, query.End); r != nil {
+		and = append(and, bson.M{"created": r})
+	}
+	if r := timeRange(query.OriginStart, query.OriginEnd); r != nil {
+		and = append(and, bson.M{"origin": r})
+	}
+	for _, c := range query.Values {
+		cond, err := valueCondition(c)
+		if err != nil {
+			return []contract.Reading{}, err
+		}
+		and = append(and, cond)
+	}
+
+	q := bson.M{}
+	if len(and) > 0 {
+		q["$and"] = and
+	}
+
+	// The object ID breaks ties in the same direction as the requested order
+	order := query.SortOrder()
+	tie := "_id"
+	if strings.HasPrefix(order, "-") {
+		tie = "-_id"
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var readings []models.Reading
+	err := s.DB(mc.database.Name).C(db.ReadingsCollection).Find(q).Sort(order, tie).Limit(query.Limit).All(&readings)
+	if err != nil {
+		return []contract.Reading{}, errorMap(err)
+	}
+	return mapReadings(readings, nil)
+}
+
+// Summarize the numeric readings matching the query in time buckets
+// Values are converted with $convert, which needs MongoDB 4.0 or later
+func (mc MongoClient) AggregateReadings(query db.AggregateQuery) ([]db.Bucket, error) {
+	if query.Interval <= 0 || len(query.Names) == 0 {
+		return []db.Bucket{}, nil
+	}
+
+	match := bson.M{"name": bson.M{"$in": query.Names}}
+	if len(query.Devices) > 0 {
+		match["device"] = bson.M{"$in": query.Devices}
+	}
+	if r := timeRange(query.Start, query.End); r != nil {
+		match["created"] = r
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$project": bson.M{
+			"device": 1,
+			"name":   1,
+			"start":  bson.M{"$subtract": []interface{}{"$created", bson.M{"$mod": []interface{}{"$created", query.Interval}}}},
+			"value":  bson.M{"$convert": bson.M{"input": "$value", "to": "double", "onError": nil, "onNull": nil}},
+		}},
+		{"$match": bson.M{"value": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":   bson.M{"device": "$device", "name": "$name", "start": "$start"},
+			"count": bson.M{"$sum": 1},
+			"min":   bson.M{"$min": "$value"},
+			"max":   bson.M{"$max": "$value"},
+			"avg":   bson.M{"$avg": "$value"},
+		}},
+		{"$sort": bson.D{{Name: "_id.device", Value: 1}, {Name: "_id.name", Value: 1}, {Name: "_id.start", Value: 1}}},
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var results []struct {
+		Id struct {
+			Device string `bson:"device"`
+			Name   string `bson:"name"`
+			Start  int64  `bson:"start"`
+		} `bson:"_id"`
+		Count int64   `bson:"count"`
+		Min   float64 `bson:"min"`
+		Max   float64 `bson:"max"`
+		Avg   float64 `bson:"avg"`
+	}
+	err := s.DB(mc.database.Name).C(db.ReadingsCollection).Pipe(pipeline).AllowDiskUse().All(&results)
+	if err != nil {
+		return []db.Bucket{}, errorMap(err)
+	}
+
+	buckets := make([]db.Bucket, len(results))
+	for i, r := range results {
+		buckets[i] = db.Bucket{
+			Device: r.Id.Device,
+			Name:   r.Id.Name,
+			Start:  r.Id.Start,
+			Count:  r.Count,
+			Min:    r.Min,
+			Max:    r.Max,
+			Avg:    r.Avg,
+		}
+	}
+	return buckets, nil
+}
+
 // Return a list of readings for a device filtered by the value descriptor and limited by the limit
 // The readings are linked to the device through an event
 func (mc MongoClient) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]contract.Reading, error) {
@@ -417,6 +1030,30 @@ func (mc MongoClient) getReadingsLimit(q bson.M, limit int) ([]models.Reading, e
 	return readings, nil
 }
 
+// Get a page of readings for the passed query, sorted by creation time then ID
+// One extra reading is requested to find out whether another page follows
+func (mc MongoClient) getReadingsPage(q bson.M, limit int) ([]models.Reading, db.Cursor, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	if limit <= 0 {
+		return []models.Reading{}, db.Cursor{}, nil
+	}
+
+	var readings []models.Reading
+	if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Find(q).Sort("created", "_id").Limit(limit + 1).All(&readings); err != nil {
+		return []models.Reading{}, db.Cursor{}, errorMap(err)
+	}
+
+	var next db.Cursor
+	if len(readings) > limit {
+		readings = readings[:limit]
+		last := readings[limit-1]
+		next = db.Cursor{Created: last.Created, Id: last.Id.Hex()}
+	}
+	return readings, next, nil
+}
+
 // Get readings from the database
 func (mc MongoClient) getReadings(q bson.M) ([]models.Reading, error) {
 	s := mc.getSessionCopy()
@@ -621,6 +1258,89 @@ func (mc MongoClient) getValueDescriptor(q bson.M) (models.ValueDescriptor, erro
 	return m, nil
 }
 
+// Restrict the query to the creation time window and to the items after the cursor
+// Items created in the same millisecond as the cursor are ordered by their object ID
+func pageQuery(q bson.M, start, end int64, cursor db.Cursor) (bson.M, error) {
+	created := bson.M{"$gte": start}
+	if end > 0 {
+		created["$lte"] = end
+	}
+	q["created"] = created
+
+	if cursor.IsZero() {
+		return q, nil
+	}
+	if !bson.IsObjectIdHex(cursor.Id) {
+		return nil, db.ErrInvalidCursor
+	}
+
+	after := bson.M{"$or": []bson.M{
+		{"created": bson.M{"$gt": cursor.Created}},
+		{"created": cursor.Created, "_id": bson.M{"$gt": bson.ObjectIdHex(cursor.Id)}},
+	}}
+	return bson.M{"$and": []bson.M{q, after}}, nil
+}
+
+// Build the query shared by event and reading purges
+func purgeQuery(filter db.PurgeFilter) bson.M {
+	q := bson.M{}
+	if filter.Before > 0 {
+		q["created"] = bson.M{"$lt": filter.Before}
+	}
+	if filter.OnlyPushed {
+		q["pushed"] = bson.M{"$gt": 0}
+	}
+
+	device := bson.M{}
+	if len(filter.Devices) > 0 {
+		device["$in"] = filter.Devices
+	}
+	if len(filter.ExcludeDevices) > 0 {
+		device["$nin"] = filter.ExcludeDevices
+	}
+	if len(device) > 0 {
+		q["device"] = device
+	}
+	return q
+}
+
+// Build an inclusive range over a timestamp, ignoring bounds set to 0
+func timeRange(start, end int64) bson.M {
+	r := bson.M{}
+	if start > 0 {
+		r["$gte"] = start
+	}
+	if end > 0 {
+		r["$lte"] = end
+	}
+	if len(r) == 0 {
+		return nil
+	}
+	return r
+}
+
+// Compile a value condition to a query on the string value of readings
+// Numeric comparisons convert the value first, readings that do not hold a number never match
+func valueCondition(c db.ValueCondition) (bson.M, error) {
+	switch c.Op {
+	case db.OpEqual:
+		return bson.M{"value": c.Value}, nil
+	case db.OpNotEqual:
+		return bson.M{"value": bson.M{"$ne": c.Value}}, nil
+	}
+
+	operand, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	value := bson.M{"$convert": bson.M{"input": "$value", "to": "double", "onError": nil, "onNull": nil}}
+	return bson.M{"$expr": bson.M{"$and": []bson.M{
+		{"$ne": []interface{}{value, nil}},
+		{"$" + c.Op: []interface{}{value, operand}},
+	}}}, nil
+}
+
 func (mc MongoClient) mapEvents(events []models.Event, errIn error) (ce []contract.Event, err error) {
 	if errIn != nil {
 		return []contract.Event{}, errIn
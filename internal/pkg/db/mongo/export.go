@@ -121,15 +121,145 @@ func (mc MongoClient) DeleteRegistrationByName(name string) error {
 	return mc.deleteRegistration(bson.M{"name": name})
 }
 
-// Delete all registrations
+// Delete all registrations, along with their templates, filters and CSV settings
 func (mc MongoClient) ScrubAllRegistrations() error {
 	s := mc.getSessionCopy()
 	defer s.Close()
 
-	_, err := s.DB(mc.database.Name).C(db.ExportCollection).RemoveAll(nil)
+	for _, c := range []string{db.ExportCollection, db.ExportTemplateCollection, db.ExportFilterCollection, db.ExportCSVCollection} {
+		if _, err := s.DB(mc.database.Name).C(c).RemoveAll(nil); err != nil {
+			return errorMap(err)
+		}
+	}
+	return nil
+}
+
+// ********************** TEMPLATE FUNCTIONS *****************************
+// Return the templates of the registrations by registration name
+// UnexpectedError - failed to retrieve templates from the database
+func (mc MongoClient) Templates() (map[string]string, error) {
+	return mc.registrationOptions(db.ExportTemplateCollection, "template")
+}
+
+// Get the template of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no template
+func (mc MongoClient) TemplateByName(name string) (string, error) {
+	return mc.registrationOption(db.ExportTemplateCollection, "template", name)
+}
+
+// Set the template of a registration, replacing the one it had
+// UnexpectedError - problem updating in database
+func (mc MongoClient) UpdateTemplate(name string, text string) error {
+	return mc.updateRegistrationOption(db.ExportTemplateCollection, "template", name, text)
+}
+
+// Delete the template of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no template
+func (mc MongoClient) DeleteTemplateByName(name string) error {
+	return mc.deleteRegistrationOption(db.ExportTemplateCollection, name)
+}
+
+// ********************** FILTER FUNCTIONS *****************************
+// Return the filter expressions of the registrations by registration name
+// UnexpectedError - failed to retrieve filters from the database
+func (mc MongoClient) Filters() (map[string]string, error) {
+	return mc.registrationOptions(db.ExportFilterCollection, "expression")
+}
+
+// Get the filter expression of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no filter
+func (mc MongoClient) FilterByName(name string) (string, error) {
+	return mc.registrationOption(db.ExportFilterCollection, "expression", name)
+}
+
+// Set the filter expression of a registration, replacing the one it had
+// UnexpectedError - problem updating in database
+func (mc MongoClient) UpdateFilter(name string, expression string) error {
+	return mc.updateRegistrationOption(db.ExportFilterCollection, "expression", name, expression)
+}
+
+// Delete the filter expression of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no filter
+func (mc MongoClient) DeleteFilterByName(name string) error {
+	return mc.deleteRegistrationOption(db.ExportFilterCollection, name)
+}
+
+// ********************** CSV FUNCTIONS *****************************
+// Return the CSV settings of the registrations by registration name
+// UnexpectedError - failed to retrieve CSV settings from the database
+func (mc MongoClient) CSVSettings() (map[string]string, error) {
+	return mc.registrationOptions(db.ExportCSVCollection, "settings")
+}
+
+// Get the CSV settings of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no CSV settings
+func (mc MongoClient) CSVSettingsByName(name string) (string, error) {
+	return mc.registrationOption(db.ExportCSVCollection, "settings", name)
+}
+
+// Set the CSV settings of a registration, replacing those it had
+// UnexpectedError - problem updating in database
+func (mc MongoClient) UpdateCSVSettings(name string, settings string) error {
+	return mc.updateRegistrationOption(db.ExportCSVCollection, "settings", name, settings)
+}
+
+// Delete the CSV settings of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no CSV settings
+func (mc MongoClient) DeleteCSVSettingsByName(name string) error {
+	return mc.deleteRegistrationOption(db.ExportCSVCollection, name)
+}
+
+// The options of registrations are documents holding the registration name and the option in a field
+func (mc MongoClient) registrationOptions(collection string, field string) (map[string]string, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var found []bson.M
+	if err := s.DB(mc.database.Name).C(collection).Find(nil).All(&found); err != nil {
+		return nil, errorMap(err)
+	}
+
+	options := make(map[string]string, len(found))
+	for _, o := range found {
+		name, _ := o["name"].(string)
+		options[name], _ = o[field].(string)
+	}
+	return options, nil
+}
+
+func (mc MongoClient) registrationOption(collection string, field string, name string) (string, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var o bson.M
+	if err := s.DB(mc.database.Name).C(collection).Find(bson.M{"name": name}).One(&o); err != nil {
+		return "", errorMap(err)
+	}
+	value, _ := o[field].(string)
+	return value, nil
+}
+
+func (mc MongoClient) updateRegistrationOption(collection string, field string, name string, value string) error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	_, err := s.DB(mc.database.Name).C(collection).Upsert(bson.M{"name": name}, bson.M{"name": name, field: value})
 	return errorMap(err)
 }
 
+func (mc MongoClient) deleteRegistrationOption(collection string, name string) error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	return errorMap(s.DB(mc.database.Name).C(collection).Remove(bson.M{"name": name}))
+}
+
 // Get registrations for the passed query
 func (mc MongoClient) getRegistrations(q bson.M) ([]models.Registration, error) {
 	s := mc.getSessionCopy()
@@ -0,0 +1,231 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mongo
+
+import (
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// The single document of the schema version collection
+const schemaVersionId = "schema"
+
+type schemaVersion struct {
+	Id       string `bson:"_id"`
+	Version  int    `bson:"version"`
+	Modified int64  `bson:"modified"`
+}
+
+// A field of the documents of a collection holding DBRefs to the documents of another
+type reference struct {
+	collection string
+	field      string
+	to         string
+}
+
+var references = []reference{
+	{db.Device, "service", db.DeviceService},
+	{db.Device, "profile", db.DeviceProfile},
+	{db.DeviceService, "addressable", db.Addressable},
+	{db.DeviceProfile, "commands", db.Command},
+	{db.ProvisionWatcher, "service", db.DeviceService},
+	{db.ProvisionWatcher, "profile", db.DeviceProfile},
+	{db.EventsCollection, "readings", db.ReadingsCollection},
+}
+
+func (mc MongoClient) SchemaVersion() (int, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var v schemaVersion
+	err := s.DB(mc.database.Name).C(db.SchemaVersionCollection).FindId(schemaVersionId).One(&v)
+	if err == mgo.ErrNotFound {
+		return db.BaseSchemaVersion, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return v.Version, nil
+}
+
+func (mc MongoClient) SetSchemaVersion(version int) error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	v := schemaVersion{Id: schemaVersionId, Version: version, Modified: db.MakeTimestamp()}
+	_, err := s.DB(mc.database.Name).C(db.SchemaVersionCollection).UpsertId(schemaVersionId, v)
+	return err
+}
+
+func (mc MongoClient) Migrations() []db.Migration {
+	return []db.Migration{
+		{
+			Version:     2,
+			Description: "Store every reference as a DBRef to the ObjectId of a document of the referenced collection",
+			Up:          mc.normalizeReferences,
+			// No Down, as the forms the references were written in are not kept to be restored
+		},
+		{
+			Version:     3,
+			Description: "Index the events and readings by origin",
+			Up:          mc.indexByOrigin,
+			Down:        mc.dropOriginIndex,
+		},
+	}
+}
+
+// Data received late is looked up by the time it was read rather than the time it was stored
+func (mc MongoClient) indexByOrigin() error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+		if err := s.DB(mc.database.Name).C(collection).EnsureIndexKey("origin"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mc MongoClient) dropOriginIndex() error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+		err := s.DB(mc.database.Name).C(collection).DropIndex("origin")
+		if err != nil && !isIndexNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rolling back is not held up by an index dropped by hand
+func isIndexNotFound(err error) bool {
+	if qe, ok := err.(*mgo.QueryError); ok && qe.Code == 27 {
+		return true
+	}
+	return strings.HasPrefix(err.Error(), "index not found")
+}
+
+// References written by other drivers or by hand may name another database, hold the ObjectId as
+// a hex string or hold the uuid of the document. The queries by reference and the removal of the
+// readings of events only match the {$ref, $id} form with an ObjectId, as written by this client.
+func (mc MongoClient) normalizeReferences() error {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	for _, ref := range references {
+		c := s.DB(mc.database.Name).C(ref.collection)
+		iter := c.Find(bson.M{ref.field: bson.M{"$exists": true}}).Select(bson.M{ref.field: 1}).Iter()
+
+		var doc bson.M
+		for iter.Next(&doc) {
+			value, changed, err := mc.normalizeField(s, doc[ref.field], ref.to)
+			if err != nil {
+				iter.Close()
+				return err
+			}
+			if changed {
+				if err = c.UpdateId(doc["_id"], bson.M{"$set": bson.M{ref.field: value}}); err != nil {
+					iter.Close()
+					return errorMap(err)
+				}
+			}
+			doc = nil
+		}
+		if err := iter.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Normalize a reference or a list of them
+func (mc MongoClient) normalizeField(s *mgo.Session, field interface{}, to string) (interface{}, bool, error) {
+	if field == nil {
+		return nil, false, nil
+	}
+	if _, many := field.([]interface{}); !many {
+		var ref mgo.DBRef
+		if err := convert(field, &ref); err != nil {
+			return nil, false, err
+		}
+		return mc.normalizeReference(s, ref, to)
+	}
+
+	var refs []mgo.DBRef
+	if err := convert(field, &refs); err != nil {
+		return nil, false, err
+	}
+	changed := false
+	for i := range refs {
+		ref, c, err := mc.normalizeReference(s, refs[i], to)
+		if err != nil {
+			return nil, false, err
+		}
+		refs[i] = ref
+		changed = changed || c
+	}
+	return refs, changed, nil
+}
+
+func (mc MongoClient) normalizeReference(s *mgo.Session, ref mgo.DBRef, to string) (mgo.DBRef, bool, error) {
+	if ref.Id == nil {
+		return ref, false, nil
+	}
+
+	n := mgo.DBRef{Collection: to, Id: ref.Id}
+	changed := ref.Collection != to || ref.Database != ""
+
+	id, ok := ref.Id.(string)
+	if !ok {
+		return n, changed, nil
+	}
+	if bson.IsObjectIdHex(id) {
+		n.Id = bson.ObjectIdHex(id)
+		return n, true, nil
+	}
+
+	// A reference to a missing document is left as it is
+	var target struct {
+		Id bson.ObjectId `bson:"_id"`
+	}
+	err := s.DB(mc.database.Name).C(to).Find(bson.M{"uuid": id}).Select(bson.M{"_id": 1}).One(&target)
+	if err == mgo.ErrNotFound {
+		return n, changed, nil
+	} else if err != nil {
+		return ref, false, err
+	}
+	n.Id = target.Id
+	return n, true, nil
+}
+
+// Decode a value read as a generic document into a typed one
+func convert(in interface{}, out interface{}) error {
+	raw, err := bson.Marshal(bson.M{"v": in})
+	if err != nil {
+		return err
+	}
+	var wrapper struct {
+		V bson.Raw `bson:"v"`
+	}
+	if err = bson.Unmarshal(raw, &wrapper); err != nil {
+		return err
+	}
+	return wrapper.V.Unmarshal(out)
+}
@@ -24,15 +24,16 @@ import (
 )
 
 type Event struct {
-	Created  int64         `bson:"created"`
-	Modified int64         `bson:"modified"`
-	Origin   int64         `bson:"origin"`
-	Id       bson.ObjectId `bson:"_id,omitempty"`
-	Uuid     string        `bson:"uuid,omitempty"`
-	Pushed   int64         `bson:"pushed"`
-	Device   string        `bson:"device"`             // Device identifier (name or id)
-	Readings []mgo.DBRef   `bson:"readings,omitempty"` // List of readings
-	Checksum string        `bson:"checksum,omitempty"` // checksum used to identify events
+	Created   int64                `bson:"created"`
+	Modified  int64                `bson:"modified"`
+	Origin    int64                `bson:"origin"`
+	Id        bson.ObjectId        `bson:"_id,omitempty"`
+	Uuid      string               `bson:"uuid,omitempty"`
+	Pushed    int64                `bson:"pushed"`
+	Device    string               `bson:"device"`              // Device identifier (name or id)
+	Readings  []mgo.DBRef          `bson:"readings,omitempty"`  // List of readings
+	Checksum  string               `bson:"checksum,omitempty"`  // checksum used to identify events
+	Originals []db.ReadingOriginal `bson:"originals,omitempty"` // Values of the readings before their conversion
 }
 
 func (e *Event) ToContract(transform readingTransform) (c contract.Event, err error) {
@@ -85,6 +86,7 @@ func (e *Event) FromContract(from correlation.Event, transform readingTransform)
 		}
 		e.Readings = append(e.Readings, dbRef)
 	}
+	e.Originals = db.ReadingOriginals(from)
 
 	id = toContractId(e.Id, e.Uuid)
 	return
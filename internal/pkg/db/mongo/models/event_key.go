@@ -0,0 +1,24 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package models
+
+import "time"
+
+// EventKey records the event added under a key, so that the event posted again is recognized
+type EventKey struct {
+	Key     string    `bson:"_id"`
+	EventId string    `bson:"event"`
+	Expires time.Time `bson:"expires"` // A TTL index removes the key once passed
+}
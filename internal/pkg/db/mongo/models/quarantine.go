@@ -0,0 +1,52 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// QuarantinedEvent keeps the event as JSON, as it may be as malformed as the reason it was held back
+type QuarantinedEvent struct {
+	Id      string `bson:"_id"`
+	Device  string `bson:"device"`
+	Reason  string `bson:"reason"`
+	Created int64  `bson:"created"`
+	Event   string `bson:"event"`
+}
+
+func (q *QuarantinedEvent) FromContract(from db.QuarantinedEvent) error {
+	data, err := json.Marshal(from.Event)
+	if err != nil {
+		return err
+	}
+
+	q.Id = from.ID
+	q.Device = from.Event.Device
+	q.Reason = from.Reason
+	q.Created = from.Created
+	q.Event = string(data)
+	return nil
+}
+
+func (q QuarantinedEvent) ToContract() (to db.QuarantinedEvent, err error) {
+	to.ID = q.Id
+	to.Reason = q.Reason
+	to.Created = q.Created
+	err = json.Unmarshal([]byte(q.Event), &to.Event)
+	return
+}
@@ -0,0 +1,63 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package db
+
+import (
+	"sort"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+)
+
+// ReadingOriginal is the value a reading was received with before core-data converted it into
+// another unit. It is stored with the event by reading id, as the readings of an event are not
+// read back in the same order by every database.
+type ReadingOriginal struct {
+	Reading string `json:"reading"`
+	Value   string `json:"value"`
+	Unit    string `json:"unit"`
+}
+
+// The originals of an event to store, once its readings have their ids
+func ReadingOriginals(e correlation.Event) []ReadingOriginal {
+	var originals []ReadingOriginal
+	for _, o := range e.Originals {
+		if o.Index < 0 || o.Index >= len(e.Readings) {
+			continue
+		}
+		originals = append(originals, ReadingOriginal{Reading: e.Readings[o.Index].Id, Value: o.Value, Unit: o.Unit})
+	}
+	return originals
+}
+
+// The originals of the readings of an event as read back, those of readings since deleted left out
+func EventOriginals(readings []contract.Reading, originals []ReadingOriginal) []correlation.Original {
+	if len(originals) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(readings))
+	for i, r := range readings {
+		index[r.Id] = i
+	}
+	var found []correlation.Original
+	for _, o := range originals {
+		if i, ok := index[o.Reading]; ok {
+			found = append(found, correlation.Original{Index: i, Value: o.Value, Unit: o.Unit})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Index < found[j].Index })
+	return found
+}
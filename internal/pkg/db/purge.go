@@ -0,0 +1,27 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+// PurgeFilter selects the events or readings removed by a bulk delete.
+// Empty lists and a zero Before are not applied. Names only apply to readings.
+type PurgeFilter struct {
+	Devices        []string
+	ExcludeDevices []string
+	Names          []string
+	// Only data created strictly before this time is removed
+	Before int64
+	// Only data that has been pushed out of EdgeX is removed
+	OnlyPushed bool
+}
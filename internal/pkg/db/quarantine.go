@@ -0,0 +1,26 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+// QuarantinedEvent is an event core-data held back instead of adding it, kept as received along
+// with the reason until it is replayed or purged
+type QuarantinedEvent struct {
+	ID      string         `json:"id"`
+	Reason  string         `json:"reason"`
+	Created int64          `json:"created"`
+	Event   contract.Event `json:"event"`
+}
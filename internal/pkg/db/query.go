@@ -0,0 +1,131 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Operators comparing a reading value in a ValueCondition
+// Equality compares the raw strings, the other operators compare numbers
+const (
+	OpEqual          = "eq"
+	OpNotEqual       = "ne"
+	OpGreater        = "gt"
+	OpGreaterOrEqual = "gte"
+	OpLess           = "lt"
+	OpLessOrEqual    = "lte"
+)
+
+// Sort orders of a ReadingQuery, a leading - meaning newest first
+const (
+	SortCreated           = "created"
+	SortCreatedDescending = "-created"
+	SortOrigin            = "origin"
+	SortOriginDescending  = "-origin"
+)
+
+// ValueCondition compares the value of a reading against a constant
+type ValueCondition struct {
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// Numeric returns true when the condition compares numbers rather than strings.
+// Readings whose value is not a number never satisfy a numeric condition.
+func (c ValueCondition) Numeric() bool {
+	return c.Op != OpEqual && c.Op != OpNotEqual
+}
+
+// Satisfied compares the value of a reading against the condition
+func (c ValueCondition) Satisfied(value string) bool {
+	switch c.Op {
+	case OpEqual:
+		return value == c.Value
+	case OpNotEqual:
+		return value != c.Value
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	m, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return false
+	}
+
+	switch c.Op {
+	case OpGreater:
+		return n > m
+	case OpGreaterOrEqual:
+		return n >= m
+	case OpLess:
+		return n < m
+	case OpLessOrEqual:
+		return n <= m
+	}
+	return false
+}
+
+// ReadingQuery combines predicates over readings. Every predicate that is set must hold,
+// and a reading matches a list (devices, names) when it matches any entry of the list.
+// Time bounds are inclusive and a bound of 0 is not applied.
+type ReadingQuery struct {
+	Devices     []string
+	Names       []string
+	Start       int64
+	End         int64
+	OriginStart int64
+	OriginEnd   int64
+	Values      []ValueCondition
+	Sort        string
+	Limit       int
+}
+
+// Validate checks the operators, numeric operands and sort order of the query
+func (q ReadingQuery) Validate() error {
+	for _, c := range q.Values {
+		switch c.Op {
+		case OpEqual, OpNotEqual:
+		case OpGreater, OpGreaterOrEqual, OpLess, OpLessOrEqual:
+			if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+				return fmt.Errorf("value condition %s needs a number instead of '%s'", c.Op, c.Value)
+			}
+		default:
+			return fmt.Errorf("unknown value operator '%s'", c.Op)
+		}
+	}
+
+	switch q.Sort {
+	case "", SortCreated, SortCreatedDescending, SortOrigin, SortOriginDescending:
+	default:
+		return fmt.Errorf("unknown sort order '%s'", q.Sort)
+	}
+
+	if q.Limit < 0 {
+		return fmt.Errorf("negative limit %d", q.Limit)
+	}
+	return nil
+}
+
+// SortOrder returns the sort order of the query, the newest readings coming first by default
+func (q ReadingQuery) SortOrder() string {
+	if q.Sort == "" {
+		return SortCreatedDescending
+	}
+	return q.Sort
+}
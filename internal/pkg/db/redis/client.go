@@ -28,7 +28,9 @@ var once sync.Once
 
 // Client represents a Redis client
 type Client struct {
-	Pool *redis.Pool // A thread-safe pool of connections to Redis
+	Pool      *redis.Pool // A thread-safe pool of connections to Redis
+	ttl       int64       // Milliseconds events and readings are kept, forever when zero
+	maxMemory int64       // Bytes used by the server above which events and readings are evicted
 }
 
 // Return a pointer to the Redis client
@@ -63,6 +65,8 @@ func NewClient(config db.Configuration) (*Client, error) {
 				MaxIdle: 10,
 				Dial:    dialFunc,
 			},
+			ttl:       config.TTL,
+			maxMemory: config.MaxMemory,
 		}
 	})
 	return currClient, nil
@@ -80,6 +84,22 @@ func (c *Client) CloseSession() {
 	once = sync.Once{}
 }
 
+// Ping checks the connection to the server. The pool dials a new connection whenever one fails,
+// so there is nothing to do to reconnect.
+func (c *Client) Ping() error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("PING")
+	return err
+}
+
+// PoolStats gives the connections of the pool
+func (c *Client) PoolStats() db.PoolStats {
+	active := c.Pool.ActiveCount()
+	return db.PoolStats{Open: active, InUse: active - c.Pool.IdleCount()}
+}
+
 // getConnection gets a connection from the pool
 func getConnection() (conn redis.Conn, err error) {
 	if currClient == nil {
@@ -28,6 +28,10 @@ package redis
 import (
 	"testing"
 
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/test"
 )
@@ -74,6 +78,90 @@ func TestRedisDB(t *testing.T) {
 	test.TestNotificationsDB(t, rc)
 	rc.CloseSession()
 
+	rc, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect with Redis: %v", err)
+	}
+	test.TestMigrationDB(t, rc)
+	rc.CloseSession()
+
+}
+
+func TestMaintain(t *testing.T) {
+	hour := int64(60 * 60 * 1000)
+	rc, err := NewClient(db.Configuration{Host: redisHost, Port: redisPort, TTL: hour})
+	if err != nil {
+		t.Fatalf("Could not connect with Redis: %v", err)
+	}
+	defer rc.CloseSession()
+	if err = rc.ScrubAllEvents(); err != nil {
+		t.Fatalf("Could not scrub the events: %v", err)
+	}
+
+	now := db.MakeTimestamp()
+	add := func(device string, created int64) string {
+		e := contract.Event{Device: device, Created: created}
+		e.Readings = []contract.Reading{{Name: "temperature", Value: "21"}}
+		id, err := rc.AddEvent(models.Event{Event: e})
+		if err != nil {
+			t.Fatalf("Could not add the event: %v", err)
+		}
+		return id
+	}
+	add("expired", now-2*hour)
+	kept := add("kept", now)
+	// An event whose keys already expired, leaving its index entries behind
+	gone := add("gone", now-hour-1)
+	conn := rc.Pool.Get()
+	defer conn.Close()
+	if _, err = conn.Do("UNLINK", gone, db.EventsCollection+":readings:"+gone); err != nil {
+		t.Fatalf("Could not remove the event: %v", err)
+	}
+
+	expired, evicted, err := rc.Maintain()
+	if err != nil {
+		t.Fatalf("Maintain failed: %v", err)
+	}
+	if expired != 4 || evicted != 0 {
+		t.Errorf("expected 2 events and 2 readings expired, found %d expired and %d evicted", expired, evicted)
+	}
+	for _, key := range []string{db.EventsCollection + ":created", db.ReadingsCollection + ":created", db.ReadingsCollection + ":name:temperature"} {
+		if n, _ := redis.Int(conn.Do("ZCARD", key)); n != 1 {
+			t.Errorf("expected %s to index the kept object only, found %d entries", key, n)
+		}
+	}
+	for _, device := range []string{"expired", "gone"} {
+		for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+			if n, _ := redis.Int(conn.Do("ZCARD", collection+":device:"+device)); n != 0 {
+				t.Errorf("expected the %s index of device %s to be empty, found %d entries", collection, device, n)
+			}
+		}
+	}
+	if ttl, _ := redis.Int64(conn.Do("PTTL", kept)); ttl <= 0 || ttl > hour {
+		t.Errorf("expected the kept event to expire within the TTL, found %d", ttl)
+	}
+
+	// Nothing is evicted under a limit that the rest of the data already exceeds
+	rc.maxMemory = 1
+	if _, evicted, err = rc.Maintain(); err == nil || evicted != 0 {
+		t.Errorf("expected an error and nothing evicted, found %d evicted (%v)", evicted, err)
+	}
+	if count, _ := rc.EventCount(); count != 1 {
+		t.Errorf("expected the kept event left, found %d", count)
+	}
+
+	// Whether the memory freed is enough is up to the allocator, so only the eviction is checked
+	used, err := usedMemory(conn)
+	if err != nil {
+		t.Fatalf("Could not get the memory used: %v", err)
+	}
+	rc.maxMemory = used - 1
+	if _, evicted, _ = rc.Maintain(); evicted != 1 {
+		t.Errorf("expected the kept event evicted, found %d evicted", evicted)
+	}
+	if count, _ := rc.EventCount(); count != 0 {
+		t.Errorf("expected no events left, found %d", count)
+	}
 }
 
 func BenchmarkRedisDB(b *testing.B) {
@@ -14,6 +14,10 @@
 package redis
 
 import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
 	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -82,7 +86,45 @@ func (c *Client) AddEvent(e correlation.Event) (id string, err error) {
 			return "", db.ErrInvalidObjectId
 		}
 	}
-	return addEvent(conn, e)
+	return addEvent(conn, true, e, c.ttl)
+}
+
+// Add events and their readings in a single transaction
+// The ids of the events are returned in the same order
+func (c *Client) AddEvents(events []correlation.Event) (ids []string, err error) {
+	for _, e := range events {
+		if e.ID != "" {
+			if _, err = uuid.Parse(e.ID); err != nil {
+				return nil, db.ErrInvalidObjectId
+			}
+		}
+		for _, r := range e.Readings {
+			if r.Id != "" {
+				if _, err = uuid.Parse(r.Id); err != nil {
+					return nil, db.ErrInvalidObjectId
+				}
+			}
+		}
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	ids = make([]string, len(events))
+	_ = conn.Send("MULTI")
+	for i, e := range events {
+		ids[i], err = addEvent(conn, false, e, c.ttl)
+		if err != nil {
+			_, _ = conn.Do("DISCARD")
+			return nil, err
+		}
+	}
+
+	_, err = conn.Do("EXEC")
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
 // Update an event - do NOT update readings
@@ -96,13 +138,19 @@ func (c *Client) UpdateEvent(e correlation.Event) (err error) {
 
 	id := event.ID
 
-	o, err := eventByID(conn, id)
+	stored, err := eventWithOriginalsByID(conn, id)
 	if err != nil {
 		if err == redis.ErrNil {
 			return db.ErrNotFound
 		}
 		return err
 	}
+	o := stored.Event
+
+	// The readings are not updated, nor what they were received with
+	if len(e.Originals) == 0 {
+		e.Originals = db.EventOriginals(e.Readings, db.ReadingOriginals(stored))
+	}
 
 	e.Modified = db.MakeTimestamp()
 	err = mergo.Merge(&event, o)
@@ -115,7 +163,7 @@ func (c *Client) UpdateEvent(e correlation.Event) (err error) {
 		return err
 	}
 
-	_, err = addEvent(conn, e)
+	_, err = addEvent(conn, true, e, c.ttl)
 	return err
 }
 
@@ -135,6 +183,14 @@ func (c *Client) EventById(id string) (event contract.Event, err error) {
 	return event, nil
 }
 
+// Get an event by id along with the values its readings were received with
+func (c *Client) EventWithOriginalsById(id string) (event correlation.Event, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	return eventWithOriginalsByID(conn, id)
+}
+
 // EventsByChecksum Get an event by checksum
 func (c *Client) EventsByChecksum(checksum string) (events []contract.Event, err error) {
 	conn := c.Pool.Get()
@@ -258,6 +314,65 @@ func (c *Client) EventsByCreationTime(startTime, endTime int64, limit int) (even
 	return events, nil
 }
 
+// Return a list of events whose origin time is between startTime and endTime, oldest first
+// Limit the number of results by limit
+func (c *Client) EventsByOriginTime(startTime, endTime int64, limit int) (events []contract.Event, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	objects, err := getObjectsByScore(conn, db.EventsCollection+":origin", startTime, endTime, limit)
+	if err != nil {
+		if err != redis.ErrNil {
+			return events, err
+		}
+	}
+
+	events = make([]contract.Event, len(objects))
+	err = unmarshalEvents(objects, events)
+	if err != nil {
+		return events, err
+	}
+
+	return events, nil
+}
+
+// Return a page of events matching the filter, sorted by creation time (oldest first)
+// The returned cursor is empty once there are no more events to read
+func (c *Client) EventsPage(filter db.EventFilter, cursor db.Cursor, limit int) (events []contract.Event, next db.Cursor, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if limit <= 0 {
+		return []contract.Event{}, next, nil
+	}
+	if err = validateCursor(cursor); err != nil {
+		return events, next, err
+	}
+
+	key := db.EventsCollection + ":created"
+	if filter.Device != "" {
+		key = db.EventsCollection + ":device:" + filter.Device
+	}
+
+	objects, err := getObjectsAfterCursor(conn, key, "", filter.Start, filter.End, cursor, limit+1)
+	if err != nil {
+		return events, next, err
+	}
+
+	events = make([]contract.Event, len(objects))
+	err = unmarshalEvents(objects, events)
+	if err != nil {
+		return events, next, err
+	}
+
+	if len(events) > limit {
+		events = events[:limit]
+		next = db.Cursor{Created: events[limit-1].Created, Id: events[limit-1].ID}
+	}
+
+	return events, next, nil
+}
+
 // Return a list of readings for a device filtered by the value descriptor and limited by the limit
 // The readings are linked to the device through an event
 func (c *Client) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) (readings []contract.Reading, err error) {
@@ -339,6 +454,218 @@ func (c *Client) ScrubAllEvents() (err error) {
 	return nil
 }
 
+// Delete up to limit of the oldest events matching the filter, along with their readings
+// Return the number of events deleted
+func (c *Client) DeleteEvents(filter db.PurgeFilter, limit int) (count int, err error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	keys := []string{db.EventsCollection + ":created"}
+	if len(filter.Devices) > 0 {
+		keys = keys[:0]
+		for _, d := range filter.Devices {
+			keys = append(keys, db.EventsCollection+":device:"+d)
+		}
+	}
+
+	for _, key := range keys {
+		n, err := purgeEventsLua(conn, key, filter, limit-count)
+		if err != nil {
+			return count, err
+		}
+		count += n
+		if count >= limit {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+// Estimate the memory used by events and readings from a sample of the newest of each
+func (c *Client) DataSize() (int64, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	var size int64
+	for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+		s, err := collectionSize(conn, collection)
+		if err != nil {
+			return 0, err
+		}
+		size += s
+	}
+
+	return size, nil
+}
+
+// Get the id of the event recorded under a key, unless the key expired
+func (c *Client) EventIdByKey(key string) (string, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	id, err := redis.String(conn.Do("GET", db.EventKeysCollection+":"+key))
+	if err == redis.ErrNil || (err == nil && id == "") {
+		return "", db.ErrNotFound
+	}
+	return id, err
+}
+
+// Reserve a key with SET NX, returning the id recorded under it when it is already set
+func (c *Client) ReserveEventKey(key string, expires int64) (string, bool, error) {
+	ttl := expires - db.MakeTimestamp()
+	if ttl <= 0 {
+		return "", false, nil
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", db.EventKeysCollection+":"+key, "", "NX", "PX", ttl))
+	if err == nil {
+		return "", true, nil
+	}
+	if err != redis.ErrNil {
+		return "", false, err
+	}
+
+	// The key may expire in between, telling the caller to try again as if it was only reserved
+	id, err := redis.String(conn.Do("GET", db.EventKeysCollection+":"+key))
+	if err == redis.ErrNil {
+		return "", false, nil
+	}
+	return id, false, err
+}
+
+// Record the id of an event under a key, which redis expires
+func (c *Client) AddEventKey(key string, id string, expires int64) error {
+	ttl := expires - db.MakeTimestamp()
+	if ttl <= 0 {
+		return nil
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("SET", db.EventKeysCollection+":"+key, id, "PX", ttl)
+	return err
+}
+
+func (c *Client) DeleteEventKey(key string) error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", db.EventKeysCollection+":"+key)
+	return err
+}
+
+// ********************* QUARANTINE FUNCTIONS *************************
+// Hold back an event, indexed by quarantine time overall and per device
+func (c *Client) AddQuarantinedEvent(q db.QuarantinedEvent) (string, error) {
+	if q.Created == 0 {
+		q.Created = db.MakeTimestamp()
+	}
+	if q.ID == "" {
+		q.ID = uuid.New().String()
+	}
+
+	m, err := json.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_ = conn.Send("MULTI")
+	_ = conn.Send("SET", q.ID, m)
+	_ = conn.Send("ZADD", db.QuarantineCollection, q.Created, q.ID)
+	_ = conn.Send("ZADD", db.QuarantineCollection+":device:"+q.Event.Device, q.Created, q.ID)
+	_, err = conn.Do("EXEC")
+	if err != nil {
+		return "", err
+	}
+	return q.ID, nil
+}
+
+// Return the quarantined events, oldest first
+func (c *Client) QuarantinedEvents(limit int) ([]db.QuarantinedEvent, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	objects, err := getObjectsByRange(conn, db.QuarantineCollection, 0, limit-1)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalQuarantinedEvents(objects)
+}
+
+// Return the quarantined events of a device, oldest first
+func (c *Client) QuarantinedEventsByDevice(device string, limit int) ([]db.QuarantinedEvent, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	objects, err := getObjectsByRange(conn, db.QuarantineCollection+":device:"+device, 0, limit-1)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalQuarantinedEvents(objects)
+}
+
+// Return a quarantined event by its id
+func (c *Client) QuarantinedEventById(id string) (q db.QuarantinedEvent, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	return quarantinedEventById(conn, id)
+}
+
+// Remove a quarantined event by its id
+func (c *Client) DeleteQuarantinedEventById(id string) error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	q, err := quarantinedEventById(conn, id)
+	if err != nil {
+		return err
+	}
+	return deleteQuarantinedEvent(conn, q)
+}
+
+// Remove the events quarantined before a time
+func (c *Client) DeleteQuarantinedEvents(before int64) (int, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	ids, err := redis.Values(conn.Do("ZRANGEBYSCORE", db.QuarantineCollection, "-inf", "("+strconv.FormatInt(before, 10)))
+	if err != nil && err != redis.ErrNil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	objects, err := redis.ByteSlices(conn.Do("MGET", ids...))
+	if err != nil {
+		return 0, err
+	}
+	quarantined, err := unmarshalQuarantinedEvents(objects)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, q := range quarantined {
+		if err = deleteQuarantinedEvent(conn, q); err != nil {
+			return i, err
+		}
+	}
+	return len(quarantined), nil
+}
+
 // ********************* READING FUNCTIONS *************************
 // Return a list of readings sorted by reading id
 func (c *Client) Readings() (readings []contract.Reading, err error) {
@@ -373,7 +700,7 @@ func (c *Client) AddReading(r contract.Reading) (id string, err error) {
 			return "", db.ErrInvalidObjectId
 		}
 	}
-	return addReading(conn, true, r)
+	return addReading(conn, true, r, c.ttl)
 }
 
 // Update a reading
@@ -411,7 +738,7 @@ func (c *Client) UpdateReading(r contract.Reading) error {
 			return db.ErrInvalidObjectId
 		}
 	}
-	_, err = addReading(conn, true, r)
+	_, err = addReading(conn, true, r, c.ttl)
 	return err
 }
 
@@ -458,6 +785,44 @@ func (c *Client) DeleteReadingById(id string) error {
 	return nil
 }
 
+// Delete up to limit of the oldest readings matching the filter
+// Return the number of readings deleted
+func (c *Client) DeleteReadings(filter db.PurgeFilter, limit int) (count int, err error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	// The device filter is applied by the script when readings are selected by name
+	keys := []string{db.ReadingsCollection + ":created"}
+	if len(filter.Names) > 0 {
+		keys = keys[:0]
+		for _, n := range filter.Names {
+			keys = append(keys, db.ReadingsCollection+":name:"+n)
+		}
+	} else if len(filter.Devices) > 0 {
+		keys = keys[:0]
+		for _, d := range filter.Devices {
+			keys = append(keys, db.ReadingsCollection+":device:"+d)
+		}
+	}
+
+	for _, key := range keys {
+		n, err := purgeReadingsLua(conn, key, filter, limit-count)
+		if err != nil {
+			return count, err
+		}
+		count += n
+		if count >= limit {
+			break
+		}
+	}
+
+	return count, nil
+}
+
 // Return a list of readings for the given device (id or name)
 // 404 - meta data checking enabled and can't find the device
 // Sort the list of readings on creation date
@@ -569,6 +934,156 @@ func (c *Client) ReadingsByCreationTime(start, end int64, limit int) (readings [
 	return readings, nil
 }
 
+// Return a list of readings whose origin time is between start and end, oldest first
+func (c *Client) ReadingsByOriginTime(start, end int64, limit int) (readings []contract.Reading, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if limit == 0 {
+		return readings, nil
+	}
+
+	objects, err := getObjectsByScore(conn, db.ReadingsCollection+":origin", start, end, limit)
+	if err != nil {
+		return readings, err
+	}
+
+	readings = make([]contract.Reading, len(objects))
+	for i, in := range objects {
+		err = unmarshalObject(in, &readings[i])
+		if err != nil {
+			return readings, err
+		}
+	}
+
+	return readings, nil
+}
+
+// Return a page of readings matching the filter, sorted by creation time (oldest first)
+// Each value descriptor name is read separately and the results are merged
+// The returned cursor is empty once there are no more readings to read
+func (c *Client) ReadingsPage(filter db.ReadingFilter, cursor db.Cursor, limit int) (readings []contract.Reading, next db.Cursor, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if limit <= 0 {
+		return []contract.Reading{}, next, nil
+	}
+	if err = validateCursor(cursor); err != nil {
+		return readings, next, err
+	}
+
+	var deviceKey string
+	if filter.Device != "" {
+		deviceKey = db.ReadingsCollection + ":device:" + filter.Device
+	}
+
+	var objects [][]byte
+	if len(filter.Names) == 0 {
+		key := db.ReadingsCollection + ":created"
+		if deviceKey != "" {
+			key = deviceKey
+		}
+		objects, err = getObjectsAfterCursor(conn, key, "", filter.Start, filter.End, cursor, limit+1)
+		if err != nil {
+			return readings, next, err
+		}
+	} else {
+		for _, name := range filter.Names {
+			o, err := getObjectsAfterCursor(conn, db.ReadingsCollection+":name:"+name, deviceKey,
+				filter.Start, filter.End, cursor, limit+1)
+			if err != nil {
+				return readings, next, err
+			}
+			objects = append(objects, o...)
+		}
+	}
+
+	readings = make([]contract.Reading, len(objects))
+	for i, in := range objects {
+		err = unmarshalObject(in, &readings[i])
+		if err != nil {
+			return readings, next, err
+		}
+	}
+
+	if len(filter.Names) > 1 {
+		sort.Slice(readings, func(i, j int) bool {
+			if readings[i].Created != readings[j].Created {
+				return readings[i].Created < readings[j].Created
+			}
+			return readings[i].Id < readings[j].Id
+		})
+	}
+
+	if len(readings) > limit {
+		readings = readings[:limit]
+		next = db.Cursor{Created: readings[limit-1].Created, Id: readings[limit-1].Id}
+	}
+
+	return readings, next, nil
+}
+
+// Return the readings matching every predicate of the query, sorted by the query sort order
+// The smallest available index is scanned: value descriptor names, then devices, then all readings
+func (c *Client) ReadingsByQuery(query db.ReadingQuery) (readings []contract.Reading, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if query.Limit <= 0 {
+		return []contract.Reading{}, nil
+	}
+
+	var keys []string
+	switch {
+	case len(query.Names) > 0:
+		for _, name := range query.Names {
+			keys = append(keys, db.ReadingsCollection+":name:"+name)
+		}
+	case len(query.Devices) > 0:
+		for _, device := range query.Devices {
+			keys = append(keys, db.ReadingsCollection+":device:"+device)
+		}
+	default:
+		keys = []string{db.ReadingsCollection + ":created"}
+	}
+
+	return queryReadings(conn, keys, query)
+}
+
+// Summarize the numeric readings matching the query in time buckets
+// Buckets are sorted by device, value descriptor name and start
+func (c *Client) AggregateReadings(query db.AggregateQuery) (buckets []db.Bucket, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if query.Interval <= 0 || len(query.Names) == 0 {
+		return []db.Bucket{}, nil
+	}
+
+	keys := make([]string, len(query.Names))
+	for i, name := range query.Names {
+		keys[i] = db.ReadingsCollection + ":name:" + name
+	}
+
+	buckets, err = aggregateReadings(conn, keys, query)
+	if err != nil {
+		return buckets, err
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Device != buckets[j].Device {
+			return buckets[i].Device < buckets[j].Device
+		}
+		if buckets[i].Name != buckets[j].Name {
+			return buckets[i].Name < buckets[j].Name
+		}
+		return buckets[i].Start < buckets[j].Start
+	})
+
+	return buckets, nil
+}
+
 // ************************** VALUE DESCRIPTOR FUNCTIONS ***************************
 // Add a value descriptor
 // 409 - Formatting is bad or it is not unique
@@ -794,7 +1309,8 @@ func (c *Client) ScrubAllValueDescriptors() error {
 }
 
 // ************************** HELPER FUNCTIONS ***************************
-func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
+// Add an event and its readings, which expire ttl milliseconds after their creation unless ttl is 0
+func addEvent(conn redis.Conn, tx bool, e correlation.Event, ttl int64) (id string, err error) {
 	if e.Created == 0 {
 		e.Created = db.MakeTimestamp()
 	}
@@ -803,25 +1319,34 @@ func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
 		e.ID = uuid.New().String()
 	}
 
-	m, err := marshalEvent(e)
-	if err != nil {
-		return "", err
-	}
-
 	for i, reading := range e.Readings {
 		if reading.Device == "" {
 			e.Readings[i].Device = e.Device
 		}
+		// The event keeps the originals of its readings by id
+		if reading.Id == "" {
+			e.Readings[i].Id = uuid.New().String()
+		}
 	}
 
-	_ = conn.Send("MULTI")
+	m, err := marshalEvent(e)
+	if err != nil {
+		return "", err
+	}
+
+	if tx {
+		_ = conn.Send("MULTI")
+	}
 	_ = conn.Send("SET", e.ID, m)
+	expire(conn, e.ID, e.Created, ttl)
 	_ = conn.Send("ZADD", db.EventsCollection, 0, e.ID)
 	_ = conn.Send("ZADD", db.EventsCollection+":created", e.Created, e.ID)
+	_ = conn.Send("ZADD", db.EventsCollection+":origin", e.Origin, e.ID)
 	_ = conn.Send("ZADD", db.EventsCollection+":pushed", e.Pushed, e.ID)
 	_ = conn.Send("ZADD", db.EventsCollection+":device:"+e.Device, e.Created, e.ID)
 	if e.Checksum != "" {
 		_ = conn.Send("ZADD", db.EventsCollection+":checksum:"+e.Checksum, 0, e.ID)
+		expire(conn, db.EventsCollection+":checksum:"+e.Checksum, e.Created, ttl)
 	}
 
 	rids := make([]interface{}, len(e.Readings)*2+1)
@@ -836,7 +1361,7 @@ func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
 				return "", db.ErrInvalidObjectId
 			}
 		}
-		id, err = addReading(conn, false, r)
+		id, err = addReading(conn, false, r, ttl)
 		if err != nil {
 			return id, err
 		}
@@ -845,9 +1370,12 @@ func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
 	}
 	if len(rids) > 1 {
 		_ = conn.Send("ZADD", rids...)
+		expire(conn, rids[0].(string), e.Created, ttl)
 	}
 
-	_, err = conn.Do("EXEC")
+	if tx {
+		_, err = conn.Do("EXEC")
+	}
 	return e.ID, err
 }
 
@@ -858,6 +1386,7 @@ func deleteEvent(conn redis.Conn, id string) error {
 	_ = conn.Send("UNLINK", db.EventsCollection+":readings:"+id)
 	_ = conn.Send("ZREM", db.EventsCollection, id)
 	_ = conn.Send("ZREM", db.EventsCollection+":created", id)
+	_ = conn.Send("ZREM", db.EventsCollection+":origin", id)
 	res, err := redis.Values(conn.Do("EXEC"))
 	if err != nil {
 		return err
@@ -889,6 +1418,18 @@ func eventByID(conn redis.Conn, id string) (event contract.Event, err error) {
 	return event, err
 }
 
+func eventWithOriginalsByID(conn redis.Conn, id string) (event correlation.Event, err error) {
+	obj, err := redis.Bytes(conn.Do("GET", id))
+	if err == redis.ErrNil {
+		return event, db.ErrNotFound
+	}
+	if err != nil {
+		return event, err
+	}
+
+	return unmarshalEventWithOriginals(obj)
+}
+
 func eventByChecksum(conn redis.Conn, checksum string) (events []contract.Event, err error) {
 	objects, err := getObjectsByRange(conn, db.EventsCollection, 0, -1)
 	if err != nil {
@@ -905,8 +1446,8 @@ func eventByChecksum(conn redis.Conn, checksum string) (events []contract.Event,
 	return events, nil
 }
 
-// Add a reading to the database
-func addReading(conn redis.Conn, tx bool, r contract.Reading) (id string, err error) {
+// Add a reading to the database, which expires ttl milliseconds after its creation unless ttl is 0
+func addReading(conn redis.Conn, tx bool, r contract.Reading, ttl int64) (id string, err error) {
 	if r.Created == 0 {
 		r.Created = db.MakeTimestamp()
 	}
@@ -924,8 +1465,10 @@ func addReading(conn redis.Conn, tx bool, r contract.Reading) (id string, err er
 		_ = conn.Send("MULTI")
 	}
 	_ = conn.Send("SET", r.Id, m)
+	expire(conn, r.Id, r.Created, ttl)
 	_ = conn.Send("ZADD", db.ReadingsCollection, 0, r.Id)
 	_ = conn.Send("ZADD", db.ReadingsCollection+":created", r.Created, r.Id)
+	_ = conn.Send("ZADD", db.ReadingsCollection+":origin", r.Origin, r.Id)
 	_ = conn.Send("ZADD", db.ReadingsCollection+":device:"+r.Device, r.Created, r.Id)
 	_ = conn.Send("ZADD", db.ReadingsCollection+":name:"+r.Name, r.Created, r.Id)
 	if tx {
@@ -946,6 +1489,7 @@ func deleteReading(conn redis.Conn, id string) error {
 	_ = conn.Send("UNLINK", id)
 	_ = conn.Send("ZREM", db.ReadingsCollection, id)
 	_ = conn.Send("ZREM", db.ReadingsCollection+":created", id)
+	_ = conn.Send("ZREM", db.ReadingsCollection+":origin", id)
 	_ = conn.Send("ZREM", db.ReadingsCollection+":device:"+r.Device, id)
 	_ = conn.Send("ZREM", db.ReadingsCollection+":name:"+r.Name, id)
 	_, err = conn.Do("EXEC")
@@ -1026,3 +1570,280 @@ func valueByName(conn redis.Conn, name string) (value contract.ValueDescriptor,
 
 	return value, nil
 }
+
+// The id of a quarantined event is looked up in the quarantine first, as events share the keyspace
+func quarantinedEventById(conn redis.Conn, id string) (q db.QuarantinedEvent, err error) {
+	_, err = redis.Int64(conn.Do("ZSCORE", db.QuarantineCollection, id))
+	if err == redis.ErrNil {
+		return q, db.ErrNotFound
+	} else if err != nil {
+		return q, err
+	}
+
+	err = getObjectById(conn, id, json.Unmarshal, &q)
+	return q, err
+}
+
+func deleteQuarantinedEvent(conn redis.Conn, q db.QuarantinedEvent) error {
+	_ = conn.Send("MULTI")
+	_ = conn.Send("DEL", q.ID)
+	_ = conn.Send("ZREM", db.QuarantineCollection, q.ID)
+	_ = conn.Send("ZREM", db.QuarantineCollection+":device:"+q.Event.Device, q.ID)
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func unmarshalQuarantinedEvents(objects [][]byte) ([]db.QuarantinedEvent, error) {
+	quarantined := make([]db.QuarantinedEvent, 0, len(objects))
+	for _, o := range objects {
+		// Removed since listed
+		if o == nil {
+			continue
+		}
+
+		var q db.QuarantinedEvent
+		if err := json.Unmarshal(o, &q); err != nil {
+			return nil, err
+		}
+		quarantined = append(quarantined, q)
+	}
+	return quarantined, nil
+}
+
+// Number of objects whose memory usage is sampled to estimate the size of a collection
+const sizeSample = 16
+
+// Extrapolate the size of a collection from the memory used by its newest objects
+func collectionSize(conn redis.Conn, collection string) (int64, error) {
+	count, err := redis.Int64(conn.Do("ZCARD", collection+":created"))
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	ids, err := redis.Strings(conn.Do("ZRANGE", collection+":created", -sizeSample, -1))
+	if err != nil {
+		return 0, err
+	}
+
+	var sampled, n int64
+	for _, id := range ids {
+		usage, err := redis.Int64(conn.Do("MEMORY", "USAGE", id))
+		if err == redis.ErrNil {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		sampled += usage
+		n++
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	return count * sampled / n, nil
+}
+
+// The Redis identifiers are UUIDs, so any other cursor was not produced by this database
+func validateCursor(cursor db.Cursor) error {
+	if cursor.IsZero() {
+		return nil
+	}
+	if _, err := uuid.Parse(cursor.Id); err != nil {
+		return db.ErrInvalidCursor
+	}
+	return nil
+}
+
+// Return the readings indexed by any of the keys that match every predicate of the query
+// The keys are zsets of reading IDs scored by creation time. Sorted by creation, each key is read
+// in the requested order until it gave enough readings; sorted by origin, every reading created
+// within the query range is read, only the first of them being kept as it goes.
+func queryReadings(conn redis.Conn, keys []string, query db.ReadingQuery) ([]contract.Reading, error) {
+	// Ties are broken by id in the same direction as the requested order
+	order := query.SortOrder()
+	var less func(a, b contract.Reading) bool
+	switch order {
+	case db.SortCreated:
+		less = readingCreatedBefore
+	case db.SortOrigin:
+		less = readingOriginBefore
+	case db.SortOriginDescending:
+		less = func(a, b contract.Reading) bool { return readingOriginBefore(b, a) }
+	default:
+		less = func(a, b contract.Reading) bool { return readingCreatedBefore(b, a) }
+	}
+	byCreation := order == db.SortCreated || order == db.SortCreatedDescending
+
+	devices := stringSet(query.Devices)
+	names := stringSet(query.Names)
+	match := func(r contract.Reading) bool {
+		if (devices != nil && !devices[r.Device]) || (names != nil && !names[r.Name]) ||
+			!within(r.Origin, query.OriginStart, query.OriginEnd) {
+			return false
+		}
+		for _, v := range query.Values {
+			if !v.Satisfied(r.Value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	readings := []contract.Reading{}
+	keep := func() {
+		sort.Slice(readings, func(i, j int) bool { return less(readings[i], readings[j]) })
+		if len(readings) > query.Limit {
+			readings = readings[:query.Limit]
+		}
+	}
+	min, max := scoreRange(query.Start, query.End)
+	for _, key := range keys {
+		matched := 0
+		err := scanZset(conn, key, min, max, byCreation && order == db.SortCreatedDescending, func(ids []interface{}) (bool, error) {
+			window, err := readingsByIds(conn, ids)
+			if err != nil {
+				return false, err
+			}
+			for _, r := range window {
+				if match(r) {
+					readings = append(readings, r)
+					matched++
+				}
+			}
+			if len(readings) > 2*query.Limit {
+				keep()
+			}
+			return !byCreation || matched < query.Limit, nil
+		})
+		if err != nil {
+			return []contract.Reading{}, err
+		}
+	}
+	keep()
+
+	return readings, nil
+}
+
+// Summarize the numeric readings indexed by any of the keys in buckets of the query interval
+// The keys are zsets of reading IDs scored by creation time, only the buckets being held at once
+func aggregateReadings(conn redis.Conn, keys []string, query db.AggregateQuery) ([]db.Bucket, error) {
+	devices := stringSet(query.Devices)
+	type bucketKey struct {
+		device string
+		name   string
+		start  int64
+	}
+	index := map[bucketKey]int{}
+	buckets := []db.Bucket{}
+
+	min, max := scoreRange(query.Start, query.End)
+	for _, key := range keys {
+		err := scanZset(conn, key, min, max, false, func(ids []interface{}) (bool, error) {
+			window, err := readingsByIds(conn, ids)
+			if err != nil {
+				return false, err
+			}
+			for _, r := range window {
+				if devices != nil && !devices[r.Device] {
+					continue
+				}
+				value, err := strconv.ParseFloat(r.Value, 64)
+				if err != nil {
+					continue
+				}
+
+				k := bucketKey{r.Device, r.Name, db.BucketStart(r.Created, query.Interval)}
+				i, ok := index[k]
+				if !ok {
+					i = len(buckets)
+					index[k] = i
+					buckets = append(buckets, db.Bucket{Device: k.device, Name: k.name, Start: k.start, Min: value, Max: value})
+				}
+				b := &buckets[i]
+				b.Count++
+				b.Avg += value
+				if value < b.Min {
+					b.Min = value
+				}
+				if value > b.Max {
+					b.Max = value
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return []db.Bucket{}, err
+		}
+	}
+
+	// The sum of the values is held in Avg until every reading was seen
+	for i := range buckets {
+		buckets[i].Avg /= float64(buckets[i].Count)
+	}
+	return buckets, nil
+}
+
+// Return the readings stored under the ids, skipping those which expired since they were indexed
+func readingsByIds(conn redis.Conn, ids []interface{}) ([]contract.Reading, error) {
+	objects, err := redis.ByteSlices(conn.Do("MGET", ids...))
+	if err != nil {
+		return nil, err
+	}
+
+	readings := make([]contract.Reading, 0, len(objects))
+	for _, o := range objects {
+		if o == nil {
+			continue
+		}
+		var r contract.Reading
+		if err = unmarshalObject(o, &r); err != nil {
+			return nil, err
+		}
+		readings = append(readings, r)
+	}
+	return readings, nil
+}
+
+// Score bounds of a time range whose bounds of 0 are not applied
+func scoreRange(start, end int64) (min interface{}, max interface{}) {
+	min, max = "-inf", "+inf"
+	if start > 0 {
+		min = start
+	}
+	if end > 0 {
+		max = end
+	}
+	return min, max
+}
+
+func readingCreatedBefore(a, b contract.Reading) bool {
+	if a.Created != b.Created {
+		return a.Created < b.Created
+	}
+	return a.Id < b.Id
+}
+
+func readingOriginBefore(a, b contract.Reading) bool {
+	if a.Origin != b.Origin {
+		return a.Origin < b.Origin
+	}
+	return a.Id < b.Id
+}
+
+// Check an inclusive range, ignoring bounds set to 0
+func within(v, start, end int64) bool {
+	return (start == 0 || v >= start) && (end == 0 || v <= end)
+}
+
+// Return a set of strings, nil when the list is empty
+func stringSet(list []string) map[string]bool {
+	if len(list) == 0 {
+		return nil
+	}
+	s := make(map[string]bool, len(list))
+	for _, v := range list {
+		s[v] = true
+	}
+	return s
+}
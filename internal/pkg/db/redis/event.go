@@ -24,13 +24,14 @@ import (
 )
 
 type redisEvent struct {
-	ID       string
-	Checksum string
-	Pushed   int64
-	Device   string
-	Created  int64
-	Modified int64
-	Origin   int64
+	ID        string
+	Checksum  string
+	Pushed    int64
+	Device    string
+	Created   int64
+	Modified  int64
+	Origin    int64
+	Originals []db.ReadingOriginal `json:",omitempty"`
 }
 
 func marshalEvent(event correlation.Event) (out []byte, err error) {
@@ -42,6 +43,8 @@ func marshalEvent(event correlation.Event) (out []byte, err error) {
 		Created:  event.Created,
 		Modified: event.Modified,
 		Origin:   event.Origin,
+		// The readings have their ids by now
+		Originals: db.ReadingOriginals(event),
 	}
 
 	return marshalObject(s)
@@ -61,13 +64,19 @@ func unmarshalEvents(objects [][]byte, events []contract.Event) (err error) {
 }
 
 func unmarshalEvent(o []byte) (event contract.Event, err error) {
+	e, err := unmarshalEventWithOriginals(o)
+	return e.Event, err
+}
+
+func unmarshalEventWithOriginals(o []byte) (e correlation.Event, err error) {
 	var s redisEvent
 
 	err = json.Unmarshal(o, &s)
 	if err != nil {
-		return contract.Event{}, err
+		return correlation.Event{}, err
 	}
 
+	var event contract.Event
 	event.ID = s.ID
 	event.Pushed = s.Pushed
 	event.Device = s.Device
@@ -77,25 +86,33 @@ func unmarshalEvent(o []byte) (event contract.Event, err error) {
 
 	conn, err := getConnection()
 	if err != nil {
-		return event, err
+		return e, err
 	}
 	defer conn.Close()
 
 	objects, err := getObjectsByRange(conn, db.EventsCollection+":readings:"+s.ID, 0, -1)
 	if err != nil {
 		if err != redis.ErrNil {
-			return event, err
+			return e, err
 		}
 	}
 
-	event.Readings = make([]contract.Reading, len(objects))
+	event.Readings = make([]contract.Reading, 0, len(objects))
 
-	for i, in := range objects {
-		err = unmarshalObject(in, &event.Readings[i])
+	// Readings removed by a bulk delete may still be referenced by the event
+	for _, in := range objects {
+		if len(in) == 0 {
+			continue
+		}
+		var r contract.Reading
+		err = unmarshalObject(in, &r)
 		if err != nil {
-			return event, err
+			return e, err
 		}
+		event.Readings = append(event.Readings, r)
 	}
+	e.Event = event
+	e.Originals = db.EventOriginals(event.Readings, s.Originals)
 
-	return event, nil
+	return e, nil
 }
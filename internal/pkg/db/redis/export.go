@@ -131,7 +131,136 @@ func (c *Client) ScrubAllRegistrations() (err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	return unlinkCollection(conn, db.ExportCollection)
+	if err = unlinkCollection(conn, db.ExportCollection); err != nil {
+		return err
+	}
+	_, err = conn.Do("DEL", db.ExportTemplateCollection, db.ExportFilterCollection, db.ExportCSVCollection)
+	return err
+}
+
+// ********************** TEMPLATE FUNCTIONS *****************************
+// The templates are held by a hash from the registration names
+
+// Return the templates of the registrations by registration name
+// UnexpectedError - failed to retrieve templates from the database
+func (c *Client) Templates() (map[string]string, error) {
+	return c.registrationOptions(db.ExportTemplateCollection)
+}
+
+// Get the template of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no template
+func (c *Client) TemplateByName(name string) (string, error) {
+	return c.registrationOption(db.ExportTemplateCollection, name)
+}
+
+// Set the template of a registration, replacing the one it had
+// UnexpectedError - problem updating in database
+func (c *Client) UpdateTemplate(name string, template string) error {
+	return c.updateRegistrationOption(db.ExportTemplateCollection, name, template)
+}
+
+// Delete the template of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no template
+func (c *Client) DeleteTemplateByName(name string) error {
+	return c.deleteRegistrationOption(db.ExportTemplateCollection, name)
+}
+
+// ********************** FILTER FUNCTIONS *****************************
+// The filter expressions are held by a hash from the registration names, as the templates are
+
+// Return the filter expressions of the registrations by registration name
+// UnexpectedError - failed to retrieve filters from the database
+func (c *Client) Filters() (map[string]string, error) {
+	return c.registrationOptions(db.ExportFilterCollection)
+}
+
+// Get the filter expression of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no filter
+func (c *Client) FilterByName(name string) (string, error) {
+	return c.registrationOption(db.ExportFilterCollection, name)
+}
+
+// Set the filter expression of a registration, replacing the one it had
+// UnexpectedError - problem updating in database
+func (c *Client) UpdateFilter(name string, expression string) error {
+	return c.updateRegistrationOption(db.ExportFilterCollection, name, expression)
+}
+
+// Delete the filter expression of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no filter
+func (c *Client) DeleteFilterByName(name string) error {
+	return c.deleteRegistrationOption(db.ExportFilterCollection, name)
+}
+
+// ********************** CSV FUNCTIONS *****************************
+// Return the CSV settings of the registrations by registration name
+// UnexpectedError - failed to retrieve CSV settings from the database
+func (c *Client) CSVSettings() (map[string]string, error) {
+	return c.registrationOptions(db.ExportCSVCollection)
+}
+
+// Get the CSV settings of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no CSV settings
+func (c *Client) CSVSettingsByName(name string) (string, error) {
+	return c.registrationOption(db.ExportCSVCollection, name)
+}
+
+// Set the CSV settings of a registration, replacing those it had
+// UnexpectedError - problem updating in database
+func (c *Client) UpdateCSVSettings(name string, settings string) error {
+	return c.updateRegistrationOption(db.ExportCSVCollection, name, settings)
+}
+
+// Delete the CSV settings of a registration
+// UnexpectedError - problem getting in database
+// NotFound - the registration has no CSV settings
+func (c *Client) DeleteCSVSettingsByName(name string) error {
+	return c.deleteRegistrationOption(db.ExportCSVCollection, name)
+}
+
+func (c *Client) registrationOptions(hash string) (map[string]string, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	return redis.StringMap(conn.Do("HGETALL", hash))
+}
+
+func (c *Client) registrationOption(hash string, name string) (string, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	o, err := redis.String(conn.Do("HGET", hash, name))
+	if err == redis.ErrNil {
+		return "", db.ErrNotFound
+	}
+	return o, err
+}
+
+func (c *Client) updateRegistrationOption(hash string, name string, value string) error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HSET", hash, name, value)
+	return err
+}
+
+func (c *Client) deleteRegistrationOption(hash string, name string) error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	deleted, err := redis.Int(conn.Do("HDEL", hash, name))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return db.ErrNotFound
+	}
+	return nil
 }
 
 func addRegistration(conn redis.Conn, r contract.Registration) (id string, err error) {
@@ -0,0 +1,186 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// Number of events and of readings expired by a single call of the script
+	expiryBatch = 1000
+	// Number of events evicted between two checks of the memory used, kept small so that eviction
+	// stops close to the limit
+	evictionBatch = 100
+	// Number of keys asked for by each SCAN of the indexes to prune
+	pruneScanCount = 1000
+)
+
+// Maintain expires the events and readings older than the TTL, then evicts the oldest ones while
+// the server uses more memory than allowed. The keys of the events and readings expire on their
+// own, but the sorted sets indexing them only lose their entries here.
+// Return the number of events and readings expired and evicted.
+func (c *Client) Maintain() (expired int, evicted int, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if c.ttl > 0 {
+		expired, err = expireData(conn, db.MakeTimestamp()-c.ttl)
+		if err != nil {
+			return expired, 0, err
+		}
+	}
+	if c.maxMemory > 0 {
+		evicted, err = evictData(conn, c.maxMemory)
+	}
+	return expired, evicted, err
+}
+
+// Remove the events and readings created before the cutoff along with their index entries
+func expireData(conn redis.Conn, cutoff int64) (int, error) {
+	total := 0
+	for {
+		events, readings, err := expireDataLua(conn, cutoff, expiryBatch)
+		total += events + readings
+		if err != nil {
+			return total, err
+		}
+		if events < expiryBatch && readings < expiryBatch {
+			break
+		}
+	}
+
+	_, err := pruneIndexes(conn, cutoff,
+		db.EventsCollection+":device:", db.ReadingsCollection+":device:", db.ReadingsCollection+":name:")
+	return total, err
+}
+
+// Remove the members scored before the cutoff from every zset whose key starts with one of the
+// prefixes. The indexes scored by creation time this way lose the entries of the events and
+// readings which expired without their content left to tell which indexes held them.
+// The keys are scanned from here a batch at a time, so that redis is never held for long.
+func pruneIndexes(conn redis.Conn, cutoff int64, prefixes ...string) (int, error) {
+	max := "(" + strconv.FormatInt(cutoff, 10)
+	pruned := 0
+	for _, prefix := range prefixes {
+		cursor := 0
+		for {
+			reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", prefix+"*", "COUNT", pruneScanCount))
+			if err != nil {
+				return pruned, err
+			}
+			var keys []string
+			if _, err = redis.Scan(reply, &cursor, &keys); err != nil {
+				return pruned, err
+			}
+
+			for _, key := range keys {
+				if err = conn.Send("ZREMRANGEBYSCORE", key, "-inf", max); err != nil {
+					return pruned, err
+				}
+			}
+			if len(keys) > 0 {
+				if err = conn.Flush(); err != nil {
+					return pruned, err
+				}
+				for range keys {
+					n, err := redis.Int(conn.Receive())
+					if err != nil {
+						return pruned, err
+					}
+					pruned += n
+				}
+			}
+
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// Remove the oldest events with their readings, then the oldest readings left without an event,
+// until the server uses no more than maxMemory bytes. Nothing is evicted when the server uses more
+// than that for everything else, as evicting every event and reading would not be enough.
+func evictData(conn redis.Conn, maxMemory int64) (int, error) {
+	used, err := usedMemory(conn)
+	if err != nil || used <= maxMemory {
+		return 0, err
+	}
+	var data int64
+	for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+		size, err := collectionSize(conn, collection)
+		if err != nil {
+			return 0, err
+		}
+		data += size
+	}
+	if other := used - data; other >= maxMemory {
+		return 0, fmt.Errorf("redis uses about %d bytes for other than events and readings, over the %d allowed, so none are evicted", other, maxMemory)
+	}
+
+	total := 0
+	for {
+		used, err := usedMemory(conn)
+		if err != nil || used <= maxMemory {
+			return total, err
+		}
+
+		n, err := purgeEventsLua(conn, db.EventsCollection+":created", db.PurgeFilter{}, evictionBatch)
+		if err == nil && n == 0 {
+			n, err = purgeReadingsLua(conn, db.ReadingsCollection+":created", db.PurgeFilter{}, evictionBatch)
+		}
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, fmt.Errorf("redis uses %d bytes, over the %d allowed, with no events or readings left to evict", used, maxMemory)
+		}
+	}
+}
+
+// Bytes of memory used by the server
+func usedMemory(conn redis.Conn) (int64, error) {
+	info, err := redis.String(conn.Do("INFO", "memory"))
+	if err != nil {
+		return 0, err
+	}
+	return parseUsedMemory(info)
+}
+
+func parseUsedMemory(info string) (int64, error) {
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "used_memory:") {
+			return strconv.ParseInt(strings.TrimPrefix(line, "used_memory:"), 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("used_memory missing from the memory info of redis")
+}
+
+// Set a key to expire ttl milliseconds after the creation of the object it holds, unless ttl is 0
+func expire(conn redis.Conn, key string, created int64, ttl int64) {
+	if ttl > 0 {
+		_ = conn.Send("PEXPIREAT", key, created+ttl)
+	}
+}
@@ -0,0 +1,108 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package redis
+
+import (
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Number of objects read at once when rebuilding an index
+const migrationBatchSize = 1000
+
+func (c *Client) SchemaVersion() (int, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	version, err := redis.Int(conn.Do("GET", db.SchemaVersionCollection))
+	if err == redis.ErrNil {
+		return db.BaseSchemaVersion, nil
+	}
+	return version, err
+}
+
+func (c *Client) SetSchemaVersion(version int) error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("SET", db.SchemaVersionCollection, version)
+	return err
+}
+
+// The base version is the layout of the releases without migrations. Of the keys added since, only
+// the origin indexes cover data written before; the quarantine, the event keys and the options of
+// export registrations start out empty and are written as they are used. Every other key kept the
+// layout of the base version, so there is nothing else to migrate.
+func (c *Client) Migrations() []db.Migration {
+	return []db.Migration{
+		{
+			Version:     2,
+			Description: "Index the events and readings by origin",
+			Up:          c.indexByOrigin,
+			Down:        c.dropOriginIndex,
+		},
+	}
+}
+
+// The events and readings added before the origin queries are missing from the origin indexes
+func (c *Client) indexByOrigin() error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	for _, collection := range []string{db.EventsCollection, db.ReadingsCollection} {
+		for start := 0; ; start += migrationBatchSize {
+			ids, err := redis.Values(conn.Do("ZRANGE", collection, start, start+migrationBatchSize-1))
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			objects, err := redis.ByteSlices(conn.Do("MGET", ids...))
+			if err != nil {
+				return err
+			}
+
+			args := []interface{}{collection + ":origin"}
+			for i, o := range objects {
+				if o == nil {
+					continue
+				}
+				// Events are stored with capitalized fields and readings with the contract ones
+				var s struct {
+					Origin int64
+				}
+				if err = unmarshalObject(o, &s); err != nil {
+					return err
+				}
+				args = append(args, s.Origin, ids[i])
+			}
+			if len(args) > 1 {
+				if _, err = conn.Do("ZADD", args...); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) dropOriginIndex() error {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", db.EventsCollection+":origin", db.ReadingsCollection+":origin")
+	return err
+}
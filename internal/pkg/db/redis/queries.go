@@ -275,3 +275,133 @@ func getObjectsByValuesSorted(conn redis.Conn, limit int, vals ...string) (objec
 
 	return objects, nil
 }
+
+// Ids read from an index at once, so that no single command holds the server for long
+const scanWindow = 1000
+
+// Call fn with the ids of a zset scored within [min, max], by increasing or decreasing score, a
+// window at a time until fn returns false
+// Each window resumes from the last score seen, skipping the ids already seen with it, as an offset
+// into the whole range would be walked again by the server for every window.
+func scanZset(conn redis.Conn, key string, min, max interface{}, descending bool, fn func(ids []interface{}) (bool, error)) error {
+	cmd, from, to := "ZRANGEBYSCORE", min, max
+	if descending {
+		cmd, from, to = "ZREVRANGEBYSCORE", max, min
+	}
+
+	fromScore := ""
+	skip := 0
+	for {
+		values, err := redis.Strings(conn.Do(cmd, key, from, to, "WITHSCORES", "LIMIT", skip, scanWindow))
+		if err != nil {
+			return err
+		}
+		n := len(values) / 2
+		if n == 0 {
+			return nil
+		}
+
+		ids := make([]interface{}, n)
+		for i := range ids {
+			ids[i] = values[2*i]
+		}
+		more, err := fn(ids)
+		if err != nil || !more || n < scanWindow {
+			return err
+		}
+
+		last := values[2*n-1]
+		same := 0
+		for i := n - 1; i >= 0 && values[2*i+1] == last; i-- {
+			same++
+		}
+		if last == fromScore {
+			skip += same
+		} else {
+			from, fromScore, skip = last, last, same
+		}
+	}
+}
+
+// Return up to limit objects from a zset scored by creation time, starting after the cursor
+// Members with the same score as the cursor are compared by ID, matching the zset ordering
+// if filter is not empty, only members that also belong to the filter zset are returned
+// if end is 0 or negative, it is considered as positive infinity
+// The zset is read a window at a time, so that a filter matching few members does not hold the server
+func getObjectsAfterCursor(conn redis.Conn, key string, filter string, start, end int64, cursor db.Cursor, limit int) (objects [][]byte, err error) {
+	var max interface{} = end
+	if end <= 0 {
+		max = "+inf"
+	}
+
+	ids := []interface{}{}
+	collect := func(window []interface{}) (bool, error) {
+		if filter != "" {
+			if window, err = zsetMembers(conn, filter, window); err != nil {
+				return false, err
+			}
+		}
+		for _, id := range window {
+			if len(ids) == limit {
+				break
+			}
+			ids = append(ids, id)
+		}
+		return len(ids) < limit, nil
+	}
+
+	var min interface{} = start
+	if !cursor.IsZero() && cursor.Created >= start {
+		if end > 0 && cursor.Created > end {
+			return [][]byte{}, nil
+		}
+		// The members scored as the cursor follow it by ID
+		err = scanZset(conn, key, cursor.Created, cursor.Created, false, func(window []interface{}) (bool, error) {
+			after := make([]interface{}, 0, len(window))
+			for _, id := range window {
+				if id.(string) > cursor.Id {
+					after = append(after, id)
+				}
+			}
+			return collect(after)
+		})
+		if err != nil {
+			return nil, err
+		}
+		min = "(" + strconv.FormatInt(cursor.Created, 10)
+	}
+	if len(ids) < limit {
+		if err = scanZset(conn, key, min, max, false, collect); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ids) == 0 {
+		return [][]byte{}, nil
+	}
+	return redis.ByteSlices(conn.Do("MGET", ids...))
+}
+
+// Return the ids which belong to the zset, checked in a single round trip
+func zsetMembers(conn redis.Conn, key string, ids []interface{}) ([]interface{}, error) {
+	for _, id := range ids {
+		if err := conn.Send("ZSCORE", key, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		score, err := conn.Receive()
+		if err != nil {
+			return nil, err
+		}
+		if score != nil {
+			members = append(members, id)
+		}
+	}
+	return members, nil
+}
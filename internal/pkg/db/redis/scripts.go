@@ -13,7 +13,13 @@
  *******************************************************************************/
 package redis
 
-import "github.com/gomodule/redigo/redis"
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/gomodule/redigo/redis"
+)
 
 /***********************
  * Lua scripts notes:
@@ -87,6 +93,147 @@ const (
 	end
 	return rep
 	`
+	scriptPurgeEvents = `
+	local E = ARGV[4]
+	local R = ARGV[5]
+	local f = cjson.decode(ARGV[3])
+	local limit = tonumber(ARGV[2])
+	local exclude = {}
+	if type(f.exclude) == 'table' then
+		for _, d in ipairs(f.exclude) do
+			exclude[d] = true
+		end
+	end
+	local function unlinkReading(rid)
+		local o = redis.call('GET', rid)
+		if o then
+			local r = cjson.decode(o)
+			if type(r.device) == 'string' then
+				redis.call('ZREM', R .. ':device:' .. r.device, rid)
+			end
+			if type(r.name) == 'string' then
+				redis.call('ZREM', R .. ':name:' .. r.name, rid)
+			end
+		end
+		redis.call('UNLINK', rid)
+		redis.call('ZREM', R, rid)
+		redis.call('ZREM', R .. ':created', rid)
+		redis.call('ZREM', R .. ':origin', rid)
+	end
+	local deleted = 0
+	local skipped = 0
+	while deleted < limit do
+		local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', skipped, limit - deleted)
+		if #ids == 0 then
+			break
+		end
+		for _, id in ipairs(ids) do
+			local o = redis.call('GET', id)
+			local e = o and cjson.decode(o)
+			if e and (exclude[e.Device] or (f.onlyPushed and (tonumber(e.Pushed) or 0) == 0)) then
+				skipped = skipped + 1
+			else
+				if e then
+					for _, rid in ipairs(redis.call('ZRANGE', E .. ':readings:' .. id, 0, -1)) do
+						unlinkReading(rid)
+					end
+					redis.call('ZREM', E .. ':device:' .. e.Device, id)
+					if type(e.Checksum) == 'string' and e.Checksum ~= '' then
+						redis.call('ZREM', E .. ':checksum:' .. e.Checksum, id)
+					end
+				end
+				redis.call('UNLINK', id, E .. ':readings:' .. id)
+				redis.call('ZREM', KEYS[1], id)
+				redis.call('ZREM', E, id)
+				redis.call('ZREM', E .. ':created', id)
+				redis.call('ZREM', E .. ':origin', id)
+				redis.call('ZREM', E .. ':pushed', id)
+				deleted = deleted + 1
+			end
+		end
+	end
+	return deleted
+	`
+	scriptPurgeReadings = `
+	local R = ARGV[4]
+	local f = cjson.decode(ARGV[3])
+	local limit = tonumber(ARGV[2])
+	local function set(list)
+		if type(list) ~= 'table' or #list == 0 then
+			return nil
+		end
+		local s = {}
+		for _, v in ipairs(list) do
+			s[v] = true
+		end
+		return s
+	end
+	local devices = set(f.devices)
+	local exclude = set(f.exclude) or {}
+	local deleted = 0
+	local skipped = 0
+	while deleted < limit do
+		local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', skipped, limit - deleted)
+		if #ids == 0 then
+			break
+		end
+		for _, rid in ipairs(ids) do
+			local o = redis.call('GET', rid)
+			local r = o and cjson.decode(o)
+			local device = r and type(r.device) == 'string' and r.device or ''
+			if r and ((devices and not devices[device]) or exclude[device]
+				or (f.onlyPushed and (tonumber(r.pushed) or 0) == 0)) then
+				skipped = skipped + 1
+			else
+				if r then
+					redis.call('ZREM', R .. ':device:' .. device, rid)
+					if type(r.name) == 'string' then
+						redis.call('ZREM', R .. ':name:' .. r.name, rid)
+					end
+				end
+				redis.call('UNLINK', rid)
+				redis.call('ZREM', KEYS[1], rid)
+				redis.call('ZREM', R, rid)
+				redis.call('ZREM', R .. ':created', rid)
+				redis.call('ZREM', R .. ':origin', rid)
+				deleted = deleted + 1
+			end
+		end
+	end
+	return deleted
+	`
+	scriptExpireData = `
+	local E = ARGV[3]
+	local R = ARGV[4]
+	local max = '(' .. ARGV[1]
+	local limit = tonumber(ARGV[2])
+	local function expire(C, indexes, extra)
+		local ids = redis.call('ZRANGEBYSCORE', C .. ':created', '-inf', max, 'LIMIT', 0, limit)
+		for _, id in ipairs(ids) do
+			local o = redis.call('GET', id)
+			local ok, x = pcall(cjson.decode, o or '')
+			if ok and type(x) == 'table' then
+				for index, field in pairs(indexes) do
+					if type(x[field]) == 'string' and x[field] ~= '' then
+						redis.call('ZREM', C .. index .. x[field], id)
+					end
+				end
+			end
+			redis.call('UNLINK', id)
+			redis.call('ZREM', C, id)
+			redis.call('ZREM', C .. ':created', id)
+			redis.call('ZREM', C .. ':origin', id)
+			extra(id)
+		end
+		return #ids
+	end
+	local events = expire(E, {[':device:'] = 'Device', [':checksum:'] = 'Checksum'}, function(id)
+		redis.call('UNLINK', E .. ':readings:' .. id)
+		redis.call('ZREM', E .. ':pushed', id)
+	end)
+	local readings = expire(R, {[':device:'] = 'device', [':name:'] = 'name'}, function() end)
+	return {events, readings}
+	`
 	scriptUnlinkZsetMembers = `
 	local magic = 4096
 	local ids = redis.call('ZRANGE', KEYS[1], 0, -1)
@@ -111,9 +258,12 @@ const (
 )
 
 var scripts = map[string]redis.Script{
+	"expireData":              *redis.NewScript(0, scriptExpireData),
 	"getObjectsByRange":       *redis.NewScript(1, scriptGetObjectsByRange),
 	"getObjectsByRangeFilter": *redis.NewScript(2, scriptGetObjectsByRangeFilter),
 	"getObjectsByScore":       *redis.NewScript(1, scriptGetObjectsByScore),
+	"purgeEvents":             *redis.NewScript(1, scriptPurgeEvents),
+	"purgeReadings":           *redis.NewScript(1, scriptPurgeReadings),
 	"unlinkZsetMembers":       *redis.NewScript(1, scriptUnlinkZsetMembers),
 	"unlinkCollection":        *redis.NewScript(0, scriptUnlinkCollection),
 }
@@ -150,3 +300,54 @@ func getObjectsByScoreLua(conn redis.Conn, key string, start, end int64, limit i
 
 	return objects, nil
 }
+
+// Delete up to limit of the oldest events indexed by key, along with their readings
+// The events matching the filter devices are expected to be indexed by key
+func purgeEventsLua(conn redis.Conn, key string, filter db.PurgeFilter, limit int) (int, error) {
+	arg, err := json.Marshal(map[string]interface{}{
+		"onlyPushed": filter.OnlyPushed,
+		"exclude":    filter.ExcludeDevices,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s := scripts["purgeEvents"]
+	return redis.Int(s.Do(conn, key, purgeMax(filter), limit, arg, db.EventsCollection, db.ReadingsCollection))
+}
+
+// Delete up to limit of the oldest readings indexed by key
+// References held by events are left behind and skipped when the events are read
+func purgeReadingsLua(conn redis.Conn, key string, filter db.PurgeFilter, limit int) (int, error) {
+	arg, err := json.Marshal(map[string]interface{}{
+		"onlyPushed": filter.OnlyPushed,
+		"devices":    filter.Devices,
+		"exclude":    filter.ExcludeDevices,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s := scripts["purgeReadings"]
+	return redis.Int(s.Do(conn, key, purgeMax(filter), limit, arg, db.ReadingsCollection))
+}
+
+// The exclusive upper bound on the creation time of purged data
+func purgeMax(filter db.PurgeFilter) string {
+	if filter.Before <= 0 {
+		return "+inf"
+	}
+	return "(" + strconv.FormatInt(filter.Before, 10)
+}
+
+// Delete up to limit of the events and as many of the readings created before the cutoff,
+// removing them from every index even when their key already expired
+// Return the number of events and readings removed
+func expireDataLua(conn redis.Conn, cutoff int64, limit int) (events int, readings int, err error) {
+	s := scripts["expireData"]
+	counts, err := redis.Ints(s.Do(conn, cutoff, limit, db.EventsCollection, db.ReadingsCollection))
+	if err != nil {
+		return 0, 0, err
+	}
+	return counts[0], counts[1], nil
+}
@@ -498,6 +498,717 @@ func testDBEvents(t *testing.T, db interfaces.DBClient) {
 	}
 }
 
+func testDBEventsPage(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+
+	_, err = populateDbEvents(db, 25, 0)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+	_, err = populateDbEvents(db, 5, 0)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	seen := make(map[string]bool)
+	var cursor dbp.Cursor
+	var lastCreated int64
+	pages := 0
+	for {
+		events, next, err := db.EventsPage(dbp.EventFilter{}, cursor, 7)
+		if err != nil {
+			t.Fatalf("Error getting events page %v", err)
+		}
+		if len(events) > 7 {
+			t.Fatalf("There should be at most 7 events in a page instead of %d", len(events))
+		}
+		for _, e := range events {
+			if seen[e.ID] {
+				t.Fatalf("Event %s returned twice", e.ID)
+			}
+			seen[e.ID] = true
+			if e.Created < lastCreated {
+				t.Fatalf("Events should be sorted by creation time")
+			}
+			lastCreated = e.Created
+		}
+		pages++
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 30 {
+		t.Fatalf("There should be 30 events instead of %d", len(seen))
+	}
+	if pages != 5 {
+		t.Fatalf("There should be 5 pages instead of %d", pages)
+	}
+
+	events, next, err := db.EventsPage(dbp.EventFilter{Device: "name1"}, dbp.Cursor{}, 10)
+	if err != nil {
+		t.Fatalf("Error getting events page %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events instead of %d", len(events))
+	}
+	if !next.IsZero() {
+		t.Fatalf("There should be no more events for the device")
+	}
+
+	events, next, err = db.EventsPage(dbp.EventFilter{Device: "name1"}, dbp.Cursor{}, 1)
+	if err != nil {
+		t.Fatalf("Error getting events page %v", err)
+	}
+	if len(events) != 1 || next.IsZero() {
+		t.Fatalf("There should be 1 event and a cursor to the next one")
+	}
+	events, next, err = db.EventsPage(dbp.EventFilter{Device: "name1"}, next, 1)
+	if err != nil {
+		t.Fatalf("Error getting events page %v", err)
+	}
+	if len(events) != 1 || !next.IsZero() {
+		t.Fatalf("There should be 1 event and no more after it")
+	}
+
+	events, _, err = db.EventsPage(dbp.EventFilter{Start: dbp.MakeTimestamp() + 1000}, dbp.Cursor{}, 10)
+	if err != nil {
+		t.Fatalf("Error getting events page %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("There should be 0 events instead of %d", len(events))
+	}
+
+	_, _, err = db.EventsPage(dbp.EventFilter{}, dbp.Cursor{Created: 1, Id: "invalid"}, 10)
+	if err != dbp.ErrInvalidCursor {
+		t.Fatalf("Expected an invalid cursor error instead of %v", err)
+	}
+
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+}
+
+func testDBReadingsPage(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings: %v\n", err)
+	}
+
+	_, err = populateDbReadings(db, 20)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+	_, err = populateDbReadings(db, 5)
+	if err != nil {
+		t.Fatalf("Error populating db: %v\n", err)
+	}
+
+	seen := make(map[string]bool)
+	var cursor dbp.Cursor
+	for {
+		readings, next, err := db.ReadingsPage(dbp.ReadingFilter{}, cursor, 6)
+		if err != nil {
+			t.Fatalf("Error getting readings page %v", err)
+		}
+		for _, r := range readings {
+			if seen[r.Id] {
+				t.Fatalf("Reading %s returned twice", r.Id)
+			}
+			seen[r.Id] = true
+		}
+		if next.IsZero() {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 25 {
+		t.Fatalf("There should be 25 readings instead of %d", len(seen))
+	}
+
+	filter := dbp.ReadingFilter{Names: []string{"name1", "name2", "name3"}}
+	readings, next, err := db.ReadingsPage(filter, dbp.Cursor{}, 4)
+	if err != nil {
+		t.Fatalf("Error getting readings page %v", err)
+	}
+	if len(readings) != 4 || next.IsZero() {
+		t.Fatalf("There should be 4 readings and a cursor to the next ones")
+	}
+	for i := 1; i < len(readings); i++ {
+		if readings[i].Created < readings[i-1].Created {
+			t.Fatalf("Readings should be sorted by creation time")
+		}
+	}
+	readings, next, err = db.ReadingsPage(filter, next, 4)
+	if err != nil {
+		t.Fatalf("Error getting readings page %v", err)
+	}
+	if len(readings) != 2 || !next.IsZero() {
+		t.Fatalf("There should be 2 readings and no more after them instead of %d", len(readings))
+	}
+
+	filter = dbp.ReadingFilter{Device: "name1", Names: []string{"name1"}}
+	readings, _, err = db.ReadingsPage(filter, dbp.Cursor{}, 10)
+	if err != nil {
+		t.Fatalf("Error getting readings page %v", err)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("There should be 2 readings instead of %d", len(readings))
+	}
+
+	filter = dbp.ReadingFilter{Device: "name1", Names: []string{"name2"}}
+	readings, _, err = db.ReadingsPage(filter, dbp.Cursor{}, 10)
+	if err != nil {
+		t.Fatalf("Error getting readings page %v", err)
+	}
+	if len(readings) != 0 {
+		t.Fatalf("There should be 0 readings instead of %d", len(readings))
+	}
+
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings: %v\n", err)
+	}
+}
+
+func testDBReadingsQuery(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings: %v\n", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		r := contract.Reading{}
+		r.Name = "temperature"
+		if i%2 == 1 {
+			r.Name = "humidity"
+		}
+		r.Device = fmt.Sprintf("device%d", i%3)
+		r.Value = strconv.Itoa(i * 10)
+		r.Origin = int64(100 - i)
+		if _, err = db.AddReading(r); err != nil {
+			t.Fatalf("Error adding reading: %v\n", err)
+		}
+	}
+	r := contract.Reading{Name: "temperature", Device: "device0", Value: "broken", Origin: 1}
+	if _, err = db.AddReading(r); err != nil {
+		t.Fatalf("Error adding reading: %v\n", err)
+	}
+
+	tests := []struct {
+		name  string
+		query dbp.ReadingQuery
+		count int
+	}{
+		{"all", dbp.ReadingQuery{Limit: 100}, 11},
+		{"limit", dbp.ReadingQuery{Limit: 3}, 3},
+		{"name", dbp.ReadingQuery{Names: []string{"humidity"}, Limit: 100}, 5},
+		{"devices", dbp.ReadingQuery{Devices: []string{"device1", "device2"}, Limit: 100}, 6},
+		{"name and device", dbp.ReadingQuery{Names: []string{"temperature"}, Devices: []string{"device0"}, Limit: 100}, 3},
+		{"greater", dbp.ReadingQuery{Values: []dbp.ValueCondition{{Op: dbp.OpGreater, Value: "50"}}, Limit: 100}, 4},
+		{"range", dbp.ReadingQuery{Values: []dbp.ValueCondition{
+			{Op: dbp.OpGreaterOrEqual, Value: "20"}, {Op: dbp.OpLess, Value: "40"}}, Limit: 100}, 2},
+		{"equal", dbp.ReadingQuery{Values: []dbp.ValueCondition{{Op: dbp.OpEqual, Value: "broken"}}, Limit: 100}, 1},
+		{"not equal", dbp.ReadingQuery{Values: []dbp.ValueCondition{{Op: dbp.OpNotEqual, Value: "broken"}}, Limit: 100}, 10},
+		{"origin", dbp.ReadingQuery{OriginStart: 95, OriginEnd: 98, Limit: 100}, 4},
+		{"none", dbp.ReadingQuery{Names: []string{"pressure"}, Limit: 100}, 0},
+	}
+	for _, tt := range tests {
+		readings, err := db.ReadingsByQuery(tt.query)
+		if err != nil {
+			t.Fatalf("Error querying readings (%s): %v", tt.name, err)
+		}
+		if len(readings) != tt.count {
+			t.Fatalf("There should be %d readings for query %s instead of %d", tt.count, tt.name, len(readings))
+		}
+	}
+
+	readings, err := db.ReadingsByQuery(dbp.ReadingQuery{Names: []string{"humidity"}, Sort: dbp.SortOrigin, Limit: 100})
+	if err != nil {
+		t.Fatalf("Error querying readings: %v", err)
+	}
+	for i := 1; i < len(readings); i++ {
+		if readings[i].Origin < readings[i-1].Origin {
+			t.Fatalf("Readings should be sorted by origin")
+		}
+	}
+
+	readings, err = db.ReadingsByQuery(dbp.ReadingQuery{Sort: dbp.SortOriginDescending, Limit: 1})
+	if err != nil {
+		t.Fatalf("Error querying readings: %v", err)
+	}
+	if len(readings) != 1 || readings[0].Origin != 100 {
+		t.Fatalf("The reading with the latest origin should come first")
+	}
+
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings: %v\n", err)
+	}
+}
+
+func testDBReadingsAggregate(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings: %v\n", err)
+	}
+
+	values := []string{"1", "5", "3", "not a number"}
+	for _, device := range []string{"device1", "device2"} {
+		for _, v := range values {
+			r := contract.Reading{Name: "temperature", Device: device, Value: v}
+			if _, err = db.AddReading(r); err != nil {
+				t.Fatalf("Error adding reading: %v\n", err)
+			}
+		}
+	}
+	r := contract.Reading{Name: "humidity", Device: "device1", Value: "50"}
+	if _, err = db.AddReading(r); err != nil {
+		t.Fatalf("Error adding reading: %v\n", err)
+	}
+
+	// A single bucket covering every reading added above
+	query := dbp.AggregateQuery{
+		Names:    []string{"temperature"},
+		Start:    0,
+		End:      dbp.MakeTimestamp(),
+		Interval: dbp.MakeTimestamp() + 1,
+	}
+	buckets, err := db.AggregateReadings(query)
+	if err != nil {
+		t.Fatalf("Error aggregating readings: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("There should be 2 buckets instead of %d", len(buckets))
+	}
+	for i, device := range []string{"device1", "device2"} {
+		b := buckets[i]
+		if b.Device != device || b.Name != "temperature" || b.Start != 0 {
+			t.Fatalf("Unexpected bucket %+v", b)
+		}
+		if b.Count != 3 || b.Min != 1 || b.Max != 5 || b.Avg != 3 {
+			t.Fatalf("Bucket should summarize 3 readings from 1 to 5 instead of %+v", b)
+		}
+	}
+
+	query.Devices = []string{"device2"}
+	query.Names = []string{"temperature", "humidity"}
+	buckets, err = db.AggregateReadings(query)
+	if err != nil {
+		t.Fatalf("Error aggregating readings: %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Device != "device2" {
+		t.Fatalf("There should be 1 bucket for device2 instead of %d", len(buckets))
+	}
+
+	// One bucket per millisecond holding readings
+	query = dbp.AggregateQuery{Names: []string{"humidity"}, Interval: 1}
+	buckets, err = db.AggregateReadings(query)
+	if err != nil {
+		t.Fatalf("Error aggregating readings: %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Count != 1 || buckets[0].Start == 0 {
+		t.Fatalf("There should be 1 bucket starting when the reading was created")
+	}
+
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings: %v\n", err)
+	}
+}
+
+func testDBAddEvents(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events: %v\n", err)
+	}
+
+	var events []correlation.Event
+	for i := 0; i < 3; i++ {
+		e := correlation.Event{}
+		e.Device = fmt.Sprintf("device%d", i)
+		e.Readings = []contract.Reading{{Name: "temperature", Value: strconv.Itoa(i)}}
+		events = append(events, e)
+	}
+
+	ids, err := db.AddEvents(events)
+	if err != nil {
+		t.Fatalf("Error adding events: %v\n", err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("There should be 3 ids instead of %d", len(ids))
+	}
+
+	for i, id := range ids {
+		e, err := db.EventById(id)
+		if err != nil {
+			t.Fatalf("Error getting event %s: %v\n", id, err)
+		}
+		if e.Device != events[i].Device {
+			t.Fatalf("Event %s should be from %s instead of %s", id, events[i].Device, e.Device)
+		}
+		if len(e.Readings) != 1 || e.Readings[0].Value != strconv.Itoa(i) || e.Readings[0].Device != e.Device {
+			t.Fatalf("Event %s has unexpected readings %v", id, e.Readings)
+		}
+	}
+	testReadingCount(t, db, 3)
+}
+
+func testDBOriginals(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events: %v\n", err)
+	}
+
+	e := correlation.Event{}
+	e.Device = "converted"
+	e.Readings = []contract.Reading{
+		{Name: "temperature", Value: "25"},
+		{Name: "humidity", Value: "40"},
+		{Name: "pressure", Value: "101.325"},
+	}
+	e.Originals = []correlation.Original{{Index: 0, Value: "77", Unit: "F"}, {Index: 2, Value: "1.01325", Unit: "bar"}}
+	expected := map[string]correlation.Original{"temperature": e.Originals[0], "pressure": e.Originals[1]}
+
+	batch := e
+	batch.Readings = append([]contract.Reading{}, e.Readings...)
+	id, err := db.AddEvent(e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v\n", err)
+	}
+	ids, err := db.AddEvents([]correlation.Event{batch})
+	if err != nil {
+		t.Fatalf("Error adding events: %v\n", err)
+	}
+
+	checkOriginals := func(id string) correlation.Event {
+		found, err := db.EventWithOriginalsById(id)
+		if err != nil {
+			t.Fatalf("Error getting event %s: %v\n", id, err)
+		}
+		if len(found.Originals) != len(expected) {
+			t.Fatalf("Event %s should have %d originals instead of %v", id, len(expected), found.Originals)
+		}
+		// The readings may be read back in another order
+		for _, o := range found.Originals {
+			r := found.Readings[o.Index]
+			if x := expected[r.Name]; x.Value != o.Value || x.Unit != o.Unit {
+				t.Fatalf("Event %s has unexpected original %v for reading %s", id, o, r.Name)
+			}
+		}
+		return found
+	}
+	checkOriginals(id)
+	found := checkOriginals(ids[0])
+
+	// Updating the event leaves what its readings were received with
+	found.Pushed = dbp.MakeTimestamp()
+	found.Originals = nil
+	if err = db.UpdateEvent(found); err != nil {
+		t.Fatalf("Error updating event: %v\n", err)
+	}
+	checkOriginals(ids[0])
+}
+
+func testDBEventKeys(t *testing.T, db interfaces.DBClient) {
+	now := dbp.MakeTimestamp()
+	key := fmt.Sprintf("key%d", now)
+
+	if _, err := db.EventIdByKey(key); err != dbp.ErrNotFound {
+		t.Fatalf("Expected no event for a new key, got %v", err)
+	}
+
+	if err := db.AddEventKey(key, "event1", now+60000); err != nil {
+		t.Fatalf("Error adding event key: %v", err)
+	}
+	id, err := db.EventIdByKey(key)
+	if err != nil || id != "event1" {
+		t.Fatalf("Expected event1, got %s %v", id, err)
+	}
+
+	expired := key + "expired"
+	if err = db.AddEventKey(expired, "event2", now-1000); err != nil {
+		t.Fatalf("Error adding event key: %v", err)
+	}
+	if _, err = db.EventIdByKey(expired); err != dbp.ErrNotFound {
+		t.Fatalf("Expected no event for an expired key, got %v", err)
+	}
+
+	// A key is reserved once, then gives the id of its event
+	reserved := key + "reserved"
+	if _, ok, err := db.ReserveEventKey(reserved, now+60000); err != nil || !ok {
+		t.Fatalf("Expected the key to be reserved, got %v %v", ok, err)
+	}
+	if id, ok, err := db.ReserveEventKey(reserved, now+60000); err != nil || ok || id != "" {
+		t.Fatalf("Expected the key to be reserved already, got '%s' %v %v", id, ok, err)
+	}
+	if _, err = db.EventIdByKey(reserved); err != dbp.ErrNotFound {
+		t.Fatalf("Expected no event for a reserved key, got %v", err)
+	}
+	if err = db.AddEventKey(reserved, "event3", now+60000); err != nil {
+		t.Fatalf("Error adding event key: %v", err)
+	}
+	if id, ok, err := db.ReserveEventKey(reserved, now+60000); err != nil || ok || id != "event3" {
+		t.Fatalf("Expected event3, got '%s' %v %v", id, ok, err)
+	}
+	if id, ok, err := db.ReserveEventKey(key, now+60000); err != nil || ok || id != "event1" {
+		t.Fatalf("Expected event1, got '%s' %v %v", id, ok, err)
+	}
+
+	// Keys are released for other events, as are expired keys
+	if err = db.DeleteEventKey(reserved); err != nil {
+		t.Fatalf("Error deleting event key: %v", err)
+	}
+	if _, ok, err := db.ReserveEventKey(reserved, now+60000); err != nil || !ok {
+		t.Fatalf("Expected the released key to be reserved, got %v %v", ok, err)
+	}
+	if err = db.DeleteEventKey(reserved); err != nil {
+		t.Fatalf("Error deleting event key: %v", err)
+	}
+	if err = db.DeleteEventKey(reserved); err != nil {
+		t.Fatalf("Error deleting a missing event key: %v", err)
+	}
+}
+
+func testDBOriginTime(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events: %v\n", err)
+	}
+
+	// Events added in the reverse order of their origins
+	for i := 5; i > 0; i-- {
+		e := correlation.Event{}
+		e.Device = "device1"
+		e.Origin = int64(i * 1000)
+		e.Readings = []contract.Reading{{Name: "temperature", Device: e.Device, Value: "1", Origin: e.Origin}}
+		if _, err = db.AddEvent(e); err != nil {
+			t.Fatalf("Error adding event: %v\n", err)
+		}
+	}
+
+	events, err := db.EventsByOriginTime(2000, 4000, 10)
+	if err != nil {
+		t.Fatalf("Error getting EventsByOriginTime: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("There should be 3 events instead of %d", len(events))
+	}
+	for _, e := range events {
+		if e.Origin < 2000 || e.Origin > 4000 {
+			t.Fatalf("Event origin %d is out of range", e.Origin)
+		}
+	}
+
+	events, err = db.EventsByOriginTime(0, 10000, 2)
+	if err != nil {
+		t.Fatalf("Error getting EventsByOriginTime: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("There should be 2 events instead of %d", len(events))
+	}
+
+	readings, err := db.ReadingsByOriginTime(3000, 10000, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByOriginTime: %v", err)
+	}
+	if len(readings) != 3 {
+		t.Fatalf("There should be 3 readings instead of %d", len(readings))
+	}
+
+	readings, err = db.ReadingsByOriginTime(6000, 10000, 10)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByOriginTime: %v", err)
+	}
+	if len(readings) != 0 {
+		t.Fatalf("There should be no readings instead of %d", len(readings))
+	}
+}
+
+func testDBQuarantine(t *testing.T, db interfaces.DBClient) {
+	q := dbp.QuarantinedEvent{Reason: "origin too old"}
+	q.Event.Device = "device1"
+	q.Event.Origin = 1000
+	q.Event.Readings = []contract.Reading{{Name: "temperature", Device: "device1", Value: "1"}}
+
+	if _, err := db.DeleteQuarantinedEvents(dbp.MakeTimestamp() + 1); err != nil {
+		t.Fatalf("Error removing all quarantined events: %v", err)
+	}
+
+	id, err := db.AddQuarantinedEvent(q)
+	if err != nil {
+		t.Fatalf("Error adding quarantined event: %v", err)
+	}
+	if id == "" {
+		t.Fatalf("Quarantined event should have an id")
+	}
+	q.Event.Device = "device2"
+	q.Created = dbp.MakeTimestamp() + 60000
+	if _, err = db.AddQuarantinedEvent(q); err != nil {
+		t.Fatalf("Error adding quarantined event: %v", err)
+	}
+
+	got, err := db.QuarantinedEventById(id)
+	if err != nil {
+		t.Fatalf("Error getting quarantined event: %v", err)
+	}
+	if got.ID != id || got.Reason != q.Reason || got.Event.Device != "device1" || len(got.Event.Readings) != 1 {
+		t.Fatalf("Quarantined event does not match: %v", got)
+	}
+	if _, err = db.QuarantinedEventById("unknown"); err != dbp.ErrNotFound {
+		t.Fatalf("Expected no quarantined event for an unknown id, got %v", err)
+	}
+
+	list, err := db.QuarantinedEvents(0)
+	if err != nil {
+		t.Fatalf("Error getting quarantined events: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != id {
+		t.Fatalf("There should be 2 quarantined events, oldest first, instead of %v", list)
+	}
+	list, err = db.QuarantinedEvents(1)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("There should be 1 quarantined event instead of %d: %v", len(list), err)
+	}
+	list, err = db.QuarantinedEventsByDevice("device2", 0)
+	if err != nil || len(list) != 1 || list[0].Event.Device != "device2" {
+		t.Fatalf("There should be 1 quarantined event of device2 instead of %v: %v", list, err)
+	}
+
+	count, err := db.DeleteQuarantinedEvents(dbp.MakeTimestamp() + 1)
+	if err != nil || count != 1 {
+		t.Fatalf("1 quarantined event should have been removed instead of %d: %v", count, err)
+	}
+	if _, err = db.QuarantinedEventById(id); err != dbp.ErrNotFound {
+		t.Fatalf("Expected the quarantined event to be removed, got %v", err)
+	}
+
+	list, _ = db.QuarantinedEventsByDevice("device2", 0)
+	if len(list) != 1 {
+		t.Fatalf("There should be 1 quarantined event left instead of %d", len(list))
+	}
+	if err = db.DeleteQuarantinedEventById(list[0].ID); err != nil {
+		t.Fatalf("Error removing quarantined event: %v", err)
+	}
+	if err = db.DeleteQuarantinedEventById(list[0].ID); err != dbp.ErrNotFound {
+		t.Fatalf("Expected no quarantined event to remove, got %v", err)
+	}
+}
+
+func testDBPurge(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events: %v\n", err)
+	}
+
+	// Every other event of device1 has been pushed, none of device2
+	for i := 0; i < 8; i++ {
+		e := correlation.Event{}
+		e.Device = "device1"
+		if i >= 6 {
+			e.Device = "device2"
+		} else if i%2 == 1 {
+			e.Pushed = dbp.MakeTimestamp()
+		}
+		for _, name := range []string{"temperature", "humidity"} {
+			e.Readings = append(e.Readings, contract.Reading{Name: name, Device: e.Device, Value: "1"})
+		}
+		if _, err = db.AddEvent(e); err != nil {
+			t.Fatalf("Error adding event: %v\n", err)
+		}
+	}
+
+	size, err := db.DataSize()
+	if err != nil {
+		t.Fatalf("Error getting the data size: %v\n", err)
+	}
+	if size <= 0 {
+		t.Fatalf("Data size should be positive instead of %d", size)
+	}
+
+	count, err := db.DeleteEvents(dbp.PurgeFilter{}, 0)
+	if err != nil || count != 0 {
+		t.Fatalf("A limit of 0 should delete nothing instead of %d: %v", count, err)
+	}
+
+	count, err = db.DeleteEvents(dbp.PurgeFilter{Before: 1}, 10)
+	if err != nil || count != 0 {
+		t.Fatalf("No event is older than 1 ms, yet %d were deleted: %v", count, err)
+	}
+
+	count, err = db.DeleteEvents(dbp.PurgeFilter{Devices: []string{"device1"}, OnlyPushed: true}, 10)
+	if err != nil {
+		t.Fatalf("Error deleting events: %v\n", err)
+	}
+	if count != 3 {
+		t.Fatalf("3 pushed events should be deleted instead of %d", count)
+	}
+	testEventCountByDevice(t, db, "device1", 3)
+	testReadingCount(t, db, 10)
+
+	count, err = db.DeleteEvents(dbp.PurgeFilter{ExcludeDevices: []string{"device1"}}, 1)
+	if err != nil {
+		t.Fatalf("Error deleting events: %v\n", err)
+	}
+	if count != 1 {
+		t.Fatalf("1 event should be deleted instead of %d", count)
+	}
+	testEventCountByDevice(t, db, "device1", 3)
+	testEventCountByDevice(t, db, "device2", 1)
+
+	count, err = db.DeleteReadings(dbp.PurgeFilter{Names: []string{"temperature"}, Devices: []string{"device1"}}, 10)
+	if err != nil {
+		t.Fatalf("Error deleting readings: %v\n", err)
+	}
+	if count != 3 {
+		t.Fatalf("3 readings should be deleted instead of %d", count)
+	}
+	testReadingCount(t, db, 5)
+
+	events, err := db.EventsForDevice("device1")
+	if err != nil {
+		t.Fatalf("Error getting events for device1: %v\n", err)
+	}
+	for _, e := range events {
+		if len(e.Readings) != 1 || e.Readings[0].Name != "humidity" {
+			t.Fatalf("Only the humidity reading should remain in %+v", e)
+		}
+	}
+
+	count, err = db.DeleteEvents(dbp.PurgeFilter{}, 10)
+	if err != nil {
+		t.Fatalf("Error deleting events: %v\n", err)
+	}
+	if count != 4 {
+		t.Fatalf("4 events should be deleted instead of %d", count)
+	}
+	testReadingCount(t, db, 0)
+}
+
+func testEventCountByDevice(t *testing.T, db interfaces.DBClient, device string, expected int) {
+	count, err := db.EventCountByDeviceId(device)
+	if err != nil {
+		t.Fatalf("Error getting events count: %v\n", err)
+	}
+	if count != expected {
+		t.Fatalf("Device %s should have %d events instead of %d", device, expected, count)
+	}
+}
+
+func testReadingCount(t *testing.T, db interfaces.DBClient, expected int) {
+	count, err := db.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting readings count: %v\n", err)
+	}
+	if count != expected {
+		t.Fatalf("There should be %d readings instead of %d", expected, count)
+	}
+}
+
 func testDBValueDescriptors(t *testing.T, db interfaces.DBClient) {
 	err := db.ScrubAllValueDescriptors()
 	if err != nil {
@@ -660,6 +1371,16 @@ func testDBValueDescriptors(t *testing.T, db interfaces.DBClient) {
 func TestDataDB(t *testing.T, db interfaces.DBClient) {
 	testDBReadings(t, db)
 	testDBEvents(t, db)
+	testDBEventsPage(t, db)
+	testDBReadingsPage(t, db)
+	testDBReadingsQuery(t, db)
+	testDBReadingsAggregate(t, db)
+	testDBAddEvents(t, db)
+	testDBOriginals(t, db)
+	testDBEventKeys(t, db)
+	testDBOriginTime(t, db)
+	testDBQuarantine(t, db)
+	testDBPurge(t, db)
 	testDBValueDescriptors(t, db)
 
 	db.CloseSession()
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/edgexfoundry/edgex-go/internal/export"
+	dbp "github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -101,8 +102,159 @@ func TestExportDB(t *testing.T, db export.DBClient) {
 		t.Fatalf("Update should return error")
 	}
 
+	testTemplates(t, db)
+	testFilters(t, db)
+	testCSVSettings(t, db)
+
 	db.CloseSession()
 	// Calling CloseSession twice to test that there is no panic when closing an
 	// already closed db
 	db.CloseSession()
 }
+
+func testTemplates(t *testing.T, db export.DBClient) {
+	if _, err := db.TemplateByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("Template should not be found: %v", err)
+	}
+	if err := db.DeleteTemplateByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("Template should not be deleted: %v", err)
+	}
+
+	for _, template := range []string{"{{.Device}}", "{{json .}}"} {
+		if err := db.UpdateTemplate("name", template); err != nil {
+			t.Fatalf("Error updating template %v", err)
+		}
+	}
+	if err := db.UpdateTemplate("name2", "{{.Origin}}"); err != nil {
+		t.Fatalf("Error updating template %v", err)
+	}
+
+	template, err := db.TemplateByName("name")
+	if err != nil {
+		t.Fatalf("Error getting template by name %v", err)
+	}
+	if template != "{{json .}}" {
+		t.Fatalf("Template was not replaced, found %s", template)
+	}
+	templates, err := db.Templates()
+	if err != nil {
+		t.Fatalf("Error getting templates %v", err)
+	}
+	if len(templates) != 2 || templates["name2"] != "{{.Origin}}" {
+		t.Fatalf("Expected 2 templates, found %v", templates)
+	}
+
+	if err = db.DeleteTemplateByName("name"); err != nil {
+		t.Fatalf("Template should be deleted: %v", err)
+	}
+	if _, err = db.TemplateByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("Template should not be found: %v", err)
+	}
+
+	if err = db.ScrubAllRegistrations(); err != nil {
+		t.Fatalf("Error scrubbing registrations %v", err)
+	}
+	if templates, _ = db.Templates(); len(templates) != 0 {
+		t.Fatalf("Templates should be scrubbed, found %v", templates)
+	}
+}
+
+func testFilters(t *testing.T, db export.DBClient) {
+	if _, err := db.FilterByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("Filter should not be found: %v", err)
+	}
+	if err := db.DeleteFilterByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("Filter should not be deleted: %v", err)
+	}
+
+	for _, expression := range []string{"value > 10", "value > 20"} {
+		if err := db.UpdateFilter("name", expression); err != nil {
+			t.Fatalf("Error updating filter %v", err)
+		}
+	}
+	if err := db.UpdateTemplate("name", "{{.Device}}"); err != nil {
+		t.Fatalf("Error updating template %v", err)
+	}
+
+	filter, err := db.FilterByName("name")
+	if err != nil {
+		t.Fatalf("Error getting filter by name %v", err)
+	}
+	if filter != "value > 20" {
+		t.Fatalf("Filter was not replaced, found %s", filter)
+	}
+	filters, err := db.Filters()
+	if err != nil {
+		t.Fatalf("Error getting filters %v", err)
+	}
+	if len(filters) != 1 {
+		t.Fatalf("Expected 1 filter, found %v", filters)
+	}
+
+	if err = db.DeleteFilterByName("name"); err != nil {
+		t.Fatalf("Filter should be deleted: %v", err)
+	}
+	if template, _ := db.TemplateByName("name"); template != "{{.Device}}" {
+		t.Fatalf("Template should be kept, found %s", template)
+	}
+
+	if err = db.UpdateFilter("name", "age < 1000"); err != nil {
+		t.Fatalf("Error updating filter %v", err)
+	}
+	if err = db.ScrubAllRegistrations(); err != nil {
+		t.Fatalf("Error scrubbing registrations %v", err)
+	}
+	if filters, _ = db.Filters(); len(filters) != 0 {
+		t.Fatalf("Filters should be scrubbed, found %v", filters)
+	}
+}
+
+func testCSVSettings(t *testing.T, db export.DBClient) {
+	if _, err := db.CSVSettingsByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("CSV settings should not be found: %v", err)
+	}
+	if err := db.DeleteCSVSettingsByName("name"); err != dbp.ErrNotFound {
+		t.Fatalf("CSV settings should not be deleted: %v", err)
+	}
+
+	for _, settings := range []string{`{"header":true}`, `{"delimiter":";"}`} {
+		if err := db.UpdateCSVSettings("name", settings); err != nil {
+			t.Fatalf("Error updating CSV settings %v", err)
+		}
+	}
+	if err := db.UpdateFilter("name", "value > 10"); err != nil {
+		t.Fatalf("Error updating filter %v", err)
+	}
+
+	settings, err := db.CSVSettingsByName("name")
+	if err != nil {
+		t.Fatalf("Error getting CSV settings by name %v", err)
+	}
+	if settings != `{"delimiter":";"}` {
+		t.Fatalf("CSV settings were not replaced, found %s", settings)
+	}
+	all, err := db.CSVSettings()
+	if err != nil {
+		t.Fatalf("Error getting CSV settings %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("Expected 1 CSV setting, found %v", all)
+	}
+
+	if err = db.DeleteCSVSettingsByName("name"); err != nil {
+		t.Fatalf("CSV settings should be deleted: %v", err)
+	}
+	if filter, _ := db.FilterByName("name"); filter != "value > 10" {
+		t.Fatalf("Filter should be kept, found %s", filter)
+	}
+
+	if err = db.UpdateCSVSettings("name", `{"quote":"all"}`); err != nil {
+		t.Fatalf("Error updating CSV settings %v", err)
+	}
+	if err = db.ScrubAllRegistrations(); err != nil {
+		t.Fatalf("Error scrubbing registrations %v", err)
+	}
+	if all, _ = db.CSVSettings(); len(all) != 0 {
+		t.Fatalf("CSV settings should be scrubbed, found %v", all)
+	}
+}
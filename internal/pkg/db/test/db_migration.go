@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2019 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"testing"
+
+	dbp "github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func TestMigrationDB(t *testing.T, db dbp.Migrator) {
+	migrations := db.Migrations()
+	if len(migrations) == 0 {
+		t.Fatalf("Expected migrations")
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	if err := db.SetSchemaVersion(dbp.BaseSchemaVersion); err != nil {
+		t.Fatalf("Error setting schema version: %v", err)
+	}
+
+	run, err := dbp.Migrate(db, 0, true)
+	if err != nil {
+		t.Fatalf("Error on dry run: %v", err)
+	}
+	if len(run) != len(migrations) {
+		t.Fatalf("Dry run should report %d migrations instead of %d", len(migrations), len(run))
+	}
+	checkSchemaVersion(t, db, dbp.BaseSchemaVersion)
+
+	if _, err = dbp.Migrate(db, 0, false); err != nil {
+		t.Fatalf("Error migrating: %v", err)
+	}
+	checkSchemaVersion(t, db, latest)
+
+	// Migrating again is a no-op
+	run, err = dbp.Migrate(db, 0, false)
+	if err != nil {
+		t.Fatalf("Error migrating: %v", err)
+	}
+	if len(run) != 0 {
+		t.Fatalf("No migration should run at the latest version, %d did", len(run))
+	}
+
+	// Rolling back stops at the last migration that cannot be undone, and is refused past it
+	oldest := latest
+	for i := len(migrations) - 1; i >= 0 && migrations[i].Down != nil; i-- {
+		oldest = dbp.BaseSchemaVersion
+		if i > 0 {
+			oldest = migrations[i-1].Version
+		}
+	}
+	if oldest > dbp.BaseSchemaVersion {
+		if _, err = dbp.Migrate(db, dbp.BaseSchemaVersion, false); err == nil {
+			t.Fatalf("Rolling back past version %d should be refused", oldest)
+		}
+		checkSchemaVersion(t, db, latest)
+	}
+
+	if _, err = dbp.Migrate(db, oldest, false); err != nil {
+		t.Fatalf("Error rolling back: %v", err)
+	}
+	checkSchemaVersion(t, db, oldest)
+
+	if _, err = dbp.Migrate(db, 0, false); err != nil {
+		t.Fatalf("Error migrating: %v", err)
+	}
+	checkSchemaVersion(t, db, latest)
+}
+
+func checkSchemaVersion(t *testing.T, db dbp.Migrator, expected int) {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("Error getting schema version: %v", err)
+	}
+	if version != expected {
+		t.Fatalf("Schema version should be %d instead of %d", expected, version)
+	}
+}
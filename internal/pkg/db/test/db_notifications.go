@@ -295,7 +295,7 @@ func testDBTransmission(t *testing.T, db interfaces.DBClient) {
 	}
 	afterTime := dbp.MakeTimestamp()
 
-	transmissions, err = db.GetTransmissionsByStartEnd(beforeTime, afterTime, resendCount)
+	transmissions, err = db.GetTransmissionsByStartEnd(beforeTime, afterTime, amount)
 	if err != nil {
 		t.Fatalf("Fail to get transmission by start time and end time, %v", err)
 	}
@@ -304,7 +304,7 @@ func testDBTransmission(t *testing.T, db interfaces.DBClient) {
 	}
 
 	// Test GetTransmissionsByStart
-	transmissions, err = db.GetTransmissionsByStart(beforeTime, resendCount)
+	transmissions, err = db.GetTransmissionsByStart(beforeTime, amount)
 	if err != nil {
 		t.Fatalf("Fail to get transmission by start time, %v", err)
 	}
@@ -312,8 +312,17 @@ func testDBTransmission(t *testing.T, db interfaces.DBClient) {
 		t.Fatalf("Unexpect result. The amount of transmissions should be %v, but actually is %v", amount, len(transmissions))
 	}
 
+	// The limit bounds the transmissions returned
+	transmissions, err = db.GetTransmissionsByStart(beforeTime, resendCount)
+	if err != nil {
+		t.Fatalf("Fail to get transmission by start time, %v", err)
+	}
+	if len(transmissions) != resendCount {
+		t.Fatalf("Unexpect result. The amount of transmissions should be %v, but actually is %v", resendCount, len(transmissions))
+	}
+
 	// Test GetTransmissionsByEnd
-	transmissions, err = db.GetTransmissionsByEnd(afterTime, resendCount)
+	transmissions, err = db.GetTransmissionsByEnd(afterTime, amount)
 	if err != nil {
 		t.Fatalf("Fail to get transmission by start time, %v", err)
 	}
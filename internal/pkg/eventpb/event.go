@@ -0,0 +1,257 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package eventpb encodes and decodes events with the protocol buffer schema in event.proto.
+// The wire format is written by hand as the schema is small and stable, which spares the
+// services a dependency on generated code.
+package eventpb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// SchemaVersion is the protobuf package of event.proto, bumped on incompatible changes
+const SchemaVersion = "edgex.v1"
+
+// Wire types of the protobuf encoding
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// ErrTruncated is returned when a message ends in the middle of a field
+var ErrTruncated = errors.New("eventpb: truncated message")
+
+// Marshal encodes an event, leaving out empty fields as proto3 does
+func Marshal(e contract.Event) []byte {
+	var b []byte
+	b = appendString(b, 1, e.ID)
+	b = appendInt(b, 2, e.Pushed)
+	b = appendString(b, 3, e.Device)
+	b = appendInt(b, 4, e.Created)
+	b = appendInt(b, 5, e.Modified)
+	b = appendInt(b, 6, e.Origin)
+	for _, r := range e.Readings {
+		b = appendMessage(b, 7, marshalReading(r))
+	}
+	return b
+}
+
+func marshalReading(r contract.Reading) []byte {
+	var b []byte
+	b = appendString(b, 1, r.Id)
+	b = appendInt(b, 2, r.Pushed)
+	b = appendInt(b, 3, r.Created)
+	b = appendInt(b, 4, r.Origin)
+	b = appendInt(b, 5, r.Modified)
+	b = appendString(b, 6, r.Device)
+	b = appendString(b, 7, r.Name)
+	b = appendString(b, 8, r.Value)
+	b = appendBytes(b, 9, r.BinaryValue)
+	return b
+}
+
+// Unmarshal decodes an event, skipping the fields unknown to this version of the schema
+func Unmarshal(data []byte, e *contract.Event) error {
+	return decodeFields(data, func(f field) error {
+		switch f.num {
+		case 1:
+			return f.string(&e.ID)
+		case 2:
+			return f.int(&e.Pushed)
+		case 3:
+			return f.string(&e.Device)
+		case 4:
+			return f.int(&e.Created)
+		case 5:
+			return f.int(&e.Modified)
+		case 6:
+			return f.int(&e.Origin)
+		case 7:
+			if f.wire != wireBytes {
+				return f.mismatch()
+			}
+			var r contract.Reading
+			if err := unmarshalReading(f.bytes, &r); err != nil {
+				return err
+			}
+			e.Readings = append(e.Readings, r)
+		}
+		return nil
+	})
+}
+
+func unmarshalReading(data []byte, r *contract.Reading) error {
+	return decodeFields(data, func(f field) error {
+		switch f.num {
+		case 1:
+			return f.string(&r.Id)
+		case 2:
+			return f.int(&r.Pushed)
+		case 3:
+			return f.int(&r.Created)
+		case 4:
+			return f.int(&r.Origin)
+		case 5:
+			return f.int(&r.Modified)
+		case 6:
+			return f.string(&r.Device)
+		case 7:
+			return f.string(&r.Name)
+		case 8:
+			return f.string(&r.Value)
+		case 9:
+			if f.wire != wireBytes {
+				return f.mismatch()
+			}
+			r.BinaryValue = append([]byte(nil), f.bytes...)
+		}
+		return nil
+	})
+}
+
+// AppendDelimited appends an encoded event preceded by its length, as in a stream of events
+func AppendDelimited(b []byte, e contract.Event) []byte {
+	m := Marshal(e)
+	b = appendVarint(b, uint64(len(m)))
+	return append(b, m...)
+}
+
+// SplitDelimited returns the encoded events of a stream of length-delimited events
+func SplitDelimited(data []byte) ([][]byte, error) {
+	var messages [][]byte
+	for len(data) > 0 {
+		n, k := binary.Uvarint(data)
+		if k <= 0 || uint64(len(data)-k) < n {
+			return nil, ErrTruncated
+		}
+		messages = append(messages, data[k:k+int(n)])
+		data = data[k+int(n):]
+	}
+	return messages, nil
+}
+
+// field is one decoded key and value of a message
+type field struct {
+	num    int
+	wire   int
+	varint uint64
+	bytes  []byte
+}
+
+func (f field) mismatch() error {
+	return fmt.Errorf("eventpb: field %d has unexpected wire type %d", f.num, f.wire)
+}
+
+func (f field) string(s *string) error {
+	if f.wire != wireBytes {
+		return f.mismatch()
+	}
+	*s = string(f.bytes)
+	return nil
+}
+
+func (f field) int(i *int64) error {
+	if f.wire != wireVarint {
+		return f.mismatch()
+	}
+	*i = int64(f.varint)
+	return nil
+}
+
+// Call handle on every field of a message in order
+func decodeFields(data []byte, handle func(field) error) error {
+	for len(data) > 0 {
+		key, k := binary.Uvarint(data)
+		if k <= 0 {
+			return ErrTruncated
+		}
+		data = data[k:]
+
+		f := field{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.varint, k = binary.Uvarint(data)
+			if k <= 0 {
+				return ErrTruncated
+			}
+		case wireFixed64:
+			k = 8
+		case wireBytes:
+			n, l := binary.Uvarint(data)
+			if l <= 0 || uint64(len(data)-l) < n {
+				return ErrTruncated
+			}
+			f.bytes = data[l : l+int(n)]
+			k = l + int(n)
+		case wireFixed32:
+			k = 4
+		default:
+			return fmt.Errorf("eventpb: unsupported wire type %d", f.wire)
+		}
+		if len(data) < k {
+			return ErrTruncated
+		}
+		data = data[k:]
+
+		if err := handle(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendKey(b []byte, num int, wire int) []byte {
+	return appendVarint(b, uint64(num)<<3|uint64(wire))
+}
+
+func appendInt(b []byte, num int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendKey(b, num, wireVarint)
+	return appendVarint(b, uint64(v))
+}
+
+func appendBytes(b []byte, num int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendKey(b, num, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// Embedded messages are kept even when empty, unlike other fields
+func appendMessage(b []byte, num int, m []byte) []byte {
+	b = appendKey(b, num, wireBytes)
+	b = appendVarint(b, uint64(len(m)))
+	return append(b, m...)
+}
+
+func appendString(b []byte, num int, v string) []byte {
+	return appendBytes(b, num, []byte(v))
+}
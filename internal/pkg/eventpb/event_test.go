@@ -0,0 +1,107 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package eventpb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+var testEvent = contract.Event{
+	ID:       "3c5badcb-2008-47f2-ba78-eb2d992f8422",
+	Device:   "thermostat",
+	Created:  1553247000,
+	Modified: 1573900200,
+	Origin:   -9,
+	Readings: []contract.Reading{
+		{Name: "temperature", Value: "45", Origin: 9},
+		{},
+		{Name: "image", BinaryValue: []byte{0, 1, 2}},
+	},
+}
+
+func TestRoundTrip(t *testing.T) {
+	var e contract.Event
+	if err := Unmarshal(Marshal(testEvent), &e); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(e, testEvent) {
+		t.Errorf("Round trip = %+v, want %+v", e, testEvent)
+	}
+}
+
+func TestMarshalWireFormat(t *testing.T) {
+	// device = "d" (field 3), origin = 1 (field 6), one empty reading (field 7)
+	expected := []byte{0x1a, 0x01, 'd', 0x30, 0x01, 0x3a, 0x00}
+
+	b := Marshal(contract.Event{Device: "d", Origin: 1, Readings: []contract.Reading{{}}})
+	if !bytes.Equal(b, expected) {
+		t.Errorf("Marshal = %x, want %x", b, expected)
+	}
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	// A varint field 20, a fixed64 field 21 and a string field 22 around device = "d"
+	data := []byte{0xa0, 0x01, 0x05, 0xa9, 0x01, 1, 2, 3, 4, 5, 6, 7, 8, 0x1a, 0x01, 'd', 0xb2, 0x01, 0x02, 'x', 'y'}
+
+	var e contract.Event
+	if err := Unmarshal(data, &e); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if e.Device != "d" {
+		t.Errorf("Expected device d, got %+v", e)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated key", []byte{0x80}},
+		{"truncated string", []byte{0x1a, 0x05, 'd'}},
+		{"truncated fixed64", []byte{0xa9, 0x01, 1, 2}},
+		{"wrong wire type", []byte{0x18, 0x01}},
+		{"group", []byte{0x1b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e contract.Event
+			if err := Unmarshal(tt.data, &e); err == nil {
+				t.Errorf("Expected an error decoding %x", tt.data)
+			}
+		})
+	}
+}
+
+func TestDelimited(t *testing.T) {
+	second := contract.Event{Device: "other"}
+	stream := AppendDelimited(AppendDelimited(nil, testEvent), second)
+
+	messages, err := SplitDelimited(stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 2 || !bytes.Equal(messages[1], Marshal(second)) {
+		t.Fatalf("Unexpected messages %x", messages)
+	}
+
+	if _, err = SplitDelimited(stream[:len(stream)-1]); err != ErrTruncated {
+		t.Errorf("Expected a truncated stream, got %v", err)
+	}
+}
@@ -0,0 +1,645 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package expression evaluates arithmetic and boolean expressions over named values, such as
+// 'voltage * current' or 'temperature > 30 && humidity < 80'.
+//
+// Values are numbers (float64), strings and booleans. The operators are, by increasing precedence,
+// ||, &&, == and !=, < <= > >=, + and -, * / and %, and the unary ! and -. Strings are quoted
+// with ' or ", and the functions abs, ceil, exp, floor, log, max, min, pow, round and sqrt
+// take numbers. matches(s, pattern) tells whether a value matches a regular expression, and
+// contains(s, item) whether a string holds another or a list of strings ([]string) an item.
+package expression
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Variables looks up the value of a name, telling whether it has one
+type Variables func(name string) (interface{}, bool)
+
+// Expression is a parsed expression, safe for concurrent evaluation
+type Expression struct {
+	source    string
+	root      node
+	variables []string
+}
+
+// ErrSyntax is returned by Parse for an invalid expression
+type ErrSyntax struct {
+	Position int
+	Message  string
+}
+
+func (e ErrSyntax) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// ErrUnknownVariable is returned by Eval for a name without a value
+type ErrUnknownVariable struct {
+	Name string
+}
+
+func (e ErrUnknownVariable) Error() string {
+	return fmt.Sprintf("no value for '%s'", e.Name)
+}
+
+// Parse an expression
+func Parse(source string) (*Expression, error) {
+	p := &parser{lexer: lexer{source: source}}
+	p.next()
+	root := p.parseOr()
+	if p.err == nil && p.token.kind != tokenEnd {
+		p.fail(fmt.Sprintf("unexpected '%s'", p.token.text))
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Expression{source: source, root: root, variables: p.variables}, nil
+}
+
+func (x *Expression) String() string {
+	return x.source
+}
+
+// Variables returns the names the expression refers to, in order of appearance
+func (x *Expression) Variables() []string {
+	return x.variables
+}
+
+// Eval evaluates the expression, returning a float64, a string or a bool
+func (x *Expression) Eval(vars Variables) (interface{}, error) {
+	return x.root.eval(vars)
+}
+
+// Float evaluates an expression expected to give a finite number
+func (x *Expression) Float(vars Variables) (float64, error) {
+	v, err := x.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("'%s' is not a number", x.source)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("'%s' is not a finite number", x.source)
+	}
+	return f, nil
+}
+
+// Bool evaluates an expression expected to give a boolean
+func (x *Expression) Bool(vars Variables) (bool, error) {
+	v, err := x.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("'%s' is not a condition", x.source)
+	}
+	return b, nil
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+type lexer struct {
+	source   string
+	position int
+}
+
+// Operators of two characters are listed first so that they are matched before their prefixes
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ","}
+
+func (l *lexer) next() (token, error) {
+	for l.position < len(l.source) && strings.IndexByte(" \t\r\n", l.source[l.position]) >= 0 {
+		l.position++
+	}
+	start := l.position
+	if start == len(l.source) {
+		return token{kind: tokenEnd, position: start}, nil
+	}
+
+	c := l.source[start]
+	switch {
+	case isDigit(c) || (c == '.' && start+1 < len(l.source) && isDigit(l.source[start+1])):
+		for l.position < len(l.source) && (isDigit(l.source[l.position]) || l.source[l.position] == '.') {
+			l.position++
+		}
+		if l.position < len(l.source) && (l.source[l.position] == 'e' || l.source[l.position] == 'E') {
+			l.position++
+			if l.position < len(l.source) && (l.source[l.position] == '+' || l.source[l.position] == '-') {
+				l.position++
+			}
+			for l.position < len(l.source) && isDigit(l.source[l.position]) {
+				l.position++
+			}
+		}
+		return token{kind: tokenNumber, text: l.source[start:l.position], position: start}, nil
+	case isLetter(c):
+		for l.position < len(l.source) && (isLetter(l.source[l.position]) || isDigit(l.source[l.position])) {
+			l.position++
+		}
+		return token{kind: tokenIdent, text: l.source[start:l.position], position: start}, nil
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(l.source[start+1:], c)
+		if end < 0 {
+			return token{}, ErrSyntax{Position: start, Message: "unterminated string"}
+		}
+		l.position = start + end + 2
+		return token{kind: tokenString, text: l.source[start+1 : start+1+end], position: start}, nil
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.source[start:], op) {
+			l.position += len(op)
+			return token{kind: tokenOperator, text: op, position: start}, nil
+		}
+	}
+	return token{}, ErrSyntax{Position: start, Message: fmt.Sprintf("unexpected '%c'", c)}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Parser, by recursive descent. The first error stops the parse, later calls returning nil nodes.
+
+type parser struct {
+	lexer     lexer
+	token     token
+	err       error
+	variables []string
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	t, err := p.lexer.next()
+	if err != nil {
+		p.err = err
+		t = token{kind: tokenEnd}
+	}
+	p.token = t
+}
+
+func (p *parser) fail(message string) {
+	if p.err == nil {
+		p.err = ErrSyntax{Position: p.token.position, Message: message}
+	}
+	p.token = token{kind: tokenEnd}
+}
+
+// Consume the operator op if it is next
+func (p *parser) accept(op string) bool {
+	if p.token.kind == tokenOperator && p.token.text == op {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// Parse a left associative sequence of the given operators
+func (p *parser) parseBinary(operand func() node, ops ...string) node {
+	left := operand()
+	for p.token.kind == tokenOperator {
+		op := p.token.text
+		found := false
+		for _, o := range ops {
+			found = found || o == op
+		}
+		if !found {
+			break
+		}
+		p.next()
+		left = binary{op: op, left: left, right: operand()}
+	}
+	return left
+}
+
+func (p *parser) parseOr() node {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() node {
+	return p.parseBinary(p.parseEquality, "&&")
+}
+
+func (p *parser) parseEquality() node {
+	return p.parseBinary(p.parseRelational, "==", "!=")
+}
+
+func (p *parser) parseRelational() node {
+	return p.parseBinary(p.parseAdditive, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseAdditive() node {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() node {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() node {
+	if p.accept("-") {
+		return unary{op: "-", operand: p.parseUnary()}
+	}
+	if p.accept("!") {
+		return unary{op: "!", operand: p.parseUnary()}
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() node {
+	t := p.token
+	switch t.kind {
+	case tokenNumber:
+		p.next()
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			p.token = t
+			p.fail(fmt.Sprintf("invalid number '%s'", t.text))
+			return nil
+		}
+		return literal{value: f}
+	case tokenString:
+		p.next()
+		return literal{value: t.text}
+	case tokenIdent:
+		p.next()
+		switch {
+		case t.text == "true":
+			return literal{value: true}
+		case t.text == "false":
+			return literal{value: false}
+		case p.accept("("):
+			return p.parseCall(t)
+		}
+		p.addVariable(t.text)
+		return variable{name: t.text}
+	case tokenOperator:
+		if p.accept("(") {
+			n := p.parseOr()
+			if !p.accept(")") {
+				p.fail("expected ')'")
+			}
+			return n
+		}
+		p.fail(fmt.Sprintf("unexpected '%s'", t.text))
+	default:
+		p.fail("unexpected end of expression")
+	}
+	return nil
+}
+
+// Parse the arguments of a function call, its opening parenthesis being consumed
+func (p *parser) parseCall(name token) node {
+	f, ok := functions[name.text]
+	if !ok {
+		p.token = name
+		p.fail(fmt.Sprintf("unknown function '%s'", name.text))
+		return nil
+	}
+
+	var args []node
+	if !p.accept(")") {
+		for {
+			args = append(args, p.parseOr())
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				p.fail("expected ',' or ')'")
+				return nil
+			}
+		}
+	}
+
+	if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+		p.token = name
+		p.fail(fmt.Sprintf("wrong number of arguments for '%s'", name.text))
+		return nil
+	}
+	// A pattern written in the expression is checked along with it and compiled once
+	if l, ok := args[len(args)-1].(literal); ok && name.text == "matches" {
+		re, err := regexp.Compile(format(l.value))
+		if err != nil {
+			p.token = name
+			p.fail(fmt.Sprintf("invalid pattern: %s", err.Error()))
+			return nil
+		}
+		args[len(args)-1] = pattern{re}
+	}
+	return call{name: name.text, f: f, args: args}
+}
+
+func (p *parser) addVariable(name string) {
+	for _, v := range p.variables {
+		if v == name {
+			return
+		}
+	}
+	p.variables = append(p.variables, name)
+}
+
+// Evaluation
+
+type node interface {
+	eval(vars Variables) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (n literal) eval(Variables) (interface{}, error) {
+	return n.value, nil
+}
+
+// A pattern written in the expression, compiled by the parser
+type pattern struct {
+	re *regexp.Regexp
+}
+
+func (n pattern) eval(Variables) (interface{}, error) {
+	return n.re, nil
+}
+
+type variable struct {
+	name string
+}
+
+func (n variable) eval(vars Variables) (interface{}, error) {
+	if vars != nil {
+		if v, ok := vars(n.name); ok {
+			return v, nil
+		}
+	}
+	return nil, ErrUnknownVariable{Name: n.name}
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (n unary) eval(vars Variables) (interface{}, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'!' expects a condition, got %v", v)
+		}
+		return !b, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("'-' expects a number, got %v", v)
+	}
+	return -f, nil
+}
+
+type binary struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n binary) eval(vars Variables) (interface{}, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	// Conditions short-circuit
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'%s' expects conditions, got %v", n.op, l)
+		}
+		if lb == (n.op == "||") {
+			return lb, nil
+		}
+		r, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'%s' expects conditions, got %v", n.op, r)
+		}
+		return rb, nil
+	}
+
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	}
+
+	lf, lok := l.(float64)
+	rf, rok := r.(float64)
+	if !lok || !rok {
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if lok && rok {
+			switch n.op {
+			case "<":
+				return ls < rs, nil
+			case "<=":
+				return ls <= rs, nil
+			case ">":
+				return ls > rs, nil
+			case ">=":
+				return ls >= rs, nil
+			}
+		}
+		return nil, fmt.Errorf("'%s' expects numbers, got %v and %v", n.op, l, r)
+	}
+
+	switch n.op {
+	case "<":
+		return lf < rf, nil
+	case "<=":
+		return lf <= rf, nil
+	case ">":
+		return lf > rf, nil
+	case ">=":
+		return lf >= rf, nil
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	default: // %
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(lf, rf), nil
+	}
+}
+
+// Values of different types are equal when they read the same, so that the string "1" equals 1
+func equal(l, r interface{}) bool {
+	if l == r {
+		return true
+	}
+	return format(l) == format(r)
+}
+
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type function struct {
+	minArgs int
+	maxArgs int // -1 for any number
+	apply   func(args []float64) float64
+	// Takes the place of apply for the functions taking values other than numbers
+	applyValues func(args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"abs":   {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }, nil},
+	"ceil":  {1, 1, func(a []float64) float64 { return math.Ceil(a[0]) }, nil},
+	"exp":   {1, 1, func(a []float64) float64 { return math.Exp(a[0]) }, nil},
+	"floor": {1, 1, func(a []float64) float64 { return math.Floor(a[0]) }, nil},
+	"log":   {1, 1, func(a []float64) float64 { return math.Log(a[0]) }, nil},
+	"max":   {1, -1, func(a []float64) float64 { return fold(a, math.Max) }, nil},
+	"min":   {1, -1, func(a []float64) float64 { return fold(a, math.Min) }, nil},
+	"pow":   {2, 2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }, nil},
+	"round": {1, 1, func(a []float64) float64 { return math.Floor(a[0] + 0.5) }, nil},
+	"sqrt":  {1, 1, func(a []float64) float64 { return math.Sqrt(a[0]) }, nil},
+
+	"matches":  {2, 2, nil, matches},
+	"contains": {2, 2, nil, contains},
+}
+
+// The pattern is compiled by the parser when it is written in the expression, and for each call
+// when it comes from a variable, so that no value is kept
+func matches(args []interface{}) (interface{}, error) {
+	re, ok := args[1].(*regexp.Regexp)
+	if !ok {
+		var err error
+		if re, err = regexp.Compile(format(args[1])); err != nil {
+			return nil, err
+		}
+	}
+	return re.MatchString(format(args[0])), nil
+}
+
+func contains(args []interface{}) (interface{}, error) {
+	item := format(args[1])
+	if list, ok := args[0].([]string); ok {
+		for _, s := range list {
+			if s == item {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return strings.Contains(format(args[0]), item), nil
+}
+
+func fold(values []float64, f func(float64, float64) float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = f(result, v)
+	}
+	return result
+}
+
+type call struct {
+	name string
+	f    function
+	args []node
+}
+
+func (n call) eval(vars Variables) (interface{}, error) {
+	if n.f.applyValues != nil {
+		values := make([]interface{}, len(n.args))
+		for i, a := range n.args {
+			v, err := a.eval(vars)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return n.f.applyValues(values)
+	}
+
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("'%s' expects numbers, got %v", n.name, v)
+		}
+		args[i] = f
+	}
+	return n.f.apply(args), nil
+}
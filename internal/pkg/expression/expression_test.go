@@ -0,0 +1,140 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package expression
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testVariables = Variables(func(name string) (interface{}, bool) {
+	v, ok := map[string]interface{}{
+		"voltage": 230.0,
+		"current": 2.5,
+		"state":   "on",
+		"count":   "3",
+		"enabled": true,
+		"labels":  []string{"temperature", "alarm"},
+	}[name]
+	return v, ok
+})
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected interface{}
+	}{
+		{"voltage * current", 575.0},
+		{"1 + 2 * 3 - 4 / 2", 5.0},
+		{"(1 + 2) * 3", 9.0},
+		{"-voltage + 230", 0.0},
+		{"7 % 4", 3.0},
+		{"1.5e2", 150.0},
+		{".5", 0.5},
+		{"voltage > 200 && current < 3", true},
+		{"voltage < 200 || !enabled", false},
+		{"state == 'on'", true},
+		{`state != "off"`, true},
+		{"count == 3", true},
+		{"'abc' < 'abd'", true},
+		{"max(1, voltage, current)", 230.0},
+		{"min(4, 2)", 2.0},
+		{"round(current)", 3.0},
+		{"sqrt(pow(3, 2) + pow(4, 2))", 5.0},
+		{"abs(-2)", 2.0},
+		{"matches(state, '^o[nf]+$')", true},
+		{"matches(voltage, '^2..$') && !matches(state, 'off')", true},
+		{"matches('turned on', state)", true},
+		{"contains(labels, 'alarm')", true},
+		{"contains(labels, 'alar')", false},
+		{"contains(state, 'n')", true},
+		// The right side is not evaluated once the left one decides
+		{"false && unknown > 1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			x, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			v, err := x.Eval(testVariables)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if v != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{"", "1 +", "(1", "1 2", "unknown(1)", "pow(1)", "'abc", "1 # 2", "max(1,)", "matches(state, '(')", "contains(state)"}
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			if _, err := Parse(source); err == nil {
+				t.Errorf("Expected an error")
+			} else if _, ok := err.(ErrSyntax); !ok {
+				t.Errorf("Expected a syntax error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEvalInvalid(t *testing.T) {
+	tests := []string{"unknown + 1", "state * 2", "1 / 0", "!voltage", "voltage && true", "sqrt(state)"}
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			x, err := Parse(source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err = x.Eval(testVariables); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+
+	x, _ := Parse("unknown")
+	if _, err := x.Eval(testVariables); err != (ErrUnknownVariable{Name: "unknown"}) {
+		t.Errorf("Expected an unknown variable, got %v", err)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	x, err := Parse("voltage * current + max(voltage, limit)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"voltage", "current", "limit"}
+	if !reflect.DeepEqual(x.Variables(), expected) {
+		t.Errorf("Expected %v, got %v", expected, x.Variables())
+	}
+}
+
+func TestFloatAndBool(t *testing.T) {
+	x, _ := Parse("voltage > 1")
+	if _, err := x.Float(testVariables); err == nil {
+		t.Errorf("Expected an error evaluating a condition as a number")
+	}
+	if b, err := x.Bool(testVariables); err != nil || !b {
+		t.Errorf("Expected true, got %v %v", b, err)
+	}
+
+	x, _ = Parse("log(0)")
+	if _, err := x.Float(testVariables); err == nil {
+		t.Errorf("Expected an error for an infinite result")
+	}
+}
@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package readiness reports whether a service can do its work, as opposed to ping which only
+// tells that it runs, and refuses the requests a service cannot serve while it is not ready.
+package readiness
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+)
+
+// Route of the readiness endpoint of the services
+const Route = clients.ApiBase + "/ready"
+
+// Seconds clients are told to wait before retrying a refused request
+const retryAfter = 5
+
+// Check is a dependency of a service, such as its database or message bus
+type Check interface {
+	Ready() bool
+	// Details reported on the readiness endpoint
+	Status() interface{}
+}
+
+// Report of the readiness endpoint
+type Report struct {
+	Ready  bool                   `json:"ready"`
+	Checks map[string]CheckReport `json:"checks"`
+}
+
+type CheckReport struct {
+	Ready  bool        `json:"ready"`
+	Status interface{} `json:"status,omitempty"`
+}
+
+// Handler reports the checks, answering 503 Service Unavailable unless all of them are ready
+func Handler(checks map[string]Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		report := Report{Ready: true, Checks: map[string]CheckReport{}}
+		for name, check := range checks {
+			ready := check.Ready()
+			report.Checks[name] = CheckReport{Ready: ready, Status: check.Status()}
+			report.Ready = report.Ready && ready
+		}
+
+		w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// Guard is a middleware failing the requests fast with 503 Service Unavailable while the check is
+// not ready, rather than letting each of them fail on its own. The endpoints reporting on the
+// service itself stay available.
+func Guard(check Check) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt(r.URL.Path) || check.Ready() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "service unavailable, a dependency is down", http.StatusServiceUnavailable)
+		})
+	}
+}
+
+func exempt(path string) bool {
+	switch path {
+	case clients.ApiPingRoute, clients.ApiConfigRoute, clients.ApiMetricsRoute, Route:
+		return true
+	}
+	return false
+}
+
+// Connection tracks a dependency the service has no way to check on demand, from the outcome of
+// its use. It is ready once connected, until an error is recorded, and again after a success.
+type Connection struct {
+	mutex     sync.RWMutex
+	connected bool
+	err       string
+}
+
+type connectionStatus struct {
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Record the outcome of connecting to or using the dependency
+func (c *Connection) Record(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.connected = err == nil
+	c.err = ""
+	if err != nil {
+		c.err = err.Error()
+	}
+}
+
+func (c *Connection) Ready() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.connected
+}
+
+func (c *Connection) Status() interface{} {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return connectionStatus{Connected: c.connected, Error: c.err}
+}
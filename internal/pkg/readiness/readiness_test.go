@@ -0,0 +1,121 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package readiness
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+)
+
+type testCheck bool
+
+func (c testCheck) Ready() bool {
+	return bool(c)
+}
+
+func (c testCheck) Status() interface{} {
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         map[string]Check
+		expectedStatus int
+	}{
+		{"No checks", nil, http.StatusOK},
+		{"All ready", map[string]Check{"database": testCheck(true), "messageBus": testCheck(true)}, http.StatusOK},
+		{"One not ready", map[string]Check{"database": testCheck(true), "messageBus": testCheck(false)}, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			Handler(tt.checks)(rr, httptest.NewRequest(http.MethodGet, Route, nil))
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, found %d", tt.expectedStatus, rr.Code)
+			}
+
+			var report Report
+			if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+				t.Fatalf("Could not decode the report: %v", err)
+			}
+			if report.Ready != (tt.expectedStatus == http.StatusOK) || len(report.Checks) != len(tt.checks) {
+				t.Errorf("unexpected report %+v", report)
+			}
+			for name, check := range tt.checks {
+				if report.Checks[name].Ready != check.Ready() {
+					t.Errorf("%s: expected ready %v, found %+v", name, check.Ready(), report.Checks[name])
+				}
+			}
+		})
+	}
+}
+
+func TestGuard(t *testing.T) {
+	tests := []struct {
+		name           string
+		ready          bool
+		path           string
+		expectedStatus int
+	}{
+		{"Ready", true, clients.ApiEventRoute, http.StatusOK},
+		{"Not ready", false, clients.ApiEventRoute, http.StatusServiceUnavailable},
+		{"Ping while not ready", false, clients.ApiPingRoute, http.StatusOK},
+		{"Metrics while not ready", false, clients.ApiMetricsRoute, http.StatusOK},
+		{"Readiness while not ready", false, Route, http.StatusOK},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			Guard(testCheck(tt.ready))(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, found %d", tt.expectedStatus, rr.Code)
+			}
+			if refused := rr.Header().Get("Retry-After") != ""; refused != (tt.expectedStatus != http.StatusOK) {
+				t.Errorf("unexpected Retry-After header %q", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestConnection(t *testing.T) {
+	var c Connection
+	if c.Ready() {
+		t.Error("expected a connection to be ready only once connected")
+	}
+
+	c.Record(nil)
+	if !c.Ready() {
+		t.Error("expected the connection to be ready once connected")
+	}
+
+	c.Record(errors.New("connection refused"))
+	if status := c.Status().(connectionStatus); c.Ready() || status.Connected || status.Error != "connection refused" {
+		t.Errorf("expected the connection not to be ready after an error, found %+v", status)
+	}
+
+	c.Record(nil)
+	if status := c.Status().(connectionStatus); !c.Ready() || status.Error != "" {
+		t.Errorf("expected the connection to be ready again after a success, found %+v", status)
+	}
+}
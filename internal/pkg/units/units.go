@@ -0,0 +1,165 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package units converts values between the units of measure found in value descriptors
+// and device profiles. Units are looked up by label, e.g. 'degF', '°F' or 'fahrenheit'.
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unit converts values of one dimension to and from its base unit,
+// a value v of the unit being v*Scale+Offset in the base unit
+type Unit struct {
+	Dimension string
+	Scale     float64
+	Offset    float64
+}
+
+// ErrUnknownUnit is returned for a label that is not in the registry
+type ErrUnknownUnit struct {
+	Label string
+}
+
+func (e ErrUnknownUnit) Error() string {
+	return fmt.Sprintf("unknown unit '%s'", e.Label)
+}
+
+// ErrIncompatibleUnits is returned when converting between units of different dimensions
+type ErrIncompatibleUnits struct {
+	From string
+	To   string
+}
+
+func (e ErrIncompatibleUnits) Error() string {
+	return fmt.Sprintf("cannot convert '%s' to '%s'", e.From, e.To)
+}
+
+// Units by label. Labels are matched as is first, since case tells 'mW' from 'MW',
+// and then in lower case so that names may be written either way.
+var registry = make(map[string]Unit)
+
+func init() {
+	define("temperature", 1, 0, "K", "kelvin")
+	define("temperature", 1, 273.15, "C", "°C", "degC", "degrees C", "celsius", "degrees celsius")
+	define("temperature", 5.0/9, 273.15-32*5.0/9, "F", "°F", "degF", "degrees F", "fahrenheit", "degrees fahrenheit")
+
+	define("pressure", 1, 0, "Pa", "pascal")
+	define("pressure", 100, 0, "hPa", "mbar")
+	define("pressure", 1e3, 0, "kPa")
+	define("pressure", 1e6, 0, "MPa")
+	define("pressure", 1e5, 0, "bar")
+	define("pressure", 6894.757293168, 0, "psi")
+	define("pressure", 101325, 0, "atm")
+	define("pressure", 133.322387415, 0, "mmHg")
+	define("pressure", 3386.389, 0, "inHg")
+
+	define("length", 1, 0, "m", "meter", "metre")
+	define("length", 1e-3, 0, "mm")
+	define("length", 1e-2, 0, "cm")
+	define("length", 1e3, 0, "km")
+	define("length", 0.0254, 0, "in", "inch")
+	define("length", 0.3048, 0, "ft", "foot", "feet")
+	define("length", 0.9144, 0, "yd", "yard")
+	define("length", 1609.344, 0, "mi", "mile")
+
+	define("mass", 1, 0, "kg", "kilogram")
+	define("mass", 1e-3, 0, "g", "gram")
+	define("mass", 1e-6, 0, "mg")
+	define("mass", 0.45359237, 0, "lb", "lbs", "pound")
+	define("mass", 0.028349523125, 0, "oz", "ounce")
+
+	define("time", 1, 0, "s", "sec", "second", "seconds")
+	define("time", 1e-3, 0, "ms", "millisecond", "milliseconds")
+	define("time", 1e-6, 0, "us", "µs", "microsecond", "microseconds")
+	define("time", 60, 0, "min", "minute", "minutes")
+	define("time", 3600, 0, "h", "hr", "hour", "hours")
+
+	define("speed", 1, 0, "m/s")
+	define("speed", 1/3.6, 0, "km/h", "kph")
+	define("speed", 0.44704, 0, "mph")
+	define("speed", 1852/3600.0, 0, "kn", "knot", "knots")
+
+	define("volume", 1, 0, "m3", "m³")
+	define("volume", 1e-3, 0, "L", "l", "liter", "litre")
+	define("volume", 1e-6, 0, "mL", "ml")
+	define("volume", 0.003785411784, 0, "gal", "gallon")
+
+	define("energy", 1, 0, "J", "joule")
+	define("energy", 1e3, 0, "kJ")
+	define("energy", 3600, 0, "Wh")
+	define("energy", 3.6e6, 0, "kWh")
+
+	define("power", 1, 0, "W", "watt")
+	define("power", 1e-3, 0, "mW")
+	define("power", 1e3, 0, "kW")
+	define("power", 1e6, 0, "MW")
+	define("power", 745.69987158227022, 0, "hp")
+
+	define("voltage", 1, 0, "V", "volt")
+	define("voltage", 1e-3, 0, "mV")
+	define("voltage", 1e3, 0, "kV")
+
+	define("current", 1, 0, "A", "amp", "ampere")
+	define("current", 1e-3, 0, "mA")
+
+	define("frequency", 1, 0, "Hz", "hertz")
+	define("frequency", 1e3, 0, "kHz")
+	define("frequency", 1e6, 0, "MHz")
+	define("frequency", 1/60.0, 0, "rpm", "RPM")
+
+	define("ratio", 1, 0, "fraction")
+	define("ratio", 1e-2, 0, "%", "percent")
+	define("ratio", 1e-6, 0, "ppm")
+}
+
+func define(dimension string, scale float64, offset float64, labels ...string) {
+	for _, label := range labels {
+		registry[label] = Unit{Dimension: dimension, Scale: scale, Offset: offset}
+	}
+}
+
+// Lookup returns the unit a label stands for
+func Lookup(label string) (Unit, error) {
+	label = strings.TrimSpace(label)
+	if u, ok := registry[label]; ok {
+		return u, nil
+	}
+	if u, ok := registry[strings.ToLower(label)]; ok {
+		return u, nil
+	}
+	return Unit{}, ErrUnknownUnit{Label: label}
+}
+
+// Convert converts a value from one unit to another of the same dimension
+func Convert(value float64, from string, to string) (float64, error) {
+	f, err := Lookup(from)
+	if err != nil {
+		return 0, err
+	}
+	t, err := Lookup(to)
+	if err != nil {
+		return 0, err
+	}
+	if f.Dimension != t.Dimension {
+		return 0, ErrIncompatibleUnits{From: from, To: to}
+	}
+
+	if f == t {
+		return value, nil
+	}
+	return (value*f.Scale + f.Offset - t.Offset) / t.Scale, nil
+}
@@ -0,0 +1,70 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from     string
+		to       string
+		expected float64
+	}{
+		{212, "degF", "C", 100},
+		{-40, "°C", "F", -40},
+		{0, "celsius", "K", 273.15},
+		{14.5, "psi", "kPa", 99.97398},
+		{1, "atm", "hPa", 1013.25},
+		{12, "in", "ft", 1},
+		{100, "km/h", "m/s", 27.77778},
+		{1500, "mW", "W", 1.5},
+		{50, "%", "ppm", 500000},
+		{3, "Fahrenheit", "fahrenheit", 3},
+	}
+	for _, tt := range tests {
+		v, err := Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%v %s to %s: unexpected error %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(v-tt.expected) > 1e-5 {
+			t.Errorf("%v %s to %s: expected %v, got %v", tt.value, tt.from, tt.to, tt.expected, v)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	if _, err := Convert(1, "psi", "C"); err != (ErrIncompatibleUnits{From: "psi", To: "C"}) {
+		t.Errorf("Expected incompatible units, got %v", err)
+	}
+	if _, err := Convert(1, "furlong", "m"); err != (ErrUnknownUnit{Label: "furlong"}) {
+		t.Errorf("Expected an unknown unit, got %v", err)
+	}
+}
+
+func TestLookupCase(t *testing.T) {
+	milli, _ := Lookup("mW")
+	mega, _ := Lookup("MW")
+	if milli == mega {
+		t.Errorf("mW and MW should be different units")
+	}
+	if _, err := Lookup(" KELVIN "); err != nil {
+		t.Errorf("Names should be matched in any case: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+//
+// Copyright (c) 2019 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package logging
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"go.etcd.io/bbolt"
+)
+
+type boltLog struct {
+	db *bbolt.DB // Embedded database file
+}
+
+// Open the file named after the primary database in the directory given as its host
+func openBolt() (*bbolt.DB, error) {
+	dir := Configuration.Databases["Primary"].Host
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, Configuration.Databases["Primary"].Name+".db")
+	options := &bbolt.Options{Timeout: time.Duration(Configuration.Databases["Primary"].Timeout) * time.Millisecond}
+	bdb, err := bbolt.Open(path, 0600, options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bdb.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(db.LogsCollection))
+		return err
+	})
+	if err != nil {
+		bdb.Close()
+		return nil, err
+	}
+	return bdb, nil
+}
+
+func (bl *boltLog) closeSession() {
+	if bl.db != nil {
+		bl.db.Close()
+		bl.db = nil
+	}
+}
+
+func (bl *boltLog) Ping() error {
+	if bl.db == nil {
+		return bbolt.ErrDatabaseNotOpen
+	}
+	return bl.db.View(func(*bbolt.Tx) error { return nil })
+}
+
+// Entries are keyed by a sequence so that they are iterated in the order they were added
+func (bl *boltLog) add(le models.LogEntry) error {
+	res, err := json.Marshal(le)
+	if err != nil {
+		return err
+	}
+
+	return bl.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(db.LogsCollection))
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return b.Put(key, res)
+	})
+}
+
+func (bl *boltLog) remove(criteria matchCriteria) (int, error) {
+	count := 0
+	err := bl.db.Update(func(tx *bbolt.Tx) error {
+		c := tx.Bucket([]byte(db.LogsCollection)).Cursor()
+		for k, v := c.First(); k != nil; {
+			var le models.LogEntry
+			if err := json.Unmarshal(v, &le); err != nil || !criteria.match(le) {
+				k, v = c.Next()
+				continue
+			}
+
+			// Deleting moves the cursor, so look up the entry following the deleted one
+			key := append([]byte(nil), k...)
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			count += 1
+			k, v = c.Seek(key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (bl *boltLog) find(criteria matchCriteria) ([]models.LogEntry, error) {
+	logs := []models.LogEntry{}
+	err := bl.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket([]byte(db.LogsCollection)).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var le models.LogEntry
+			if err := json.Unmarshal(v, &le); err != nil || !criteria.match(le) {
+				continue
+			}
+
+			logs = append(logs, le)
+			if criteria.Limit != 0 && len(logs) >= criteria.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return logs, err
+}
+
+func (bl *boltLog) reset() {
+	bl.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(db.LogsCollection)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket([]byte(db.LogsCollection))
+		return err
+	})
+}
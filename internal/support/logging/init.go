@@ -23,11 +23,13 @@ import (
 
 	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
 var Configuration *ConfigurationStruct
 var dbClient persistence
+var dbMonitor *db.HealthMonitor
 var LoggingClient logger.LoggingClient
 var registryClient registry.Client
 var registryErrors chan error        //A channel for "config wait errors" sourced from Registry
@@ -79,12 +81,16 @@ func Init(useRegistry bool) bool {
 		go listenForConfigChanges()
 	}
 
+	dbMonitor = db.NewHealthMonitor(dbClient, LoggingClient)
+	dbMonitor.Start()
+
 	go telemetry.StartCpuUsageAverage()
 
 	return true
 }
 
 func Destruct() {
+	dbMonitor.Stop()
 	if dbClient != nil {
 		dbClient.closeSession()
 		dbClient = nil
@@ -207,6 +213,15 @@ func getPersistence() error {
 	case PersistenceFile:
 		dbClient = &fileLog{filename: Configuration.Logging.File}
 	case PersistenceDB:
+		if Configuration.Databases["Primary"].Type == db.BoltDB {
+			bdb, err := openBolt()
+			if err != nil {
+				return err
+			}
+			dbClient = &boltLog{db: bdb}
+			return nil
+		}
+
 		// TODO: Integrate db layer with internal/pkg/db/ types so we can support other databases
 		ms, err := connectToMongo()
 		if err != nil {
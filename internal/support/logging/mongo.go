@@ -49,6 +49,18 @@ func (ml *mongoLog) closeSession() {
 	}
 }
 
+func (ml *mongoLog) Ping() error {
+	session := ml.session.Copy()
+	defer session.Close()
+	return session.Ping()
+}
+
+// Reconnect drops the connections of the session so that it dials the database again
+func (ml *mongoLog) Reconnect() error {
+	ml.session.Refresh()
+	return nil
+}
+
 func (ml *mongoLog) add(le models.LogEntry) error {
 
 	session := ml.session.Copy()
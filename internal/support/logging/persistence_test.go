@@ -7,9 +7,11 @@
 package logging
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -146,3 +148,36 @@ func TestFileRemove(t *testing.T) {
 	fl := fileLog{filename: testFilename}
 	testPersistenceRemove(t, &fl)
 }
+
+func openTestBolt(t *testing.T) *boltLog {
+	dir, err := ioutil.TempDir("", "logging")
+	if err != nil {
+		t.Fatalf("Error creating directory: %s", err.Error())
+	}
+	Configuration = &ConfigurationStruct{Databases: map[string]config.DatabaseInfo{
+		"Primary": {Host: dir, Name: "logging", Timeout: 5000},
+	}}
+
+	bdb, err := openBolt()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Error opening database: %s", err.Error())
+	}
+	return &boltLog{db: bdb}
+}
+
+func TestBoltFind(t *testing.T) {
+	bl := openTestBolt(t)
+	defer os.RemoveAll(Configuration.Databases["Primary"].Host)
+	defer bl.closeSession()
+
+	testPersistenceFind(t, bl)
+}
+
+func TestBoltRemove(t *testing.T) {
+	bl := openTestBolt(t)
+	defer os.RemoveAll(Configuration.Databases["Primary"].Host)
+	defer bl.closeSession()
+
+	testPersistenceRemove(t, bl)
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/readiness"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
@@ -218,7 +219,13 @@ func delLogs(w http.ResponseWriter, r *http.Request) {
 }
 
 func metricsHandler(w http.ResponseWriter, _ *http.Request) {
-	s := telemetry.NewSystemUsage()
+	s := struct {
+		telemetry.SystemUsage
+		Database *db.Health
+	}{
+		SystemUsage: telemetry.NewSystemUsage(),
+		Database:    dbMonitor.Health(),
+	}
 
 	encode(s, w)
 
@@ -252,6 +259,9 @@ func HttpServer() http.Handler {
 	// Metrics
 	r.HandleFunc(clients.ApiMetricsRoute, metricsHandler).Methods(http.MethodGet)
 
+	// Readiness
+	r.HandleFunc(readiness.Route, readiness.Handler(map[string]readiness.Check{"database": dbMonitor})).Methods(http.MethodGet)
+
 	// Logs
 	r.HandleFunc(clients.ApiLoggingRoute, addLog).Methods(http.MethodPost)
 
@@ -274,6 +284,7 @@ func HttpServer() http.Handler {
 	r.Use(correlation.ManageHeader)
 	r.Use(correlation.OnResponseComplete)
 	r.Use(correlation.OnRequestBegin)
+	r.Use(readiness.Guard(dbMonitor))
 
 	return r
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/edgexfoundry/edgex-go/internal"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/bolt"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/redis"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
@@ -40,6 +41,7 @@ import (
 var Configuration *ConfigurationStruct
 var LoggingClient logger.LoggingClient
 var dbClient interfaces.DBClient
+var dbMonitor *db.HealthMonitor
 var scClient interfaces.SchedulerQueueClient
 var registryClient registry.Client
 var registryErrors chan error        //A channel for "config wait error" sourced from Registry
@@ -108,6 +110,9 @@ func Init(useRegistry bool) bool {
 		go listenForConfigChanges()
 	}
 
+	dbMonitor = db.NewHealthMonitor(dbClient, LoggingClient)
+	dbMonitor.Start()
+
 	go telemetry.StartCpuUsageAverage()
 
 	return true
@@ -126,6 +131,7 @@ func Destruct() {
 		StopTicker()
 	}
 
+	dbMonitor.Stop()
 	if dbClient != nil {
 		dbClient.CloseSession()
 		dbClient = nil
@@ -247,6 +253,14 @@ func connectToDatabase() error {
 		return fmt.Errorf("couldn't create database client: %v", err.Error())
 	}
 
+	primary := Configuration.Databases["Primary"]
+	err = db.MigrateSchema(dbClient, primary.SchemaVersion, primary.MigrationDryRun, LoggingClient)
+	if err != nil {
+		dbClient.CloseSession()
+		dbClient = nil
+		return fmt.Errorf("couldn't migrate database: %v", err.Error())
+	}
+
 	return nil
 }
 
@@ -269,6 +283,13 @@ func newDBClient(dbType string) (interfaces.DBClient, error) {
 			Port: Configuration.Databases["Primary"].Port,
 		}
 		return redis.NewClient(dbConfig) //TODO: Verify this also connects to Redis
+	case db.BoltDB:
+		dbConfig := db.Configuration{
+			Host:         Configuration.Databases["Primary"].Host,
+			Timeout:      Configuration.Databases["Primary"].Timeout,
+			DatabaseName: Configuration.Databases["Primary"].Name,
+		}
+		return bolt.NewClient(dbConfig)
 	default:
 		return nil, db.ErrUnsupportedDatabase
 	}
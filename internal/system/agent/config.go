@@ -23,6 +23,8 @@ type ConfigurationStruct struct {
 	Registry        config.RegistryInfo
 	Logging         config.LoggingInfo
 	FormatSpecifier string
+	// Databases of the services, by service key
+	Databases map[string]config.DatabaseInfo
 }
 
 type WritableInfo struct {
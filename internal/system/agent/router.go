@@ -18,8 +18,10 @@ package agent
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
@@ -27,6 +29,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/backup"
 )
 
 func LoadRestRoutes() *mux.Router {
@@ -41,6 +45,11 @@ func LoadRestRoutes() *mux.Router {
 	// /api/v1/health
 	b.HandleFunc("/health/{services}", healthHandler).Methods(http.MethodGet)
 
+	// Backup Resource
+	// /api/v1/backup
+	b.HandleFunc("/backup", backupHandler).Methods(http.MethodGet)
+	b.HandleFunc("/restore", restoreHandler).Methods(http.MethodPost)
+
 	// Ping Resource
 	// /api/v1/ping
 	b.HandleFunc("/ping", pingHandler).Methods(http.MethodGet)
@@ -175,6 +184,79 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// Stream a snapshot of the databases of the services
+func backupHandler(w http.ResponseWriter, r *http.Request) {
+	dbClients, err := backup.Connect(Configuration.Databases)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer dbClients.Close()
+
+	// The snapshot is streamed, so a failure past this point can only cut it short. The missing
+	// trailer tells the client.
+	w.Header().Set(clients.ContentType, "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=edgex-%d.backup", db.MakeTimestamp()))
+	if err = backup.Backup(w, dbClients); err != nil {
+		LoggingClient.Error("backup failed: " + err.Error())
+		return
+	}
+	LoggingClient.Info("backup of the databases written")
+}
+
+// Add the objects of a snapshot the databases do not hold yet
+func restoreHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// The snapshot is read twice, once to verify it then to restore it
+	f, err := ioutil.TempFile("", "edgex-restore")
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err = io.Copy(f, r.Body); err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = backup.Verify(f); err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dbClients, err := backup.Connect(Configuration.Databases)
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer dbClients.Close()
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		LoggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	summary, err := backup.Restore(f, dbClients)
+	if err != nil {
+		LoggingClient.Error("restore failed: " + err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	LoggingClient.Info("restore of the databases done")
+	encode(summary, w)
+}
+
 // Helper function for encoding things for returning from REST calls
 func encode(i interface{}, w http.ResponseWriter) {
 	w.Header().Add(clients.ContentType, clients.ContentTypeJSON)